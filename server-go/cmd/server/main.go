@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -12,6 +14,7 @@ import (
 	"github.com/shampsdev/go-telegram-template/pkg/config"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest"
 	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
 	"github.com/shampsdev/go-telegram-template/pkg/usecase"
 	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
 )
@@ -33,6 +36,15 @@ func main() {
 	slog.SetDefault(log)
 	log.Info("Hello from GoPadel server!")
 
+	// За обратным прокси без доверенных прокси ClientIP — адрес прокси, и проверка IP отклонит все вебхуки YooKassa
+	if cfg.YooKassa.WebhookIPCheck && len(cfg.Server.TrustedProxies) == 0 {
+		if !cfg.Debug {
+			log.Error("YOOKASSA_WEBHOOK_IP_CHECK is enabled but HTTP_TRUSTED_PROXIES is empty: set the reverse proxy addresses or disable the IP check")
+			os.Exit(1)
+		}
+		log.Warn("YOOKASSA_WEBHOOK_IP_CHECK is enabled but HTTP_TRUSTED_PROXIES is empty: webhooks behind a reverse proxy will be rejected")
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 	ctx = slogx.NewCtx(ctx, log)
@@ -45,19 +57,79 @@ func main() {
 	}
 	defer pool.Close()
 
-	cases := usecase.Setup(ctx, cfg, pool)
-	
-	var notificationService *notifications.NotificationService
+	var natsClient *notifications.NATSClient
 	natsConn, err := cfg.ConnectNATS()
 	if err != nil {
 		log.Error("Failed to connect to NATS", "error", err)
-		notificationService = nil
 	} else {
-		natsClient := notifications.NewNATSClient(natsConn, "tasks.active", cfg.Logger())
-		notificationService = notifications.NewNotificationService(natsClient)
+		client, err := notifications.NewNATSClient(ctx, natsConn, notifications.NATSStreamConfig{
+			Stream:               cfg.NATS.Stream,
+			StreamSubjects:       cfg.NATS.StreamSubjects,
+			Subject:              cfg.NATS.TasksSubject,
+			ControlSubject:       cfg.NATS.ControlSubject,
+			RequestSubjectPrefix: cfg.NATS.RequestSubjectPrefix,
+			PublishTimeout:       cfg.NATS.PublishTimeout,
+		}, cfg.Logger())
+		if err != nil {
+			log.Error("Failed to set up NATS JetStream", "error", err)
+			natsConn.Close()
+		} else {
+			natsClient = client
+		}
+	}
+
+	// Задачи воркера копятся в outbox и без NATS, relay опубликует их, когда сервер запустится с NATS
+	outboxRepo := pg.NewOutboxRepo(pool)
+	notificationService := notifications.NewNotificationService(natsClient, outboxRepo)
+	if natsClient != nil {
+		relay := notifications.NewOutboxRelay(outboxRepo, natsClient, notifications.OutboxRelayConfig{
+			Interval:       cfg.Outbox.RelayInterval,
+			BatchSize:      cfg.Outbox.BatchSize,
+			RetryBaseDelay: cfg.Outbox.RetryBaseDelay,
+			RetryMaxDelay:  cfg.Outbox.RetryMaxDelay,
+			Retention:      cfg.Outbox.Retention,
+		})
+		go relay.Run(ctx)
+	}
+
+	cases := usecase.Setup(ctx, cfg, pool, notificationService)
+
+	// Фоновые задачи запускаются только на сервере: usecase.Setup вызывается еще и в боте
+	go cases.Payment.RunReconciler(ctx)
+	go cases.Loyalty.RunRecalculator(ctx)
+	go cases.EventSeries.RunMaterializer(ctx)
+
+	// Воркер сообщает об истекших предложениях мест из листа ожидания
+	if natsClient != nil {
+		sub, err := natsClient.HandleRequests(ctx, notifications.RequestWaitlistOfferExpire, func(ctx context.Context, data []byte) error {
+			var request notifications.WaitlistOfferExpireRequest
+			if err := json.Unmarshal(data, &request); err != nil {
+				return fmt.Errorf("invalid waitlist offer expire request: %w", err)
+			}
+			return cases.WaitlistOffer.Expire(ctx, request.OfferID)
+		})
+		if err != nil {
+			log.Error("Failed to subscribe to worker requests", "error", err)
+		} else {
+			defer sub.Unsubscribe()
+		}
+
+		// Срок оплаты регистрации истек, регистрация отменяется через тот же usecase, что и ручная отмена
+		sub, err = natsClient.HandleRequests(ctx, notifications.RequestRegistrationPaymentExpire, func(ctx context.Context, data []byte) error {
+			var request notifications.RegistrationPaymentExpireRequest
+			if err := json.Unmarshal(data, &request); err != nil {
+				return fmt.Errorf("invalid registration payment expire request: %w", err)
+			}
+			return cases.Registration.ExpireUnpaidRegistration(ctx, request.UserTelegramID, request.EventID)
+		})
+		if err != nil {
+			log.Error("Failed to subscribe to worker requests", "error", err)
+		} else {
+			defer sub.Unsubscribe()
+		}
 	}
 
-	s := rest.NewServer(ctx, cfg, cases, notificationService)
+	s := rest.NewServer(ctx, cfg, cases)
 	if err := s.Run(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		slogx.WithErr(log, err).Error("error during server shutdown")
 	}
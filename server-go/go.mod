@@ -12,11 +12,11 @@ require (
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/lmittmann/tint v1.1.2
 	github.com/nats-io/nats.go v1.43.0
-	github.com/rvinnie/yookassa-sdk-go v0.0.0-20250216122401-4440061bd0ca
 	github.com/swaggo/files v1.0.1
 	github.com/swaggo/gin-swagger v1.6.0
 	github.com/swaggo/swag v1.16.4
 	golang.org/x/sync v0.15.0
+	gopadel/taskcontract v0.0.0
 )
 
 require (
@@ -86,3 +86,5 @@ require (
 	google.golang.org/protobuf v1.34.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace gopadel/taskcontract => ../taskcontract
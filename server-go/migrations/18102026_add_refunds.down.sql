@@ -0,0 +1,6 @@
+-- Удаляем таблицу возвратов
+DROP TRIGGER IF EXISTS update_refunds_updated_at ON refunds;
+DROP TABLE IF EXISTS refunds;
+
+-- Удаляем политику возвратов у событий
+ALTER TABLE "event" DROP COLUMN IF EXISTS refund_policy;
@@ -0,0 +1,27 @@
+-- Политика возвратов для события (окна возврата в процентах)
+ALTER TABLE "event" ADD COLUMN refund_policy JSONB;
+
+COMMENT ON COLUMN "event"."refund_policy" IS 'JSONB поле с окнами возврата: процент возврата в зависимости от времени до начала события';
+
+-- Таблица возвратов по платежам
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    refund_id VARCHAR(255) NOT NULL,
+    payment_id UUID NOT NULL,
+    amount INTEGER NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT ck_refunds_status CHECK (status IN ('pending', 'succeeded', 'canceled')),
+    CONSTRAINT ck_refunds_amount CHECK (amount > 0),
+    CONSTRAINT uq_refunds_refund_id UNIQUE (refund_id),
+    CONSTRAINT fk_refunds_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id);
+
+-- Создаем trigger для обновления updated_at в refunds
+CREATE TRIGGER update_refunds_updated_at
+    BEFORE UPDATE ON refunds
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
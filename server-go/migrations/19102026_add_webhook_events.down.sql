@@ -0,0 +1,3 @@
+-- Удаляем журнал вебхуков
+DROP TRIGGER IF EXISTS update_webhook_events_updated_at ON webhook_events;
+DROP TABLE IF EXISTS webhook_events;
@@ -0,0 +1,22 @@
+-- Журнал входящих вебхуков платежного провайдера
+-- Ключ (event, object_id) гарантирует однократную обработку каждого уведомления
+CREATE TABLE webhook_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event VARCHAR(100) NOT NULL,
+    object_id VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    payload JSONB,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT,
+    processed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_webhook_events_event_object UNIQUE (event, object_id)
+);
+
+CREATE INDEX idx_webhook_events_object_id ON webhook_events(object_id);
+
+-- Создаем trigger для обновления updated_at в webhook_events
+CREATE TRIGGER update_webhook_events_updated_at
+    BEFORE UPDATE ON webhook_events
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
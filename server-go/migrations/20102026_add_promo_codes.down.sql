@@ -0,0 +1,9 @@
+-- Удаляем промокоды из платежей
+DROP INDEX IF EXISTS idx_payments_promo_code_id;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS fk_payments_promo_code_id;
+ALTER TABLE payments DROP COLUMN IF EXISTS original_amount;
+ALTER TABLE payments DROP COLUMN IF EXISTS promo_code_id;
+
+-- Удаляем таблицу промокодов
+DROP TRIGGER IF EXISTS update_promo_codes_updated_at ON promo_codes;
+DROP TABLE IF EXISTS promo_codes;
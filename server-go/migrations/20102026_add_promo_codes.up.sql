@@ -0,0 +1,37 @@
+-- Промокоды
+CREATE TABLE promo_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(64) NOT NULL,
+    discount_type VARCHAR(20) NOT NULL,
+    discount_value INTEGER NOT NULL,
+    max_uses INTEGER,
+    max_uses_per_user INTEGER,
+    valid_from TIMESTAMP,
+    valid_until TIMESTAMP,
+    event_types VARCHAR(50)[],
+    club_ids VARCHAR(255)[],
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT ck_promo_codes_discount_type CHECK (discount_type IN ('percent', 'fixed')),
+    CONSTRAINT ck_promo_codes_discount_value CHECK (discount_value > 0),
+    CONSTRAINT ck_promo_codes_percent CHECK (discount_type <> 'percent' OR discount_value <= 100),
+    CONSTRAINT ck_promo_codes_max_uses CHECK (max_uses IS NULL OR max_uses > 0),
+    CONSTRAINT ck_promo_codes_max_uses_per_user CHECK (max_uses_per_user IS NULL OR max_uses_per_user > 0)
+);
+
+-- Код уникален без учета регистра
+CREATE UNIQUE INDEX uq_promo_codes_code ON promo_codes (UPPER(code));
+
+-- Создаем trigger для обновления updated_at в promo_codes
+CREATE TRIGGER update_promo_codes_updated_at
+    BEFORE UPDATE ON promo_codes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Примененный промокод и исходная цена в платеже
+ALTER TABLE payments ADD COLUMN promo_code_id UUID;
+ALTER TABLE payments ADD COLUMN original_amount INTEGER;
+ALTER TABLE payments ADD CONSTRAINT fk_payments_promo_code_id
+    FOREIGN KEY (promo_code_id) REFERENCES promo_codes(id);
+
+CREATE INDEX idx_payments_promo_code_id ON payments(promo_code_id);
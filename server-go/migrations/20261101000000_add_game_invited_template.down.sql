@@ -0,0 +1 @@
+DELETE FROM message_templates WHERE key = 'game.registration.invited';
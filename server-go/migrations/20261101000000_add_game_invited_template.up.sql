@@ -0,0 +1,5 @@
+-- Уведомление организатору игры о новой заявке, под ним бот показывает кнопки «Принять» и «Отклонить»
+INSERT INTO message_templates (key, language, body, parse_mode, description) VALUES
+    ('game.registration.invited', 'ru', $$Новая заявка на игру "{{html .event_name}}" от {{html .user_name}}.
+Примите или отклоните ее кнопками ниже или на <a href="{{.event_url}}">странице игры</a>.$$, 'HTML', 'Заявка на игру для организатора')
+ON CONFLICT (key, language) DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM message_templates WHERE key IN ('waitlist.offer', 'waitlist.offer.expired');
+
+DROP TABLE IF EXISTS waitlist_offers;
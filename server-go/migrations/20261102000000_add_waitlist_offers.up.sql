@@ -0,0 +1,28 @@
+-- Предложения освободившегося места из листа ожидания. Пока предложение в pending, место удерживается
+-- за пользователем; по истечении expires_at воркер просит сервер закрыть его и предложить место следующему
+CREATE TABLE waitlist_offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_waitlist_offers_event_id FOREIGN KEY (event_id) REFERENCES "event"(id) ON DELETE CASCADE,
+    CONSTRAINT fk_waitlist_offers_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT ck_waitlist_offers_status CHECK (status IN ('pending', 'accepted', 'declined', 'expired', 'cancelled'))
+);
+
+-- Одновременно у пользователя может быть только одно активное предложение на событие
+CREATE UNIQUE INDEX uq_waitlist_offers_pending ON waitlist_offers(event_id, user_id) WHERE status = 'pending';
+CREATE INDEX idx_waitlist_offers_event_status ON waitlist_offers(event_id, status);
+
+CREATE TRIGGER update_waitlist_offers_updated_at
+    BEFORE UPDATE ON waitlist_offers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO message_templates (key, language, body, parse_mode, description) VALUES
+    ('waitlist.offer', 'ru', $$Освободилось место на "{{html .event_name}}"!
+Мы держим его за вами до {{.expires_at}}. Подтвердите участие кнопкой ниже или откажитесь, чтобы место досталось следующему в листе ожидания.$$, 'HTML', 'Предложение места из листа ожидания'),
+    ('waitlist.offer.expired', 'ru', $$Время на ответ истекло, место на "{{html .event_name}}" предложено следующему в листе ожидания.$$, 'HTML', 'Предложение места истекло')
+ON CONFLICT (key, language) DO NOTHING;
@@ -0,0 +1,17 @@
+-- Задачи воркера, записанные в одной транзакции с изменением регистрации, платежа или рассылки.
+-- Relay сервера публикует их в NATS JetStream и отмечает sent_at; id служит message id для дедупликации
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_type VARCHAR(100) NOT NULL,
+    execute_at TIMESTAMP NOT NULL,
+    data JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    -- clock_timestamp, а не NOW(): задачи одной транзакции публикуются в порядке записи
+    created_at TIMESTAMP NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(next_attempt_at) WHERE sent_at IS NULL;
+CREATE INDEX idx_outbox_sent_at ON outbox(sent_at) WHERE sent_at IS NOT NULL;
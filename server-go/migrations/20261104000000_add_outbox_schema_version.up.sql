@@ -0,0 +1,2 @@
+-- Версия данных задачи из контракта taskcontract, воркер разбирает данные по ней
+ALTER TABLE outbox ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Часовой пояс корта (IANA), в нем воркер показывает время начала события в уведомлениях
+ALTER TABLE courts ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Europe/Moscow';
@@ -0,0 +1,13 @@
+DELETE FROM message_templates WHERE key IN (
+    'event.registration.confirmed',
+    'event.reminder.48hours',
+    'event.reminder.24hours',
+    'event.registration.canceled',
+    'event.registration.approved',
+    'event.registration.rejected'
+);
+
+UPDATE message_templates
+SET key = 'game.registration.invited',
+    body = replace(body, '.applicant_name', '.user_name')
+WHERE key = 'event.registration.requested';
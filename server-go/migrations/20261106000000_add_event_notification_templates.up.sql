@@ -0,0 +1,45 @@
+-- Заявку на игру организатору теперь отправляет воркер задачей event.registration.requested,
+-- имя участника приходит в applicant_name
+UPDATE message_templates
+SET key = 'event.registration.requested',
+    body = replace(body, '.user_name', '.applicant_name')
+WHERE key = 'game.registration.invited';
+
+-- Уведомления воркера о регистрации на игру, турнир или тренировку. Время начала дано в часовом поясе корта
+INSERT INTO message_templates (key, language, body, parse_mode, description) VALUES
+    ('event.registration.confirmed', 'ru', $$🎉 Вы зарегистрированы на {{if eq .event_type "game"}}игру{{else if eq .event_type "training"}}тренировку{{else}}турнир{{end}} "{{html .event_name}}"!
+
+📅 {{.start_time_local}}{{if .court_name}}
+📍 {{html .court_name}}{{if .court_address}}, {{html .court_address}}{{end}}{{end}}
+{{if .is_paid}}✅ Ваше место забронировано{{else}}💡 Не забудьте оплатить участие{{end}}
+
+<a href="{{.event_url}}">Открыть в приложении</a>$$, 'HTML', 'Регистрация на событие'),
+    ('event.reminder.48hours', 'ru', $$🎾 Через 2 дня {{if eq .event_type "game"}}игра{{else if eq .event_type "training"}}тренировка{{else}}турнир{{end}} "{{html .event_name}}"!
+
+📅 {{.start_time_local}}{{if .court_name}}
+📍 {{html .court_name}}{{if .court_address}}, {{html .court_address}}{{end}}{{end}}
+{{if .is_paid}}✅ Все готово к вашему участию{{else}}⏰ Ваша регистрация пока не оплачена, завершите оплату, чтобы сохранить место{{end}}
+
+<a href="{{.event_url}}">Открыть в приложении</a>$$, 'HTML', 'Напоминание за 2 дня до события'),
+    ('event.reminder.24hours', 'ru', $$🔥 Завтра {{if eq .event_type "game"}}игра{{else if eq .event_type "training"}}тренировка{{else}}турнир{{end}} "{{html .event_name}}"!
+
+📅 {{.start_time_local}}{{if .court_name}}
+📍 {{html .court_name}}{{if .court_address}}, {{html .court_address}}{{end}}{{end}}
+{{if .is_paid}}🏓 Не забудьте прийти вовремя{{else}}🚨 Срочно завершите оплату, без нее регистрация будет отменена{{end}}
+
+<a href="{{.event_url}}">Открыть в приложении</a>$$, 'HTML', 'Напоминание за день до события'),
+    ('event.registration.canceled', 'ru', $$😔 Ваша регистрация на {{if eq .event_type "game"}}игру{{else if eq .event_type "training"}}тренировку{{else}}турнир{{end}} "{{html .event_name}}" ({{.start_time_local}}) отменена.
+
+🔄 Вы можете зарегистрироваться снова, если есть свободные места.
+
+<a href="{{.event_url}}">Открыть в приложении</a>$$, 'HTML', 'Отмена регистрации на событие'),
+    ('event.registration.approved', 'ru', $$✅ Организатор принял вашу заявку на игру "{{html .event_name}}"!
+
+📅 {{.start_time_local}}{{if .court_name}}
+📍 {{html .court_name}}{{if .court_address}}, {{html .court_address}}{{end}}{{end}}
+
+<a href="{{.event_url}}">Открыть в приложении</a>$$, 'HTML', 'Заявка на игру принята'),
+    ('event.registration.rejected', 'ru', $$Организатор отклонил вашу заявку на игру "{{html .event_name}}" ({{.start_time_local}}).
+
+Посмотрите другие игры в <a href="{{.event_url}}">приложении</a>.$$, 'HTML', 'Заявка на игру отклонена')
+ON CONFLICT (key, language) DO NOTHING;
@@ -0,0 +1,4 @@
+DELETE FROM message_templates WHERE key = 'event.payment.expired';
+
+ALTER TABLE "event" DROP CONSTRAINT IF EXISTS ck_event_payment_deadline_hours;
+ALTER TABLE "event" DROP COLUMN IF EXISTS payment_deadline_hours;
@@ -0,0 +1,17 @@
+-- Срок оплаты регистрации на событие в часах. NULL — срок из настроек сервера (PAYMENT_REGISTRATION_DEADLINE),
+-- 0 — неоплаченная регистрация не отменяется
+ALTER TABLE "event" ADD COLUMN payment_deadline_hours INTEGER;
+ALTER TABLE "event" ADD CONSTRAINT ck_event_payment_deadline_hours CHECK (payment_deadline_hours >= 0);
+
+COMMENT ON COLUMN "event"."payment_deadline_hours" IS 'Сколько часов после регистрации дается на оплату, потом неоплаченная регистрация отменяется';
+
+-- Уведомление об отмене регистрации, которую не оплатили в срок. Регистрацию отменяет сервер по задаче воркера
+INSERT INTO message_templates (key, language, body, parse_mode, description) VALUES
+    ('event.payment.expired', 'ru', $$⏰ Время на оплату вышло
+
+Ваша регистрация на {{if eq .event_type "game"}}игру{{else if eq .event_type "training"}}тренировку{{else}}турнир{{end}} "{{html .event_name}}" ({{.start_time_local}}) отменена, потому что участие не было оплачено вовремя.
+
+🔄 Если есть свободные места, вы можете зарегистрироваться снова.
+
+<a href="{{.event_url}}">Открыть в приложении</a>$$, 'HTML', 'Отмена неоплаченной регистрации на событие')
+ON CONFLICT (key, language) DO NOTHING;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_refunds_unsent;
+
+-- Возвраты, которые провайдер так и не принял, без ID провайдера не сохранить
+DELETE FROM refunds WHERE refund_id IS NULL;
+
+ALTER TABLE refunds DROP CONSTRAINT ck_refunds_status;
+ALTER TABLE refunds ADD CONSTRAINT ck_refunds_status CHECK (status IN ('pending', 'succeeded', 'canceled'));
+
+ALTER TABLE refunds ALTER COLUMN refund_id SET NOT NULL;
@@ -0,0 +1,8 @@
+-- Возврат сохраняется до запроса к провайдеру: ID провайдера появляется, когда провайдер принял возврат.
+-- Непринятый возврат помечается failed и повторяется при сверке платежей
+ALTER TABLE refunds ALTER COLUMN refund_id DROP NOT NULL;
+
+ALTER TABLE refunds DROP CONSTRAINT ck_refunds_status;
+ALTER TABLE refunds ADD CONSTRAINT ck_refunds_status CHECK (status IN ('pending', 'succeeded', 'canceled', 'failed'));
+
+CREATE INDEX idx_refunds_unsent ON refunds(created_at) WHERE refund_id IS NULL;
@@ -0,0 +1,4 @@
+-- Возвращаем обнуление ссылки на удаленный матч
+ALTER TABLE rating_history DROP CONSTRAINT fk_rating_history_match_id;
+ALTER TABLE rating_history ADD CONSTRAINT fk_rating_history_match_id
+    FOREIGN KEY (match_id) REFERENCES tournament_matches(id) ON DELETE SET NULL;
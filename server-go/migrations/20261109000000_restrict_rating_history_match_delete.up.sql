@@ -0,0 +1,5 @@
+-- Сыгранный матч нельзя удалить, пока по нему есть изменения ранга: при удалении турнира изменения
+-- сначала откатываются у игроков, иначе ранг расходился бы с историей
+ALTER TABLE rating_history DROP CONSTRAINT fk_rating_history_match_id;
+ALTER TABLE rating_history ADD CONSTRAINT fk_rating_history_match_id
+    FOREIGN KEY (match_id) REFERENCES tournament_matches(id) ON DELETE RESTRICT;
@@ -0,0 +1,3 @@
+ALTER TABLE broadcast_recipients DROP COLUMN IF EXISTS not_before;
+ALTER TABLE broadcast_recipients DROP COLUMN IF EXISTS attempted_at;
+ALTER TABLE broadcast_recipients DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,7 @@
+-- Получатели берутся в отправку пачкой с арендой до locked_until. Если воркер упал,
+-- получатели с истекшей арендой, которым сообщение еще не отправлялось (attempted_at пуст), отправляются снова,
+-- а те, кому отправка уже начиналась, помечаются failed, чтобы не было дублей.
+-- not_before — получатель отложен до конца своих тихих часов
+ALTER TABLE broadcast_recipients ADD COLUMN locked_until TIMESTAMP;
+ALTER TABLE broadcast_recipients ADD COLUMN attempted_at TIMESTAMP;
+ALTER TABLE broadcast_recipients ADD COLUMN not_before TIMESTAMP;
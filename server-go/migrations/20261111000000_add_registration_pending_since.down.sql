@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS set_registration_pending_since ON registrations;
+DROP FUNCTION IF EXISTS set_registration_pending_since();
+ALTER TABLE registrations DROP COLUMN IF EXISTS pending_since;
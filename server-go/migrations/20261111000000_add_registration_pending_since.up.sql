@@ -0,0 +1,22 @@
+-- Срок оплаты отсчитывается от перехода регистрации в PENDING. updated_at для этого не подходит:
+-- триггер двигает его при любом изменении регистрации, и срок оплаты незаметно продлевался бы.
+-- pending_since выставляет триггер при любом переходе в PENDING и очищает при выходе из него
+ALTER TABLE registrations ADD COLUMN pending_since TIMESTAMP;
+
+UPDATE registrations SET pending_since = updated_at WHERE status = 'PENDING';
+
+CREATE OR REPLACE FUNCTION set_registration_pending_since()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status <> 'PENDING' THEN
+        NEW.pending_since = NULL;
+    ELSIF TG_OP = 'INSERT' OR OLD.status IS DISTINCT FROM 'PENDING' THEN
+        NEW.pending_since = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_registration_pending_since
+    BEFORE INSERT OR UPDATE ON registrations
+    FOR EACH ROW EXECUTE FUNCTION set_registration_pending_since();
@@ -0,0 +1,5 @@
+-- Удаляем признак ручного уровня лояльности
+ALTER TABLE users DROP COLUMN IF EXISTS loyalty_locked;
+
+-- Удаляем условия уровней лояльности
+ALTER TABLE loyalties DROP COLUMN IF EXISTS rules;
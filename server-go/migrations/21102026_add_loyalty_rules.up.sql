@@ -0,0 +1,5 @@
+-- Машиночитаемые условия уровня лояльности для автоматического пересчета
+ALTER TABLE loyalties ADD COLUMN rules JSONB;
+
+-- Уровень, назначенный администратором вручную, не меняется автоматическим пересчетом
+ALTER TABLE users ADD COLUMN loyalty_locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+-- Удаляем турнирные таблицы
+DROP TRIGGER IF EXISTS update_tournament_matches_updated_at ON tournament_matches;
+DROP TRIGGER IF EXISTS update_tournaments_updated_at ON tournaments;
+DROP TABLE IF EXISTS tournament_matches;
+DROP TABLE IF EXISTS tournament_teams;
+DROP TABLE IF EXISTS tournaments;
@@ -0,0 +1,65 @@
+-- Турнирная сетка события
+CREATE TABLE tournaments (
+    event_id VARCHAR(255) PRIMARY KEY,
+    format VARCHAR(30) NOT NULL,
+    courts INTEGER NOT NULL,
+    points_per_match INTEGER NOT NULL DEFAULT 0,
+    rounds INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_tournaments_event_id FOREIGN KEY (event_id) REFERENCES "event"(id) ON DELETE CASCADE,
+    CONSTRAINT ck_tournaments_format CHECK (format IN ('americano', 'mexicano', 'round_robin', 'single_elimination')),
+    CONSTRAINT ck_tournaments_status CHECK (status IN ('in_progress', 'completed')),
+    CONSTRAINT ck_tournaments_courts CHECK (courts > 0)
+);
+
+-- Постоянные пары турнира
+CREATE TABLE tournament_teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    seed INTEGER NOT NULL,
+    player_ids UUID[] NOT NULL,
+    CONSTRAINT fk_tournament_teams_event_id FOREIGN KEY (event_id) REFERENCES tournaments(event_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tournament_teams_event_id ON tournament_teams(event_id);
+
+-- Матчи турнира
+CREATE TABLE tournament_matches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id VARCHAR(255) NOT NULL,
+    round INTEGER NOT NULL,
+    court INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    team1_id UUID,
+    team2_id UUID,
+    team1_players UUID[] NOT NULL DEFAULT '{}',
+    team2_players UUID[] NOT NULL DEFAULT '{}',
+    score1 INTEGER,
+    score2 INTEGER,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    next_match_id UUID,
+    next_match_slot SMALLINT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_tournament_matches_event_id FOREIGN KEY (event_id) REFERENCES tournaments(event_id) ON DELETE CASCADE,
+    CONSTRAINT fk_tournament_matches_team1_id FOREIGN KEY (team1_id) REFERENCES tournament_teams(id) ON DELETE CASCADE,
+    CONSTRAINT fk_tournament_matches_team2_id FOREIGN KEY (team2_id) REFERENCES tournament_teams(id) ON DELETE CASCADE,
+    CONSTRAINT fk_tournament_matches_next_match_id FOREIGN KEY (next_match_id) REFERENCES tournament_matches(id) ON DELETE SET NULL,
+    CONSTRAINT ck_tournament_matches_status CHECK (status IN ('scheduled', 'completed')),
+    CONSTRAINT ck_tournament_matches_next_match_slot CHECK (next_match_slot IS NULL OR next_match_slot IN (1, 2)),
+    CONSTRAINT ck_tournament_matches_scores CHECK ((score1 IS NULL OR score1 >= 0) AND (score2 IS NULL OR score2 >= 0))
+);
+
+CREATE INDEX idx_tournament_matches_event_id ON tournament_matches(event_id, round, position);
+
+-- Создаем triggers для обновления updated_at
+CREATE TRIGGER update_tournaments_updated_at
+    BEFORE UPDATE ON tournaments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_tournament_matches_updated_at
+    BEFORE UPDATE ON tournament_matches
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,5 @@
+-- Удаляем счетчик рейтинговых матчей
+ALTER TABLE users DROP COLUMN IF EXISTS rated_matches;
+
+-- Удаляем историю ранга
+DROP TABLE IF EXISTS rating_history;
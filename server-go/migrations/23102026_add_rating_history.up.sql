@@ -0,0 +1,21 @@
+-- История изменения ранга игроков: пересчет по результатам матчей и ручные правки администратора
+CREATE TABLE rating_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    event_id VARCHAR(255),
+    match_id UUID,
+    old_rank DOUBLE PRECISION NOT NULL,
+    new_rank DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_rating_history_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_rating_history_event_id FOREIGN KEY (event_id) REFERENCES "event"(id) ON DELETE SET NULL,
+    CONSTRAINT fk_rating_history_match_id FOREIGN KEY (match_id) REFERENCES tournament_matches(id) ON DELETE SET NULL,
+    CONSTRAINT ck_rating_history_source CHECK (source IN ('match', 'admin'))
+);
+
+CREATE INDEX idx_rating_history_user_id ON rating_history(user_id, created_at);
+CREATE INDEX idx_rating_history_match_id ON rating_history(match_id);
+
+-- Количество матчей, по которым пересчитан ранг. Пока он больше нуля, пользователь не может менять ранг сам
+ALTER TABLE users ADD COLUMN rated_matches INTEGER NOT NULL DEFAULT 0;
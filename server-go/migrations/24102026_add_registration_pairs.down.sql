@@ -0,0 +1,7 @@
+-- Удаляем связь листа ожидания с парами
+ALTER TABLE waitlists DROP CONSTRAINT IF EXISTS fk_waitlists_pair_id;
+ALTER TABLE waitlists DROP COLUMN IF EXISTS pair_id;
+
+-- Удаляем пары
+DROP TRIGGER IF EXISTS update_registration_pairs_updated_at ON registration_pairs;
+DROP TABLE IF EXISTS registration_pairs;
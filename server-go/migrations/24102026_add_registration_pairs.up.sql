@@ -0,0 +1,31 @@
+-- Пары игроков, регистрирующихся на турнир вместе
+CREATE TABLE registration_pairs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id VARCHAR(255) NOT NULL,
+    captain_id UUID NOT NULL,
+    partner_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'invited',
+    payment_mode VARCHAR(20) NOT NULL DEFAULT 'split',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_registration_pairs_event_id FOREIGN KEY (event_id) REFERENCES "event"(id) ON DELETE CASCADE,
+    CONSTRAINT fk_registration_pairs_captain_id FOREIGN KEY (captain_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_registration_pairs_partner_id FOREIGN KEY (partner_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT ck_registration_pairs_status CHECK (status IN ('invited', 'registered', 'waitlisted', 'declined', 'cancelled')),
+    CONSTRAINT ck_registration_pairs_payment_mode CHECK (payment_mode IN ('split', 'captain')),
+    CONSTRAINT ck_registration_pairs_different_users CHECK (captain_id <> partner_id)
+);
+
+-- Пользователь может быть капитаном или партнером только одной активной пары на событие
+CREATE UNIQUE INDEX idx_registration_pairs_active_captain ON registration_pairs(event_id, captain_id)
+    WHERE status IN ('invited', 'registered', 'waitlisted');
+CREATE UNIQUE INDEX idx_registration_pairs_active_partner ON registration_pairs(event_id, partner_id)
+    WHERE status IN ('invited', 'registered', 'waitlisted');
+
+CREATE TRIGGER update_registration_pairs_updated_at
+    BEFORE UPDATE ON registration_pairs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Пара в листе ожидания занимает одну запись капитана и регистрируется целиком
+ALTER TABLE waitlists ADD COLUMN pair_id UUID;
+ALTER TABLE waitlists ADD CONSTRAINT fk_waitlists_pair_id FOREIGN KEY (pair_id) REFERENCES registration_pairs(id) ON DELETE CASCADE;
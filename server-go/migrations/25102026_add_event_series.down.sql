@@ -0,0 +1,9 @@
+-- Удаляем связь событий с сериями
+DROP INDEX IF EXISTS idx_event_series_occurrence;
+ALTER TABLE "event" DROP CONSTRAINT IF EXISTS fk_event_series_id;
+ALTER TABLE "event" DROP COLUMN IF EXISTS series_occurrence;
+ALTER TABLE "event" DROP COLUMN IF EXISTS series_id;
+
+-- Удаляем серии
+DROP TRIGGER IF EXISTS update_event_series_updated_at ON event_series;
+DROP TABLE IF EXISTS event_series;
@@ -0,0 +1,25 @@
+-- Серии повторяющихся событий: шаблон события и правило повторения
+CREATE TABLE event_series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template JSONB NOT NULL,
+    rule JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    -- До какого момента уже созданы занятия серии
+    materialized_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT ck_event_series_status CHECK (status IN ('active', 'cancelled'))
+);
+
+CREATE INDEX idx_event_series_status ON event_series(status);
+
+CREATE TRIGGER update_event_series_updated_at
+    BEFORE UPDATE ON event_series
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Занятие серии помнит исходное время по правилу, даже если его перенесли вручную
+ALTER TABLE "event" ADD COLUMN series_id UUID;
+ALTER TABLE "event" ADD COLUMN series_occurrence TIMESTAMP;
+ALTER TABLE "event" ADD CONSTRAINT fk_event_series_id FOREIGN KEY (series_id) REFERENCES event_series(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX idx_event_series_occurrence ON "event"(series_id, series_occurrence) WHERE series_id IS NOT NULL;
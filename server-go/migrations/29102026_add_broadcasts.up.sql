@@ -0,0 +1,46 @@
+-- Рассылки админов: сообщение и целевая аудитория, получатели фиксируются при создании.
+-- Отправляет воркер по задаче broadcast.send
+CREATE TABLE broadcasts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message JSONB NOT NULL,
+    target JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    scheduled_at TIMESTAMP NOT NULL,
+    created_by UUID,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_broadcasts_created_by FOREIGN KEY (created_by) REFERENCES admin_users(id) ON DELETE SET NULL,
+    CONSTRAINT ck_broadcasts_status CHECK (status IN ('scheduled', 'sending', 'completed', 'cancelled'))
+);
+
+CREATE INDEX idx_broadcasts_status ON broadcasts(status);
+
+CREATE TRIGGER update_broadcasts_updated_at
+    BEFORE UPDATE ON broadcasts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Статус доставки для каждого получателя. sending — сообщение взято воркером в отправку;
+-- если воркер упал, такой получатель не отправляется повторно, чтобы не было дублей
+CREATE TABLE broadcast_recipients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    broadcast_id UUID NOT NULL,
+    user_id UUID,
+    telegram_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_broadcast_recipients_broadcast_id FOREIGN KEY (broadcast_id) REFERENCES broadcasts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_broadcast_recipients_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT ck_broadcast_recipients_status CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'blocked')),
+    CONSTRAINT uq_broadcast_recipients_telegram_id UNIQUE (broadcast_id, telegram_id)
+);
+
+CREATE INDEX idx_broadcast_recipients_status ON broadcast_recipients(broadcast_id, status);
+
+CREATE TRIGGER update_broadcast_recipients_updated_at
+    BEFORE UPDATE ON broadcast_recipients
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS message_templates;
+
+ALTER TABLE users DROP COLUMN IF EXISTS language_code;
@@ -0,0 +1,141 @@
+-- Язык пользователя из Telegram (language_code), по нему выбирается вариант шаблона уведомления
+ALTER TABLE users ADD COLUMN language_code VARCHAR(16);
+
+-- Шаблоны уведомлений (text/template). key — тип задачи воркера или уведомления сервера,
+-- language — код языка Telegram (ru, en, pt-br). Если варианта для языка пользователя нет,
+-- берется базовый язык (pt для pt-br), затем язык по умолчанию
+CREATE TABLE message_templates (
+    key VARCHAR(100) NOT NULL,
+    language VARCHAR(16) NOT NULL,
+    body TEXT NOT NULL,
+    parse_mode VARCHAR(20),
+    description TEXT NOT NULL DEFAULT '',
+    updated_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (key, language),
+    CONSTRAINT fk_message_templates_updated_by FOREIGN KEY (updated_by) REFERENCES admin_users(id) ON DELETE SET NULL,
+    CONSTRAINT ck_message_templates_parse_mode CHECK (parse_mode IN ('HTML', 'MarkdownV2'))
+);
+
+CREATE TRIGGER update_message_templates_updated_at
+    BEFORE UPDATE ON message_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Тексты, которые раньше были зашиты в код
+INSERT INTO message_templates (key, language, body, parse_mode, description) VALUES
+    ('tournament.registration.success', 'ru', $${{if .is_free}}🎉 Поздравляем! Вы успешно зарегистрированы на бесплатный турнир '{{.tournament_name}}'!
+
+✅ Ваше место забронировано
+🆓 Участие абсолютно бесплатное
+📅 Не забудьте прийти вовремя
+
+До встречи на корте! 🏓
+
+{{.tournament_url}}{{else}}🎉 Поздравляем! Вы успешно зарегистрированы на турнир '{{.tournament_name}}'!
+
+✅ Ваше место забронировано
+💡 Не забудьте оплатить участие в течение 24 часов
+
+До встречи на корте! 🏓
+
+{{.tournament_url}}{{end}}$$, NULL, 'Регистрация на турнир'),
+    ('tournament.reminder.48hours', 'ru', $${{if .is_paid}}🎾 Скоро большая игра!
+
+Через 2 дня стартует турнир '{{.tournament_name}}'!
+
+✅ Все готово к вашему участию
+🏓 Готовьте ракетки и настройтесь на победу!
+
+Увидимся на корте! 💪
+
+{{.tournament_url}}{{else}}⚡️ Внимание! Осталось 2 дня до турнира '{{.tournament_name}}'
+
+⏰ Ваша регистрация пока не оплачена
+💳 Завершите оплату прямо сейчас, чтобы гарантированно участвовать в турнире
+
+👆 Нажмите на ссылку ниже:
+
+{{.tournament_url}}{{end}}$$, NULL, 'Напоминание за 2 дня до турнира'),
+    ('tournament.reminder.24hours', 'ru', $${{if .is_paid}}🔥 Завтра ваш турнир!
+
+Турнир '{{.tournament_name}}' стартует уже завтра!
+
+🎯 Вы готовы показать свою лучшую игру
+⏰ Не забудьте прийти вовремя
+🏓 Возьмите ракетку и хорошее настроение!
+
+Удачи на турнире! 🏆
+
+{{.tournament_url}}{{else}}🚨 Последний шанс!
+
+До турнира '{{.tournament_name}}' остался всего 1 день!
+
+💳 Срочно завершите оплату, чтобы не потерять свое место
+⚠️ Без оплаты регистрация будет автоматически отменена
+
+👇 Оплатить можно здесь:
+
+{{.tournament_url}}{{end}}$$, NULL, 'Напоминание за день до турнира'),
+    ('tournament.free.reminder.48hours', 'ru', $$🎉 Отличная новость!
+
+Через 2 дня стартует бесплатный турнир '{{.tournament_name}}'!
+
+🆓 Участие абсолютно бесплатное
+✅ Вы уже зарегистрированы
+🏓 Просто приходите и играйте!
+
+📅 Добавьте турнир в календарь, чтобы не забыть
+
+Встретимся на корте! 🤝
+
+{{.tournament_url}}$$, NULL, 'Напоминание за 2 дня до бесплатного турнира'),
+    ('tournament.payment.success', 'ru', $$🎉 Отлично! Оплата прошла успешно!
+
+Вы официально участвуете в турнире '{{.tournament_name}}'!
+
+✅ Место забронировано
+🏆 Готовьтесь к победе
+📱 Следите за обновлениями в приложении
+
+Удачи на турнире! 💪
+
+{{.tournament_url}}$$, NULL, 'Успешная оплата турнира'),
+    ('tournament.registration.canceled', 'ru', $$😔 Ваша регистрация отменена
+
+Регистрация на турнир '{{.tournament_name}}' была отменена.
+
+🔄 Вы можете зарегистрироваться снова, если есть свободные места
+📱 Проверьте актуальную информацию в приложении
+
+Не расстраивайтесь - впереди еще много турниров! 💪
+
+{{.tournament_url}}$$, NULL, 'Отмена регистрации на турнир'),
+    ('tournament.loyalty.changed', 'ru', $$🎉 Отличные новости!
+
+Ваш уровень лояльности повышен!
+
+📈 Было: {{.old_level}}
+⭐️ Стало: {{.new_level}}
+
+🎁 Теперь вам доступны новые преимущества и скидки!
+📱 Проверьте их в разделе "Лояльность"
+
+Продолжайте играть и достигайте новых высот! 🚀$$, NULL, 'Повышение уровня лояльности'),
+    ('tournament.registration.auto_delete_unpaid', 'ru', $$⏰ Время вышло...
+
+К сожалению, ваша регистрация на турнир '{{.tournament_name}}' была отменена из-за неоплаты.
+
+💡 В следующий раз не забудьте оплатить участие вовремя
+🔄 Если есть свободные места, вы можете зарегистрироваться повторно
+
+До встречи на следующих турнирах! 🏓
+
+{{.tournament_url}}$$, NULL, 'Отмена неоплаченной регистрации'),
+    ('default', 'ru', $$🏓 У нас есть новости для вас!
+
+Проверьте приложение GoPadel для получения подробной информации.$$, NULL, 'Уведомление без своего шаблона'),
+    ('event.waitlist.registered', 'ru', $$Вы были успешно перемещены из листа ожидания и зарегистрированы на событие "{{html .event_name}}"!
+Перейдите на <a href="{{.event_url}}">страницу события</a> и проверьте, требуется ли оплата.$$, 'HTML', 'Регистрация из листа ожидания'),
+    ('pair.waitlist.registered', 'ru', $$Ваша пара перемещена из листа ожидания и зарегистрирована на турнир "{{html .event_name}}"!
+Перейдите на <a href="{{.event_url}}">страницу турнира</a> и проверьте, требуется ли оплата.$$, 'HTML', 'Регистрация пары из листа ожидания');
@@ -0,0 +1,22 @@
+-- Настройки уведомлений пользователя. Строка создается при первом изменении настроек,
+-- без нее действуют значения по умолчанию: все категории включены, тихих часов нет
+CREATE TABLE user_notification_settings (
+    user_id UUID PRIMARY KEY,
+    muted BOOLEAN NOT NULL DEFAULT FALSE,
+    reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    payments BOOLEAN NOT NULL DEFAULT TRUE,
+    waitlist BOOLEAN NOT NULL DEFAULT TRUE,
+    broadcasts BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Тихие часы в часовом поясе пользователя, интервал может переходить через полночь (23:00-08:00)
+    quiet_hours_start TIME,
+    quiet_hours_end TIME,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Europe/Moscow',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user_notification_settings_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT ck_user_notification_settings_quiet_hours CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+CREATE TRIGGER update_user_notification_settings_updated_at
+    BEFORE UPDATE ON user_notification_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
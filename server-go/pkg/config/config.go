@@ -20,6 +20,8 @@ type Config struct {
 	Server struct {
 		Port uint16 `envconfig:"HTTP_PORT" default:"8000"`
 		Host string `envconfig:"HTTP_HOST" default:"0.0.0.0"`
+		// Прокси, которым доверяется X-Forwarded-For (адреса или CIDR). Пустой список — IP клиента берется из соединения
+		TrustedProxies []string `envconfig:"HTTP_TRUSTED_PROXIES"`
 	}
 	DB struct {
 		User     string `envconfig:"POSTGRES_USER"`
@@ -32,6 +34,8 @@ type Config struct {
 		BotToken   string `envconfig:"TG_BOT_TOKEN"`
 		WebAppName string `envconfig:"WEBAPP_NAME"`
 		BotUsername string `envconfig:"TG_BOT_USERNAME" default:"gopadel_bot"`
+		// Ключ подписи inline-кнопок уведомлений, должен совпадать с ключом воркера. Без него кнопки не отправляются
+		CallbackSecret string `envconfig:"TG_CALLBACK_SECRET"`
 	}
 	Storage struct {
 		ImagesPath string `envconfig:"STORAGE_IMAGES_PATH" default:"images"`
@@ -39,9 +43,58 @@ type Config struct {
 	Log struct {
 		Handler string `envconfig:"LOG_HANDLER" default:"tint"`
 	}
+	Payments struct {
+		// Платежный провайдер: yookassa или fake (локальные платежи без сети для тестов и стейджинга)
+		Provider  string        `envconfig:"PAYMENT_PROVIDER" default:"yookassa"`
+		FakeDelay time.Duration `envconfig:"PAYMENT_FAKE_DELAY" default:"5s"`
+		// Сверка зависших платежей с провайдером
+		ReconcileInterval   time.Duration `envconfig:"PAYMENT_RECONCILE_INTERVAL" default:"5m"`
+		ReconcileStaleAfter time.Duration `envconfig:"PAYMENT_RECONCILE_STALE_AFTER" default:"15m"`
+		// Окно оплаты платежа, если у регистрации нет срока оплаты. Платеж в waiting_for_capture после него отменяется у провайдера
+		PaymentWindow time.Duration `envconfig:"PAYMENT_WINDOW" default:"1h"`
+		// Срок оплаты регистрации, если у события не задан свой: по его истечении неоплаченная регистрация отменяется
+		RegistrationDeadline time.Duration `envconfig:"PAYMENT_REGISTRATION_DEADLINE" default:"24h"`
+		// Сочетание промокода и скидки лояльности: best, sum, sequential или promo_only
+		DiscountStacking string `envconfig:"PAYMENT_DISCOUNT_STACKING" default:"best"`
+	}
+	Loyalty struct {
+		// Периодический пересчет уровней лояльности, 0 — отключен
+		RecalculateInterval time.Duration `envconfig:"LOYALTY_RECALCULATE_INTERVAL" default:"24h"`
+		// Базовый уровень, на который возвращается пользователь, не выполняющий условий ни одного уровня
+		DefaultLevelID int `envconfig:"LOYALTY_DEFAULT_LEVEL_ID" default:"1"`
+	}
+	Rating struct {
+		// Изменение ранга за матч при полностью неожиданном результате
+		KFactor float64 `envconfig:"RATING_K_FACTOR" default:"0.2"`
+		// Повышенный коэффициент для первых матчей, пока ранг игрока еще не устоялся
+		ProvisionalKFactor float64 `envconfig:"RATING_PROVISIONAL_K_FACTOR" default:"0.4"`
+		ProvisionalMatches int     `envconfig:"RATING_PROVISIONAL_MATCHES" default:"10"`
+		// Разница рангов пар, при которой сильная пара выигрывает с шансом 10 к 1
+		Scale   float64 `envconfig:"RATING_SCALE" default:"2"`
+		MaxRank float64 `envconfig:"RATING_MAX_RANK" default:"7"`
+	}
+	Series struct {
+		// На сколько вперед создаются занятия повторяющихся серий
+		Horizon time.Duration `envconfig:"SERIES_HORIZON" default:"672h"`
+		// Периодичность создания новых занятий, 0 — отключено
+		MaterializeInterval time.Duration `envconfig:"SERIES_MATERIALIZE_INTERVAL" default:"1h"`
+	}
+	Waitlist struct {
+		// Сколько следующий в листе ожидания может думать над предложением места, место в это время удерживается
+		OfferWindow time.Duration `envconfig:"WAITLIST_OFFER_WINDOW" default:"2h"`
+	}
+	Messages struct {
+		// Язык шаблонов уведомлений, если для языка пользователя нет своего варианта
+		DefaultLanguage string `envconfig:"MESSAGES_DEFAULT_LANGUAGE" default:"ru"`
+	}
 	YooKassa struct {
 		ShopID    string `envconfig:"SHOP_ID"`
 		SecretKey string `envconfig:"SHOP_SECRET"`
+		APIURL    string `envconfig:"YOOKASSA_API_URL" default:"https://api.yookassa.ru/v3"`
+		Currency  string `envconfig:"YOOKASSA_CURRENCY" default:"RUB"`
+		// Проверка IP отправителя вебхуков, диапазоны из документации YooKassa
+		WebhookIPCheck    bool     `envconfig:"YOOKASSA_WEBHOOK_IP_CHECK" default:"true"`
+		WebhookAllowedIPs []string `envconfig:"YOOKASSA_WEBHOOK_ALLOWED_IPS" default:"185.71.76.0/27,185.71.77.0/27,77.75.153.0/25,77.75.156.11,77.75.156.35,77.75.154.128/25,2a02:5180::/32"`
 	}
 	JWT struct {
 		SecretKey            string        `envconfig:"JWT_SECRET_KEY"`
@@ -52,6 +105,24 @@ type Config struct {
 		URL   string `envconfig:"NATS_URL"`
 		Port  uint16 `envconfig:"NATS_PORT" default:"4222"`
 		Token string `envconfig:"NATS_TOKEN" default:""`
+		// JetStream стрим задач воркера, общий с воркером
+		Stream         string        `envconfig:"NATS_STREAM" default:"TASKS"`
+		StreamSubjects []string      `envconfig:"NATS_STREAM_SUBJECTS" default:"tasks.>"`
+		TasksSubject   string        `envconfig:"NATS_TASKS_SUBJECT" default:"tasks.active"`
+		ControlSubject string        `envconfig:"NATS_CONTROL_SUBJECT" default:"worker.control"`
+		PublishTimeout time.Duration `envconfig:"NATS_PUBLISH_TIMEOUT" default:"5s"`
+		// Запросы воркера к серверу (request/reply), должен совпадать с настройкой воркера
+		RequestSubjectPrefix string `envconfig:"NATS_SERVER_REQUEST_SUBJECT_PREFIX" default:"server.requests"`
+	}
+	Outbox struct {
+		// Как часто relay публикует в NATS задачи воркера из outbox
+		RelayInterval time.Duration `envconfig:"OUTBOX_RELAY_INTERVAL" default:"1s"`
+		BatchSize     int           `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+		// Повтор неудачной публикации: RetryBaseDelay, затем вдвое дольше, но не дольше RetryMaxDelay
+		RetryBaseDelay time.Duration `envconfig:"OUTBOX_RETRY_BASE_DELAY" default:"5s"`
+		RetryMaxDelay  time.Duration `envconfig:"OUTBOX_RETRY_MAX_DELAY" default:"10m"`
+		// Сколько хранятся опубликованные задачи
+		Retention time.Duration `envconfig:"OUTBOX_RETENTION" default:"168h"`
 	}
 
 	S3 S3Config
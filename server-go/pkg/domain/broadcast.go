@@ -0,0 +1,126 @@
+package domain
+
+import "time"
+
+type BroadcastStatus string
+
+const (
+	BroadcastStatusScheduled BroadcastStatus = "scheduled" // Ждет времени отправки
+	BroadcastStatusSending   BroadcastStatus = "sending"   // Воркер отправляет сообщения
+	BroadcastStatusCompleted BroadcastStatus = "completed"
+	BroadcastStatusCancelled BroadcastStatus = "cancelled"
+)
+
+// BroadcastMessageType типы сообщений совпадают с типами сообщений воркера
+type BroadcastMessageType string
+
+const (
+	BroadcastMessageTypeText       BroadcastMessageType = "text"
+	BroadcastMessageTypePhoto      BroadcastMessageType = "photo"
+	BroadcastMessageTypeMediaGroup BroadcastMessageType = "media_group"
+)
+
+// BroadcastMessage сообщение рассылки в формате domain.Message воркера
+type BroadcastMessage struct {
+	Type       BroadcastMessageType   `json:"type" binding:"required,oneof=text photo media_group"`
+	Text       *BroadcastMessageText  `json:"text,omitempty"`
+	Photo      *BroadcastMessagePhoto `json:"photo,omitempty"`
+	MediaGroup *BroadcastMessageMedia `json:"media_group,omitempty"`
+}
+
+type BroadcastMessageText struct {
+	Text string `json:"text" binding:"required"`
+}
+
+type BroadcastMessagePhoto struct {
+	Caption string `json:"caption,omitempty"`
+	URL     string `json:"url" binding:"required"`
+}
+
+type BroadcastMessageMedia struct {
+	Caption string   `json:"caption,omitempty"`
+	URLS    []string `json:"urls" binding:"required"`
+}
+
+type BroadcastTargetType string
+
+const (
+	BroadcastTargetEvent    BroadcastTargetType = "event"    // Подтвержденные участники события
+	BroadcastTargetClub     BroadcastTargetType = "club"     // Участники клуба
+	BroadcastTargetWaitlist BroadcastTargetType = "waitlist" // Лист ожидания события
+	BroadcastTargetUsers    BroadcastTargetType = "users"    // Пользователи по фильтру
+)
+
+// BroadcastTarget аудитория рассылки
+type BroadcastTarget struct {
+	Type       BroadcastTargetType `json:"type" binding:"required,oneof=event club waitlist users"`
+	EventID    *string             `json:"eventId,omitempty"`    // Для event и waitlist
+	ClubID     *string             `json:"clubId,omitempty"`     // Для club
+	UserFilter *FilterUser         `json:"userFilter,omitempty"` // Для users
+}
+
+type Broadcast struct {
+	ID          string           `json:"id"`
+	Message     BroadcastMessage `json:"message"`
+	Target      BroadcastTarget  `json:"target"`
+	Status      BroadcastStatus  `json:"status"`
+	ScheduledAt time.Time        `json:"scheduledAt"`
+	CreatedBy   *string          `json:"createdBy,omitempty"`
+	StartedAt   *time.Time       `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time       `json:"finishedAt,omitempty"`
+	Stats       BroadcastStats   `json:"stats"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+// BroadcastStats количество получателей по статусам доставки
+type BroadcastStats struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"`
+	Sending int `json:"sending"`
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Blocked int `json:"blocked"`
+}
+
+type CreateBroadcast struct {
+	Message     BroadcastMessage `json:"message" binding:"required"`
+	Target      BroadcastTarget  `json:"target" binding:"required"`
+	ScheduledAt *time.Time       `json:"scheduledAt,omitempty"` // По умолчанию — сразу
+	CreatedBy   *string          `json:"-"`
+}
+
+type FilterBroadcast struct {
+	ID     *string          `json:"id,omitempty"`
+	Status *BroadcastStatus `json:"status,omitempty"`
+}
+
+type BroadcastRecipientStatus string
+
+const (
+	BroadcastRecipientStatusPending BroadcastRecipientStatus = "pending"
+	BroadcastRecipientStatusSending BroadcastRecipientStatus = "sending" // Взят в отправку, результат неизвестен, если воркер упал
+	BroadcastRecipientStatusSent    BroadcastRecipientStatus = "sent"
+	BroadcastRecipientStatusFailed  BroadcastRecipientStatus = "failed"
+	BroadcastRecipientStatusBlocked BroadcastRecipientStatus = "blocked" // Пользователь заблокировал бота
+)
+
+type BroadcastRecipient struct {
+	ID          string                   `json:"id"`
+	BroadcastID string                   `json:"broadcastId"`
+	UserID      *string                  `json:"userId,omitempty"`
+	TelegramID  int64                    `json:"telegramId"`
+	Status      BroadcastRecipientStatus `json:"status"`
+	Error       *string                  `json:"error,omitempty"`
+	SentAt      *time.Time               `json:"sentAt,omitempty"`
+}
+
+type CreateBroadcastRecipient struct {
+	UserID     string
+	TelegramID int64
+}
+
+type FilterBroadcastRecipient struct {
+	BroadcastID string                    `json:"-"`
+	Status      *BroadcastRecipientStatus `json:"status,omitempty"`
+}
@@ -1,19 +1,25 @@
 package domain
 
+// DefaultCourtTimezone часовой пояс корта, если он не указан при создании
+const DefaultCourtTimezone = "Europe/Moscow"
+
 type Court struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Address string `json:"address"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Timezone string `json:"timezone"` // IANA, в нем показывается время событий на корте
 }
 
 type CreateCourt struct {
-	Name    string `json:"name" binding:"required"`
-	Address string `json:"address" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Address  string `json:"address" binding:"required"`
+	Timezone string `json:"timezone,omitempty"` // По умолчанию DefaultCourtTimezone
 }
 
 type PatchCourt struct {
-	Name    *string `json:"name,omitempty"`
-	Address *string `json:"address,omitempty"`
+	Name     *string `json:"name,omitempty"`
+	Address  *string `json:"address,omitempty"`
+	Timezone *string `json:"timezone,omitempty"`
 }
 
 type FilterCourt struct {
@@ -38,41 +38,53 @@ type Event struct {
 	Organizer    User            `json:"organizer"`
 	ClubID       *string         `json:"clubId,omitempty"`
 	Data         json.RawMessage `json:"data,omitempty" swaggertype:"object"`
-	Participants []*Registration `json:"participants,omitempty"`
-	CreatedAt    time.Time       `json:"createdAt"`
-	UpdatedAt    time.Time       `json:"updatedAt"`
+	RefundPolicy *RefundPolicy   `json:"refundPolicy,omitempty"`
+	// Сколько часов после регистрации дается на оплату, потом неоплаченная регистрация отменяется.
+	// Не задано — срок из настроек сервера, 0 — регистрация не отменяется
+	PaymentDeadlineHours *int            `json:"paymentDeadlineHours,omitempty"`
+	SeriesID             *string         `json:"seriesId,omitempty"` // Серия, по которой создано событие
+	Participants         []*Registration `json:"participants,omitempty"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
 }
 
 type CreateEvent struct {
-	Name        string          `json:"name" binding:"required"`
-	Description *string         `json:"description,omitempty"`
-	StartTime   time.Time       `json:"startTime" binding:"required"`
-	EndTime     time.Time       `json:"endTime" binding:"required"`
-	RankMin     float64         `json:"rankMin" binding:"min=0"`
-	RankMax     float64         `json:"rankMax" binding:"min=0"`
-	Price       int             `json:"price" binding:"min=0"`
-	MaxUsers    int             `json:"maxUsers" binding:"required,min=2"`
-	Type        EventType       `json:"type" binding:"required"`
-	CourtID     string          `json:"courtId" binding:"required"`
-	OrganizerID string          `json:"organizerId,omitempty"`
-	ClubID      *string         `json:"clubId,omitempty"`
-	Data        json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	Name                 string          `json:"name" binding:"required"`
+	Description          *string         `json:"description,omitempty"`
+	StartTime            time.Time       `json:"startTime" binding:"required"`
+	EndTime              time.Time       `json:"endTime" binding:"required"`
+	RankMin              float64         `json:"rankMin" binding:"min=0"`
+	RankMax              float64         `json:"rankMax" binding:"min=0"`
+	Price                int             `json:"price" binding:"min=0"`
+	MaxUsers             int             `json:"maxUsers" binding:"required,min=2"`
+	Type                 EventType       `json:"type" binding:"required"`
+	CourtID              string          `json:"courtId" binding:"required"`
+	OrganizerID          string          `json:"organizerId,omitempty"`
+	ClubID               *string         `json:"clubId,omitempty"`
+	Data                 json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	RefundPolicy         *RefundPolicy   `json:"refundPolicy,omitempty"`
+	PaymentDeadlineHours *int            `json:"paymentDeadlineHours,omitempty" binding:"omitempty,min=0"`
+	// Заполняются только при создании занятия серии
+	SeriesID         *string    `json:"-"`
+	SeriesOccurrence *time.Time `json:"-"`
 }
 
 type PatchEvent struct {
-	Name        *string         `json:"name,omitempty"`
-	Description *string         `json:"description,omitempty"`
-	StartTime   *time.Time      `json:"startTime,omitempty"`
-	EndTime     *time.Time      `json:"endTime,omitempty"`
-	RankMin     *float64        `json:"rankMin,omitempty"`
-	RankMax     *float64        `json:"rankMax,omitempty"`
-	Price       *int            `json:"price,omitempty"`
-	MaxUsers    *int            `json:"maxUsers,omitempty"`
-	Status      *EventStatus    `json:"status,omitempty"`
-	Type        *EventType      `json:"type,omitempty"`
-	CourtID     *string         `json:"courtId,omitempty"`
-	ClubID      *string         `json:"clubId,omitempty"`
-	Data        json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	Name                 *string         `json:"name,omitempty"`
+	Description          *string         `json:"description,omitempty"`
+	StartTime            *time.Time      `json:"startTime,omitempty"`
+	EndTime              *time.Time      `json:"endTime,omitempty"`
+	RankMin              *float64        `json:"rankMin,omitempty"`
+	RankMax              *float64        `json:"rankMax,omitempty"`
+	Price                *int            `json:"price,omitempty"`
+	MaxUsers             *int            `json:"maxUsers,omitempty"`
+	Status               *EventStatus    `json:"status,omitempty"`
+	Type                 *EventType      `json:"type,omitempty"`
+	CourtID              *string         `json:"courtId,omitempty"`
+	ClubID               *string         `json:"clubId,omitempty"`
+	Data                 json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	RefundPolicy         *RefundPolicy   `json:"refundPolicy,omitempty"`
+	PaymentDeadlineHours *int            `json:"paymentDeadlineHours,omitempty" binding:"omitempty,min=0"`
 }
 
 type FilterEvent struct {
@@ -85,24 +97,28 @@ type FilterEvent struct {
 	OrganizerID       *string        `json:"organizerId,omitempty"`
 	ClubID            *string        `json:"clubId,omitempty"`
 	FilterByUserClubs *string        `json:"filterByUserClubs,omitempty"` // user ID для фильтрации по клубам пользователя
+	SeriesID          *string        `json:"seriesId,omitempty"`
+	StartFrom         *time.Time     `json:"startFrom,omitempty"` // события, начинающиеся не раньше указанного момента
 }
 
 // Админские события
 type AdminPatchEvent struct {
-	Name        *string         `json:"name,omitempty"`
-	Description *string         `json:"description,omitempty"`
-	StartTime   *time.Time      `json:"startTime,omitempty"`
-	EndTime     *time.Time      `json:"endTime,omitempty"`
-	RankMin     *float64        `json:"rankMin,omitempty"`
-	RankMax     *float64        `json:"rankMax,omitempty"`
-	Price       *int            `json:"price,omitempty"`
-	MaxUsers    *int            `json:"maxUsers,omitempty"`
-	Status      *EventStatus    `json:"status,omitempty"`
-	Type        *EventType      `json:"type,omitempty"`
-	CourtID     *string         `json:"courtId,omitempty"`
-	OrganizerID *string         `json:"organizerId,omitempty"`
-	ClubID      *string         `json:"clubId,omitempty"`
-	Data        json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	Name                 *string         `json:"name,omitempty"`
+	Description          *string         `json:"description,omitempty"`
+	StartTime            *time.Time      `json:"startTime,omitempty"`
+	EndTime              *time.Time      `json:"endTime,omitempty"`
+	RankMin              *float64        `json:"rankMin,omitempty"`
+	RankMax              *float64        `json:"rankMax,omitempty"`
+	Price                *int            `json:"price,omitempty"`
+	MaxUsers             *int            `json:"maxUsers,omitempty"`
+	Status               *EventStatus    `json:"status,omitempty"`
+	Type                 *EventType      `json:"type,omitempty"`
+	CourtID              *string         `json:"courtId,omitempty"`
+	OrganizerID          *string         `json:"organizerId,omitempty"`
+	ClubID               *string         `json:"clubId,omitempty"`
+	Data                 json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	RefundPolicy         *RefundPolicy   `json:"refundPolicy,omitempty"`
+	PaymentDeadlineHours *int            `json:"paymentDeadlineHours,omitempty" binding:"omitempty,min=0"`
 }
 
 type AdminFilterEvent struct {
@@ -112,6 +128,7 @@ type AdminFilterEvent struct {
 	Type        *EventType     `json:"type,omitempty"`
 	ClubID      *string        `json:"clubId,omitempty"`
 	OrganizerID *string        `json:"organizerId,omitempty"`
+	SeriesID    *string        `json:"seriesId,omitempty"`
 	// Дополнительные поля для удобства фильтрации
 	OrganizerTelegramID *int64  `json:"organizerTelegramId,omitempty"`
 	OrganizerFirstName  *string `json:"organizerFirstName,omitempty"`
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventSeriesStatus string
+
+const (
+	EventSeriesStatusActive    EventSeriesStatus = "active"    // Новые занятия создаются по расписанию
+	EventSeriesStatusCancelled EventSeriesStatus = "cancelled" // Серия отменена, занятия больше не создаются
+)
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceFrequencyDaily   RecurrenceFrequency = "daily"
+	RecurrenceFrequencyWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceFrequencyMonthly RecurrenceFrequency = "monthly"
+)
+
+// RecurrenceRule правило повторения по мотивам RRULE (RFC 5545): FREQ, INTERVAL, BYDAY, COUNT и UNTIL.
+// Время занятия берется из шаблона события, первое занятие — StartTime шаблона
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Interval  int                 `json:"interval,omitempty" binding:"omitempty,min=1"`                            // По умолчанию 1
+	ByWeekday []string            `json:"byWeekday,omitempty" binding:"omitempty,dive,oneof=MO TU WE TH FR SA SU"` // Только для weekly, по умолчанию день недели первого занятия
+	Count     *int                `json:"count,omitempty" binding:"omitempty,min=1"`                               // Общее количество занятий
+	Until     *time.Time          `json:"until,omitempty"`                                                         // Последний момент, когда может начаться занятие
+}
+
+// EventSeries серия повторяющихся событий, занятия создаются на заданный горизонт вперед
+type EventSeries struct {
+	ID                string            `json:"id"`
+	Template          CreateEvent       `json:"template"`
+	Rule              RecurrenceRule    `json:"rule"`
+	Status            EventSeriesStatus `json:"status"`
+	MaterializedUntil *time.Time        `json:"materializedUntil,omitempty"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+}
+
+type CreateEventSeries struct {
+	Template CreateEvent    `json:"template" binding:"required"`
+	Rule     RecurrenceRule `json:"rule" binding:"required"`
+}
+
+// UpdateEventSeries изменение всех будущих занятий серии.
+// У StartTime и EndTime учитывается только время суток, дата каждого занятия сохраняется
+type UpdateEventSeries struct {
+	Name                 *string         `json:"name,omitempty"`
+	Description          *string         `json:"description,omitempty"`
+	StartTime            *time.Time      `json:"startTime,omitempty"`
+	EndTime              *time.Time      `json:"endTime,omitempty"`
+	RankMin              *float64        `json:"rankMin,omitempty"`
+	RankMax              *float64        `json:"rankMax,omitempty"`
+	Price                *int            `json:"price,omitempty"`
+	MaxUsers             *int            `json:"maxUsers,omitempty"`
+	CourtID              *string         `json:"courtId,omitempty"`
+	OrganizerID          *string         `json:"organizerId,omitempty"`
+	ClubID               *string         `json:"clubId,omitempty"`
+	Data                 json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	RefundPolicy         *RefundPolicy   `json:"refundPolicy,omitempty"`
+	PaymentDeadlineHours *int            `json:"paymentDeadlineHours,omitempty" binding:"omitempty,min=0"`
+	From                 *time.Time      `json:"from,omitempty"` // Изменяются занятия, начинающиеся не раньше From, по умолчанию — с текущего момента
+}
+
+// CancelEventSeries отмена серии: занятия, начинающиеся не раньше From, отменяются вместе с регистрациями
+type CancelEventSeries struct {
+	From *time.Time `json:"from,omitempty"`
+}
+
+type PatchEventSeries struct {
+	Template          *CreateEvent
+	Rule              *RecurrenceRule
+	Status            *EventSeriesStatus
+	MaterializedUntil *time.Time
+}
+
+type FilterEventSeries struct {
+	ID     *string            `json:"id,omitempty"`
+	Status *EventSeriesStatus `json:"status,omitempty"`
+}
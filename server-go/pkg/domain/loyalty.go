@@ -1,27 +1,105 @@
 package domain
 
+import "time"
+
 type Loyalty struct {
-	ID           int    `json:"id"`
-	Name         string `json:"name"`
-	Discount     int    `json:"discount"`
-	Description  string `json:"description"`
-	Requirements string `json:"requirements"`
+	ID           int           `json:"id"`
+	Name         string        `json:"name"`
+	Discount     int           `json:"discount"`
+	Description  string        `json:"description"`
+	Requirements string        `json:"requirements"`
+	Rules        *LoyaltyRules `json:"rules,omitempty"`
 }
 
 type CreateLoyalty struct {
-	Name         string `json:"name"`
-	Discount     int    `json:"discount"`
-	Description  string `json:"description"`
-	Requirements string `json:"requirements"`
+	Name         string        `json:"name"`
+	Discount     int           `json:"discount"`
+	Description  string        `json:"description"`
+	Requirements string        `json:"requirements"`
+	Rules        *LoyaltyRules `json:"rules,omitempty"`
 }
 
 type PatchLoyalty struct {
-	Name         *string `json:"name"`
-	Discount     *int    `json:"discount"`
-	Description  *string `json:"description"`
-	Requirements *string `json:"requirements"`
+	Name         *string       `json:"name"`
+	Discount     *int          `json:"discount"`
+	Description  *string       `json:"description"`
+	Requirements *string       `json:"requirements"`
+	Rules        *LoyaltyRules `json:"rules"`
 }
 
 type FilterLoyalty struct {
 	ID *int `json:"id"`
 }
+
+type LoyaltyRulesMatch string
+
+const (
+	LoyaltyRulesMatchAll LoyaltyRulesMatch = "all" // Должны выполняться все заданные условия
+	LoyaltyRulesMatchAny LoyaltyRulesMatch = "any" // Достаточно одного из заданных условий
+)
+
+// LoyaltyRules машиночитаемые условия получения уровня лояльности.
+// Уровни без условий назначаются только вручную
+type LoyaltyRules struct {
+	// Минимальное число подтвержденных участий в событиях
+	MinConfirmedEvents int `json:"minConfirmedEvents,omitempty" binding:"min=0"`
+	// Минимальная сумма успешных оплат за вычетом возвратов
+	MinTotalPaid int `json:"minTotalPaid,omitempty" binding:"min=0"`
+	// Скользящее окно в днях, 0 — за все время
+	WindowDays int `json:"windowDays,omitempty" binding:"min=0"`
+	// Типы событий, которые учитываются, пусто — все
+	EventTypes []EventType `json:"eventTypes,omitempty"`
+	// Сочетание условий: all (по умолчанию) или any
+	Match LoyaltyRulesMatch `json:"match,omitempty" binding:"omitempty,oneof=all any"`
+}
+
+// LoyaltyActivity активность пользователя, по которой проверяются условия уровня
+type LoyaltyActivity struct {
+	ConfirmedEvents int `json:"confirmedEvents"`
+	TotalPaid       int `json:"totalPaid"`
+}
+
+type FilterLoyaltyActivity struct {
+	UserID     string      `json:"userId"`
+	Since      *time.Time  `json:"since,omitempty"`
+	EventTypes []EventType `json:"eventTypes,omitempty"`
+}
+
+// Since возвращает начало скользящего окна относительно now или nil, если окно не задано
+func (r *LoyaltyRules) Since(now time.Time) *time.Time {
+	if r.WindowDays <= 0 {
+		return nil
+	}
+	since := now.AddDate(0, 0, -r.WindowDays)
+	return &since
+}
+
+// IsSatisfied проверяет, выполнены ли условия уровня для активности пользователя
+func (r *LoyaltyRules) IsSatisfied(activity *LoyaltyActivity) bool {
+	if r == nil {
+		return false
+	}
+
+	checks := []bool{}
+	if r.MinConfirmedEvents > 0 {
+		checks = append(checks, activity.ConfirmedEvents >= r.MinConfirmedEvents)
+	}
+	if r.MinTotalPaid > 0 {
+		checks = append(checks, activity.TotalPaid >= r.MinTotalPaid)
+	}
+
+	// Уровень без количественных условий доступен всем
+	if len(checks) == 0 {
+		return true
+	}
+
+	for _, ok := range checks {
+		if r.Match == LoyaltyRulesMatchAny && ok {
+			return true
+		}
+		if r.Match != LoyaltyRulesMatchAny && !ok {
+			return false
+		}
+	}
+	return r.Match != LoyaltyRulesMatchAny
+}
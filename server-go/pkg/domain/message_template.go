@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// MessageTemplate шаблон уведомления на text/template. Данные шаблона — данные задачи воркера
+// или уведомления сервера, ключи в snake_case
+type MessageTemplate struct {
+	Key         string    `json:"key"`
+	Language    string    `json:"language"`
+	Body        string    `json:"body"`
+	ParseMode   *string   `json:"parseMode,omitempty"` // HTML, MarkdownV2 или пусто для обычного текста
+	Description string    `json:"description"`
+	UpdatedBy   *string   `json:"updatedBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// MessageTemplateKey известный ключ шаблона с примером данных для предпросмотра
+type MessageTemplateKey struct {
+	Key         string         `json:"key"`
+	Description string         `json:"description"`
+	SampleData  map[string]any `json:"sampleData"`
+}
+
+type UpsertMessageTemplate struct {
+	Body        string  `json:"body" binding:"required"`
+	ParseMode   *string `json:"parseMode,omitempty" binding:"omitempty,oneof=HTML MarkdownV2"`
+	Description *string `json:"description,omitempty"`
+	UpdatedBy   *string `json:"-"`
+}
+
+type FilterMessageTemplate struct {
+	Key       *string  `json:"key,omitempty"`
+	Language  *string  `json:"language,omitempty"`
+	Languages []string `json:"-"`
+}
+
+// PreviewMessageTemplate без body отображается сохраненный шаблон с учетом цепочки языков,
+// без data используются данные из примера для ключа
+type PreviewMessageTemplate struct {
+	Key       string         `json:"key" binding:"required"`
+	Language  string         `json:"language,omitempty"`
+	Body      *string        `json:"body,omitempty"`
+	ParseMode *string        `json:"parseMode,omitempty" binding:"omitempty,oneof=HTML MarkdownV2"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+// RenderedMessage готовый текст и язык шаблона, по которому он построен
+type RenderedMessage struct {
+	Text      string  `json:"text"`
+	ParseMode *string `json:"parseMode,omitempty"`
+	Language  string  `json:"language"`
+}
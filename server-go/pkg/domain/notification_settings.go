@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // В alpine-образах нет базы часовых поясов
+)
+
+// DefaultNotificationTimezone часовой пояс тихих часов, если пользователь его не менял
+const DefaultNotificationTimezone = "Europe/Moscow"
+
+type NotificationCategory string
+
+const (
+	NotificationCategoryReminders  NotificationCategory = "reminders"  // Напоминания о событиях
+	NotificationCategoryPayments   NotificationCategory = "payments"   // Напоминания об оплате и отмена неоплаченных регистраций
+	NotificationCategoryWaitlist   NotificationCategory = "waitlist"   // Регистрация из листа ожидания
+	NotificationCategoryBroadcasts NotificationCategory = "broadcasts" // Рассылки клубов и админов
+)
+
+// NotificationSettings настройки уведомлений пользователя. Уведомления без категории
+// (успешная регистрация, оплата) отключаются только полным отключением уведомлений
+type NotificationSettings struct {
+	Muted      bool `json:"muted"`
+	Reminders  bool `json:"reminders"`
+	Payments   bool `json:"payments"`
+	Waitlist   bool `json:"waitlist"`
+	Broadcasts bool `json:"broadcasts"`
+	// Тихие часы в формате HH:MM, уведомления откладываются до их окончания
+	QuietHoursStart *string `json:"quietHoursStart"`
+	QuietHoursEnd   *string `json:"quietHoursEnd"`
+	Timezone        string  `json:"timezone"`
+}
+
+func DefaultNotificationSettings() NotificationSettings {
+	return NotificationSettings{
+		Reminders:  true,
+		Payments:   true,
+		Waitlist:   true,
+		Broadcasts: true,
+		Timezone:   DefaultNotificationTimezone,
+	}
+}
+
+// Allows можно ли отправить уведомление категории category. Пустая категория — уведомление без категории
+func (s NotificationSettings) Allows(category NotificationCategory) bool {
+	if s.Muted {
+		return false
+	}
+	switch category {
+	case NotificationCategoryReminders:
+		return s.Reminders
+	case NotificationCategoryPayments:
+		return s.Payments
+	case NotificationCategoryWaitlist:
+		return s.Waitlist
+	case NotificationCategoryBroadcasts:
+		return s.Broadcasts
+	default:
+		return true
+	}
+}
+
+// QuietUntil время окончания тихих часов, если now в них попадает. Интервал может переходить через полночь
+func (s NotificationSettings) QuietUntil(now time.Time) (time.Time, bool) {
+	if s.QuietHoursStart == nil || s.QuietHoursEnd == nil {
+		return time.Time{}, false
+	}
+	startClock, startErr := time.Parse(QuietHoursLayout, *s.QuietHoursStart)
+	endClock, endErr := time.Parse(QuietHoursLayout, *s.QuietHoursEnd)
+	if startErr != nil || endErr != nil {
+		return time.Time{}, false
+	}
+	start := startClock.Hour()*60 + startClock.Minute()
+	end := endClock.Hour()*60 + endClock.Minute()
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	endOn := func(day int) time.Time {
+		return time.Date(local.Year(), local.Month(), day, end/60, end%60, 0, 0, location)
+	}
+
+	switch {
+	case start < end && minute >= start && minute < end:
+		return endOn(local.Day()), true
+	case start > end && minute >= start:
+		return endOn(local.Day() + 1), true
+	case start > end && minute < end:
+		return endOn(local.Day()), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// QuietHoursLayout формат времени тихих часов
+const QuietHoursLayout = "15:04"
+
+// PatchNotificationSettings изменение настроек. Пустые строки в тихих часах отключают их
+type PatchNotificationSettings struct {
+	Muted           *bool   `json:"muted"`
+	Reminders       *bool   `json:"reminders"`
+	Payments        *bool   `json:"payments"`
+	Waitlist        *bool   `json:"waitlist"`
+	Broadcasts      *bool   `json:"broadcasts"`
+	QuietHoursStart *string `json:"quietHoursStart"`
+	QuietHoursEnd   *string `json:"quietHoursEnd"`
+	Timezone        *string `json:"timezone"`
+}
+
+// Apply применяет изменение к текущим настройкам и проверяет результат
+func (p *PatchNotificationSettings) Apply(settings NotificationSettings) (NotificationSettings, error) {
+	if p.Muted != nil {
+		settings.Muted = *p.Muted
+	}
+	if p.Reminders != nil {
+		settings.Reminders = *p.Reminders
+	}
+	if p.Payments != nil {
+		settings.Payments = *p.Payments
+	}
+	if p.Waitlist != nil {
+		settings.Waitlist = *p.Waitlist
+	}
+	if p.Broadcasts != nil {
+		settings.Broadcasts = *p.Broadcasts
+	}
+	if p.QuietHoursStart != nil {
+		settings.QuietHoursStart = emptyToNil(*p.QuietHoursStart)
+	}
+	if p.QuietHoursEnd != nil {
+		settings.QuietHoursEnd = emptyToNil(*p.QuietHoursEnd)
+	}
+	if p.Timezone != nil {
+		settings.Timezone = *p.Timezone
+	}
+
+	if (settings.QuietHoursStart == nil) != (settings.QuietHoursEnd == nil) {
+		return settings, fmt.Errorf("quiet hours start and end must be set together")
+	}
+	for _, value := range []*string{settings.QuietHoursStart, settings.QuietHoursEnd} {
+		if value == nil {
+			continue
+		}
+		if _, err := time.Parse(QuietHoursLayout, *value); err != nil {
+			return settings, fmt.Errorf("invalid quiet hours time %q, expected HH:MM", *value)
+		}
+	}
+	if settings.QuietHoursStart != nil && *settings.QuietHoursStart == *settings.QuietHoursEnd {
+		return settings, fmt.Errorf("quiet hours start and end must differ")
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "" {
+		return settings, fmt.Errorf("invalid timezone %q", settings.Timezone)
+	}
+	return settings, nil
+}
+
+func emptyToNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
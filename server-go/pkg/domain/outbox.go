@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage задача воркера, ожидающая публикации в NATS
+type OutboxMessage struct {
+	ID            string
+	TaskType      string
+	SchemaVersion int
+	ExecuteAt     time.Time
+	Data          json.RawMessage
+	Attempts      int
+	CreatedAt     time.Time
+}
+
+type CreateOutboxMessage struct {
+	TaskType      string
+	SchemaVersion int
+	ExecuteAt     time.Time
+	Data          json.RawMessage
+}
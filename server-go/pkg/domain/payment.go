@@ -21,6 +21,9 @@ type Payment struct {
 	ConfirmationToken string        `json:"confirmationToken"`
 	UserID            string        `json:"userId"`
 	EventID           string        `json:"eventId"`
+	OriginalAmount    *int          `json:"originalAmount,omitempty"`
+	PromoCodeID       *string       `json:"promoCodeId,omitempty"`
+	PromoCode         *string       `json:"promoCode,omitempty"`
 	Registration      *Registration `json:"registration,omitempty"`
 }
 
@@ -32,6 +35,8 @@ type CreatePayment struct {
 	ConfirmationToken string        `json:"confirmationToken"`
 	UserID            string        `json:"userId" binding:"required"`
 	EventID           string        `json:"eventId" binding:"required"`
+	OriginalAmount    *int          `json:"originalAmount,omitempty"`
+	PromoCodeID       *string       `json:"promoCodeId,omitempty"`
 }
 
 type PatchPayment struct {
@@ -41,9 +46,23 @@ type PatchPayment struct {
 }
 
 type FilterPayment struct {
-	ID        *string        `json:"id,omitempty"`
-	PaymentID *string        `json:"paymentId,omitempty"`
-	Status    *PaymentStatus `json:"status,omitempty"`
-	UserID    *string        `json:"userId,omitempty"`
-	EventID   *string        `json:"eventId,omitempty"`
-} 
\ No newline at end of file
+	ID            *string          `json:"id,omitempty"`
+	PaymentID     *string          `json:"paymentId,omitempty"`
+	Status        *PaymentStatus   `json:"status,omitempty"`
+	Statuses      *[]PaymentStatus `json:"statuses,omitempty"`
+	UserID        *string          `json:"userId,omitempty"`
+	EventID       *string          `json:"eventId,omitempty"`
+	PromoCodeID   *string          `json:"promoCodeId,omitempty"`
+	CreatedBefore *time.Time       `json:"createdBefore,omitempty"`
+} 
+// PaymentStatusesBefore возвращает статусы, из которых допустим переход в status.
+// succeeded и canceled — финальные, из них переходов нет
+func PaymentStatusesBefore(status PaymentStatus) []PaymentStatus {
+	switch status {
+	case PaymentStatusWaitingForCapture:
+		return []PaymentStatus{PaymentStatusPending}
+	case PaymentStatusSucceeded, PaymentStatusCanceled:
+		return []PaymentStatus{PaymentStatusPending, PaymentStatusWaitingForCapture}
+	}
+	return nil
+}
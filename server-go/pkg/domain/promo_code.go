@@ -0,0 +1,88 @@
+package domain
+
+import "time"
+
+type PromoDiscountType string
+
+const (
+	PromoDiscountTypePercent PromoDiscountType = "percent" // Скидка в процентах от цены
+	PromoDiscountTypeFixed   PromoDiscountType = "fixed"   // Фиксированная скидка в рублях
+)
+
+// DiscountStacking определяет, как промокод сочетается со скидкой уровня лояльности
+type DiscountStacking string
+
+const (
+	DiscountStackingBest       DiscountStacking = "best"       // Применяется большая из двух скидок
+	DiscountStackingSum        DiscountStacking = "sum"        // Скидки складываются, обе считаются от исходной цены
+	DiscountStackingSequential DiscountStacking = "sequential" // Сначала скидка лояльности, промокод применяется к остатку
+	DiscountStackingPromoOnly  DiscountStacking = "promo_only" // С промокодом скидка лояльности не применяется
+)
+
+type PromoCode struct {
+	ID             string            `json:"id"`
+	Code           string            `json:"code"`
+	DiscountType   PromoDiscountType `json:"discountType"`
+	DiscountValue  int               `json:"discountValue"`
+	MaxUses        *int              `json:"maxUses,omitempty"`
+	MaxUsesPerUser *int              `json:"maxUsesPerUser,omitempty"`
+	ValidFrom      *time.Time        `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time        `json:"validUntil,omitempty"`
+	EventTypes     []EventType       `json:"eventTypes,omitempty"`
+	ClubIDs        []string          `json:"clubIds,omitempty"`
+	IsActive       bool              `json:"isActive"`
+	UsesCount      int               `json:"usesCount"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+type CreatePromoCode struct {
+	Code           string            `json:"code" binding:"required,max=64"`
+	DiscountType   PromoDiscountType `json:"discountType" binding:"required,oneof=percent fixed"`
+	DiscountValue  int               `json:"discountValue" binding:"required,min=1"`
+	MaxUses        *int              `json:"maxUses,omitempty" binding:"omitempty,min=1"`
+	MaxUsesPerUser *int              `json:"maxUsesPerUser,omitempty" binding:"omitempty,min=1"`
+	ValidFrom      *time.Time        `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time        `json:"validUntil,omitempty"`
+	EventTypes     []EventType       `json:"eventTypes,omitempty"`
+	ClubIDs        []string          `json:"clubIds,omitempty"`
+	IsActive       *bool             `json:"isActive,omitempty"`
+}
+
+type PatchPromoCode struct {
+	DiscountType   *PromoDiscountType `json:"discountType,omitempty" binding:"omitempty,oneof=percent fixed"`
+	DiscountValue  *int               `json:"discountValue,omitempty" binding:"omitempty,min=1"`
+	MaxUses        *int               `json:"maxUses,omitempty" binding:"omitempty,min=1"`
+	MaxUsesPerUser *int               `json:"maxUsesPerUser,omitempty" binding:"omitempty,min=1"`
+	ValidFrom      *time.Time         `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time         `json:"validUntil,omitempty"`
+	EventTypes     *[]EventType       `json:"eventTypes,omitempty"`
+	ClubIDs        *[]string          `json:"clubIds,omitempty"`
+	IsActive       *bool              `json:"isActive,omitempty"`
+}
+
+type FilterPromoCode struct {
+	ID       *string `json:"id,omitempty"`
+	Code     *string `json:"code,omitempty"`
+	IsActive *bool   `json:"isActive,omitempty"`
+}
+
+// DiscountAmount возвращает размер скидки по промокоду для указанной цены, не больше самой цены
+func (p *PromoCode) DiscountAmount(price int) int {
+	if p == nil || price <= 0 {
+		return 0
+	}
+
+	discount := 0
+	switch p.DiscountType {
+	case PromoDiscountTypePercent:
+		discount = (price*p.DiscountValue + 50) / 100
+	case PromoDiscountTypeFixed:
+		discount = p.DiscountValue
+	}
+
+	if discount > price {
+		return price
+	}
+	return discount
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+type RatingSource string
+
+const (
+	RatingSourceMatch RatingSource = "match" // Пересчет по результату матча
+	RatingSourceAdmin RatingSource = "admin" // Ручная правка администратора
+)
+
+// RatingChange запись истории ранга игрока
+type RatingChange struct {
+	ID        string       `json:"id"`
+	UserID    string       `json:"userId"`
+	Source    RatingSource `json:"source"`
+	EventID   *string      `json:"eventId,omitempty"`
+	MatchID   *string      `json:"matchId,omitempty"`
+	OldRank   float64      `json:"oldRank"`
+	NewRank   float64      `json:"newRank"`
+	Delta     float64      `json:"delta"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+type CreateRatingChange struct {
+	UserID  string
+	Source  RatingSource
+	EventID *string
+	MatchID *string
+	OldRank float64
+	NewRank float64
+}
+
+type FilterRatingChange struct {
+	UserID  *string    `json:"userId,omitempty"`
+	MatchID *string    `json:"matchId,omitempty"`
+	From    *time.Time `json:"from,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+}
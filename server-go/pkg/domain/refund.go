@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusSucceeded RefundStatus = "succeeded"
+	RefundStatusCanceled  RefundStatus = "canceled"
+	// RefundStatusFailed провайдер не принял возврат, сверка платежей повторяет запрос
+	RefundStatusFailed RefundStatus = "failed"
+)
+
+// Refund возврат по платежу. RefundID пустой, пока провайдер не принял возврат
+type Refund struct {
+	ID        string       `json:"id"`
+	RefundID  string       `json:"refundId"`
+	PaymentID string       `json:"paymentId"`
+	Amount    int          `json:"amount"`
+	Status    RefundStatus `json:"status"`
+	Reason    string       `json:"reason,omitempty"`
+	UserID    string       `json:"userId"`
+	EventID   string       `json:"eventId"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+
+	// ProviderPaymentID ID платежа у провайдера, по которому оформляется возврат
+	ProviderPaymentID string `json:"providerPaymentId"`
+}
+
+type CreateRefund struct {
+	RefundID  *string      `json:"refundId,omitempty"`
+	PaymentID string       `json:"paymentId" binding:"required"`
+	Amount    int          `json:"amount" binding:"required"`
+	Status    RefundStatus `json:"status" binding:"required"`
+	Reason    string       `json:"reason,omitempty"`
+}
+
+type PatchRefund struct {
+	Status *RefundStatus `json:"status,omitempty"`
+}
+
+type FilterRefund struct {
+	ID        *string       `json:"id,omitempty"`
+	RefundID  *string       `json:"refundId,omitempty"`
+	PaymentID *string       `json:"paymentId,omitempty"`
+	Status    *RefundStatus `json:"status,omitempty"`
+	UserID    *string       `json:"userId,omitempty"`
+	EventID   *string       `json:"eventId,omitempty"`
+	// Unsent только возвраты, которые провайдер еще не принял
+	Unsent        *bool      `json:"unsent,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// RefundWindow процент возврата, действующий пока до начала события остается не меньше HoursBeforeStart часов
+type RefundWindow struct {
+	HoursBeforeStart int `json:"hoursBeforeStart" binding:"min=0"`
+	Percent          int `json:"percent" binding:"min=0,max=100"`
+}
+
+// RefundPolicy политика возвратов события, например 100% до 48 часов до начала и 50% после
+type RefundPolicy struct {
+	Windows []RefundWindow `json:"windows" binding:"dive"`
+}
+
+// PercentAt возвращает процент возврата на момент now для события, начинающегося в startTime
+func (p *RefundPolicy) PercentAt(startTime, now time.Time) int {
+	if p == nil || len(p.Windows) == 0 {
+		return 0
+	}
+
+	left := startTime.Sub(now)
+	if left < 0 {
+		return 0
+	}
+
+	windows := make([]RefundWindow, len(p.Windows))
+	copy(windows, p.Windows)
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].HoursBeforeStart > windows[j].HoursBeforeStart
+	})
+
+	for _, window := range windows {
+		if left >= time.Duration(window.HoursBeforeStart)*time.Hour {
+			return window.Percent
+		}
+	}
+
+	return 0
+}
+
+// RefundStatusesBefore возвращает статусы, из которых допустим переход в status
+func RefundStatusesBefore(status RefundStatus) []RefundStatus {
+	switch status {
+	case RefundStatusSucceeded, RefundStatusCanceled:
+		return []RefundStatus{RefundStatusPending, RefundStatusFailed}
+	case RefundStatusPending:
+		return []RefundStatus{RefundStatusFailed}
+	case RefundStatusFailed:
+		return []RefundStatus{RefundStatusPending}
+	}
+	return nil
+}
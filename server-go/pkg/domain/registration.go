@@ -12,17 +12,38 @@ const (
 	RegistrationStatusConfirmed              RegistrationStatus = "CONFIRMED"                // участник принят в игру
 	RegistrationStatusCancelledBeforePayment RegistrationStatus = "CANCELLED_BEFORE_PAYMENT" // не используется в играх (можно удалить, если не нужен)
 	RegistrationStatusCancelledAfterPayment  RegistrationStatus = "CANCELLED_AFTER_PAYMENT"  // не используется в играх (можно удалить, если не нужен)
-	RegistrationStatusRefunded               RegistrationStatus = "REFUNDED"                 // не используется в играх (можно удалить)
+	RegistrationStatusRefunded               RegistrationStatus = "REFUNDED"                 // оплата возвращена после отмены (YooKassa refund.succeeded)
 	RegistrationStatusCancelled              RegistrationStatus = "CANCELLED"                // заявка отклонена (оргом) или отменена (участником) до подтверждения
 	RegistrationStatusLeft                   RegistrationStatus = "LEFT"                     // участник вышел после подтверждения
 )
 
+// SeatStatuses статусы регистраций, которые занимают место на событии. В играх место занимает только
+// принятый участник, INVITED мест не занимает
+func SeatStatuses(eventType EventType) []RegistrationStatus {
+	if eventType == EventTypeGame {
+		return []RegistrationStatus{RegistrationStatusConfirmed}
+	}
+	return []RegistrationStatus{RegistrationStatusPending, RegistrationStatusConfirmed}
+}
+
+// OccupiesSeat занимает ли регистрация в этом статусе место на событии типа eventType
+func (s RegistrationStatus) OccupiesSeat(eventType EventType) bool {
+	for _, status := range SeatStatuses(eventType) {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Registration struct {
 	UserID    string             `json:"userId"`
 	EventID   string             `json:"eventId"`
 	Status    RegistrationStatus `json:"status"`
 	CreatedAt time.Time          `json:"createdAt"`
 	UpdatedAt time.Time          `json:"updatedAt"`
+	// PendingSince когда регистрация перешла в ожидание оплаты, от него считается срок оплаты. nil вне PENDING
+	PendingSince *time.Time      `json:"-"`
 	User      *User              `json:"user,omitempty"`
 	Event     *EventForRegistration `json:"event,omitempty"`
 }
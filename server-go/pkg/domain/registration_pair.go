@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+type RegistrationPairStatus string
+
+const (
+	RegistrationPairStatusInvited    RegistrationPairStatus = "invited"    // Партнер приглашен, места не заняты
+	RegistrationPairStatusRegistered RegistrationPairStatus = "registered" // Приглашение принято, оба партнера зарегистрированы
+	RegistrationPairStatusWaitlisted RegistrationPairStatus = "waitlisted" // Приглашение принято, но мест для пары нет — пара в листе ожидания
+	RegistrationPairStatusDeclined   RegistrationPairStatus = "declined"   // Партнер отклонил приглашение
+	RegistrationPairStatusCancelled  RegistrationPairStatus = "cancelled"  // Пара отменена одним из партнеров
+)
+
+// RegistrationPairActiveStatuses статусы, в которых пользователь не может состоять в другой паре на то же событие
+var RegistrationPairActiveStatuses = []RegistrationPairStatus{
+	RegistrationPairStatusInvited,
+	RegistrationPairStatusRegistered,
+	RegistrationPairStatusWaitlisted,
+}
+
+type PairPaymentMode string
+
+const (
+	PairPaymentModeSplit   PairPaymentMode = "split"   // Каждый партнер оплачивает свое место
+	PairPaymentModeCaptain PairPaymentMode = "captain" // Пригласивший оплачивает оба места
+)
+
+// RegistrationPair пара игроков, регистрирующихся на турнир вместе
+type RegistrationPair struct {
+	ID          string                 `json:"id"`
+	EventID     string                 `json:"eventId"`
+	CaptainID   string                 `json:"captainId"`
+	PartnerID   string                 `json:"partnerId"`
+	Status      RegistrationPairStatus `json:"status"`
+	PaymentMode PairPaymentMode        `json:"paymentMode"`
+	Captain     *User                  `json:"captain,omitempty"`
+	Partner     *User                  `json:"partner,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+// PartnerOf возвращает ID второго игрока пары
+func (p *RegistrationPair) PartnerOf(userID string) string {
+	if p.CaptainID == userID {
+		return p.PartnerID
+	}
+	return p.CaptainID
+}
+
+// InvitePartner запрос на регистрацию с партнером: партнер указывается по ID или Telegram username
+type InvitePartner struct {
+	PartnerID       *string         `json:"partnerId,omitempty"`
+	PartnerUsername *string         `json:"partnerUsername,omitempty"`
+	PaymentMode     PairPaymentMode `json:"paymentMode,omitempty" binding:"omitempty,oneof=split captain"`
+}
+
+type CreateRegistrationPair struct {
+	EventID     string
+	CaptainID   string
+	PartnerID   string
+	PaymentMode PairPaymentMode
+}
+
+type FilterRegistrationPair struct {
+	ID      *string `json:"id,omitempty"`
+	EventID *string `json:"eventId,omitempty"`
+	// Пары, в которых пользователь капитан или партнер
+	UserID   *string                  `json:"userId,omitempty"`
+	Statuses []RegistrationPairStatus `json:"statuses,omitempty"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskStatus статус задачи воркера уведомлений (таблица tasks)
+type TaskStatus string
+
+const (
+	TaskStatusPending    TaskStatus = "pending"
+	TaskStatusProcessing TaskStatus = "processing"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusFailed     TaskStatus = "failed"
+	TaskStatusCancelled  TaskStatus = "cancelled"
+	TaskStatusDead       TaskStatus = "dead" // Попытки исчерпаны или ошибка неустранима
+)
+
+// Task задача воркера уведомлений. Задачи создает воркер из сообщений NATS, сервер только читает
+// их и перезапускает из админки
+type Task struct {
+	ID          string          `json:"id"`
+	TaskType    string          `json:"taskType"`
+	Status      TaskStatus      `json:"status"`
+	ExecuteAt   time.Time       `json:"executeAt"`
+	Data        json.RawMessage `json:"data" swaggertype:"object"`
+	RetryCount  int             `json:"retryCount"`
+	MaxRetries  int             `json:"maxRetries"`
+	LastError   *string         `json:"lastError,omitempty"`
+	LockedBy    *string         `json:"lockedBy,omitempty"`
+	LockedUntil *time.Time      `json:"lockedUntil,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+type FilterTask struct {
+	ID             *string      `json:"id,omitempty"`
+	TaskType       *string      `json:"taskType,omitempty"`
+	Status         *TaskStatus  `json:"status,omitempty"`
+	Statuses       []TaskStatus `json:"statuses,omitempty"`
+	UserTelegramID *int64       `json:"userTelegramId,omitempty"`
+	TournamentID   *string      `json:"tournamentId,omitempty"`
+	ExecuteFrom    *time.Time   `json:"executeFrom,omitempty"` // execute_at >= ExecuteFrom
+	ExecuteTo      *time.Time   `json:"executeTo,omitempty"`   // execute_at < ExecuteTo
+	Limit          *uint64      `json:"limit,omitempty"`
+	Offset         *uint64      `json:"offset,omitempty"`
+}
+
+// PatchTask изменение задачи из админки
+type PatchTask struct {
+	Status       *TaskStatus
+	ExecuteAt    *time.Time
+	ResetRetries bool
+}
+
+type RescheduleTask struct {
+	ExecuteAt time.Time `json:"executeAt" binding:"required"`
+}
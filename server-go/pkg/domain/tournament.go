@@ -0,0 +1,129 @@
+package domain
+
+import "time"
+
+type TournamentFormat string
+
+const (
+	TournamentFormatAmericano         TournamentFormat = "americano"          // Игроки меняют партнеров каждый раунд, расписание составляется сразу
+	TournamentFormatMexicano          TournamentFormat = "mexicano"           // Пары каждого следующего раунда составляются по текущей таблице
+	TournamentFormatRoundRobin        TournamentFormat = "round_robin"        // Постоянные пары, каждая играет с каждой
+	TournamentFormatSingleElimination TournamentFormat = "single_elimination" // Постоянные пары, игра на выбывание
+)
+
+type TournamentStatus string
+
+const (
+	TournamentStatusInProgress TournamentStatus = "in_progress" // Расписание составлено, идут матчи
+	TournamentStatusCompleted  TournamentStatus = "completed"   // Все матчи сыграны
+)
+
+type TournamentPairing string
+
+const (
+	TournamentPairingBalanced TournamentPairing = "balanced" // Сильнейший по рангу с самым слабым
+	TournamentPairingRandom   TournamentPairing = "random"   // Случайные пары
+	TournamentPairingManual   TournamentPairing = "manual"   // Пары задает организатор
+)
+
+type MatchStatus string
+
+const (
+	MatchStatusScheduled MatchStatus = "scheduled" // Матч ожидает результата
+	MatchStatusCompleted MatchStatus = "completed" // Счет записан
+)
+
+// IsIndividual возвращает true для форматов, где игроки меняют партнеров и таблица ведется по игрокам
+func (f TournamentFormat) IsIndividual() bool {
+	return f == TournamentFormatAmericano || f == TournamentFormatMexicano
+}
+
+type Tournament struct {
+	EventID        string                `json:"eventId"`
+	Format         TournamentFormat      `json:"format"`
+	Courts         int                   `json:"courts"`
+	PointsPerMatch int                   `json:"pointsPerMatch"`
+	Rounds         int                   `json:"rounds"`
+	Status         TournamentStatus      `json:"status"`
+	Teams          []*TournamentTeam     `json:"teams"`
+	Matches        []*TournamentMatch    `json:"matches"`
+	Standings      []*TournamentStanding `json:"standings"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+}
+
+// TournamentTeam постоянная пара для форматов round_robin и single_elimination
+type TournamentTeam struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Seed      int      `json:"seed"`
+	PlayerIDs []string `json:"playerIds"`
+}
+
+type TournamentMatch struct {
+	ID            string      `json:"id"`
+	Round         int         `json:"round"`
+	Court         int         `json:"court"`
+	Position      int         `json:"position"`
+	Team1ID       *string     `json:"team1Id,omitempty"`
+	Team2ID       *string     `json:"team2Id,omitempty"`
+	Team1Players  []string    `json:"team1Players"`
+	Team2Players  []string    `json:"team2Players"`
+	Score1        *int        `json:"score1,omitempty"`
+	Score2        *int        `json:"score2,omitempty"`
+	Status        MatchStatus `json:"status"`
+	NextMatchID   *string     `json:"nextMatchId,omitempty"`
+	NextMatchSlot *int        `json:"nextMatchSlot,omitempty"`
+}
+
+// TournamentStanding строка турнирной таблицы: по паре (TeamID) или по игроку (UserID)
+type TournamentStanding struct {
+	Position      int     `json:"position"`
+	TeamID        *string `json:"teamId,omitempty"`
+	UserID        *string `json:"userId,omitempty"`
+	Name          string  `json:"name"`
+	Played        int     `json:"played"`
+	Won           int     `json:"won"`
+	Drawn         int     `json:"drawn"`
+	Lost          int     `json:"lost"`
+	PointsFor     int     `json:"pointsFor"`
+	PointsAgainst int     `json:"pointsAgainst"`
+	PointsDiff    int     `json:"pointsDiff"`
+}
+
+type CreateTournament struct {
+	Format TournamentFormat `json:"format" binding:"required,oneof=americano mexicano round_robin single_elimination"`
+	// Количество кортов, на которых одновременно идут матчи
+	Courts int `json:"courts" binding:"required,min=1"`
+	// Сумма очков в матче для americano/mexicano (например, 24), 0 — без ограничения
+	PointsPerMatch int `json:"pointsPerMatch" binding:"min=0"`
+	// Количество раундов для americano/mexicano, 0 — по числу игроков
+	Rounds int `json:"rounds" binding:"min=0"`
+	// Способ составления постоянных пар для round_robin и single_elimination
+	Pairing TournamentPairing `json:"pairing,omitempty" binding:"omitempty,oneof=balanced random manual"`
+	// Пары игроков (ID пользователей) при pairing=manual
+	Teams [][]string `json:"teams,omitempty"`
+}
+
+type RecordMatchScore struct {
+	Score1 *int `json:"score1" binding:"required,min=0"`
+	Score2 *int `json:"score2" binding:"required,min=0"`
+	// Исправление уже записанного счета. Без него повторная запись счета матча отклоняется
+	Correction bool `json:"correction,omitempty"`
+}
+
+type FilterTournamentMatch struct {
+	ID      *string `json:"id,omitempty"`
+	EventID *string `json:"eventId,omitempty"`
+	Round   *int    `json:"round,omitempty"`
+}
+
+type PatchTournamentMatch struct {
+	Team1ID      *string      `json:"team1Id,omitempty"`
+	Team2ID      *string      `json:"team2Id,omitempty"`
+	Team1Players *[]string    `json:"team1Players,omitempty"`
+	Team2Players *[]string    `json:"team2Players,omitempty"`
+	Score1       *int         `json:"score1,omitempty"`
+	Score2       *int         `json:"score2,omitempty"`
+	Status       *MatchStatus `json:"status,omitempty"`
+}
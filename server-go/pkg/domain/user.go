@@ -18,7 +18,11 @@ type User struct {
 	PlayingPosition PlayingPosition `json:"playingPosition"`
 	PadelProfiles   string          `json:"padelProfiles"`
 	Loyalty         *Loyalty        `json:"loyalty,omitempty"`
+	LoyaltyLocked   bool            `json:"loyaltyLocked"`
+	RatedMatches    int             `json:"ratedMatches"` // Матчи, по которым ранг пересчитан автоматически; пока их больше нуля, ранг нельзя менять самому
 	IsRegistered    bool            `json:"isRegistered"`
+	// Заполняется только для текущего пользователя
+	NotificationSettings *NotificationSettings `json:"notificationSettings,omitempty"`
 }
 
 type UserTGData struct {
@@ -27,6 +31,7 @@ type UserTGData struct {
 	FirstName        string `json:"firstName"`
 	LastName         string `json:"lastName"`
 	Avatar           string `json:"avatar"`
+	LanguageCode     string `json:"languageCode"` // language_code из Telegram, по нему выбирается язык уведомлений
 }
 
 type CreateUser struct {
@@ -46,6 +51,9 @@ type PatchUser struct {
 	PadelProfiles    *string          `json:"padelProfiles"`
 	IsRegistered     *bool            `json:"isRegistered"`
 	LoyaltyID        *int             `json:"loyaltyId"`
+	LoyaltyLocked    *bool            `json:"-"`
+	LanguageCode     *string          `json:"-"`
+	NotificationSettings *PatchNotificationSettings `json:"notificationSettings"`
 }
 
 type FilterUser struct {
@@ -55,6 +63,7 @@ type FilterUser struct {
 	FirstName        *string `json:"firstName"`
 	LastName         *string `json:"lastName"`
 	FilterByUserClubs *string `json:"filterByUserClubs,omitempty"`
+	ClubID           *string `json:"clubId,omitempty"` // Участники клуба
 }
 
 type AdminPatchUser struct {
@@ -69,4 +78,7 @@ type AdminPatchUser struct {
 	PadelProfiles    *string          `json:"padelProfiles"`
 	IsRegistered     *bool            `json:"isRegistered"`
 	LoyaltyID        *int             `json:"loyaltyId"`
+	// Закрепить уровень лояльности за пользователем, чтобы его не менял автоматический пересчет.
+	// При ручной смене loyaltyId уровень закрепляется, если не передано иное
+	LoyaltyLocked    *bool            `json:"loyaltyLocked"`
 }
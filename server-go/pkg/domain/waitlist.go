@@ -7,6 +7,7 @@ type Waitlist struct {
 	UserID  string    `json:"userId"`
 	EventID string    `json:"eventId"`
 	Date    time.Time `json:"date"`
+	PairID  *string   `json:"pairId,omitempty"` // Запись пары: капитан и партнер регистрируются вместе
 	User    *User     `json:"user,omitempty"`
 }
 
@@ -15,13 +16,22 @@ type WaitlistUser struct {
 	Date time.Time `json:"date"`
 }
 
+// WaitlistPosition место пользователя в листе ожидания события, Position считается с 1
+type WaitlistPosition struct {
+	Event    *Event `json:"event"`
+	Position int    `json:"position"`
+	Total    int    `json:"total"`
+}
+
 type CreateWaitlist struct {
-	UserID  string `json:"userId" binding:"required"`
-	EventID string `json:"eventId" binding:"required"`
+	UserID  string  `json:"userId" binding:"required"`
+	EventID string  `json:"eventId" binding:"required"`
+	PairID  *string `json:"-"`
 }
 
 type FilterWaitlist struct {
 	ID      *int    `json:"id,omitempty"`
 	UserID  *string `json:"userId,omitempty"`
 	EventID *string `json:"eventId,omitempty"`
+	PairID  *string `json:"pairId,omitempty"`
 } 
\ No newline at end of file
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+type WaitlistOfferStatus string
+
+const (
+	WaitlistOfferStatusPending   WaitlistOfferStatus = "pending"   // Ждет ответа, место удерживается
+	WaitlistOfferStatusAccepted  WaitlistOfferStatus = "accepted"  // Пользователь согласился и зарегистрирован
+	WaitlistOfferStatusDeclined  WaitlistOfferStatus = "declined"  // Пользователь отказался
+	WaitlistOfferStatusExpired   WaitlistOfferStatus = "expired"   // Окно ответа истекло
+	WaitlistOfferStatusCancelled WaitlistOfferStatus = "cancelled" // Предложение отозвано, например место больше не свободно
+)
+
+// WaitlistOffer предложение освободившегося места следующему в листе ожидания.
+// Пока предложение ждет ответа, место за пользователем удерживается
+type WaitlistOffer struct {
+	ID        string              `json:"id"`
+	EventID   string              `json:"eventId"`
+	UserID    string              `json:"userId"`
+	Status    WaitlistOfferStatus `json:"status"`
+	ExpiresAt time.Time           `json:"expiresAt"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+type CreateWaitlistOffer struct {
+	EventID   string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+type FilterWaitlistOffer struct {
+	ID            *string
+	EventID       *string
+	UserID        *string
+	Statuses      []WaitlistOfferStatus
+	ExpiresBefore *time.Time
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent запись журнала входящих уведомлений платежного провайдера
+type WebhookEvent struct {
+	ID          string          `json:"id"`
+	Event       string          `json:"event"`
+	ObjectID    string          `json:"objectId"`
+	Status      string          `json:"status"`
+	Payload     json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	Attempts    int             `json:"attempts"`
+	LastError   *string         `json:"lastError,omitempty"`
+	ProcessedAt *time.Time      `json:"processedAt,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+type CreateWebhookEvent struct {
+	Event    string          `json:"event" binding:"required"`
+	ObjectID string          `json:"objectId" binding:"required"`
+	Status   string          `json:"status"`
+	Payload  json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+}
+
+type PatchWebhookEvent struct {
+	LastError   *string    `json:"lastError,omitempty"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+}
+
+type FilterWebhookEvent struct {
+	ID       *string `json:"id,omitempty"`
+	Event    *string `json:"event,omitempty"`
+	ObjectID *string `json:"objectId,omitempty"`
+}
@@ -0,0 +1,171 @@
+package admin_broadcasts
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+type Handler struct {
+	broadcastCase *usecase.Broadcast
+}
+
+func NewHandler(broadcastCase *usecase.Broadcast) *Handler {
+	return &Handler{
+		broadcastCase: broadcastCase,
+	}
+}
+
+// CreateBroadcast создает рассылку
+// @Summary Create broadcast (Admin)
+// @Description Send a text, photo or media group message to confirmed event participants, club members, an event waitlist or users matching a filter. Recipients are fixed at creation. Without scheduledAt the broadcast is sent immediately.
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param broadcast body domain.CreateBroadcast true "Message, target and schedule"
+// @Success 201 {object} domain.Broadcast
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/broadcasts [post]
+func (h *Handler) CreateBroadcast(c *gin.Context) {
+	var createBroadcast domain.CreateBroadcast
+	if err := c.ShouldBindJSON(&createBroadcast); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	broadcast, err := h.broadcastCase.Create(ctx, &createBroadcast)
+	if abortIfBroadcastErr(c, err, "Failed to create broadcast") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, broadcast)
+}
+
+// FilterBroadcasts фильтрует рассылки
+// @Summary Filter broadcasts (Admin)
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter body domain.FilterBroadcast true "Broadcast filter"
+// @Success 200 {array} domain.Broadcast
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/broadcasts/filter [post]
+func (h *Handler) FilterBroadcasts(c *gin.Context) {
+	var filter domain.FilterBroadcast
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	broadcasts, err := h.broadcastCase.Filter(c, &filter)
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to filter broadcasts") {
+		return
+	}
+
+	c.JSON(http.StatusOK, broadcasts)
+}
+
+// GetBroadcast получает рассылку со статистикой доставки
+// @Summary Get broadcast (Admin)
+// @Tags admin-broadcasts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} domain.Broadcast
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/broadcasts/{id} [get]
+func (h *Handler) GetBroadcast(c *gin.Context) {
+	broadcast, err := h.broadcastCase.Get(c, c.Param("id"))
+	if abortIfBroadcastErr(c, err, "Failed to get broadcast") {
+		return
+	}
+
+	c.JSON(http.StatusOK, broadcast)
+}
+
+// FilterRecipients получает статусы доставки по получателям рассылки
+// @Summary Filter broadcast recipients (Admin)
+// @Description Delivery status per recipient: pending, sending, sent, failed (with error) or blocked (the user blocked the bot).
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Broadcast ID"
+// @Param filter body domain.FilterBroadcastRecipient false "Recipient filter"
+// @Success 200 {array} domain.BroadcastRecipient
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/broadcasts/{id}/recipients [post]
+func (h *Handler) FilterRecipients(c *gin.Context) {
+	var filter domain.FilterBroadcastRecipient
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&filter); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	filter.BroadcastID = c.Param("id")
+
+	recipients, err := h.broadcastCase.Recipients(c, &filter)
+	if abortIfBroadcastErr(c, err, "Failed to filter broadcast recipients") {
+		return
+	}
+
+	c.JSON(http.StatusOK, recipients)
+}
+
+// CancelBroadcast отменяет запланированную рассылку или останавливает отправку
+// @Summary Cancel broadcast (Admin)
+// @Description Cancel a scheduled broadcast or stop one that is being sent. Recipients that were not reached stay pending.
+// @Tags admin-broadcasts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} domain.Broadcast
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/broadcasts/{id}/cancel [post]
+func (h *Handler) CancelBroadcast(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	broadcast, err := h.broadcastCase.Cancel(ctx, c.Param("id"))
+	if abortIfBroadcastErr(c, err, "Failed to cancel broadcast") {
+		return
+	}
+
+	c.JSON(http.StatusOK, broadcast)
+}
+
+func abortIfBroadcastErr(c *gin.Context, err error, msg string) bool {
+	switch {
+	case errors.Is(err, usecase.ErrBroadcastNotFound):
+		return ginerr.AbortIfErr(c, err, http.StatusNotFound, msg)
+	case errors.Is(err, usecase.ErrBroadcastInvalidStatus):
+		return ginerr.AbortIfErr(c, err, http.StatusConflict, msg)
+	case errors.Is(err, usecase.ErrInvalidBroadcast):
+		return ginerr.AbortIfErr(c, err, http.StatusBadRequest, msg)
+	default:
+		return ginerr.AbortIfErr(c, err, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,32 @@
+package admin_broadcasts
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+func Setup(r *gin.RouterGroup, useCases usecase.Cases) {
+	handler := NewHandler(useCases.Broadcast)
+
+	adminBroadcastsGroup := r.Group("/admin/broadcasts")
+	{
+		// Все эндпоинты требуют JWT авторизации
+		adminBroadcastsGroup.Use(middlewares.RequireAdminJWT(useCases.AdminUser))
+
+		// POST /admin/broadcasts - создать рассылку (любой админ)
+		adminBroadcastsGroup.POST("", handler.CreateBroadcast)
+
+		// POST /admin/broadcasts/filter - фильтровать рассылки (любой админ)
+		adminBroadcastsGroup.POST("/filter", handler.FilterBroadcasts)
+
+		// GET /admin/broadcasts/:id - получить рассылку со статистикой доставки (любой админ)
+		adminBroadcastsGroup.GET("/:id", handler.GetBroadcast)
+
+		// POST /admin/broadcasts/:id/recipients - статусы доставки по получателям (любой админ)
+		adminBroadcastsGroup.POST("/:id/recipients", handler.FilterRecipients)
+
+		// POST /admin/broadcasts/:id/cancel - отменить запланированную рассылку или остановить отправку (любой админ)
+		adminBroadcastsGroup.POST("/:id/cancel", handler.CancelBroadcast)
+	}
+}
@@ -1,6 +1,7 @@
 package admin_courts
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -66,6 +67,10 @@ func (h *Handler) CreateCourt(c *gin.Context) {
 	}
 
 	id, err := h.courtCase.Create(usecase.NewContext(c, nil), &createData)
+	if errors.Is(err, usecase.ErrInvalidCourt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to create court") {
 		return
 	}
@@ -143,6 +148,10 @@ func (h *Handler) UpdateCourt(c *gin.Context) {
 
 	err := h.courtCase.Update(usecase.NewContext(c, nil), id, &patchData)
 	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCourt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err.Error() == fmt.Sprintf("court with id %s not found", id) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -12,12 +12,16 @@ import (
 )
 
 type Handler struct {
-	eventCase *usecase.Event
+	eventCase      *usecase.Event
+	tournamentCase *usecase.Tournament
+	seriesCase     *usecase.EventSeries
 }
 
-func NewHandler(eventCase *usecase.Event) *Handler {
+func NewHandler(eventCase *usecase.Event, tournamentCase *usecase.Tournament, seriesCase *usecase.EventSeries) *Handler {
 	return &Handler{
-		eventCase: eventCase,
+		eventCase:      eventCase,
+		tournamentCase: tournamentCase,
+		seriesCase:     seriesCase,
 	}
 }
 
@@ -0,0 +1,196 @@
+package admin_events
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+// CreateSeries создает серию повторяющихся событий
+// @Summary Create event series (Admin)
+// @Description Create recurring events from a template and a recurrence rule. Occurrences are created ahead for the configured horizon.
+// @Tags admin-events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param series body domain.CreateEventSeries true "Template and recurrence rule"
+// @Success 201 {object} domain.EventSeries
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/series [post]
+func (h *Handler) CreateSeries(c *gin.Context) {
+	var createSeries domain.CreateEventSeries
+	if err := c.ShouldBindJSON(&createSeries); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	if createSeries.Template.OrganizerID == "" {
+		createSeries.Template.OrganizerID = admin.UserID
+	}
+	ctx := usecase.NewContext(c, admin.User)
+
+	series, err := h.seriesCase.Create(ctx, &createSeries)
+	if abortIfSeriesErr(c, err, "Failed to create event series") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, series)
+}
+
+// FilterSeries получает список серий
+// @Summary Filter event series (Admin)
+// @Tags admin-events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter body domain.FilterEventSeries true "Series filter"
+// @Success 200 {array} domain.EventSeries
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/series/filter [post]
+func (h *Handler) FilterSeries(c *gin.Context) {
+	var filter domain.FilterEventSeries
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seriesList, err := h.seriesCase.Filter(c, &filter)
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to filter event series") {
+		return
+	}
+
+	c.JSON(http.StatusOK, seriesList)
+}
+
+// GetSeries возвращает серию. Занятия серии доступны через фильтр событий по seriesId
+// @Summary Get event series (Admin)
+// @Tags admin-events
+// @Produce json
+// @Security BearerAuth
+// @Param series_id path string true "Series ID"
+// @Success 200 {object} domain.EventSeries
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/series/{series_id} [get]
+func (h *Handler) GetSeries(c *gin.Context) {
+	series, err := h.seriesCase.Get(c, c.Param("series_id"))
+	if abortIfSeriesErr(c, err, "Failed to get event series") {
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// PatchSeries изменяет все будущие занятия серии. Отдельное занятие изменяется через PATCH /admin/events/{id}
+// @Summary Update all future occurrences (Admin)
+// @Description Update the series template and all upcoming occurrences starting from "from" (now by default). For startTime and endTime only the time of day is used.
+// @Tags admin-events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param series_id path string true "Series ID"
+// @Param series body domain.UpdateEventSeries true "Series update"
+// @Success 200 {object} domain.EventSeries
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/series/{series_id} [patch]
+func (h *Handler) PatchSeries(c *gin.Context) {
+	var updateSeries domain.UpdateEventSeries
+	if err := c.ShouldBindJSON(&updateSeries); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	series, err := h.seriesCase.UpdateFuture(ctx, c.Param("series_id"), &updateSeries)
+	if abortIfSeriesErr(c, err, "Failed to update event series") {
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// CancelSeries отменяет серию с указанного момента
+// @Summary Cancel event series (Admin)
+// @Description Stop the series and cancel upcoming occurrences starting from "from" (now by default) with their registrations, refunds, waitlists and scheduled reminders.
+// @Tags admin-events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param series_id path string true "Series ID"
+// @Param cancel body domain.CancelEventSeries false "Cancel from"
+// @Success 200 {object} domain.EventSeries
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/series/{series_id}/cancel [post]
+func (h *Handler) CancelSeries(c *gin.Context) {
+	var cancelSeries domain.CancelEventSeries
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&cancelSeries); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	series, err := h.seriesCase.Cancel(ctx, c.Param("series_id"), &cancelSeries)
+	if abortIfSeriesErr(c, err, "Failed to cancel event series") {
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// CancelEvent отменяет одно событие (или одно занятие серии) вместе с регистрациями
+// @Summary Cancel event (Admin)
+// @Description Cancel a single event or series occurrence with its registrations, refunds, waitlist and scheduled reminders.
+// @Tags admin-events
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Success 200 {object} domain.Event
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/{id}/cancel [post]
+func (h *Handler) CancelEvent(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	event, err := h.seriesCase.CancelOccurrence(ctx, c.Param("id"))
+	if abortIfSeriesErr(c, err, "Failed to cancel event") {
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+func abortIfSeriesErr(c *gin.Context, err error, msg string) bool {
+	switch {
+	case errors.Is(err, usecase.ErrEventSeriesNotFound):
+		return ginerr.AbortIfErr(c, err, http.StatusNotFound, msg)
+	case errors.Is(err, usecase.ErrInvalidEventSeries):
+		return ginerr.AbortIfErr(c, err, http.StatusBadRequest, msg)
+	default:
+		return ginerr.AbortIfErr(c, err, http.StatusInternalServerError, msg)
+	}
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func Setup(r *gin.RouterGroup, useCases usecase.Cases) {
-	handler := NewHandler(useCases.Event)
+	handler := NewHandler(useCases.Event, useCases.Tournament, useCases.EventSeries)
 	
 	adminEventsGroup := r.Group("/admin/events")
 	{
@@ -25,5 +25,22 @@ func Setup(r *gin.RouterGroup, useCases usecase.Cases) {
 		
 		// DELETE /admin/events/:id - удалить событие (любой админ)
 		adminEventsGroup.DELETE("/:id", handler.DeleteEvent)
+
+		// POST /admin/events/:id/cancel - отменить событие или одно занятие серии вместе с регистрациями (любой админ)
+		adminEventsGroup.POST("/:id/cancel", handler.CancelEvent)
+
+		// Серии повторяющихся событий (любой админ)
+		adminEventsGroup.POST("/series", handler.CreateSeries)
+		adminEventsGroup.POST("/series/filter", handler.FilterSeries)
+		adminEventsGroup.GET("/series/:series_id", handler.GetSeries)
+		adminEventsGroup.PATCH("/series/:series_id", handler.PatchSeries)
+		adminEventsGroup.POST("/series/:series_id/cancel", handler.CancelSeries)
+
+		// Турнирная сетка события (любой админ)
+		adminEventsGroup.POST("/:id/tournament", handler.CreateTournament)
+		adminEventsGroup.GET("/:id/tournament", handler.GetTournament)
+		adminEventsGroup.DELETE("/:id/tournament", handler.DeleteTournament)
+		adminEventsGroup.PATCH("/:id/tournament/matches/:match_id", handler.RecordMatchScore)
+		adminEventsGroup.POST("/:id/tournament/rounds", handler.GenerateNextRound)
 	}
 } 
\ No newline at end of file
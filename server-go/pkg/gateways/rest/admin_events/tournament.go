@@ -0,0 +1,159 @@
+package admin_events
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+// CreateTournament составляет расписание турнира
+// @Summary Create tournament schedule (Admin)
+// @Description Generate tournament schedule from confirmed participants. Re-generation is allowed until any match result is recorded.
+// @Tags admin-events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param tournament body domain.CreateTournament true "Tournament settings"
+// @Success 201 {object} domain.Tournament
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/{id}/tournament [post]
+func (h *Handler) CreateTournament(c *gin.Context) {
+	var createTournament domain.CreateTournament
+	if err := c.ShouldBindJSON(&createTournament); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	tournament, err := h.tournamentCase.Create(ctx, c.Param("id"), &createTournament)
+	if abortIfTournamentErr(c, err, "Failed to create tournament") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, tournament)
+}
+
+// GetTournament возвращает расписание и таблицу турнира
+// @Summary Get tournament (Admin)
+// @Tags admin-events
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Success 200 {object} domain.Tournament
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/{id}/tournament [get]
+func (h *Handler) GetTournament(c *gin.Context) {
+	tournament, err := h.tournamentCase.Get(c, c.Param("id"))
+	if abortIfTournamentErr(c, err, "Failed to get tournament") {
+		return
+	}
+
+	c.JSON(http.StatusOK, tournament)
+}
+
+// DeleteTournament удаляет расписание и результаты турнира
+// @Summary Delete tournament (Admin)
+// @Tags admin-events
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Success 204 "Tournament deleted successfully"
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/{id}/tournament [delete]
+func (h *Handler) DeleteTournament(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	err := h.tournamentCase.Delete(ctx, c.Param("id"))
+	if abortIfTournamentErr(c, err, "Failed to delete tournament") {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RecordMatchScore записывает счет матча
+// @Summary Record match score (Admin)
+// @Description Record match score. A recorded score is changed only with correction=true. In single elimination the winner advances to the next match.
+// @Tags admin-events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param match_id path string true "Match ID"
+// @Param score body domain.RecordMatchScore true "Match score"
+// @Success 200 {object} domain.Tournament
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/{id}/tournament/matches/{match_id} [patch]
+func (h *Handler) RecordMatchScore(c *gin.Context) {
+	var score domain.RecordMatchScore
+	if err := c.ShouldBindJSON(&score); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	tournament, err := h.tournamentCase.RecordMatchScore(ctx, c.Param("id"), c.Param("match_id"), &score)
+	if abortIfTournamentErr(c, err, "Failed to record match score") {
+		return
+	}
+
+	c.JSON(http.StatusOK, tournament)
+}
+
+// GenerateNextRound составляет следующий раунд mexicano
+// @Summary Generate next mexicano round (Admin)
+// @Description Generate next round by current standings. Previous round must be completed.
+// @Tags admin-events
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Success 201 {object} domain.Tournament
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/events/{id}/tournament/rounds [post]
+func (h *Handler) GenerateNextRound(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	tournament, err := h.tournamentCase.GenerateNextRound(ctx, c.Param("id"))
+	if abortIfTournamentErr(c, err, "Failed to generate next round") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, tournament)
+}
+
+func abortIfTournamentErr(c *gin.Context, err error, msg string) bool {
+	switch {
+	case errors.Is(err, usecase.ErrTournamentNotFound):
+		return ginerr.AbortIfErr(c, err, http.StatusNotFound, msg)
+	case errors.Is(err, usecase.ErrInvalidTournament):
+		return ginerr.AbortIfErr(c, err, http.StatusBadRequest, msg)
+	case errors.Is(err, usecase.ErrMatchAlreadyRecorded):
+		return ginerr.AbortIfErr(c, err, http.StatusConflict, msg)
+	default:
+		return ginerr.AbortIfErr(c, err, http.StatusInternalServerError, msg)
+	}
+}
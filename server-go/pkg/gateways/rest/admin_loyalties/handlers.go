@@ -176,4 +176,24 @@ func (h *Handler) DeleteLoyalty(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Loyalty level deleted successfully"})
-} 
\ No newline at end of file
+}
+
+// RecalculateLoyalties запускает пересчет уровней лояльности всех пользователей
+// @Summary Recalculate loyalty levels (Admin)
+// @Description Recalculate loyalty levels of all users by level rules. Users with a locked level are skipped. Available only for superuser.
+// @Tags admin-loyalties
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.MessageResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/loyalties/recalculate [post]
+func (h *Handler) RecalculateLoyalties(c *gin.Context) {
+	err := h.loyaltyCase.RecalculateAll(c)
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to recalculate loyalties") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Loyalty levels recalculated successfully"})
+}
@@ -24,6 +24,9 @@ func Setup(r *gin.RouterGroup, useCases usecase.Cases) {
 		// POST /admin/loyalties - создать новый уровень лояльности (только суперпользователь)
 		superUserGroup.POST("", handler.CreateLoyalty)
 		
+		// POST /admin/loyalties/recalculate - пересчитать уровни всех пользователей (только суперпользователь)
+		superUserGroup.POST("/recalculate", handler.RecalculateLoyalties)
+		
 		// PATCH /admin/loyalties/:id - обновить уровень лояльности (только суперпользователь)
 		superUserGroup.PATCH("/:id", handler.PatchLoyalty)
 		
@@ -0,0 +1,160 @@
+package admin_message_templates
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+type Handler struct {
+	templateCase *usecase.MessageTemplate
+}
+
+func NewHandler(templateCase *usecase.MessageTemplate) *Handler {
+	return &Handler{
+		templateCase: templateCase,
+	}
+}
+
+// GetKeys возвращает ключи шаблонов
+// @Summary Get message template keys (Admin)
+// @Description Known template keys with descriptions and sample data available in templates.
+// @Tags admin-message-templates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.MessageTemplateKey
+// @Failure 401 {object} domain.ErrorResponse
+// @Router /admin/message-templates/keys [get]
+func (h *Handler) GetKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, h.templateCase.Keys())
+}
+
+// FilterTemplates фильтрует шаблоны
+// @Summary Filter message templates (Admin)
+// @Tags admin-message-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter body domain.FilterMessageTemplate true "Template filter"
+// @Success 200 {array} domain.MessageTemplate
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/message-templates/filter [post]
+func (h *Handler) FilterTemplates(c *gin.Context) {
+	var filter domain.FilterMessageTemplate
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	templates, err := h.templateCase.Filter(c, &filter)
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to filter message templates") {
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// PreviewTemplate отображает шаблон
+// @Summary Preview message template (Admin)
+// @Description Render the given body, or the stored template for the language with fallback, against the given data or the key's sample data.
+// @Tags admin-message-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preview body domain.PreviewMessageTemplate true "Template and data"
+// @Success 200 {object} domain.RenderedMessage
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/message-templates/preview [post]
+func (h *Handler) PreviewTemplate(c *gin.Context) {
+	var preview domain.PreviewMessageTemplate
+	if err := c.ShouldBindJSON(&preview); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := h.templateCase.Preview(c, &preview)
+	if abortIfTemplateErr(c, err, "Failed to preview message template") {
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// UpsertTemplate создает или изменяет вариант шаблона
+// @Summary Save message template (Admin)
+// @Description Create or replace the template for the key and language. The template must render against the key's sample data.
+// @Tags admin-message-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Template key"
+// @Param language path string true "Language code (ru, en, pt-br)"
+// @Param template body domain.UpsertMessageTemplate true "Template"
+// @Success 200 {object} domain.MessageTemplate
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/message-templates/{key}/{language} [put]
+func (h *Handler) UpsertTemplate(c *gin.Context) {
+	var upsert domain.UpsertMessageTemplate
+	if err := c.ShouldBindJSON(&upsert); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	template, err := h.templateCase.Upsert(ctx, c.Param("key"), c.Param("language"), &upsert)
+	if abortIfTemplateErr(c, err, "Failed to save message template") {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate удаляет вариант шаблона
+// @Summary Delete message template (Admin)
+// @Description Delete a language variant. The default language variant cannot be deleted.
+// @Tags admin-message-templates
+// @Security BearerAuth
+// @Param key path string true "Template key"
+// @Param language path string true "Language code"
+// @Success 204
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/message-templates/{key}/{language} [delete]
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	err := h.templateCase.Delete(ctx, c.Param("key"), c.Param("language"))
+	if abortIfTemplateErr(c, err, "Failed to delete message template") {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func abortIfTemplateErr(c *gin.Context, err error, msg string) bool {
+	switch {
+	case errors.Is(err, usecase.ErrMessageTemplateNotFound):
+		return ginerr.AbortIfErr(c, err, http.StatusNotFound, msg)
+	case errors.Is(err, usecase.ErrInvalidMessageTemplate):
+		return ginerr.AbortIfErr(c, err, http.StatusBadRequest, msg)
+	default:
+		return ginerr.AbortIfErr(c, err, http.StatusInternalServerError, msg)
+	}
+}
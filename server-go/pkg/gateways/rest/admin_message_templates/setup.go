@@ -0,0 +1,32 @@
+package admin_message_templates
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+func Setup(r *gin.RouterGroup, useCases usecase.Cases) {
+	handler := NewHandler(useCases.MessageTemplate)
+
+	adminTemplatesGroup := r.Group("/admin/message-templates")
+	{
+		// Все эндпоинты требуют JWT авторизации
+		adminTemplatesGroup.Use(middlewares.RequireAdminJWT(useCases.AdminUser))
+
+		// GET /admin/message-templates/keys - ключи шаблонов с примерами данных (любой админ)
+		adminTemplatesGroup.GET("/keys", handler.GetKeys)
+
+		// POST /admin/message-templates/filter - фильтровать шаблоны (любой админ)
+		adminTemplatesGroup.POST("/filter", handler.FilterTemplates)
+
+		// POST /admin/message-templates/preview - отобразить шаблон на примере данных (любой админ)
+		adminTemplatesGroup.POST("/preview", handler.PreviewTemplate)
+
+		// PUT /admin/message-templates/:key/:language - создать или изменить вариант шаблона (любой админ)
+		adminTemplatesGroup.PUT("/:key/:language", handler.UpsertTemplate)
+
+		// DELETE /admin/message-templates/:key/:language - удалить вариант шаблона (любой админ)
+		adminTemplatesGroup.DELETE("/:key/:language", handler.DeleteTemplate)
+	}
+}
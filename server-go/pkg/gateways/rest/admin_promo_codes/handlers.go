@@ -0,0 +1,162 @@
+package admin_promo_codes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+type Handler struct {
+	promoCodeCase *usecase.PromoCode
+}
+
+func NewHandler(promoCodeCase *usecase.PromoCode) *Handler {
+	return &Handler{
+		promoCodeCase: promoCodeCase,
+	}
+}
+
+// GetAllPromoCodes получает все промокоды для админов
+// @Summary Get all promo codes (Admin)
+// @Description Get all promo codes with usage counters. Available for any admin.
+// @Tags admin-promo-codes
+// @Produce json
+// @Security BearerAuth
+// @Param isActive query bool false "Filter by active flag"
+// @Success 200 {array} domain.PromoCode
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/promo-codes [get]
+func (h *Handler) GetAllPromoCodes(c *gin.Context) {
+	filter := &domain.FilterPromoCode{}
+	if isActive := c.Query("isActive"); isActive != "" {
+		value := isActive == "true"
+		filter.IsActive = &value
+	}
+
+	promoCodes, err := h.promoCodeCase.Filter(usecase.NewContext(c, nil), filter)
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to get promo codes") {
+		return
+	}
+
+	c.JSON(http.StatusOK, promoCodes)
+}
+
+// GetPromoCode получает промокод по ID
+// @Summary Get promo code (Admin)
+// @Description Get promo code by ID. Available for any admin.
+// @Tags admin-promo-codes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promo code ID"
+// @Success 200 {object} domain.PromoCode
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/promo-codes/{id} [get]
+func (h *Handler) GetPromoCode(c *gin.Context) {
+	promoCode, err := h.promoCodeCase.GetByID(usecase.NewContext(c, nil), c.Param("id"))
+	if errors.Is(err, usecase.ErrPromoCodeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to get promo code") {
+		return
+	}
+
+	c.JSON(http.StatusOK, promoCode)
+}
+
+// CreatePromoCode создает новый промокод
+// @Summary Create promo code (Admin)
+// @Description Create a new promo code. Available only for superuser.
+// @Tags admin-promo-codes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param promoCode body domain.CreatePromoCode true "Promo code data"
+// @Success 201 {object} domain.PromoCode
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/promo-codes [post]
+func (h *Handler) CreatePromoCode(c *gin.Context) {
+	var createData domain.CreatePromoCode
+	if err := c.ShouldBindJSON(&createData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promoCode, err := h.promoCodeCase.Create(usecase.NewContext(c, nil), &createData)
+	if ginerr.AbortIfErr(c, err, http.StatusBadRequest, "Failed to create promo code") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, promoCode)
+}
+
+// PatchPromoCode обновляет промокод
+// @Summary Update promo code (Admin)
+// @Description Update promo code data. Available only for superuser.
+// @Tags admin-promo-codes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promo code ID"
+// @Param promoCode body domain.PatchPromoCode true "Promo code update data"
+// @Success 200 {object} domain.PromoCode
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/promo-codes/{id} [patch]
+func (h *Handler) PatchPromoCode(c *gin.Context) {
+	var patchData domain.PatchPromoCode
+	if err := c.ShouldBindJSON(&patchData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promoCode, err := h.promoCodeCase.Patch(usecase.NewContext(c, nil), c.Param("id"), &patchData)
+	if errors.Is(err, usecase.ErrPromoCodeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if ginerr.AbortIfErr(c, err, http.StatusBadRequest, "Failed to update promo code") {
+		return
+	}
+
+	c.JSON(http.StatusOK, promoCode)
+}
+
+// DeletePromoCode удаляет промокод
+// @Summary Delete promo code (Admin)
+// @Description Delete promo code that was never used in payments. Used promo codes can only be deactivated. Available only for superuser.
+// @Tags admin-promo-codes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promo code ID"
+// @Success 200 {object} domain.MessageResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /admin/promo-codes/{id} [delete]
+func (h *Handler) DeletePromoCode(c *gin.Context) {
+	err := h.promoCodeCase.Delete(usecase.NewContext(c, nil), c.Param("id"))
+	if errors.Is(err, usecase.ErrPromoCodeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if ginerr.AbortIfErr(c, err, http.StatusBadRequest, "Failed to delete promo code") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deleted successfully"})
+}
@@ -0,0 +1,36 @@
+package admin_promo_codes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+func Setup(r *gin.RouterGroup, useCases usecase.Cases) {
+	handler := NewHandler(useCases.PromoCode)
+
+	adminPromoCodesGroup := r.Group("/admin/promo-codes")
+	{
+		// Все эндпоинты требуют JWT авторизации
+		adminPromoCodesGroup.Use(middlewares.RequireAdminJWT(useCases.AdminUser))
+
+		// GET /admin/promo-codes - получить все промокоды (любой админ)
+		adminPromoCodesGroup.GET("", handler.GetAllPromoCodes)
+
+		// GET /admin/promo-codes/:id - получить промокод (любой админ)
+		adminPromoCodesGroup.GET("/:id", handler.GetPromoCode)
+
+		// Эндпоинты для изменения данных требуют права суперпользователя
+		superUserGroup := adminPromoCodesGroup.Group("")
+		superUserGroup.Use(middlewares.RequireAdminSuperuser())
+
+		// POST /admin/promo-codes - создать промокод (только суперпользователь)
+		superUserGroup.POST("", handler.CreatePromoCode)
+
+		// PATCH /admin/promo-codes/:id - обновить промокод (только суперпользователь)
+		superUserGroup.PATCH("/:id", handler.PatchPromoCode)
+
+		// DELETE /admin/promo-codes/:id - удалить неиспользованный промокод (только суперпользователь)
+		superUserGroup.DELETE("/:id", handler.DeletePromoCode)
+	}
+}
@@ -0,0 +1,192 @@
+package admin_tasks
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+type Handler struct {
+	taskCase *usecase.Task
+}
+
+func NewHandler(taskCase *usecase.Task) *Handler {
+	return &Handler{
+		taskCase: taskCase,
+	}
+}
+
+// FilterTasks фильтрует задачи воркера уведомлений
+// @Summary Filter notification tasks (Admin)
+// @Description Filter worker tasks by id, type, status, user Telegram id, tournament id and execute_at range. Use status "dead" to find tasks that exhausted their retries.
+// @Tags admin-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter body domain.FilterTask true "Task filter"
+// @Success 200 {array} domain.Task
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/tasks/filter [post]
+func (h *Handler) FilterTasks(c *gin.Context) {
+	var filter domain.FilterTask
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tasks, err := h.taskCase.Filter(c, &filter)
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to filter tasks") {
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetTask получает задачу
+// @Summary Get notification task (Admin)
+// @Tags admin-tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} domain.Task
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/tasks/{id} [get]
+func (h *Handler) GetTask(c *gin.Context) {
+	task, err := h.taskCase.Get(c, c.Param("id"))
+	if abortIfTaskErr(c, err, "Failed to get task") {
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// RedriveTask перезапускает задачу в статусе dead или failed
+// @Summary Redrive notification task (Admin)
+// @Description Reset retries of a dead or failed task and execute it as soon as possible.
+// @Tags admin-tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} domain.Task
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/tasks/{id}/redrive [post]
+func (h *Handler) RedriveTask(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	task, err := h.taskCase.Redrive(ctx, c.Param("id"))
+	if abortIfTaskErr(c, err, "Failed to redrive task") {
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// CancelTask отменяет ожидающую задачу
+// @Summary Cancel notification task (Admin)
+// @Description Cancel a pending task. Worker replicas drop it from their schedule.
+// @Tags admin-tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} domain.Task
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/tasks/{id}/cancel [post]
+func (h *Handler) CancelTask(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	task, err := h.taskCase.Cancel(ctx, c.Param("id"))
+	if abortIfTaskErr(c, err, "Failed to cancel task") {
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// RescheduleTask переносит ожидающую задачу
+// @Summary Reschedule notification task (Admin)
+// @Description Change execute_at of a pending task.
+// @Tags admin-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param reschedule body domain.RescheduleTask true "New execution time"
+// @Success 200 {object} domain.Task
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/tasks/{id}/reschedule [post]
+func (h *Handler) RescheduleTask(c *gin.Context) {
+	var reschedule domain.RescheduleTask
+	if err := c.ShouldBindJSON(&reschedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	task, err := h.taskCase.Reschedule(ctx, c.Param("id"), &reschedule)
+	if abortIfTaskErr(c, err, "Failed to reschedule task") {
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// RunTask выполняет задачу немедленно
+// @Summary Run notification task now (Admin)
+// @Description Execute a task immediately, including completed or cancelled ones. Retries are reset.
+// @Tags admin-tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} domain.Task
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/tasks/{id}/run [post]
+func (h *Handler) RunTask(c *gin.Context) {
+	admin := middlewares.MustGetAdmin(c)
+	ctx := usecase.NewContext(c, admin.User)
+
+	task, err := h.taskCase.Run(ctx, c.Param("id"))
+	if abortIfTaskErr(c, err, "Failed to run task") {
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+func abortIfTaskErr(c *gin.Context, err error, msg string) bool {
+	switch {
+	case errors.Is(err, usecase.ErrTaskNotFound):
+		return ginerr.AbortIfErr(c, err, http.StatusNotFound, msg)
+	case errors.Is(err, usecase.ErrTaskInvalidStatus):
+		return ginerr.AbortIfErr(c, err, http.StatusConflict, msg)
+	case errors.Is(err, usecase.ErrInvalidTask):
+		return ginerr.AbortIfErr(c, err, http.StatusBadRequest, msg)
+	default:
+		return ginerr.AbortIfErr(c, err, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,35 @@
+package admin_tasks
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+func Setup(r *gin.RouterGroup, useCases usecase.Cases) {
+	handler := NewHandler(useCases.Task)
+
+	adminTasksGroup := r.Group("/admin/tasks")
+	{
+		// Все эндпоинты требуют JWT авторизации
+		adminTasksGroup.Use(middlewares.RequireAdminJWT(useCases.AdminUser))
+
+		// POST /admin/tasks/filter - фильтровать задачи воркера уведомлений (любой админ)
+		adminTasksGroup.POST("/filter", handler.FilterTasks)
+
+		// GET /admin/tasks/:id - получить задачу вместе с данными и последней ошибкой (любой админ)
+		adminTasksGroup.GET("/:id", handler.GetTask)
+
+		// POST /admin/tasks/:id/redrive - перезапустить задачу в статусе dead или failed (любой админ)
+		adminTasksGroup.POST("/:id/redrive", handler.RedriveTask)
+
+		// POST /admin/tasks/:id/run - выполнить задачу немедленно (любой админ)
+		adminTasksGroup.POST("/:id/run", handler.RunTask)
+
+		// POST /admin/tasks/:id/cancel - отменить ожидающую задачу (любой админ)
+		adminTasksGroup.POST("/:id/cancel", handler.CancelTask)
+
+		// POST /admin/tasks/:id/reschedule - перенести ожидающую задачу (любой админ)
+		adminTasksGroup.POST("/:id/reschedule", handler.RescheduleTask)
+	}
+}
@@ -29,4 +29,6 @@ func Setup(r *gin.RouterGroup, cases usecase.Cases) {
 	g.GET("/:event_id/waitlist", handler.getWaitlist)            // получить список ожидания
 	g.POST("/:event_id/waitlist", handler.addToWaitlist)         // добавить себя в список ожидания
 	g.DELETE("/:event_id/waitlist", handler.removeFromWaitlist)  // убрать себя из списка ожидания
+	g.GET("/:event_id/tournament", handler.getTournament)                    // расписание и результаты турнира
+	g.GET("/:event_id/tournament/standings", handler.getTournamentStandings) // турнирная таблица
 } 
\ No newline at end of file
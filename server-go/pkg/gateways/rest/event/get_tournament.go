@@ -0,0 +1,63 @@
+package event
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+// GetTournament godoc
+// @Summary Get event tournament
+// @Description Get tournament schedule, match results and standings
+// @Tags events
+// @Accept json
+// @Produce json
+// @Schemes http https
+// @Param event_id path string true "Event ID"
+// @Success 200 {object} domain.Tournament "Tournament"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Tournament not found"
+// @Failure 500 "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /events/{event_id}/tournament [get]
+func (h *Handler) getTournament(c *gin.Context) {
+	tournament, err := h.cases.Tournament.Get(c, c.Param("event_id"))
+	if errors.Is(err, usecase.ErrTournamentNotFound) {
+		ginerr.AbortIfErr(c, err, http.StatusNotFound, "tournament not found")
+		return
+	}
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "failed to get tournament") {
+		return
+	}
+
+	c.JSON(http.StatusOK, tournament)
+}
+
+// GetTournamentStandings godoc
+// @Summary Get event tournament standings
+// @Tags events
+// @Accept json
+// @Produce json
+// @Schemes http https
+// @Param event_id path string true "Event ID"
+// @Success 200 {array} domain.TournamentStanding "Standings"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Tournament not found"
+// @Failure 500 "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /events/{event_id}/tournament/standings [get]
+func (h *Handler) getTournamentStandings(c *gin.Context) {
+	standings, err := h.cases.Tournament.GetStandings(c, c.Param("event_id"))
+	if errors.Is(err, usecase.ErrTournamentNotFound) {
+		ginerr.AbortIfErr(c, err, http.StatusNotFound, "tournament not found")
+		return
+	}
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "failed to get tournament standings") {
+		return
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
@@ -35,6 +35,7 @@ func ExtractUserTGData() gin.HandlerFunc {
 		LastName:         parsed.User.LastName,
 		TelegramUsername: parsed.User.Username, // Пустая строка будет конвертирована в NULL при сохранении в БД
 		Avatar:           parsed.User.PhotoURL,
+		LanguageCode:     parsed.User.LanguageCode,
 	})
 
 		c.Next()
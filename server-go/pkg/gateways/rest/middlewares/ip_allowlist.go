@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireIPAllowlist пропускает только запросы с IP из списка (адреса или CIDR-подсети).
+// Если enabled == false, проверка отключена.
+func RequireIPAllowlist(enabled bool, allowed []string) gin.HandlerFunc {
+	nets := parseAllowedNets(allowed)
+
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		ip := net.ParseIP(c.ClientIP())
+		if ip != nil {
+			for _, n := range nets {
+				if n.Contains(ip) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		slog.Warn("Request from IP outside of allowlist",
+			"ip", c.ClientIP(),
+			"path", c.Request.URL.Path)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "ip is not allowed"})
+	}
+}
+
+func parseAllowedNets(allowed []string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, entry := range allowed {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				slog.Warn("Invalid IP in allowlist", "entry", entry)
+				continue
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			slog.Warn("Invalid CIDR in allowlist", "entry", entry, "error", err)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
@@ -1,6 +1,7 @@
 package registration
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -9,8 +10,14 @@ import (
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
 	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
 )
 
+// CreatePaymentRequest необязательное тело запроса на создание платежа
+type CreatePaymentRequest struct {
+	PromoCode string `json:"promoCode,omitempty"`
+}
+
 // PaymentResponse представляет ответ с ссылкой на платеж
 type PaymentResponse struct {
 	PaymentURL string `json:"payment_url"`
@@ -21,12 +28,15 @@ type PaymentResponse struct {
 // @Description Creates a payment for event registration and returns payment URL
 // @Tags registrations
 // @Security ApiKeyAuth
+// @Accept json
 // @Param event_id path string true "Event ID"
+// @Param request body CreatePaymentRequest false "Optional promo code"
 // @Success 201 {object} PaymentResponse "Payment URL and ID"
-// @Failure 400 "Bad request"
+// @Failure 400 "Bad request or promo code is not applicable"
 // @Failure 401 "Unauthorized"
-// @Failure 403 "Forbidden - no pending registration found"
-// @Failure 404 "Event not found"
+// @Failure 403 "Forbidden - no pending registration found or registration is paid by the pair captain"
+// @Failure 404 "Event or promo code not found"
+// @Failure 409 "Pending payment already exists"
 // @Failure 500 "Internal server error"
 // @Router /registrations/{event_id}/payment [post]
 func (h *Handler) createPayment(c *gin.Context) {
@@ -36,6 +46,15 @@ func (h *Handler) createPayment(c *gin.Context) {
 		return
 	}
 
+	// Тело необязательное: промокод передается только при необходимости
+	var req CreatePaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	// Получаем пользователя из контекста
 	user := middlewares.MustGetUser(c)
 
@@ -73,11 +92,27 @@ func (h *Handler) createPayment(c *gin.Context) {
 		return
 	}
 
-	// Используем готовый метод для создания платежа через ЮKassa
+	// Используем готовый метод для создания платежа через платежного провайдера
 	// Он создает платеж и возвращает ссылку на оплату
 	// Формируем return URL для Telegram Web App
 	returnURL := fmt.Sprintf("https://t.me/%s/%s?startapp=%s", h.config.TG.BotUsername, h.config.TG.WebAppName, eventID)
-	payment, err := h.cases.Payment.CreateYooKassaPayment(c, user, eventID, returnURL)
+	payment, err := h.cases.Payment.CreateProviderPayment(c, user, eventID, returnURL, req.PromoCode)
+	if errors.Is(err, usecase.ErrPromoCodeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, usecase.ErrPromoCodeNotApplicable) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, usecase.ErrPartnerPaysForPair) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, usecase.ErrPendingPaymentExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "failed to create payment") {
 		return
 	}
@@ -0,0 +1,141 @@
+package registration
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+// @Summary Invite tournament partner
+// @Description Creates a pair registration and sends the partner a Telegram invite. Partner is set by user id or Telegram username
+// @Tags registrations
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param event_id path string true "Event ID"
+// @Param request body domain.InvitePartner true "Partner and payment mode"
+// @Success 201 {object} domain.RegistrationPair "Created pair"
+// @Failure 400 "Bad request, partner not found or pair rank does not fit the event"
+// @Failure 401 "Unauthorized"
+// @Failure 500 "Internal server error"
+// @Router /registrations/{event_id}/pair [post]
+func (h *Handler) invitePartner(c *gin.Context) {
+	var req domain.InvitePartner
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := middlewares.MustGetUser(c)
+
+	pair, err := h.cases.RegistrationPair.Invite(c, user, c.Param("event_id"), &req)
+	if abortIfPairErr(c, err, "failed to invite partner") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, pair)
+}
+
+// @Summary Get my tournament pair
+// @Description Returns the active pair of the current user for the event
+// @Tags registrations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param event_id path string true "Event ID"
+// @Success 200 {object} domain.RegistrationPair "Pair"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Pair not found"
+// @Failure 500 "Internal server error"
+// @Router /registrations/{event_id}/pair [get]
+func (h *Handler) getMyPair(c *gin.Context) {
+	user := middlewares.MustGetUser(c)
+
+	pair, err := h.cases.RegistrationPair.GetMyPair(c, user, c.Param("event_id"))
+	if abortIfPairErr(c, err, "failed to get pair") {
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// @Summary Accept partner invite
+// @Description Accepts the invite. The pair takes two seats at once or joins the waitlist as a unit
+// @Tags registrations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param event_id path string true "Event ID"
+// @Success 200 {object} domain.RegistrationPair "Registered or waitlisted pair"
+// @Failure 400 "Invite is no longer active or pair cannot be registered"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Invite not found"
+// @Failure 500 "Internal server error"
+// @Router /registrations/{event_id}/pair/accept [post]
+func (h *Handler) acceptPair(c *gin.Context) {
+	user := middlewares.MustGetUser(c)
+
+	pair, err := h.cases.RegistrationPair.Accept(c, user, c.Param("event_id"))
+	if abortIfPairErr(c, err, "failed to accept invite") {
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// @Summary Decline partner invite
+// @Tags registrations
+// @Security ApiKeyAuth
+// @Param event_id path string true "Event ID"
+// @Success 204 "Invite declined"
+// @Failure 400 "Invite is no longer active"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Invite not found"
+// @Failure 500 "Internal server error"
+// @Router /registrations/{event_id}/pair/decline [post]
+func (h *Handler) declinePair(c *gin.Context) {
+	user := middlewares.MustGetUser(c)
+
+	err := h.cases.RegistrationPair.Decline(c, user, c.Param("event_id"))
+	if abortIfPairErr(c, err, "failed to decline invite") {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Cancel pair
+// @Description Cancels a pending invite or a waitlisted pair. A registered pair is cancelled by cancelling the registration of either partner
+// @Tags registrations
+// @Security ApiKeyAuth
+// @Param event_id path string true "Event ID"
+// @Success 204 "Pair cancelled"
+// @Failure 400 "Pair cannot be cancelled"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Pair not found"
+// @Failure 500 "Internal server error"
+// @Router /registrations/{event_id}/pair [delete]
+func (h *Handler) cancelPair(c *gin.Context) {
+	user := middlewares.MustGetUser(c)
+
+	err := h.cases.RegistrationPair.Cancel(c, user, c.Param("event_id"))
+	if abortIfPairErr(c, err, "failed to cancel pair") {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func abortIfPairErr(c *gin.Context, err error, msg string) bool {
+	switch {
+	case errors.Is(err, usecase.ErrPairNotFound):
+		return ginerr.AbortIfErr(c, err, http.StatusNotFound, msg)
+	case errors.Is(err, usecase.ErrInvalidPair):
+		return ginerr.AbortIfErr(c, err, http.StatusBadRequest, msg)
+	default:
+		return ginerr.AbortIfErr(c, err, http.StatusInternalServerError, msg)
+	}
+}
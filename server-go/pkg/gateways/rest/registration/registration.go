@@ -31,6 +31,13 @@ func Setup(r *gin.RouterGroup, cases usecase.Cases, cfg *config.Config) {
 	g.POST("/:event_id/cancel", handler.cancelRegistration)             // отмена до оплаты (CANCELLED_BEFORE_PAYMENT)
 	g.POST("/:event_id/cancel-paid", handler.cancelPaidRegistration)    // отмена после оплаты (CANCELLED_AFTER_PAYMENT)
 	g.POST("/:event_id/reactivate", handler.reactivateRegistration)     // повторная активация
+
+	// Регистрация на турнир парой
+	g.GET("/:event_id/pair", handler.getMyPair)                         // моя пара на турнир
+	g.POST("/:event_id/pair", handler.invitePartner)                    // пригласить партнера
+	g.DELETE("/:event_id/pair", handler.cancelPair)                     // отменить приглашение или заявку пары в листе ожидания
+	g.POST("/:event_id/pair/accept", handler.acceptPair)                // принять приглашение
+	g.POST("/:event_id/pair/decline", handler.declinePair)              // отклонить приглашение
 	
 	// Новые эндпоинты для организаторов игр
 	g.PUT("/:event_id/:user_id/approve", handler.approveRegistration)   // одобрить заявку (PENDING -> CONFIRMED)
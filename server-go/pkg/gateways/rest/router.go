@@ -10,9 +10,13 @@ import (
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_auth"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_clubs"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_courts"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_broadcasts"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_events"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_loyalties"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_message_templates"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_promo_codes"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_registrations"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_tasks"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_users"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/admin_waitlist"
 
@@ -26,13 +30,12 @@ import (
 
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/user"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/webhook"
-	"github.com/shampsdev/go-telegram-template/pkg/notifications"
 	"github.com/shampsdev/go-telegram-template/pkg/usecase"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func setupRouter(ctx context.Context, r *gin.Engine, useCases usecase.Cases, cfg *config.Config, notificationService *notifications.NotificationService) {
+func setupRouter(ctx context.Context, r *gin.Engine, useCases usecase.Cases, cfg *config.Config) {
 	r.HandleMethodNotAllowed = true
 	r.Use(middlewares.AllowOrigin())
 	r.Use(middlewares.Logger(ctx))
@@ -49,14 +52,18 @@ func setupRouter(ctx context.Context, r *gin.Engine, useCases usecase.Cases, cfg
 	registration.Setup(v1, useCases, cfg)
 
 	loyalty.Setup(v1, useCases)
-	webhook.Setup(v1, useCases, cfg, notificationService)
+	webhook.Setup(v1, useCases, cfg)
 	admin_auth.Setup(v1, useCases)
 	admin_clubs.Setup(v1, useCases)
 	admin_users.Setup(v1, useCases)
 	admin_admins.Setup(v1, useCases)
 	admin_loyalties.Setup(v1, useCases)
+	admin_promo_codes.Setup(v1, useCases)
 	admin_courts.Setup(v1, useCases)
 	admin_events.Setup(v1, useCases)
 	admin_registrations.Setup(v1, useCases)
 	admin_waitlist.Setup(v1, useCases)
+	admin_tasks.Setup(v1, useCases)
+	admin_broadcasts.Setup(v1, useCases)
+	admin_message_templates.Setup(v1, useCases)
 }
@@ -3,6 +3,7 @@ package rest
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -10,7 +11,6 @@ import (
 	"github.com/penglongli/gin-metrics/ginmetrics"
 	"github.com/shampsdev/go-telegram-template/pkg/config"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
-	"github.com/shampsdev/go-telegram-template/pkg/notifications"
 	"github.com/shampsdev/go-telegram-template/pkg/usecase"
 	"github.com/tj/go-spin"
 	"golang.org/x/sync/errgroup"
@@ -23,10 +23,16 @@ type Server struct {
 	Router     *gin.Engine
 }
 
-func NewServer(ctx context.Context, cfg *config.Config, useCases usecase.Cases, notificationService *notifications.NotificationService) *Server {
+func NewServer(ctx context.Context, cfg *config.Config, useCases usecase.Cases) *Server {
 	r := gin.New()
 	r.Use(gin.Recovery())
 
+	// ClientIP (проверка IP отправителя вебхуков) верит X-Forwarded-For только от своих прокси
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		slog.Error("Invalid trusted proxies, X-Forwarded-For is ignored", "trusted_proxies", cfg.Server.TrustedProxies, "error", err)
+		r.SetTrustedProxies(nil)
+	}
+
 	m := ginmetrics.GetMonitor()
 	m.SetMetricPath("/metrics")
 	m.Use(r)
@@ -40,7 +46,7 @@ func NewServer(ctx context.Context, cfg *config.Config, useCases usecase.Cases,
 	}
 
 	middlewares.BotToken = cfg.TG.BotToken
-	setupRouter(ctx, s.Router, useCases, cfg, notificationService)
+	setupRouter(ctx, s.Router, useCases, cfg)
 
 	return s
 }
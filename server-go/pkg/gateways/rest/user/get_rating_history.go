@@ -0,0 +1,76 @@
+package user
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+type ratingHistoryQuery struct {
+	From *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// GetMyRatingHistory godoc
+// @Summary Get current user rank history
+// @Tags users
+// @Accept json
+// @Produce json
+// @Schemes http https
+// @Param from query string false "Period start (RFC3339)"
+// @Param to query string false "Period end (RFC3339)"
+// @Success 200 {array} domain.RatingChange "Rank changes in chronological order"
+// @Failure 400 "Bad Request"
+// @Failure 500 "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /users/me/rating-history [get]
+func GetMyRatingHistory(ratingCase *usecase.Rating) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := middlewares.MustGetUser(c)
+		getRatingHistory(c, ratingCase, user.ID)
+	}
+}
+
+// GetUserRatingHistory godoc
+// @Summary Get user rank history
+// @Tags users
+// @Accept json
+// @Produce json
+// @Schemes http https
+// @Param id path string true "User ID"
+// @Param from query string false "Period start (RFC3339)"
+// @Param to query string false "Period end (RFC3339)"
+// @Success 200 {array} domain.RatingChange "Rank changes in chronological order"
+// @Failure 400 "Bad Request"
+// @Failure 500 "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /users/{id}/rating-history [get]
+func GetUserRatingHistory(ratingCase *usecase.Rating) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		getRatingHistory(c, ratingCase, c.Param("id"))
+	}
+}
+
+func getRatingHistory(c *gin.Context, ratingCase *usecase.Rating, userID string) {
+	var query ratingHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		ginerr.AbortIfErr(c, err, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	history, err := ratingCase.GetHistory(c, &domain.FilterRatingChange{
+		UserID: &userID,
+		From:   query.From,
+		To:     query.To,
+	})
+	if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "failed to get rating history") {
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
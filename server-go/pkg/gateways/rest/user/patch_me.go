@@ -1,6 +1,7 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -18,7 +19,8 @@ import (
 // @Schemes http https
 // @Param user body domain.PatchUser true "User update data"
 // @Success 200 {object} domain.User "Updated user data"
-// @Failure 400 "Bad Request"
+// @Failure 400 "Bad Request or invalid notification settings"
+// @Failure 403 "Rank is calculated from match results"
 // @Failure 500 "Internal Server Error"
 // @Security ApiKeyAuth
 // @Router /users/me [patch]
@@ -33,6 +35,14 @@ func PatchMe(userCase *usecase.User) gin.HandlerFunc {
 		}
 
 		updatedUser, err := userCase.PatchMe(usecase.NewContext(c, user), &patchUser)
+		if errors.Is(err, usecase.ErrInvalidNotificationSettings) {
+			ginerr.AbortIfErr(c, err, http.StatusBadRequest, "invalid notification settings")
+			return
+		}
+		if errors.Is(err, usecase.ErrRankLocked) {
+			ginerr.AbortIfErr(c, err, http.StatusForbidden, "rank is locked")
+			return
+		}
 		if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "failed to update user") {
 			return
 		}
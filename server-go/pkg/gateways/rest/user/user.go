@@ -18,4 +18,6 @@ func Setup(r *gin.RouterGroup, cases usecase.Cases) {
 		GET("", GetMe(cases.User)).PATCH("", PatchMe(cases.User))
 	gAuth.Group("/me").GET("/bio", GetUserBio(cases.User))
 	gAuth.Group("/me").GET("/admin", GetMeAdmin(cases.AdminUser))
+	gAuth.Group("/me").GET("/rating-history", GetMyRatingHistory(cases.Rating))
+	gAuth.GET("/:id/rating-history", GetUserRatingHistory(cases.Rating))
 }
@@ -3,10 +3,13 @@ package webhook
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/shampsdev/go-telegram-template/pkg/config"
-	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/middlewares"
 	"github.com/shampsdev/go-telegram-template/pkg/usecase"
 )
 
-func Setup(r *gin.RouterGroup, cases usecase.Cases, cfg *config.Config, notificationService *notifications.NotificationService) {
-	r.POST("/yookassa_webhook", YooKassaWebhook(cases.Payment, cases.Registration, cases.Event, notificationService, cfg))
-} 
\ No newline at end of file
+func Setup(r *gin.RouterGroup, cases usecase.Cases, cfg *config.Config) {
+	r.POST("/yookassa_webhook",
+		middlewares.RequireIPAllowlist(cfg.YooKassa.WebhookIPCheck, cfg.YooKassa.WebhookAllowedIPs),
+		YooKassaWebhook(cases.Payment, cases.Refund, cases.WebhookEvent),
+	)
+}
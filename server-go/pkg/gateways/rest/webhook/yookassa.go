@@ -1,14 +1,16 @@
 package webhook
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/rvinnie/yookassa-sdk-go/yookassa"
-	"github.com/shampsdev/go-telegram-template/pkg/config"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
 	"github.com/shampsdev/go-telegram-template/pkg/gateways/rest/ginerr"
-	"github.com/shampsdev/go-telegram-template/pkg/notifications"
 	"github.com/shampsdev/go-telegram-template/pkg/usecase"
 )
 
@@ -25,85 +27,101 @@ type WebhookEvent struct {
 
 // YooKassaWebhook godoc
 // @Summary YooKassa webhook for payment notifications
-// @Description Handles payment status updates from YooKassa
+// @Description Handles payment and refund status updates from YooKassa. Each event+object pair is processed exactly once
 // @Tags webhook
 // @Accept json
 // @Produce json
 // @Param event body WebhookEvent true "Webhook event"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/yookassa_webhook [post]
-func YooKassaWebhook(paymentUseCase *usecase.Payment, registrationUseCase *usecase.Registration, eventUseCase *usecase.Event, notificationService *notifications.NotificationService, cfg *config.Config) gin.HandlerFunc {
+func YooKassaWebhook(paymentUseCase *usecase.Payment, refundUseCase *usecase.Refund, webhookEventUseCase *usecase.WebhookEvent) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		body, err := c.GetRawData()
+		if ginerr.AbortIfErr(c, err, http.StatusBadRequest, "Invalid request body") {
+			return
+		}
+
 		var event WebhookEvent
-		if err := c.ShouldBindJSON(&event); err != nil {
-			if ginerr.AbortIfErr(c, err, http.StatusBadRequest, "Invalid request body") {
-				return
-			}
+		err = json.Unmarshal(body, &event)
+		if ginerr.AbortIfErr(c, err, http.StatusBadRequest, "Invalid request body") {
+			return
 		}
 
-		paymentID := event.Object.ID
-		
-		client := yookassa.NewClient(cfg.YooKassa.ShopID, cfg.YooKassa.SecretKey)
-		paymentHandler := yookassa.NewPaymentHandler(client)
-		
-		yooPayment, err := paymentHandler.FindPayment(paymentID)
-		if err != nil {
-			if ginerr.AbortIfErr(c, err, http.StatusBadRequest, "Payment not found in YooKassa") {
-				return
-			}
+		if event.Event == "" || event.Object.ID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "event and object.id are required"})
+			return
 		}
 
-		if string(yooPayment.Status) != event.Object.Status {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Status mismatch"})
+		journal, err := webhookEventUseCase.Register(c.Request.Context(), &domain.CreateWebhookEvent{
+			Event:    event.Event,
+			ObjectID: event.Object.ID,
+			Status:   event.Object.Status,
+			Payload:  body,
+		})
+		if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to register webhook event") {
 			return
 		}
 
-		payment, err := paymentUseCase.GetPaymentByPaymentID(c.Request.Context(), paymentID)
-		if err != nil {
-			if ginerr.AbortIfErr(c, err, http.StatusNotFound, "Payment not found in database") {
-				return
-			}
+		// Повторная доставка уже обработанного уведомления
+		if journal.ProcessedAt != nil {
+			slog.Info("Webhook event already processed",
+				"event", event.Event,
+				"object_id", event.Object.ID,
+				"attempts", journal.Attempts)
+			c.JSON(http.StatusOK, gin.H{"status": "already processed"})
+			return
 		}
 
-		paymentStatus := domain.PaymentStatus(yooPayment.Status)
-		if payment.Status != paymentStatus {
-			err = paymentUseCase.UpdatePaymentStatus(c.Request.Context(), payment.ID, paymentStatus)
-			if err != nil {
-				if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to update payment status") {
-					return
-				}
-			}
+		if strings.HasPrefix(event.Event, "refund.") {
+			err = processRefundEvent(c.Request.Context(), event, paymentUseCase, refundUseCase)
+		} else {
+			err = processPaymentEvent(c.Request.Context(), event, paymentUseCase)
 		}
 
-		if paymentStatus == domain.PaymentStatusSucceeded && payment.Registration != nil {
-			if payment.Registration.Status == domain.RegistrationStatusPending {
-				// Получаем событие для проверки типа
-				event, err := eventUseCase.GetEventByID(c.Request.Context(), payment.Registration.EventID)
-				if err != nil {
-					if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to get event") {
-						return
-					}
-				}
-				
-				// Активируем регистрацию только для турниров, не для игр
-				if event.Type == domain.EventTypeTournament {
-					err = registrationUseCase.UpdateRegistrationStatus(c.Request.Context(), payment.Registration.UserID, payment.Registration.EventID, domain.RegistrationStatusConfirmed)
-					if err != nil {
-						if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to update registration status") {
-							return
-						}
-					}
-				}
+		if err != nil {
+			if markErr := webhookEventUseCase.MarkFailed(c.Request.Context(), journal.ID, err); markErr != nil {
+				slog.Error("Failed to mark webhook event as failed", "id", journal.ID, "error", markErr)
 			}
-		} else if paymentStatus == domain.PaymentStatusCanceled && payment.Registration != nil {
-			// При отмененном платеже регистрация остается в статусе PENDING,
-			// чтобы пользователь мог создать новый платеж.
-			// Регистрация переходит в CANCELED только при отмене пользователем
+			ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to process webhook event")
+			return
+		}
+
+		err = webhookEventUseCase.MarkProcessed(c.Request.Context(), journal.ID)
+		if ginerr.AbortIfErr(c, err, http.StatusInternalServerError, "Failed to mark webhook event as processed") {
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{"status": "success"})
 	}
-} 
\ No newline at end of file
+}
+
+// processPaymentEvent перепроверяет платеж у провайдера и применяет его актуальный статус.
+// Уведомления могут приходить не по порядку, поэтому источником истины считаем статус из API
+func processPaymentEvent(ctx context.Context, event WebhookEvent, paymentUseCase *usecase.Payment) error {
+	return paymentUseCase.SyncPaymentStatus(ctx, event.Object.ID)
+}
+
+// processRefundEvent обрабатывает уведомления о возвратах (refund.succeeded)
+func processRefundEvent(ctx context.Context, event WebhookEvent, paymentUseCase *usecase.Payment, refundUseCase *usecase.Refund) error {
+	providerRefund, err := paymentUseCase.GetProviderRefund(ctx, event.Object.ID)
+	if err != nil {
+		return fmt.Errorf("refund not found in provider: %w", err)
+	}
+
+	if string(providerRefund.Status) != event.Object.Status {
+		slog.Warn("Webhook status differs from provider refund status",
+			"refund_id", event.Object.ID,
+			"webhook_status", event.Object.Status,
+			"actual_status", providerRefund.Status)
+	}
+
+	refund, err := refundUseCase.GetRefundByRefundID(ctx, providerRefund.ID)
+	if err != nil {
+		return fmt.Errorf("refund not found in database: %w", err)
+	}
+
+	return refundUseCase.ApplyRefundStatus(ctx, refund, providerRefund.Status)
+}
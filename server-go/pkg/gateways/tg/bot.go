@@ -10,6 +10,8 @@ import (
 	"github.com/go-telegram/bot/models"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
 	"github.com/shampsdev/go-telegram-template/pkg/usecase"
 	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
 )
@@ -22,6 +24,8 @@ type Bot struct {
 
 	botUrl    string
 	webAppUrl string
+	// callbackSecret ключ подписи кнопок уведомлений
+	callbackSecret string
 }
 
 func NewBot(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) (*Bot, error) {
@@ -34,13 +38,17 @@ func NewBot(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) (*Bot,
 	if err != nil {
 		return nil, fmt.Errorf("error creating bot: %w", err)
 	}
-	cases := usecase.Setup(ctx, cfg, pool)
+	// Бот не подключается к NATS: задачи воркера, поставленные из бота, публикует relay сервера из outbox
+	notificationService := notifications.NewNotificationService(nil, pg.NewOutboxRepo(pool))
+	cases := usecase.Setup(ctx, cfg, pool, notificationService)
 
 	b := &Bot{
 		Bot:      tgb,
 		cases:    cases,
 		log:      slogx.FromCtx(ctx),
 		messages: NewBotMessages(),
+
+		callbackSecret: cfg.TG.CallbackSecret,
 	}
 
 	me, err := b.GetMe(context.Background())
@@ -55,15 +63,18 @@ func NewBot(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) (*Bot,
 
 func (b *Bot) Run(ctx context.Context) {
 	_, err := b.SetMyCommands(ctx, &bot.SetMyCommandsParams{
-		Commands: []models.BotCommand{
+		Commands: append([]models.BotCommand{
 			{Command: "start", Description: "Запустить бота"},
-		},
+		}, append(playerCommands, notificationCommands...)...),
 	})
 	if err != nil {
 		panic(fmt.Errorf("error setting bot commands: %w", err))
 	}
 
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypeExact, b.handleCommandStart)
+	b.registerPlayerHandlers()
+	b.registerConfirmationHandlers()
+	b.registerNotificationHandlers()
 	b.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		return update.Message != nil && update.Message.Text != "" && update.Message.Text != "/start"
 	}, b.handleAnyText)
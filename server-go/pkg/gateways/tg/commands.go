@@ -0,0 +1,127 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+)
+
+// screen ответ бота: текст без разметки и необязательная клавиатура
+type screen struct {
+	text     string
+	keyboard [][]models.InlineKeyboardButton
+}
+
+func (s *screen) replyMarkup() models.ReplyMarkup {
+	if len(s.keyboard) == 0 {
+		return nil
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: s.keyboard}
+}
+
+// sendScreen отправляет ответ новым сообщением. Текст отправляется без разметки,
+// чтобы не экранировать названия событий и пользовательский ввод
+func (b *Bot) sendScreen(ctx context.Context, update *models.Update, s *screen) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      updateChatID(update),
+		Text:        s.text,
+		ReplyMarkup: s.replyMarkup(),
+	})
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error sending message")
+	}
+}
+
+// editScreen заменяет сообщение, на кнопке которого нажали, например при переходе по страницам
+func (b *Bot) editScreen(ctx context.Context, update *models.Update, s *screen) {
+	message := update.CallbackQuery.Message.Message
+	if message == nil {
+		b.sendScreen(ctx, update, s)
+		return
+	}
+
+	_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      message.Chat.ID,
+		MessageID:   message.ID,
+		Text:        s.text,
+		ReplyMarkup: s.replyMarkup(),
+	})
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error editing message")
+	}
+}
+
+func (b *Bot) reply(ctx context.Context, update *models.Update, text string) {
+	b.sendScreen(ctx, update, &screen{text: text})
+}
+
+// answerCallback убирает индикатор загрузки с кнопки, text показывается всплывающим уведомлением
+func (b *Bot) answerCallback(ctx context.Context, update *models.Update, text string) {
+	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+		Text:            text,
+	})
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error answering callback query")
+	}
+}
+
+// currentUser пользователь приложения, который написал команду или нажал кнопку.
+// Профиль создается при первом открытии мини-приложения
+func (b *Bot) currentUser(ctx context.Context, update *models.Update) (*domain.User, bool) {
+	user, err := b.cases.User.GetByTelegramID(ctx, updateSenderID(update))
+	if errors.Is(err, repo.ErrNotFound) {
+		b.reply(ctx, update, b.messages.UserNotFound())
+		return nil, false
+	}
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error getting user")
+		b.reply(ctx, update, b.messages.CommandError())
+		return nil, false
+	}
+	return user, true
+}
+
+func updateChatID(update *models.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil {
+		return update.CallbackQuery.Message.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil {
+		// Личный чат с ботом совпадает с ID пользователя
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+func updateSenderID(update *models.Update) int64 {
+	if update.Message != nil && update.Message.From != nil {
+		return update.Message.From.ID
+	}
+	if update.CallbackQuery != nil {
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+// commandArgs аргументы команды без самой команды
+func commandArgs(text string) []string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields[1:]
+}
+
+// callbackArg часть данных кнопки после префикса: "events:2" -> "2"
+func callbackArg(update *models.Update, prefix string) string {
+	return strings.TrimPrefix(update.CallbackQuery.Data, prefix)
+}
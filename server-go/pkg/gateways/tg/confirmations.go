@@ -0,0 +1,156 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+	"gopadel/taskcontract/tgcallback"
+)
+
+// registerConfirmationHandlers обработчики подписанных кнопок из уведомлений: напоминаний воркера,
+// заявок на игры для организатора и предложений мест из листа ожидания
+func (b *Bot) registerConfirmationHandlers() {
+	for _, action := range tgcallback.Actions {
+		b.RegisterHandler(bot.HandlerTypeCallbackQueryData, action.Prefix(), bot.MatchTypePrefix, b.handleSignedCallback)
+	}
+}
+
+func (b *Bot) handleSignedCallback(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	action, args, err := tgcallback.Verify(b.callbackSecret, query.From.ID, query.Data)
+	if err != nil || len(args) == 0 {
+		slog.Warn("Rejected notification button with invalid signature",
+			"user_telegram_id", query.From.ID,
+			"data", query.Data)
+		b.answerCallback(ctx, update, b.messages.InvalidButton())
+		return
+	}
+
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		b.answerCallback(ctx, update, "")
+		return
+	}
+
+	// Кнопки предложений из листа ожидания ссылаются на предложение, а не на событие
+	if action == tgcallback.ActionOfferAccept || action == tgcallback.ActionOfferDecline {
+		b.answerCallback(ctx, update, "")
+		b.editScreen(ctx, update, b.answerWaitlistOffer(ctx, user, action, args[0]))
+		return
+	}
+
+	event, err := b.cases.Event.GetEventByID(ctx, args[0])
+	if err != nil {
+		b.answerCallback(ctx, update, b.messages.EventNotFound())
+		return
+	}
+
+	switch action {
+	case tgcallback.ActionAttend:
+		b.answerCallback(ctx, update, b.confirmAttendance(ctx, user, event))
+	case tgcallback.ActionCancel:
+		b.answerCallback(ctx, update, "")
+		b.sendScreen(ctx, update, b.cancelConfirmationScreen(ctx, user, event))
+	case tgcallback.ActionPay:
+		b.answerCallback(ctx, update, "")
+		b.sendScreen(ctx, update, b.paymentScreen(ctx, user, event.ID))
+	case tgcallback.ActionApprove, tgcallback.ActionReject:
+		b.answerCallback(ctx, update, "")
+		if len(args) != 2 {
+			b.reply(ctx, update, b.messages.InvalidButton())
+			return
+		}
+		b.editScreen(ctx, update, b.decideGameRegistration(ctx, user, event, action, args[1]))
+	default:
+		b.answerCallback(ctx, update, b.messages.InvalidButton())
+	}
+}
+
+// confirmAttendance ответ на «Я приду», показывается всплывающим уведомлением, напоминание остается как есть
+func (b *Bot) confirmAttendance(ctx context.Context, user *domain.User, event *domain.Event) string {
+	_, err := b.cases.Registration.ConfirmAttendance(ctx, user, event.ID)
+	switch {
+	case errors.Is(err, usecase.ErrRegistrationNotFound):
+		return b.messages.RegistrationNotActive(event)
+	case err != nil:
+		slogx.FromCtxWithErr(ctx, err).Error("error confirming attendance")
+		return b.messages.CommandError()
+	}
+	return b.messages.AttendanceConfirmed(event)
+}
+
+// decideGameRegistration решение организатора по заявке, заменяет сообщение с заявкой
+func (b *Bot) decideGameRegistration(ctx context.Context, organizer *domain.User, event *domain.Event, action tgcallback.Action, applicantTelegramID string) *screen {
+	telegramID, err := strconv.ParseInt(applicantTelegramID, 10, 64)
+	if err != nil {
+		return &screen{text: b.messages.InvalidButton()}
+	}
+	applicant, err := b.cases.User.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return &screen{text: b.messages.GameRegistrationDecisionUnavailable(event)}
+	}
+
+	decide, text := b.cases.Registration.ApproveGameRegistration, b.messages.GameRegistrationApproved(event, applicant)
+	if action == tgcallback.ActionReject {
+		decide, text = b.cases.Registration.RejectGameRegistration, b.messages.GameRegistrationRejected(event, applicant)
+	}
+
+	_, err = decide(ctx, organizer, applicant.ID, event.ID)
+	switch {
+	case errors.Is(err, usecase.ErrRegistrationNotFound), errors.Is(err, usecase.ErrNotGameEvent), errors.Is(err, usecase.ErrDecisionForbidden):
+		slog.Warn("Game registration decision from bot rejected",
+			"organizer_id", organizer.ID,
+			"user_id", applicant.ID,
+			"event_id", event.ID,
+			"error", err)
+		return &screen{text: b.messages.GameRegistrationDecisionUnavailable(event)}
+	case err != nil:
+		slog.Error("Failed to decide game registration from bot",
+			"organizer_id", organizer.ID,
+			"user_id", applicant.ID,
+			"event_id", event.ID,
+			"error", err)
+		return &screen{text: b.messages.CommandError()}
+	}
+	return &screen{text: text}
+}
+
+// answerWaitlistOffer ответ на предложение места из листа ожидания, заменяет сообщение с предложением
+func (b *Bot) answerWaitlistOffer(ctx context.Context, user *domain.User, action tgcallback.Action, offerID string) *screen {
+	offer, err := b.cases.WaitlistOffer.GetByID(ctx, offerID)
+	if err != nil || offer.UserID != user.ID {
+		return &screen{text: b.messages.InvalidButton()}
+	}
+	event, err := b.cases.Event.GetEventByID(ctx, offer.EventID)
+	if err != nil {
+		return &screen{text: b.messages.EventNotFound()}
+	}
+
+	var registration *domain.Registration
+	if action == tgcallback.ActionOfferAccept {
+		registration, err = b.cases.WaitlistOffer.Accept(ctx, user, offerID)
+	} else {
+		err = b.cases.WaitlistOffer.Decline(ctx, user, offerID)
+	}
+	switch {
+	case errors.Is(err, usecase.ErrWaitlistOfferClosed), errors.Is(err, usecase.ErrWaitlistOfferNotFound):
+		return &screen{text: b.messages.WaitlistOfferClosed(event)}
+	case err != nil:
+		slog.Warn("Failed to answer waitlist offer from bot",
+			"offer_id", offerID,
+			"user_id", user.ID,
+			"event_id", event.ID,
+			"error", err)
+		return &screen{text: b.messages.WaitlistOfferClosed(event)}
+	case registration != nil:
+		return &screen{text: b.messages.WaitlistOfferAccepted(event, registration)}
+	}
+	return &screen{text: b.messages.WaitlistOfferDeclined(event)}
+}
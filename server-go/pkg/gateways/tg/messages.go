@@ -1,5 +1,13 @@
 package tg
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
 type BotMessages struct{}
 
 func NewBotMessages() *BotMessages {
@@ -17,7 +25,227 @@ func (m *BotMessages) Welcome() string {
 func (m *BotMessages) UnknownCommand() string {
 	return `🤖 Привет\! Я бот GoPadel\.
 
-Я пока не умею отвечать на сообщения, но могу показать тебе наше крутое приложение\!
+Вот что я умею:
+/events — предстоящие события
+/my — мои регистрации
+/pay — оплатить участие
+/waitlist — мои места в листах ожидания
+/notifications — настройки уведомлений
+
+А все остальное есть в нашем приложении\! Нажми на кнопку ниже, чтобы открыть мини\-приложение и начать играть 🎾`
+}
+
+func (m *BotMessages) NotificationSettings(settings *domain.NotificationSettings) string {
+	onOff := func(enabled bool) string {
+		if enabled {
+			return "вкл"
+		}
+		return "выкл"
+	}
+
+	var text strings.Builder
+	text.WriteString("🔔 Настройки уведомлений\n\n")
+	if settings.Muted {
+		text.WriteString("Все уведомления отключены. Включить: /unmute\n\n")
+	}
+	fmt.Fprintf(&text, "Напоминания о событиях (reminders): %s\n", onOff(settings.Reminders))
+	fmt.Fprintf(&text, "Оплата (payments): %s\n", onOff(settings.Payments))
+	fmt.Fprintf(&text, "Лист ожидания (waitlist): %s\n", onOff(settings.Waitlist))
+	fmt.Fprintf(&text, "Рассылки клубов (broadcasts): %s\n", onOff(settings.Broadcasts))
+	if settings.QuietHoursStart != nil && settings.QuietHoursEnd != nil {
+		fmt.Fprintf(&text, "Тихие часы: %s–%s (%s)\n", *settings.QuietHoursStart, *settings.QuietHoursEnd, settings.Timezone)
+	} else {
+		fmt.Fprintf(&text, "Тихие часы: нет (%s)\n", settings.Timezone)
+	}
+	text.WriteString(`
+/notify <категория> on|off — включить или отключить категорию
+/quiet 23:00 08:00 — тихие часы, /quiet off — отключить
+/timezone Europe/Moscow — часовой пояс
+/mute, /unmute — отключить или включить все уведомления
+
+В тихие часы уведомления не теряются, а приходят после их окончания.`)
+	return text.String()
+}
+
+func (m *BotMessages) NotifyUsage() string {
+	return "Использование: /notify <категория> on|off\nКатегории: reminders, payments, waitlist, broadcasts"
+}
+
+func (m *BotMessages) QuietUsage() string {
+	return "Использование: /quiet 23:00 08:00 или /quiet off"
+}
+
+func (m *BotMessages) TimezoneUsage() string {
+	return "Использование: /timezone Europe/Moscow"
+}
+
+func (m *BotMessages) InvalidNotificationSettings(err error) string {
+	return fmt.Sprintf("Не удалось сохранить настройки: %v", err)
+}
+
+func (m *BotMessages) CommandError() string {
+	return "Не удалось обработать команду, попробуйте позже."
+}
+
+func (m *BotMessages) UserNotFound() string {
+	return "Сначала откройте мини-приложение GoPadel, чтобы создать профиль."
+}
+
+// registrationStatusNames статусы регистраций в списках бота
+var registrationStatusNames = map[domain.RegistrationStatus]string{
+	domain.RegistrationStatusPending:   "ожидает оплаты или подтверждения",
+	domain.RegistrationStatusInvited:   "приглашение",
+	domain.RegistrationStatusConfirmed: "подтверждена",
+}
+
+// eventTimeLayout время события в списках бота
+const eventTimeLayout = "02.01 15:04"
+
+func pageSuffix(page, pages int) string {
+	if pages <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" (стр. %d из %d)", page+1, pages)
+}
+
+func (m *BotMessages) EventsHeader(page, pages int) string {
+	return "🎾 Предстоящие события" + pageSuffix(page, pages) + "\n\n"
+}
+
+func (m *BotMessages) EventLine(n int, event *domain.Event, location *time.Location) string {
+	participants := 0
+	for _, participant := range event.Participants {
+		if participant.Status == domain.RegistrationStatusConfirmed || participant.Status == domain.RegistrationStatusPending {
+			participants++
+		}
+	}
+
+	line := fmt.Sprintf("%d. %s\n   %s, %s · %d/%d", n, event.Name,
+		event.StartTime.In(location).Format(eventTimeLayout), event.Court.Name, participants, event.MaxUsers)
+	if event.Price > 0 {
+		line += fmt.Sprintf(" · %d ₽", event.Price)
+	}
+	if event.Status == domain.EventStatusFull {
+		line += " · мест нет"
+	}
+	return line + "\n"
+}
+
+func (m *BotMessages) NoEvents() string {
+	return "Предстоящих событий пока нет."
+}
+
+func (m *BotMessages) RegistrationsHeader(page, pages int) string {
+	return "📋 Мои регистрации" + pageSuffix(page, pages) + "\n\n"
+}
+
+func (m *BotMessages) RegistrationLine(n int, registration *domain.RegistrationWithEvent, location *time.Location) string {
+	status, ok := registrationStatusNames[registration.Status]
+	if !ok {
+		status = string(registration.Status)
+	}
+	return fmt.Sprintf("%d. %s — %s\n   %s, %s\n", n, registration.Event.Name, status,
+		registration.Event.StartTime.In(location).Format(eventTimeLayout), registration.Event.Court.Name)
+}
+
+func (m *BotMessages) NoRegistrations() string {
+	return "У вас нет предстоящих регистраций. Список событий: /events"
+}
+
+func (m *BotMessages) WaitlistHeader(page, pages int) string {
+	return "⏳ Листы ожидания" + pageSuffix(page, pages) + "\n\n"
+}
+
+func (m *BotMessages) WaitlistLine(n int, position *domain.WaitlistPosition, location *time.Location) string {
+	return fmt.Sprintf("%d. %s — место %d из %d\n   %s\n", n, position.Event.Name, position.Position, position.Total,
+		position.Event.StartTime.In(location).Format(eventTimeLayout))
+}
+
+func (m *BotMessages) NoWaitlist() string {
+	return "Вы не стоите в листах ожидания."
+}
+
+func (m *BotMessages) CancelConfirmation(event *domain.Event, location *time.Location) string {
+	return fmt.Sprintf("Отменить регистрацию на «%s» (%s)?", event.Name, event.StartTime.In(location).Format(eventTimeLayout))
+}
+
+func (m *BotMessages) RegistrationCancelled(event *domain.Event) string {
+	return fmt.Sprintf("Регистрация на «%s» отменена.", event.Name)
+}
+
+func (m *BotMessages) CancelFailed(event *domain.Event) string {
+	return fmt.Sprintf("Не удалось отменить регистрацию на «%s». Возможно, она уже отменена или ее нельзя отменить — проверьте в приложении.", event.Name)
+}
+
+func (m *BotMessages) EventNotFound() string {
+	return "Событие не найдено."
+}
+
+func (m *BotMessages) ChooseEventToPay() string {
+	return "💳 Выберите событие для оплаты:"
+}
+
+func (m *BotMessages) NothingToPay() string {
+	return "У вас нет регистраций, ожидающих оплаты."
+}
+
+func (m *BotMessages) PaymentNotRequired(event *domain.Event) string {
+	return fmt.Sprintf("Участие в «%s» не требует оплаты.", event.Name)
+}
+
+func (m *BotMessages) NoPendingRegistration(event *domain.Event) string {
+	return fmt.Sprintf("У вас нет регистрации на «%s», ожидающей оплаты.", event.Name)
+}
+
+func (m *BotMessages) PendingPaymentExists(event *domain.Event) string {
+	return fmt.Sprintf("Платеж за «%s» уже создан. Завершите его или дождитесь, пока он истечет.", event.Name)
+}
+
+func (m *BotMessages) PartnerPaysForPair(event *domain.Event) string {
+	return fmt.Sprintf("Участие пары в «%s» оплачивает капитан.", event.Name)
+}
+
+func (m *BotMessages) PaymentLink(event *domain.Event, payment *domain.Payment) string {
+	return fmt.Sprintf("💳 Оплата участия в «%s»: %d ₽\n\nНажмите кнопку ниже, чтобы перейти к оплате.", event.Name, payment.Amount)
+}
+
+// Кнопки уведомлений
+
+func (m *BotMessages) InvalidButton() string {
+	return "Кнопка недействительна."
+}
+
+func (m *BotMessages) AttendanceConfirmed(event *domain.Event) string {
+	return fmt.Sprintf("Отлично, ждем вас на «%s»!", event.Name)
+}
+
+func (m *BotMessages) RegistrationNotActive(event *domain.Event) string {
+	return fmt.Sprintf("У вас нет активной регистрации на «%s».", event.Name)
+}
+
+func (m *BotMessages) GameRegistrationApproved(event *domain.Event, applicant *domain.User) string {
+	return fmt.Sprintf("✅ Заявка %s %s на игру «%s» принята.", applicant.FirstName, applicant.LastName, event.Name)
+}
+
+func (m *BotMessages) GameRegistrationRejected(event *domain.Event, applicant *domain.User) string {
+	return fmt.Sprintf("❌ Заявка %s %s на игру «%s» отклонена.", applicant.FirstName, applicant.LastName, event.Name)
+}
+
+func (m *BotMessages) GameRegistrationDecisionUnavailable(event *domain.Event) string {
+	return fmt.Sprintf("Заявку на «%s» уже рассмотрели или отменили.", event.Name)
+}
+
+func (m *BotMessages) WaitlistOfferAccepted(event *domain.Event, registration *domain.Registration) string {
+	if registration.Status == domain.RegistrationStatusPending && event.Price > 0 {
+		return fmt.Sprintf("✅ Вы записаны на «%s». Оплатить участие: /pay", event.Name)
+	}
+	return fmt.Sprintf("✅ Вы записаны на «%s».", event.Name)
+}
+
+func (m *BotMessages) WaitlistOfferDeclined(event *domain.Event) string {
+	return fmt.Sprintf("Вы отказались от места на «%s», мы предложим его следующему в листе ожидания.", event.Name)
+}
 
-Нажми на кнопку ниже, чтобы открыть мини\-приложение и начать играть 🎾`
+func (m *BotMessages) WaitlistOfferClosed(event *domain.Event) string {
+	return fmt.Sprintf("Предложение места на «%s» больше не действует.", event.Name)
 }
@@ -0,0 +1,130 @@
+package tg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+)
+
+// notificationCommands команды настройки уведомлений, регистрируются до обработчика любого текста
+var notificationCommands = []models.BotCommand{
+	{Command: "notifications", Description: "Настройки уведомлений"},
+	{Command: "mute", Description: "Отключить все уведомления"},
+	{Command: "unmute", Description: "Включить уведомления"},
+	{Command: "notify", Description: "Включить или отключить категорию уведомлений"},
+	{Command: "quiet", Description: "Тихие часы"},
+	{Command: "timezone", Description: "Часовой пояс для тихих часов"},
+}
+
+func (b *Bot) registerNotificationHandlers() {
+	handlers := map[string]bot.HandlerFunc{
+		"notifications": b.handleCommandNotifications,
+		"mute":          b.handleCommandMute,
+		"unmute":        b.handleCommandUnmute,
+		"notify":        b.handleCommandNotify,
+		"quiet":         b.handleCommandQuiet,
+		"timezone":      b.handleCommandTimezone,
+	}
+	for command, handler := range handlers {
+		b.RegisterHandler(bot.HandlerTypeMessageText, command, bot.MatchTypeCommandStartOnly, handler)
+	}
+}
+
+func (b *Bot) handleCommandNotifications(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+
+	settings, err := b.cases.NotificationSettings.Get(ctx, user.ID)
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error getting notification settings")
+		b.reply(ctx, update, b.messages.CommandError())
+		return
+	}
+	b.reply(ctx, update, b.messages.NotificationSettings(settings))
+}
+
+func (b *Bot) handleCommandMute(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	muted := true
+	b.patchNotificationSettings(ctx, update, &domain.PatchNotificationSettings{Muted: &muted})
+}
+
+func (b *Bot) handleCommandUnmute(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	muted := false
+	b.patchNotificationSettings(ctx, update, &domain.PatchNotificationSettings{Muted: &muted})
+}
+
+// handleCommandNotify /notify <категория> on|off
+func (b *Bot) handleCommandNotify(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	args := commandArgs(update.Message.Text)
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		b.reply(ctx, update, b.messages.NotifyUsage())
+		return
+	}
+
+	enabled := args[1] == "on"
+	patch := &domain.PatchNotificationSettings{}
+	switch domain.NotificationCategory(args[0]) {
+	case domain.NotificationCategoryReminders:
+		patch.Reminders = &enabled
+	case domain.NotificationCategoryPayments:
+		patch.Payments = &enabled
+	case domain.NotificationCategoryWaitlist:
+		patch.Waitlist = &enabled
+	case domain.NotificationCategoryBroadcasts:
+		patch.Broadcasts = &enabled
+	default:
+		b.reply(ctx, update, b.messages.NotifyUsage())
+		return
+	}
+	b.patchNotificationSettings(ctx, update, patch)
+}
+
+// handleCommandQuiet /quiet 23:00 08:00 или /quiet off
+func (b *Bot) handleCommandQuiet(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	args := commandArgs(update.Message.Text)
+	switch {
+	case len(args) == 1 && args[0] == "off":
+		empty := ""
+		b.patchNotificationSettings(ctx, update, &domain.PatchNotificationSettings{QuietHoursStart: &empty, QuietHoursEnd: &empty})
+	case len(args) == 2:
+		b.patchNotificationSettings(ctx, update, &domain.PatchNotificationSettings{QuietHoursStart: &args[0], QuietHoursEnd: &args[1]})
+	default:
+		b.reply(ctx, update, b.messages.QuietUsage())
+	}
+}
+
+// handleCommandTimezone /timezone Europe/Moscow
+func (b *Bot) handleCommandTimezone(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	args := commandArgs(update.Message.Text)
+	if len(args) != 1 {
+		b.reply(ctx, update, b.messages.TimezoneUsage())
+		return
+	}
+	b.patchNotificationSettings(ctx, update, &domain.PatchNotificationSettings{Timezone: &args[0]})
+}
+
+func (b *Bot) patchNotificationSettings(ctx context.Context, update *models.Update, patch *domain.PatchNotificationSettings) {
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+
+	settings, err := b.cases.NotificationSettings.Patch(ctx, user.ID, patch)
+	if errors.Is(err, usecase.ErrInvalidNotificationSettings) {
+		b.reply(ctx, update, b.messages.InvalidNotificationSettings(err))
+		return
+	}
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error updating notification settings")
+		b.reply(ctx, update, b.messages.CommandError())
+		return
+	}
+	b.reply(ctx, update, b.messages.NotificationSettings(settings))
+}
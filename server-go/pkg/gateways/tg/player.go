@@ -0,0 +1,432 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+)
+
+// pageSize количество событий на одной странице списков бота
+const pageSize = 5
+
+// Префиксы данных inline-кнопок
+const (
+	callbackEvents        = "events:"   // страница предстоящих событий
+	callbackMy            = "my:"       // страница моих регистраций
+	callbackWaitlist      = "waitlist:" // страница листов ожидания
+	callbackCancel        = "cancel:"   // подтверждение отмены регистрации
+	callbackCancelConfirm = "cancelok:" // отмена регистрации
+	callbackPay           = "pay:"      // ссылка на оплату
+)
+
+var playerCommands = []models.BotCommand{
+	{Command: "events", Description: "Предстоящие события"},
+	{Command: "my", Description: "Мои регистрации"},
+	{Command: "cancel", Description: "Отменить регистрацию"},
+	{Command: "pay", Description: "Оплатить участие"},
+	{Command: "waitlist", Description: "Мои места в листах ожидания"},
+}
+
+// activeRegistrationStatuses регистрации, которые показываются в /my и которые можно отменить
+var activeRegistrationStatuses = []domain.RegistrationStatus{
+	domain.RegistrationStatusPending,
+	domain.RegistrationStatusInvited,
+	domain.RegistrationStatusConfirmed,
+}
+
+func (b *Bot) registerPlayerHandlers() {
+	commands := map[string]bot.HandlerFunc{
+		"events":   b.handleCommandEvents,
+		"my":       b.handleCommandMy,
+		"cancel":   b.handleCommandCancel,
+		"pay":      b.handleCommandPay,
+		"waitlist": b.handleCommandWaitlist,
+	}
+	for command, handler := range commands {
+		b.RegisterHandler(bot.HandlerTypeMessageText, command, bot.MatchTypeCommandStartOnly, handler)
+	}
+
+	callbacks := map[string]bot.HandlerFunc{
+		callbackEvents:        b.handleCallbackEvents,
+		callbackMy:            b.handleCallbackMy,
+		callbackWaitlist:      b.handleCallbackWaitlist,
+		callbackCancel:        b.handleCallbackCancel,
+		callbackCancelConfirm: b.handleCallbackCancelConfirm,
+		callbackPay:           b.handleCallbackPay,
+	}
+	for prefix, handler := range callbacks {
+		b.RegisterHandler(bot.HandlerTypeCallbackQueryData, prefix, bot.MatchTypePrefix, handler)
+	}
+}
+
+func (b *Bot) handleCommandEvents(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.showPage(ctx, update, 0, b.eventsScreen)
+}
+
+func (b *Bot) handleCallbackEvents(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.showPage(ctx, update, callbackPage(update, callbackEvents), b.eventsScreen)
+}
+
+func (b *Bot) handleCommandMy(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.showPage(ctx, update, 0, b.myScreen)
+}
+
+func (b *Bot) handleCallbackMy(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.showPage(ctx, update, callbackPage(update, callbackMy), b.myScreen)
+}
+
+func (b *Bot) handleCommandWaitlist(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.showPage(ctx, update, 0, b.waitlistScreen)
+}
+
+func (b *Bot) handleCallbackWaitlist(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.showPage(ctx, update, callbackPage(update, callbackWaitlist), b.waitlistScreen)
+}
+
+// showPage строит страницу списка. На команду отвечает новым сообщением, на кнопку — заменяет текущее
+func (b *Bot) showPage(ctx context.Context, update *models.Update, page int, build func(ctx context.Context, user *domain.User, page int) (*screen, error)) {
+	if update.CallbackQuery != nil {
+		b.answerCallback(ctx, update, "")
+	}
+
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+
+	s, err := build(ctx, user, page)
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error building bot screen")
+		b.reply(ctx, update, b.messages.CommandError())
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		b.editScreen(ctx, update, s)
+		return
+	}
+	b.sendScreen(ctx, update, s)
+}
+
+// handleCommandCancel /cancel <id события> отменяет регистрацию, без аргумента показывает регистрации с кнопками отмены
+func (b *Bot) handleCommandCancel(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	args := commandArgs(update.Message.Text)
+	if len(args) != 1 {
+		b.showPage(ctx, update, 0, b.myScreen)
+		return
+	}
+
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+	b.sendScreen(ctx, update, b.cancelRegistration(ctx, user, args[0]))
+}
+
+func (b *Bot) handleCallbackCancel(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.answerCallback(ctx, update, "")
+
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+
+	event, err := b.cases.Event.GetEventByID(ctx, callbackArg(update, callbackCancel))
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error getting event")
+		b.reply(ctx, update, b.messages.CommandError())
+		return
+	}
+
+	b.editScreen(ctx, update, b.cancelConfirmationScreen(ctx, user, event))
+}
+
+func (b *Bot) cancelConfirmationScreen(ctx context.Context, user *domain.User, event *domain.Event) *screen {
+	return &screen{
+		text: b.messages.CancelConfirmation(event, b.userLocation(ctx, user)),
+		keyboard: [][]models.InlineKeyboardButton{{
+			{Text: "Да, отменить", CallbackData: callbackCancelConfirm + event.ID},
+			{Text: "Назад", CallbackData: callbackMy + "0"},
+		}},
+	}
+}
+
+func (b *Bot) handleCallbackCancelConfirm(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.answerCallback(ctx, update, "")
+
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+	b.editScreen(ctx, update, b.cancelRegistration(ctx, user, callbackArg(update, callbackCancelConfirm)))
+}
+
+func (b *Bot) cancelRegistration(ctx context.Context, user *domain.User, eventID string) *screen {
+	event, err := b.cases.Event.GetEventByID(ctx, eventID)
+	if err != nil {
+		return &screen{text: b.messages.EventNotFound()}
+	}
+
+	if _, err := b.cases.Registration.CancelEventRegistration(ctx, user, eventID); err != nil {
+		slog.Warn("Failed to cancel registration from bot",
+			"user_id", user.ID,
+			"event_id", eventID,
+			"error", err)
+		return &screen{text: b.messages.CancelFailed(event)}
+	}
+
+	return &screen{
+		text:     b.messages.RegistrationCancelled(event),
+		keyboard: [][]models.InlineKeyboardButton{{{Text: "Мои регистрации", CallbackData: callbackMy + "0"}}},
+	}
+}
+
+// handleCommandPay /pay <id события> присылает ссылку на оплату, без аргумента показывает неоплаченные регистрации
+func (b *Bot) handleCommandPay(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+
+	args := commandArgs(update.Message.Text)
+	if len(args) == 1 {
+		b.sendScreen(ctx, update, b.paymentScreen(ctx, user, args[0]))
+		return
+	}
+
+	registrations, err := b.activeRegistrations(ctx, user)
+	if err != nil {
+		slogx.FromCtxWithErr(ctx, err).Error("error getting registrations")
+		b.reply(ctx, update, b.messages.CommandError())
+		return
+	}
+
+	var keyboard [][]models.InlineKeyboardButton
+	for _, registration := range registrations {
+		if registrationPayable(registration) {
+			keyboard = append(keyboard, []models.InlineKeyboardButton{
+				{Text: registration.Event.Name, CallbackData: callbackPay + registration.EventID},
+			})
+		}
+	}
+	if len(keyboard) == 0 {
+		b.reply(ctx, update, b.messages.NothingToPay())
+		return
+	}
+	b.sendScreen(ctx, update, &screen{text: b.messages.ChooseEventToPay(), keyboard: keyboard})
+}
+
+func (b *Bot) handleCallbackPay(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	b.answerCallback(ctx, update, "")
+
+	user, ok := b.currentUser(ctx, update)
+	if !ok {
+		return
+	}
+	b.sendScreen(ctx, update, b.paymentScreen(ctx, user, callbackArg(update, callbackPay)))
+}
+
+// paymentScreen создает платеж с теми же проверками, что и POST /registrations/{event_id}/payment
+func (b *Bot) paymentScreen(ctx context.Context, user *domain.User, eventID string) *screen {
+	event, err := b.cases.Event.GetEventByID(ctx, eventID)
+	if err != nil {
+		return &screen{text: b.messages.EventNotFound()}
+	}
+	if event.Type == domain.EventTypeGame || event.Price == 0 {
+		return &screen{text: b.messages.PaymentNotRequired(event)}
+	}
+	if _, err := b.cases.Registration.FindPendingRegistration(ctx, user.ID, eventID); err != nil {
+		return &screen{text: b.messages.NoPendingRegistration(event)}
+	}
+
+	payment, err := b.cases.Payment.CreateProviderPayment(ctx, user, eventID, b.eventURL(eventID), "")
+	switch {
+	case errors.Is(err, usecase.ErrPendingPaymentExists):
+		return &screen{text: b.messages.PendingPaymentExists(event)}
+	case errors.Is(err, usecase.ErrPartnerPaysForPair):
+		return &screen{text: b.messages.PartnerPaysForPair(event)}
+	case err != nil:
+		slog.Error("Failed to create payment from bot",
+			"user_id", user.ID,
+			"event_id", eventID,
+			"error", err)
+		return &screen{text: b.messages.CommandError()}
+	}
+
+	return &screen{
+		text:     b.messages.PaymentLink(event, payment),
+		keyboard: [][]models.InlineKeyboardButton{{{Text: "Оплатить", URL: payment.PaymentLink}}},
+	}
+}
+
+func (b *Bot) eventsScreen(ctx context.Context, user *domain.User, page int) (*screen, error) {
+	now := time.Now()
+	events, err := b.cases.Event.FilterForUser(&usecase.Context{Context: ctx, User: user}, &domain.FilterEvent{
+		Statuses:  &[]domain.EventStatus{domain.EventStatusRegistration, domain.EventStatusFull},
+		StartFrom: &now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+	if len(events) == 0 {
+		return &screen{text: b.messages.NoEvents()}, nil
+	}
+
+	page, start, end, pages := paginate(len(events), page)
+	location := b.userLocation(ctx, user)
+
+	s := &screen{text: b.messages.EventsHeader(page, pages)}
+	for i, event := range events[start:end] {
+		s.text += b.messages.EventLine(start+i+1, event, location)
+		s.keyboard = append(s.keyboard, []models.InlineKeyboardButton{
+			{Text: fmt.Sprintf("%d. %s", start+i+1, event.Name), URL: b.eventURL(event.ID)},
+		})
+	}
+	s.keyboard = appendPageButtons(s.keyboard, callbackEvents, page, pages)
+	return s, nil
+}
+
+func (b *Bot) myScreen(ctx context.Context, user *domain.User, page int) (*screen, error) {
+	registrations, err := b.activeRegistrations(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(registrations) == 0 {
+		return &screen{text: b.messages.NoRegistrations()}, nil
+	}
+
+	page, start, end, pages := paginate(len(registrations), page)
+	location := b.userLocation(ctx, user)
+
+	s := &screen{text: b.messages.RegistrationsHeader(page, pages)}
+	for i, registration := range registrations[start:end] {
+		n := start + i + 1
+		s.text += b.messages.RegistrationLine(n, registration, location)
+
+		row := []models.InlineKeyboardButton{
+			{Text: fmt.Sprintf("%d. Открыть", n), URL: b.eventURL(registration.EventID)},
+			{Text: "Отменить", CallbackData: callbackCancel + registration.EventID},
+		}
+		if registrationPayable(registration) {
+			row = append(row, models.InlineKeyboardButton{Text: "Оплатить", CallbackData: callbackPay + registration.EventID})
+		}
+		s.keyboard = append(s.keyboard, row)
+	}
+	s.keyboard = appendPageButtons(s.keyboard, callbackMy, page, pages)
+	return s, nil
+}
+
+func (b *Bot) waitlistScreen(ctx context.Context, user *domain.User, page int) (*screen, error) {
+	positions, err := b.cases.Waitlist.GetUserPositions(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist positions: %w", err)
+	}
+	if len(positions) == 0 {
+		return &screen{text: b.messages.NoWaitlist()}, nil
+	}
+
+	page, start, end, pages := paginate(len(positions), page)
+	location := b.userLocation(ctx, user)
+
+	s := &screen{text: b.messages.WaitlistHeader(page, pages)}
+	for i, position := range positions[start:end] {
+		s.text += b.messages.WaitlistLine(start+i+1, position, location)
+		s.keyboard = append(s.keyboard, []models.InlineKeyboardButton{
+			{Text: fmt.Sprintf("%d. %s", start+i+1, position.Event.Name), URL: b.eventURL(position.Event.ID)},
+		})
+	}
+	s.keyboard = appendPageButtons(s.keyboard, callbackWaitlist, page, pages)
+	return s, nil
+}
+
+// activeRegistrations предстоящие регистрации пользователя, ближайшие первыми
+func (b *Bot) activeRegistrations(ctx context.Context, user *domain.User) ([]*domain.RegistrationWithEvent, error) {
+	registrations, err := b.cases.Registration.GetUserRegistrationsWithEvent(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registrations: %w", err)
+	}
+
+	now := time.Now()
+	active := make([]*domain.RegistrationWithEvent, 0, len(registrations))
+	for _, registration := range registrations {
+		if registration.Event == nil || registration.Event.EndTime.Before(now) {
+			continue
+		}
+		for _, status := range activeRegistrationStatuses {
+			if registration.Status == status {
+				active = append(active, registration)
+				break
+			}
+		}
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Event.StartTime.Before(active[j].Event.StartTime)
+	})
+	return active, nil
+}
+
+// userLocation часовой пояс из настроек уведомлений, в нем показывается время событий
+func (b *Bot) userLocation(ctx context.Context, user *domain.User) *time.Location {
+	timezone := domain.DefaultNotificationTimezone
+	if settings, err := b.cases.NotificationSettings.Get(ctx, user.ID); err == nil {
+		timezone = settings.Timezone
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func (b *Bot) eventURL(eventID string) string {
+	return fmt.Sprintf("%s?startapp=%s", b.webAppUrl, eventID)
+}
+
+// registrationPayable неоплаченная регистрация на платный турнир или тренировку
+func registrationPayable(registration *domain.RegistrationWithEvent) bool {
+	return registration.Status == domain.RegistrationStatusPending &&
+		registration.Event.Type != domain.EventTypeGame &&
+		registration.Event.Price > 0
+}
+
+// paginate ограничивает номер страницы (с 0) и возвращает границы элементов на ней
+func paginate(total, page int) (current, start, end, pages int) {
+	pages = (total + pageSize - 1) / pageSize
+	current = min(max(page, 0), pages-1)
+	start = current * pageSize
+	end = min(start+pageSize, total)
+	return current, start, end, pages
+}
+
+func appendPageButtons(keyboard [][]models.InlineKeyboardButton, prefix string, page, pages int) [][]models.InlineKeyboardButton {
+	var row []models.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, models.InlineKeyboardButton{Text: "◀️ Назад", CallbackData: prefix + strconv.Itoa(page-1)})
+	}
+	if page < pages-1 {
+		row = append(row, models.InlineKeyboardButton{Text: "Вперед ▶️", CallbackData: prefix + strconv.Itoa(page+1)})
+	}
+	if len(row) == 0 {
+		return keyboard
+	}
+	return append(keyboard, row)
+}
+
+func callbackPage(update *models.Update, prefix string) int {
+	page, err := strconv.Atoi(callbackArg(update, prefix))
+	if err != nil {
+		return 0
+	}
+	return page
+}
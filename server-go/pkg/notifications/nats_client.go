@@ -3,50 +3,83 @@ package notifications
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"gopadel/taskcontract"
 )
 
-// NATSTaskMessage структура сообщения для отправки в NATS
-type NATSTaskMessage struct {
-	TaskName  string          `json:"task_name"`
-	ExecuteAt string          `json:"execute_at"`
-	Data      json.RawMessage `json:"data"`
-	CreatedAt string          `json:"created_at"`
+// NATSStreamConfig настройки JetStream стрима задач, должны совпадать с настройками воркера
+type NATSStreamConfig struct {
+	Stream         string
+	StreamSubjects []string
+	Subject        string
+	ControlSubject string
+	// RequestSubjectPrefix запросы воркера к серверу, например <prefix>.waitlist.offer.expire
+	RequestSubjectPrefix string
+	PublishTimeout       time.Duration
 }
 
-// TaskType типы задач для уведомлений
-type TaskType string
-
-const (
-	TaskTypeTournamentRegistrationSuccess      TaskType = "tournament.registration.success"
-	TaskTypeTournamentReminder48Hours          TaskType = "tournament.reminder.48hours"
-	TaskTypeTournamentReminder24Hours          TaskType = "tournament.reminder.24hours"
-	TaskTypeTournamentFreeReminder48Hours      TaskType = "tournament.free.reminder.48hours"
-	TaskTypeTournamentPaymentSuccess           TaskType = "tournament.payment.success"
-	TaskTypeTournamentLoyaltyChanged           TaskType = "tournament.loyalty.changed"
-	TaskTypeTournamentRegistrationCanceled     TaskType = "tournament.registration.canceled"
-	TaskTypeTournamentRegistrationAutoDeleteUnpaid TaskType = "tournament.registration.auto_delete_unpaid"
-	TaskTypeTournamentTasksCancel              TaskType = "tournament.tasks.cancel"
-)
+// natsStreamMaxAge сколько хранятся сообщения в стриме. Воркер подтверждает сообщение после
+// сохранения задачи в БД, поэтому стрим нужен только на время его недоступности
+const natsStreamMaxAge = 7 * 24 * time.Hour
 
-// NATSClient клиент для отправки уведомлений через NATS
+// NATSClient клиент для отправки уведомлений через NATS JetStream
 type NATSClient struct {
-	conn    *nats.Conn
-	subject string
-	logger  *slog.Logger
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	config NATSStreamConfig
+	logger *slog.Logger
+}
+
+// NewNATSClient создает новый NATS клиент и стрим задач, если его еще нет
+func NewNATSClient(ctx context.Context, conn *nats.Conn, config NATSStreamConfig, logger *slog.Logger) (*NATSClient, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	c := &NATSClient{
+		conn:   conn,
+		js:     js,
+		config: config,
+		logger: logger,
+	}
+
+	if err := c.ensureStream(ctx); err != nil {
+		return nil, err
+	}
+
+	return c, nil
 }
 
-// NewNATSClient создает новый NATS клиент
-func NewNATSClient(conn *nats.Conn, subject string, logger *slog.Logger) *NATSClient {
-	return &NATSClient{
-		conn:    conn,
-		subject: subject,
-		logger:  logger,
+// ensureStream создает стрим задач. Существующий стрим не изменяется, его может создать и воркер
+func (c *NATSClient) ensureStream(ctx context.Context) error {
+	_, err := c.js.Stream(ctx, c.config.Stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("failed to get stream %s: %w", c.config.Stream, err)
 	}
+
+	_, err = c.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:      c.config.Stream,
+		Subjects:  c.config.StreamSubjects,
+		Storage:   jetstream.FileStorage,
+		Retention: jetstream.LimitsPolicy,
+		MaxAge:    natsStreamMaxAge,
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("failed to create stream %s: %w", c.config.Stream, err)
+	}
+
+	c.logger.Info("JetStream stream created", slog.String("stream", c.config.Stream))
+	return nil
 }
 
 // Close закрывает соединение с NATS
@@ -56,49 +89,61 @@ func (c *NATSClient) Close() {
 	}
 }
 
-// SendNotification отправляет уведомление в NATS
-func (c *NATSClient) SendNotification(ctx context.Context, taskType TaskType, executeAt time.Time, data interface{}) error {
-	// Сериализуем данные
-	dataBytes, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
-	}
-
-	// Создаем сообщение
-	message := NATSTaskMessage{
-		TaskName:  string(taskType),
-		ExecuteAt: executeAt.Format(time.RFC3339),
-		Data:      dataBytes,
-		CreatedAt: time.Now().Format(time.RFC3339),
+// PublishTask публикует задачу воркеру в JetStream и ждет подтверждения записи в стрим.
+// messageID — id записи outbox: повторную публикацию того же сообщения JetStream отбрасывает
+func (c *NATSClient) PublishTask(ctx context.Context, messageID string, taskType taskcontract.TaskType, schemaVersion int, executeAt time.Time, data json.RawMessage) error {
+	message := taskcontract.Message{
+		TaskName:      string(taskType),
+		SchemaVersion: schemaVersion,
+		ExecuteAt:     executeAt.Format(time.RFC3339),
+		Data:          data,
+		CreatedAt:     time.Now().Format(time.RFC3339),
 	}
 
-	// Сериализуем сообщение
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	// Отправляем в NATS
-	err = c.conn.Publish(c.subject, messageBytes)
+	publishCtx, cancel := context.WithTimeout(ctx, c.config.PublishTimeout)
+	defer cancel()
+
+	_, err = c.js.Publish(publishCtx, c.config.Subject, messageBytes, jetstream.WithMsgID(messageID))
 	if err != nil {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
 	c.logger.Info("Notification sent to NATS",
+		slog.String("message_id", messageID),
 		slog.String("task_type", string(taskType)),
+		slog.Int("schema_version", schemaVersion),
 		slog.String("execute_at", executeAt.Format(time.RFC3339)),
-		slog.String("subject", c.subject),
+		slog.String("subject", c.config.Subject),
 	)
 
 	return nil
 }
 
-// SendImmediateNotification отправляет уведомление для немедленного выполнения
-func (c *NATSClient) SendImmediateNotification(ctx context.Context, taskType TaskType, data interface{}) error {
-	return c.SendNotification(ctx, taskType, time.Now(), data)
-}
+// SendTaskControl отправляет воркерам команду по задаче. Команда не сохраняется:
+// если воркер ее пропустит, задачу все равно подберет опрос БД
+func (c *NATSClient) SendTaskControl(ctx context.Context, action taskcontract.ControlAction, taskID string) error {
+	messageBytes, err := json.Marshal(taskcontract.ControlMessage{
+		Action: action,
+		TaskID: taskID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal control message: %w", err)
+	}
+
+	if err := c.conn.Publish(c.config.ControlSubject, messageBytes); err != nil {
+		return fmt.Errorf("failed to publish control message: %w", err)
+	}
 
-// SendScheduledNotification отправляет запланированное уведомление
-func (c *NATSClient) SendScheduledNotification(ctx context.Context, taskType TaskType, executeAt time.Time, data interface{}) error {
-	return c.SendNotification(ctx, taskType, executeAt, data)
-} 
\ No newline at end of file
+	c.logger.Info("Task control sent to NATS",
+		slog.String("action", string(action)),
+		slog.String("task_id", taskID),
+		slog.String("subject", c.config.ControlSubject),
+	)
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+	"gopadel/taskcontract"
+)
+
+// outboxClaimLease на сколько relay забирает задачи. Если реплика упадет во время публикации,
+// задачи опубликует другая реплика по истечении этого времени
+const outboxClaimLease = time.Minute
+
+// outboxCleanupInterval как часто удаляются опубликованные задачи старше OutboxRelayConfig.Retention
+const outboxCleanupInterval = time.Hour
+
+type OutboxRelayConfig struct {
+	Interval       time.Duration
+	BatchSize      int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	Retention      time.Duration
+}
+
+// OutboxRelay публикует в NATS задачи воркера, записанные в outbox. Задача публикуется
+// как минимум один раз: повторную публикацию после сбоя JetStream отбрасывает по message id
+type OutboxRelay struct {
+	outbox     repo.Outbox
+	natsClient *NATSClient
+	config     OutboxRelayConfig
+}
+
+func NewOutboxRelay(outbox repo.Outbox, natsClient *NATSClient, config OutboxRelayConfig) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:     outbox,
+		natsClient: natsClient,
+		config:     config,
+	}
+}
+
+// Run публикует задачи каждые Interval, пока не отменен ctx
+func (r *OutboxRelay) Run(ctx context.Context) {
+	log := slogx.FromCtx(ctx)
+	log.Info("outbox relay started", "interval", r.config.Interval)
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	var cleanedAt time.Time
+	for {
+		r.relay(ctx)
+
+		if r.config.Retention > 0 && time.Since(cleanedAt) >= outboxCleanupInterval {
+			cleanedAt = time.Now()
+			deleted, err := r.outbox.DeleteSentBefore(ctx, cleanedAt.Add(-r.config.Retention))
+			if err != nil {
+				log.Error("failed to clean up outbox", "error", err)
+			} else if deleted > 0 {
+				log.Info("outbox cleaned up", "deleted", deleted)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay публикует накопившиеся задачи пачками по BatchSize
+func (r *OutboxRelay) relay(ctx context.Context) {
+	log := slogx.FromCtx(ctx)
+
+	for ctx.Err() == nil {
+		messages, err := r.outbox.Claim(ctx, r.config.BatchSize, outboxClaimLease)
+		if err != nil {
+			log.Error("failed to claim outbox messages", "error", err)
+			return
+		}
+
+		for _, message := range messages {
+			r.publish(ctx, message)
+		}
+
+		if len(messages) < r.config.BatchSize {
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, message *domain.OutboxMessage) {
+	log := slogx.FromCtx(ctx).With(
+		slog.String("outbox_id", message.ID),
+		slog.String("task_type", message.TaskType))
+
+	err := r.natsClient.PublishTask(ctx, message.ID, taskcontract.TaskType(message.TaskType), message.SchemaVersion, message.ExecuteAt, message.Data)
+	if err != nil {
+		attempt := message.Attempts + 1
+		nextAttemptAt := time.Now().Add(r.retryDelay(attempt))
+		log.Warn("failed to publish outbox message, will retry",
+			"attempt", attempt,
+			"next_attempt_at", nextAttemptAt,
+			"error", err)
+
+		if err := r.outbox.MarkFailed(ctx, message.ID, err.Error(), nextAttemptAt); err != nil {
+			log.Error("failed to mark outbox message failed", "error", err)
+		}
+		return
+	}
+
+	if err := r.outbox.MarkSent(ctx, message.ID); err != nil {
+		// Задача будет опубликована повторно после lease, JetStream отбросит дубликат
+		log.Error("failed to mark outbox message sent", "error", err)
+	}
+}
+
+// retryDelay экспоненциальная задержка перед попыткой attempt+1
+func (r *OutboxRelay) retryDelay(attempt int) time.Duration {
+	delay := r.config.RetryBaseDelay
+	for i := 1; i < attempt && delay < r.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.RetryMaxDelay)
+}
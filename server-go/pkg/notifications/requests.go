@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Запросы воркера к серверу. Воркер ждет ответа: если сервер недоступен или вернул ошибку,
+// задача воркера повторяется
+const (
+	RequestWaitlistOfferExpire       = "waitlist.offer.expire"
+	RequestRegistrationPaymentExpire = "registration.payment.expire"
+)
+
+// requestQueue группа подписчиков: каждый запрос обрабатывает одна реплика сервера
+const requestQueue = "server"
+
+// NATSReply ответ сервера на запрос воркера, пустая ошибка — запрос обработан
+type NATSReply struct {
+	Error string `json:"error,omitempty"`
+}
+
+// WaitlistOfferExpireRequest запрос закрыть предложение места, на которое не ответили вовремя
+type WaitlistOfferExpireRequest struct {
+	OfferID string `json:"offer_id"`
+}
+
+// RegistrationPaymentExpireRequest запрос отменить регистрацию пользователя на событие, если срок оплаты истек
+type RegistrationPaymentExpireRequest struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	EventID        string `json:"event_id"`
+}
+
+// RequestHandler обрабатывает тело запроса воркера
+type RequestHandler func(ctx context.Context, data []byte) error
+
+// HandleRequests подписывает сервер на запросы воркера вида <RequestSubjectPrefix>.<request>
+func (c *NATSClient) HandleRequests(ctx context.Context, request string, handle RequestHandler) (*nats.Subscription, error) {
+	subject := c.config.RequestSubjectPrefix + "." + request
+
+	sub, err := c.conn.QueueSubscribe(subject, requestQueue, func(m *nats.Msg) {
+		reply := NATSReply{}
+		if err := handle(ctx, m.Data); err != nil {
+			c.logger.Error("Failed to handle worker request",
+				slog.String("subject", subject),
+				slog.String("error", err.Error()))
+			reply.Error = err.Error()
+		}
+
+		data, err := json.Marshal(reply)
+		if err != nil {
+			return
+		}
+		if err := m.Respond(data); err != nil {
+			c.logger.Error("Failed to respond to worker request",
+				slog.String("subject", subject),
+				slog.String("error", err.Error()))
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.Info("Handling worker requests", slog.String("subject", subject))
+	return sub, nil
+}
@@ -1,171 +1,211 @@
 package notifications
 
-import "time"
-
-// Базовые структуры для данных уведомлений
-
-// TournamentRegistrationData данные для уведомления о регистрации на турнир
-type TournamentRegistrationData struct {
-	UserTelegramID int64  `json:"user_telegram_id"`
-	TournamentID   string `json:"tournament_id"`
-	TournamentName string `json:"tournament_name"`
-	IsFree         bool   `json:"is_free"`
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"gopadel/taskcontract"
+)
+
+// NotificationService сервис для отправки уведомлений. Задачи воркера записываются в outbox
+// в транзакции из контекста и публикуются в NATS через OutboxRelay после коммита.
+// natsClient нужен только для команд управления задачами и может быть nil
+type NotificationService struct {
+	natsClient *NATSClient
+	outbox     repo.Outbox
 }
 
-// TournamentReminderData данные для напоминания о турнире
-type TournamentReminderData struct {
-	UserTelegramID int64  `json:"user_telegram_id"`
-	TournamentID   string `json:"tournament_id"`
-	TournamentName string `json:"tournament_name"`
-	IsPaid         bool   `json:"is_paid"`
+// NewNotificationService создает новый сервис уведомлений
+func NewNotificationService(natsClient *NATSClient, outbox repo.Outbox) *NotificationService {
+	return &NotificationService{
+		natsClient: natsClient,
+		outbox:     outbox,
+	}
 }
 
-// TournamentPaymentSuccessData данные для уведомления об успешной оплате
-type TournamentPaymentSuccessData struct {
-	UserTelegramID int64  `json:"user_telegram_id"`
-	TournamentID   string `json:"tournament_id"`
-	TournamentName string `json:"tournament_name"`
-}
+// enqueue проверяет данные задачи по контракту воркера и записывает задачу в outbox
+func (s *NotificationService) enqueue(ctx context.Context, taskType taskcontract.TaskType, executeAt time.Time, payload taskcontract.Payload) error {
+	data, err := taskcontract.Encode(taskType, payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode task data: %w", err)
+	}
 
-// TournamentLoyaltyChangedData данные для уведомления об изменении лояльности
-type TournamentLoyaltyChangedData struct {
-	UserTelegramID int64  `json:"user_telegram_id"`
-	OldLevel       string `json:"old_level"`
-	NewLevel       string `json:"new_level"`
+	_, err = s.outbox.Create(ctx, &domain.CreateOutboxMessage{
+		TaskType:      string(taskType),
+		SchemaVersion: taskcontract.SchemaVersion,
+		ExecuteAt:     executeAt,
+		Data:          data,
+	})
+	return err
+}
+
+// eventData данные события для уведомления. Время начала передается в UTC вместе с часовым поясом корта
+func eventData(event *domain.Event) taskcontract.Event {
+	return taskcontract.Event{
+		EventID:       event.ID,
+		EventName:     event.Name,
+		EventType:     string(event.Type),
+		StartTime:     event.StartTime.UTC(),
+		CourtName:     event.Court.Name,
+		CourtAddress:  event.Court.Address,
+		CourtTimezone: event.Court.Timezone,
+	}
 }
 
-// TournamentRegistrationCanceledData данные для уведомления об отмене регистрации
-type TournamentRegistrationCanceledData struct {
-	UserTelegramID int64  `json:"user_telegram_id"`
-	TournamentID   string `json:"tournament_id"`
-	TournamentName string `json:"tournament_name"`
+// SendEventRegistrationConfirmed отправляет подтверждение регистрации на событие
+func (s *NotificationService) SendEventRegistrationConfirmed(ctx context.Context, userTelegramID int64, event *domain.Event, isPaid bool) error {
+	data := &taskcontract.EventReminder{
+		UserTelegramID: userTelegramID,
+		Event:          eventData(event),
+		IsPaid:         isPaid,
+	}
+
+	return s.enqueue(ctx, taskcontract.TaskTypeEventRegistrationConfirmed, time.Now(), data)
 }
 
-// TournamentAutoDeleteUnpaidData данные для автоматического удаления неоплаченных регистраций
-type TournamentAutoDeleteUnpaidData struct {
-	UserTelegramID int64  `json:"user_telegram_id"`
-	TournamentID   string `json:"tournament_id"`
-	TournamentName string `json:"tournament_name"`
-	RegistrationID string `json:"registration_id"`
+// SendEventReminder48Hours ставит напоминание за 48 часов до начала события
+func (s *NotificationService) SendEventReminder48Hours(ctx context.Context, userTelegramID int64, event *domain.Event, isPaid bool, scheduleAt time.Time) error {
+	data := &taskcontract.EventReminder{
+		UserTelegramID: userTelegramID,
+		Event:          eventData(event),
+		IsPaid:         isPaid,
+	}
+
+	return s.enqueue(ctx, taskcontract.TaskTypeEventReminder48Hours, scheduleAt, data)
 }
 
-// TournamentTasksCancelData данные для отмены всех задач турнира
-type TournamentTasksCancelData struct {
-	UserTelegramID int64  `json:"user_telegram_id"`
-	TournamentID   string `json:"tournament_id"`
+// SendEventReminder24Hours ставит напоминание за 24 часа до начала события
+func (s *NotificationService) SendEventReminder24Hours(ctx context.Context, userTelegramID int64, event *domain.Event, isPaid bool, scheduleAt time.Time) error {
+	data := &taskcontract.EventReminder{
+		UserTelegramID: userTelegramID,
+		Event:          eventData(event),
+		IsPaid:         isPaid,
+	}
+
+	return s.enqueue(ctx, taskcontract.TaskTypeEventReminder24Hours, scheduleAt, data)
 }
 
-// NotificationService сервис для отправки уведомлений
-type NotificationService struct {
-	natsClient *NATSClient
+// SendEventPaymentDeadline ставит срок оплаты регистрации: в scheduleAt сервер отменит регистрацию, если она не оплачена
+func (s *NotificationService) SendEventPaymentDeadline(ctx context.Context, userTelegramID int64, eventID string, scheduleAt time.Time) error {
+	data := &taskcontract.EventPaymentDeadline{
+		UserTelegramID: userTelegramID,
+		EventID:        eventID,
+	}
+
+	return s.enqueue(ctx, taskcontract.TaskTypeEventPaymentDeadline, scheduleAt, data)
 }
 
-// NewNotificationService создает новый сервис уведомлений
-func NewNotificationService(natsClient *NATSClient) *NotificationService {
-	return &NotificationService{
-		natsClient: natsClient,
+// SendEventPaymentExpired отправляет уведомление об отмене регистрации, которую не оплатили в срок
+func (s *NotificationService) SendEventPaymentExpired(ctx context.Context, userTelegramID int64, event *domain.Event) error {
+	data := &taskcontract.EventNotification{
+		UserTelegramID: userTelegramID,
+		Event:          eventData(event),
 	}
+
+	return s.enqueue(ctx, taskcontract.TaskTypeEventPaymentExpired, time.Now(), data)
 }
 
-// SendTournamentRegistrationSuccess отправляет уведомление об успешной регистрации
-func (s *NotificationService) SendTournamentRegistrationSuccess(userTelegramID int64, tournamentID, tournamentName string, isFree bool) error {
-	data := TournamentRegistrationData{
+// SendEventRegistrationCanceled отправляет уведомление об отмене регистрации на событие
+func (s *NotificationService) SendEventRegistrationCanceled(ctx context.Context, userTelegramID int64, event *domain.Event) error {
+	data := &taskcontract.EventNotification{
 		UserTelegramID: userTelegramID,
-		TournamentID:   tournamentID,
-		TournamentName: tournamentName,
-		IsFree:         isFree,
+		Event:          eventData(event),
+	}
+
+	return s.enqueue(ctx, taskcontract.TaskTypeEventRegistrationCanceled, time.Now(), data)
+}
+
+// SendEventRegistrationRequested отправляет организатору заявку на игру с кнопками «Принять» и «Отклонить»
+func (s *NotificationService) SendEventRegistrationRequested(ctx context.Context, organizerTelegramID int64, event *domain.Event, applicant *domain.User) error {
+	data := &taskcontract.EventRegistrationRequested{
+		UserTelegramID:      organizerTelegramID,
+		Event:               eventData(event),
+		ApplicantTelegramID: applicant.TelegramID,
+		ApplicantName:       applicant.FirstName + " " + applicant.LastName,
 	}
 
-	return s.natsClient.SendImmediateNotification(nil, TaskTypeTournamentRegistrationSuccess, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeEventRegistrationRequested, time.Now(), data)
 }
 
-// SendTournamentReminder48Hours отправляет напоминание за 48 часов до турнира
-func (s *NotificationService) SendTournamentReminder48Hours(userTelegramID int64, tournamentID, tournamentName string, isPaid bool, scheduleAt time.Time) error {
-	data := TournamentReminderData{
+// SendEventRegistrationApproved сообщает участнику, что организатор принял его заявку на игру
+func (s *NotificationService) SendEventRegistrationApproved(ctx context.Context, userTelegramID int64, event *domain.Event) error {
+	data := &taskcontract.EventNotification{
 		UserTelegramID: userTelegramID,
-		TournamentID:   tournamentID,
-		TournamentName: tournamentName,
-		IsPaid:         isPaid,
+		Event:          eventData(event),
 	}
 
-	return s.natsClient.SendScheduledNotification(nil, TaskTypeTournamentReminder48Hours, scheduleAt, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeEventRegistrationApproved, time.Now(), data)
 }
 
-// SendTournamentReminder24Hours отправляет напоминание за 24 часа до турнира
-func (s *NotificationService) SendTournamentReminder24Hours(userTelegramID int64, tournamentID, tournamentName string, isPaid bool, scheduleAt time.Time) error {
-	data := TournamentReminderData{
+// SendEventRegistrationRejected сообщает участнику, что организатор отклонил его заявку на игру
+func (s *NotificationService) SendEventRegistrationRejected(ctx context.Context, userTelegramID int64, event *domain.Event) error {
+	data := &taskcontract.EventNotification{
 		UserTelegramID: userTelegramID,
-		TournamentID:   tournamentID,
-		TournamentName: tournamentName,
-		IsPaid:         isPaid,
+		Event:          eventData(event),
 	}
 
-	return s.natsClient.SendScheduledNotification(nil, TaskTypeTournamentReminder24Hours, scheduleAt, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeEventRegistrationRejected, time.Now(), data)
 }
 
-// SendTournamentFreeReminder48Hours отправляет напоминание за 48 часов для бесплатного турнира
-func (s *NotificationService) SendTournamentFreeReminder48Hours(userTelegramID int64, tournamentID, tournamentName string, scheduleAt time.Time) error {
-	data := TournamentReminderData{
+// SendEventTasksCancel отправляет команду для отмены напоминаний пользователя о событии
+func (s *NotificationService) SendEventTasksCancel(ctx context.Context, userTelegramID int64, eventID string) error {
+	data := &taskcontract.EventTasksCancel{
 		UserTelegramID: userTelegramID,
-		TournamentID:   tournamentID,
-		TournamentName: tournamentName,
-		IsPaid:         true,
+		EventID:        eventID,
 	}
 
-	return s.natsClient.SendScheduledNotification(nil, TaskTypeTournamentFreeReminder48Hours, scheduleAt, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeEventTasksCancel, time.Now(), data)
 }
 
 // SendTournamentPaymentSuccess отправляет уведомление об успешной оплате
-func (s *NotificationService) SendTournamentPaymentSuccess(userTelegramID int64, tournamentID, tournamentName string) error {
-	data := TournamentPaymentSuccessData{
+func (s *NotificationService) SendTournamentPaymentSuccess(ctx context.Context, userTelegramID int64, tournamentID, tournamentName string) error {
+	data := &taskcontract.PaymentSuccess{
 		UserTelegramID: userTelegramID,
 		TournamentID:   tournamentID,
 		TournamentName: tournamentName,
 	}
 
-	return s.natsClient.SendImmediateNotification(nil, TaskTypeTournamentPaymentSuccess, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeTournamentPaymentSuccess, time.Now(), data)
 }
 
 // SendTournamentLoyaltyChanged отправляет уведомление об изменении лояльности
-func (s *NotificationService) SendTournamentLoyaltyChanged(userTelegramID int64, oldLevel, newLevel string) error {
-	data := TournamentLoyaltyChangedData{
+func (s *NotificationService) SendTournamentLoyaltyChanged(ctx context.Context, userTelegramID int64, oldLevel, newLevel string) error {
+	data := &taskcontract.LoyaltyChanged{
 		UserTelegramID: userTelegramID,
 		OldLevel:       oldLevel,
 		NewLevel:       newLevel,
 	}
 
-	return s.natsClient.SendImmediateNotification(nil, TaskTypeTournamentLoyaltyChanged, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeTournamentLoyaltyChanged, time.Now(), data)
 }
 
-// SendTournamentRegistrationCanceled отправляет уведомление об отмене регистрации
-func (s *NotificationService) SendTournamentRegistrationCanceled(userTelegramID int64, tournamentID, tournamentName string) error {
-	data := TournamentRegistrationCanceledData{
-		UserTelegramID: userTelegramID,
-		TournamentID:   tournamentID,
-		TournamentName: tournamentName,
+// SendBroadcast ставит воркеру задачу отправить рассылку в указанное время
+func (s *NotificationService) SendBroadcast(ctx context.Context, broadcastID string, scheduleAt time.Time) error {
+	data := &taskcontract.BroadcastSend{
+		BroadcastID: broadcastID,
 	}
 
-	return s.natsClient.SendImmediateNotification(nil, TaskTypeTournamentRegistrationCanceled, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeBroadcastSend, scheduleAt, data)
 }
 
-// SendTournamentAutoDeleteUnpaid отправляет уведомление об автоматическом удалении неоплаченной регистрации
-func (s *NotificationService) SendTournamentAutoDeleteUnpaid(userTelegramID int64, tournamentID, tournamentName string, registrationID string, scheduleAt time.Time) error {
-	data := TournamentAutoDeleteUnpaidData{
-		UserTelegramID: userTelegramID,
-		TournamentID:   tournamentID,
-		TournamentName: tournamentName,
-		RegistrationID: registrationID,
+// SendWaitlistOfferExpire ставит воркеру задачу закрыть предложение места, если на него не ответили до expiresAt
+func (s *NotificationService) SendWaitlistOfferExpire(ctx context.Context, offerID string, expiresAt time.Time) error {
+	data := &taskcontract.WaitlistOfferExpire{
+		OfferID: offerID,
 	}
 
-	return s.natsClient.SendScheduledNotification(nil, TaskTypeTournamentRegistrationAutoDeleteUnpaid, scheduleAt, data)
+	return s.enqueue(ctx, taskcontract.TaskTypeWaitlistOfferExpire, expiresAt, data)
 }
 
-// SendTournamentTasksCancel отправляет команду для отмены всех задач пользователя по турниру
-func (s *NotificationService) SendTournamentTasksCancel(userTelegramID int64, tournamentID string) error {
-	data := TournamentTasksCancelData{
-		UserTelegramID: userTelegramID,
-		TournamentID:   tournamentID,
+// SendTaskControl отправляет воркерам команду обновить запланированную задачу. Команда идет
+// напрямую в NATS: воркер в любом случае подберет изменения задачи опросом БД
+func (s *NotificationService) SendTaskControl(action taskcontract.ControlAction, taskID string) error {
+	if s.natsClient == nil {
+		return errors.New("NATS client is not configured")
 	}
-
-	return s.natsClient.SendImmediateNotification(nil, TaskTypeTournamentTasksCancel, data)
-} 
\ No newline at end of file
+	return s.natsClient.SendTaskControl(context.Background(), action, taskID)
+}
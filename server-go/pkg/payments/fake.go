@@ -0,0 +1,179 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+// FakeProvider локальный провайдер для тестов и стейджинга: не ходит в сеть,
+// платежи автоматически становятся succeeded через заданную задержку, возвраты проходят сразу.
+// Повторный запрос с тем же ключом идемпотентности возвращает уже созданный платеж или возврат
+type FakeProvider struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	payments map[string]*ProviderPayment
+	refunds  map[string]*ProviderRefund
+	keys     map[string]string
+	onChange func(paymentID string)
+}
+
+func NewFakeProvider(delay time.Duration) *FakeProvider {
+	return &FakeProvider{
+		delay:    delay,
+		payments: map[string]*ProviderPayment{},
+		refunds:  map[string]*ProviderRefund{},
+		keys:     map[string]string{},
+	}
+}
+
+// OnPaymentStatusChange задает обработчик, который вызывается после автоматического подтверждения платежа.
+// Заменяет вебхук, который прислал бы настоящий провайдер
+func (f *FakeProvider) OnPaymentStatusChange(fn func(paymentID string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onChange = fn
+}
+
+func (f *FakeProvider) CreatePayment(ctx context.Context, req *CreatePaymentRequest) (*ProviderPayment, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid payment amount: %d", req.Amount)
+	}
+
+	f.mu.Lock()
+	if existing, ok := f.payments[f.keys[req.IdempotenceKey]]; ok && req.IdempotenceKey != "" {
+		copied := *existing
+		f.mu.Unlock()
+		return &copied, nil
+	}
+	f.mu.Unlock()
+
+	payment := &ProviderPayment{
+		ID:              "fake-" + uuid.New().String(),
+		Status:          domain.PaymentStatusPending,
+		Amount:          req.Amount,
+		ConfirmationURL: req.ReturnURL,
+	}
+
+	f.mu.Lock()
+	f.payments[payment.ID] = payment
+	if req.IdempotenceKey != "" {
+		f.keys[req.IdempotenceKey] = payment.ID
+	}
+	f.mu.Unlock()
+
+	slog.Info("Fake payment created", "payment_id", payment.ID, "amount", req.Amount, "delay", f.delay)
+
+	time.AfterFunc(f.delay, func() {
+		f.succeed(payment.ID)
+	})
+
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *FakeProvider) GetPayment(ctx context.Context, paymentID string) (*ProviderPayment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[paymentID]
+	if !ok {
+		return nil, fmt.Errorf("fake payment %s not found", paymentID)
+	}
+
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *FakeProvider) CancelPayment(ctx context.Context, paymentID string) (*ProviderPayment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[paymentID]
+	if !ok {
+		return nil, fmt.Errorf("fake payment %s not found", paymentID)
+	}
+
+	if payment.Status == domain.PaymentStatusSucceeded {
+		return nil, fmt.Errorf("fake payment %s is already succeeded", paymentID)
+	}
+
+	payment.Status = domain.PaymentStatusCanceled
+
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *FakeProvider) CreateRefund(ctx context.Context, req *CreateRefundRequest) (*ProviderRefund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if existing, ok := f.refunds[f.keys[req.IdempotenceKey]]; ok && req.IdempotenceKey != "" {
+		copied := *existing
+		return &copied, nil
+	}
+
+	payment, ok := f.payments[req.PaymentID]
+	if !ok {
+		return nil, fmt.Errorf("fake payment %s not found", req.PaymentID)
+	}
+
+	if payment.Status != domain.PaymentStatusSucceeded {
+		return nil, fmt.Errorf("fake payment %s is not succeeded", req.PaymentID)
+	}
+
+	if req.Amount <= 0 || req.Amount > payment.Amount {
+		return nil, fmt.Errorf("invalid refund amount: %d", req.Amount)
+	}
+
+	refund := &ProviderRefund{
+		ID:        "fake-" + uuid.New().String(),
+		PaymentID: req.PaymentID,
+		Status:    domain.RefundStatusSucceeded,
+		Amount:    req.Amount,
+	}
+	f.refunds[refund.ID] = refund
+	if req.IdempotenceKey != "" {
+		f.keys[req.IdempotenceKey] = refund.ID
+	}
+
+	copied := *refund
+	return &copied, nil
+}
+
+func (f *FakeProvider) GetRefund(ctx context.Context, refundID string) (*ProviderRefund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	refund, ok := f.refunds[refundID]
+	if !ok {
+		return nil, fmt.Errorf("fake refund %s not found", refundID)
+	}
+
+	copied := *refund
+	return &copied, nil
+}
+
+func (f *FakeProvider) succeed(paymentID string) {
+	f.mu.Lock()
+	payment, ok := f.payments[paymentID]
+	if !ok || payment.Status != domain.PaymentStatusPending {
+		f.mu.Unlock()
+		return
+	}
+	payment.Status = domain.PaymentStatusSucceeded
+	onChange := f.onChange
+	f.mu.Unlock()
+
+	slog.Info("Fake payment succeeded", "payment_id", paymentID)
+
+	if onChange != nil {
+		onChange(paymentID)
+	}
+}
@@ -0,0 +1,81 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+const (
+	ProviderYooKassa = "yookassa"
+	ProviderFake     = "fake"
+)
+
+// CreatePaymentRequest данные для создания платежа у провайдера (суммы в рублях)
+type CreatePaymentRequest struct {
+	// IdempotenceKey ключ идемпотентности: повторный запрос с тем же ключом не создает второй платеж
+	IdempotenceKey  string
+	Amount          int
+	Description     string
+	ItemDescription string
+	ReturnURL       string
+	CustomerEmail   string
+}
+
+// CreateRefundRequest данные для возврата по платежу
+type CreateRefundRequest struct {
+	// IdempotenceKey ключ идемпотентности: повторный запрос с тем же ключом не создает второй возврат
+	IdempotenceKey  string
+	PaymentID       string
+	Amount          int
+	Description     string
+	ItemDescription string
+	CustomerEmail   string
+}
+
+// ProviderPayment состояние платежа на стороне провайдера
+type ProviderPayment struct {
+	ID              string
+	Status          domain.PaymentStatus
+	Amount          int
+	ConfirmationURL string
+}
+
+// ProviderRefund состояние возврата на стороне провайдера
+type ProviderRefund struct {
+	ID        string
+	PaymentID string
+	Status    domain.RefundStatus
+	Amount    int
+}
+
+// PaymentProvider платежный провайдер, через который проходят оплаты и возвраты
+type PaymentProvider interface {
+	CreatePayment(ctx context.Context, req *CreatePaymentRequest) (*ProviderPayment, error)
+	GetPayment(ctx context.Context, paymentID string) (*ProviderPayment, error)
+	CancelPayment(ctx context.Context, paymentID string) (*ProviderPayment, error)
+	CreateRefund(ctx context.Context, req *CreateRefundRequest) (*ProviderRefund, error)
+	GetRefund(ctx context.Context, refundID string) (*ProviderRefund, error)
+}
+
+// NewProvider создает провайдера, выбранного в конфигурации
+func NewProvider(cfg *config.Config) (PaymentProvider, error) {
+	switch cfg.Payments.Provider {
+	case ProviderYooKassa, "":
+		return NewYooKassaProvider(cfg.YooKassa.APIURL, cfg.YooKassa.ShopID, cfg.YooKassa.SecretKey, cfg.YooKassa.Currency), nil
+	case ProviderFake:
+		return NewFakeProvider(cfg.Payments.FakeDelay), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider: %s", cfg.Payments.Provider)
+	}
+}
+
+// IdempotenceKey строит ключ идемпотентности из идентификаторов операции.
+// Одинаковые части дают одинаковый ключ, поэтому повтор операции не выполняется у провайдера дважды
+func IdempotenceKey(parts ...string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(strings.Join(parts, ":"))).String()
+}
@@ -0,0 +1,310 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type YooKassaAmount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type YooKassaConfirmation struct {
+	Type      string `json:"type"`
+	ReturnURL string `json:"return_url"`
+}
+
+type YooKassaCustomer struct {
+	Email string `json:"email"`
+}
+
+type YooKassaItem struct {
+	Description    string         `json:"description"`
+	PaymentSubject string         `json:"payment_subject"`
+	Amount         YooKassaAmount `json:"amount"`
+	VatCode        int            `json:"vat_code"`
+	Quantity       int            `json:"quantity"`
+	Measure        string         `json:"measure"`
+	PaymentMode    string         `json:"payment_mode"`
+}
+
+type YooKassaReceipt struct {
+	Customer YooKassaCustomer `json:"customer"`
+	Items    []YooKassaItem   `json:"items"`
+}
+
+type YooKassaPaymentRequest struct {
+	Amount       YooKassaAmount       `json:"amount"`
+	Confirmation YooKassaConfirmation `json:"confirmation"`
+	Capture      bool                 `json:"capture"`
+	Description  string               `json:"description"`
+	Receipt      YooKassaReceipt      `json:"receipt"`
+}
+
+type YooKassaPaymentResponse struct {
+	ID           string                   `json:"id"`
+	Status       string                   `json:"status"`
+	Amount       YooKassaAmount           `json:"amount"`
+	Confirmation YooKassaConfirmationResp `json:"confirmation"`
+}
+
+type YooKassaConfirmationResp struct {
+	ConfirmationURL string `json:"confirmation_url"`
+}
+
+type YooKassaRefundRequest struct {
+	PaymentID   string          `json:"payment_id"`
+	Amount      YooKassaAmount  `json:"amount"`
+	Description string          `json:"description,omitempty"`
+	Receipt     YooKassaReceipt `json:"receipt"`
+}
+
+type YooKassaRefundResponse struct {
+	ID        string         `json:"id"`
+	PaymentID string         `json:"payment_id"`
+	Status    string         `json:"status"`
+	Amount    YooKassaAmount `json:"amount"`
+}
+
+// YooKassaProvider работает с API YooKassa v3
+type YooKassaProvider struct {
+	apiURL    string
+	shopID    string
+	secretKey string
+	currency  string
+	client    *http.Client
+}
+
+func NewYooKassaProvider(apiURL, shopID, secretKey, currency string) *YooKassaProvider {
+	return &YooKassaProvider{
+		apiURL:    apiURL,
+		shopID:    shopID,
+		secretKey: secretKey,
+		currency:  currency,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (y *YooKassaProvider) CreatePayment(ctx context.Context, req *CreatePaymentRequest) (*ProviderPayment, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid payment amount: %d", req.Amount)
+	}
+
+	amount := y.amount(req.Amount)
+	paymentData := YooKassaPaymentRequest{
+		Amount: amount,
+		Confirmation: YooKassaConfirmation{
+			Type:      "redirect",
+			ReturnURL: req.ReturnURL,
+		},
+		Capture:     true,
+		Description: req.Description,
+		Receipt:     y.receipt(req.CustomerEmail, req.ItemDescription, amount),
+	}
+
+	var paymentResponse YooKassaPaymentResponse
+	if err := y.doRequest(ctx, http.MethodPost, "/payments", req.IdempotenceKey, paymentData, &paymentResponse); err != nil {
+		slog.Error("Failed to create YooKassa payment",
+			"error", err.Error(),
+			"shop_id", y.shopID,
+			"amount", amount.Value,
+			"email", req.CustomerEmail,
+		)
+		return nil, err
+	}
+
+	slog.Info("YooKassa payment created successfully",
+		"payment_id", paymentResponse.ID,
+		"status", paymentResponse.Status,
+		"confirmation_url", paymentResponse.Confirmation.ConfirmationURL,
+	)
+
+	return y.toPayment(&paymentResponse), nil
+}
+
+func (y *YooKassaProvider) GetPayment(ctx context.Context, paymentID string) (*ProviderPayment, error) {
+	var paymentResponse YooKassaPaymentResponse
+	if err := y.doRequest(ctx, http.MethodGet, "/payments/"+paymentID, "", nil, &paymentResponse); err != nil {
+		return nil, err
+	}
+
+	return y.toPayment(&paymentResponse), nil
+}
+
+func (y *YooKassaProvider) CancelPayment(ctx context.Context, paymentID string) (*ProviderPayment, error) {
+	var paymentResponse YooKassaPaymentResponse
+	if err := y.doRequest(ctx, http.MethodPost, "/payments/"+paymentID+"/cancel", IdempotenceKey("cancel", paymentID), struct{}{}, &paymentResponse); err != nil {
+		return nil, err
+	}
+
+	slog.Info("YooKassa payment cancelled",
+		"payment_id", paymentResponse.ID,
+		"status", paymentResponse.Status,
+	)
+
+	return y.toPayment(&paymentResponse), nil
+}
+
+func (y *YooKassaProvider) CreateRefund(ctx context.Context, req *CreateRefundRequest) (*ProviderRefund, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid refund amount: %d", req.Amount)
+	}
+
+	amount := y.amount(req.Amount)
+	refundData := YooKassaRefundRequest{
+		PaymentID:   req.PaymentID,
+		Amount:      amount,
+		Description: req.Description,
+		Receipt:     y.receipt(req.CustomerEmail, req.ItemDescription, amount),
+	}
+
+	var refundResponse YooKassaRefundResponse
+	if err := y.doRequest(ctx, http.MethodPost, "/refunds", req.IdempotenceKey, refundData, &refundResponse); err != nil {
+		slog.Error("Failed to create YooKassa refund",
+			"error", err.Error(),
+			"payment_id", req.PaymentID,
+			"amount", amount.Value,
+		)
+		return nil, err
+	}
+
+	slog.Info("YooKassa refund created successfully",
+		"refund_id", refundResponse.ID,
+		"payment_id", refundResponse.PaymentID,
+		"status", refundResponse.Status,
+	)
+
+	return y.toRefund(&refundResponse), nil
+}
+
+func (y *YooKassaProvider) GetRefund(ctx context.Context, refundID string) (*ProviderRefund, error) {
+	var refundResponse YooKassaRefundResponse
+	if err := y.doRequest(ctx, http.MethodGet, "/refunds/"+refundID, "", nil, &refundResponse); err != nil {
+		return nil, err
+	}
+
+	return y.toRefund(&refundResponse), nil
+}
+
+func (y *YooKassaProvider) amount(value int) YooKassaAmount {
+	return YooKassaAmount{
+		Value:    fmt.Sprintf("%d.00", value),
+		Currency: y.currency,
+	}
+}
+
+func (y *YooKassaProvider) receipt(email, itemDescription string, amount YooKassaAmount) YooKassaReceipt {
+	return YooKassaReceipt{
+		Customer: YooKassaCustomer{
+			Email: email,
+		},
+		Items: []YooKassaItem{
+			{
+				Description:    itemDescription,
+				PaymentSubject: "service",
+				Amount:         amount,
+				VatCode:        1,
+				Quantity:       1,
+				Measure:        "piece",
+				PaymentMode:    "full_payment",
+			},
+		},
+	}
+}
+
+func (y *YooKassaProvider) toPayment(resp *YooKassaPaymentResponse) *ProviderPayment {
+	return &ProviderPayment{
+		ID:              resp.ID,
+		Status:          domain.PaymentStatus(resp.Status),
+		Amount:          parseAmount(resp.Amount.Value),
+		ConfirmationURL: resp.Confirmation.ConfirmationURL,
+	}
+}
+
+func (y *YooKassaProvider) toRefund(resp *YooKassaRefundResponse) *ProviderRefund {
+	return &ProviderRefund{
+		ID:        resp.ID,
+		PaymentID: resp.PaymentID,
+		Status:    domain.RefundStatus(resp.Status),
+		Amount:    parseAmount(resp.Amount.Value),
+	}
+}
+
+// doRequest выполняет запрос к API YooKassa и декодирует ответ в out.
+// POST-запросы требуют ключ идемпотентности, по нему YooKassa не выполняет повтор запроса дважды
+func (y *YooKassaProvider) doRequest(ctx context.Context, method, path, idempotenceKey string, body interface{}, out interface{}) error {
+	if method == http.MethodPost && idempotenceKey == "" {
+		return fmt.Errorf("idempotence key is required for %s %s", method, path)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, y.apiURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if method == http.MethodPost {
+		req.Header.Set("Idempotence-Key", idempotenceKey)
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(y.shopID + ":" + y.secretKey))
+	req.Header.Set("Authorization", "Basic "+auth)
+
+	resp, err := y.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to YooKassa: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Проверяем статус ответа
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("YooKassa API returned error",
+			"status_code", resp.StatusCode,
+			"response_body", string(bodyBytes),
+			"method", method,
+			"path", path,
+		)
+		return fmt.Errorf("YooKassa API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if err := json.Unmarshal(bodyBytes, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// parseAmount переводит сумму вида "1500.00" в целые рубли
+func parseAmount(value string) int {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return int(math.Round(amount))
+}
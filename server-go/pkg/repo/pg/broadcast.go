@@ -0,0 +1,244 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+// broadcastRecipientsBatch количество получателей в одном INSERT
+const broadcastRecipientsBatch = 1000
+
+type BroadcastRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewBroadcastRepo(db *pgxpool.Pool) *BroadcastRepo {
+	return &BroadcastRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *BroadcastRepo) Create(ctx context.Context, broadcast *domain.CreateBroadcast, recipients []*domain.CreateBroadcastRecipient) (string, error) {
+	message, err := json.Marshal(broadcast.Message)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal message: %w", err)
+	}
+	target, err := json.Marshal(broadcast.Target)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal target: %w", err)
+	}
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	sql, args, err := r.psql.Insert(`"broadcasts"`).
+		Columns("message", "target", "status", "scheduled_at", "created_by").
+		Values(message, target, domain.BroadcastStatusScheduled, broadcast.ScheduledAt.UTC(), broadcast.CreatedBy).
+		Suffix(`RETURNING "id"`).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to create broadcast: %w", err)
+	}
+
+	for start := 0; start < len(recipients); start += broadcastRecipientsBatch {
+		end := min(start+broadcastRecipientsBatch, len(recipients))
+
+		insert := r.psql.Insert(`"broadcast_recipients"`).
+			Columns("broadcast_id", "user_id", "telegram_id").
+			Suffix(`ON CONFLICT ("broadcast_id", "telegram_id") DO NOTHING`)
+		for _, recipient := range recipients[start:end] {
+			insert = insert.Values(id, recipient.UserID, recipient.TelegramID)
+		}
+
+		sql, args, err := insert.ToSql()
+		if err != nil {
+			return "", fmt.Errorf("failed to build SQL: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return "", fmt.Errorf("failed to create broadcast recipients: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *BroadcastRepo) Filter(ctx context.Context, filter *domain.FilterBroadcast) ([]*domain.Broadcast, error) {
+	s := r.psql.Select(
+		`"b"."id"`, `"b"."message"`, `"b"."target"`, `"b"."status"`, `"b"."scheduled_at"`, `"b"."created_by"`,
+		`"b"."started_at"`, `"b"."finished_at"`, `"b"."created_at"`, `"b"."updated_at"`,
+		`COUNT("r"."id")`,
+		`COUNT("r"."id") FILTER (WHERE "r"."status" = 'pending')`,
+		`COUNT("r"."id") FILTER (WHERE "r"."status" = 'sending')`,
+		`COUNT("r"."id") FILTER (WHERE "r"."status" = 'sent')`,
+		`COUNT("r"."id") FILTER (WHERE "r"."status" = 'failed')`,
+		`COUNT("r"."id") FILTER (WHERE "r"."status" = 'blocked')`,
+	).
+		From(`"broadcasts" AS b`).
+		LeftJoin(`"broadcast_recipients" AS r ON "r"."broadcast_id" = "b"."id"`).
+		GroupBy(`"b"."id"`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{`"b"."id"`: *filter.ID})
+	}
+
+	if filter.Status != nil {
+		s = s.Where(sq.Eq{`"b"."status"`: *filter.Status})
+	}
+
+	s = s.OrderBy(`"b"."scheduled_at" DESC`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.Broadcast{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	broadcasts := []*domain.Broadcast{}
+	for rows.Next() {
+		var broadcast domain.Broadcast
+		var message, target []byte
+		var createdBy pgtype.Text
+		var startedAt, finishedAt pgtype.Timestamp
+
+		err := rows.Scan(
+			&broadcast.ID, &message, &target, &broadcast.Status, &broadcast.ScheduledAt, &createdBy,
+			&startedAt, &finishedAt, &broadcast.CreatedAt, &broadcast.UpdatedAt,
+			&broadcast.Stats.Total, &broadcast.Stats.Pending, &broadcast.Stats.Sending,
+			&broadcast.Stats.Sent, &broadcast.Stats.Failed, &broadcast.Stats.Blocked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if err := json.Unmarshal(message, &broadcast.Message); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		if err := json.Unmarshal(target, &broadcast.Target); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal target: %w", err)
+		}
+		if createdBy.Valid {
+			broadcast.CreatedBy = &createdBy.String
+		}
+		broadcast.StartedAt = timestampPtr(startedAt)
+		broadcast.FinishedAt = timestampPtr(finishedAt)
+
+		broadcasts = append(broadcasts, &broadcast)
+	}
+
+	return broadcasts, nil
+}
+
+func (r *BroadcastRepo) FilterRecipients(ctx context.Context, filter *domain.FilterBroadcastRecipient) ([]*domain.BroadcastRecipient, error) {
+	s := r.psql.Select(
+		"id", "broadcast_id", "user_id", "telegram_id", "status", "error", "sent_at",
+	).
+		From(`"broadcast_recipients"`).
+		Where(sq.Eq{"broadcast_id": filter.BroadcastID})
+
+	if filter.Status != nil {
+		s = s.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	s = s.OrderBy("created_at", "telegram_id")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.BroadcastRecipient{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []*domain.BroadcastRecipient{}
+	for rows.Next() {
+		var recipient domain.BroadcastRecipient
+		var userID, sendError pgtype.Text
+		var sentAt pgtype.Timestamp
+
+		err := rows.Scan(
+			&recipient.ID, &recipient.BroadcastID, &userID, &recipient.TelegramID, &recipient.Status, &sendError, &sentAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if userID.Valid {
+			recipient.UserID = &userID.String
+		}
+		if sendError.Valid {
+			recipient.Error = &sendError.String
+		}
+		recipient.SentAt = timestampPtr(sentAt)
+
+		recipients = append(recipients, &recipient)
+	}
+
+	return recipients, nil
+}
+
+// TransitionStatus меняет статус рассылки, только если она в одном из статусов from
+func (r *BroadcastRepo) TransitionStatus(ctx context.Context, id string, from []domain.BroadcastStatus, to domain.BroadcastStatus) (bool, error) {
+	s := r.psql.Update(`"broadcasts"`).
+		Set("status", to).
+		Where(sq.Eq{"id": id, "status": from})
+
+	if to == domain.BroadcastStatusCancelled {
+		s = s.Set("finished_at", sq.Expr("NOW() AT TIME ZONE 'UTC'"))
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	tag, err := conn(ctx, r.db).Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update broadcast status: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func timestampPtr(ts pgtype.Timestamp) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	return &ts.Time
+}
@@ -25,8 +25,8 @@ func NewCourtRepo(db *pgxpool.Pool) *CourtRepo {
 
 func (r *CourtRepo) Create(ctx context.Context, court *domain.CreateCourt) (string, error) {
 	s := r.psql.Insert(`"courts"`).
-		Columns("name", "address").
-		Values(court.Name, court.Address).
+		Columns("name", "address", "timezone").
+		Values(court.Name, court.Address, court.Timezone).
 		Suffix("RETURNING id")
 
 	sql, args, err := s.ToSql()
@@ -44,7 +44,7 @@ func (r *CourtRepo) Create(ctx context.Context, court *domain.CreateCourt) (stri
 }
 
 func (r *CourtRepo) Filter(ctx context.Context, filter *domain.FilterCourt) ([]*domain.Court, error) {
-	s := r.psql.Select("id", "name", "address").From(`"courts"`)
+	s := r.psql.Select("id", "name", "address", "timezone").From(`"courts"`)
 
 	if filter.ID != nil {
 		s = s.Where(sq.Eq{"id": *filter.ID})
@@ -76,6 +76,7 @@ func (r *CourtRepo) Filter(ctx context.Context, filter *domain.FilterCourt) ([]*
 			&court.ID,
 			&court.Name,
 			&court.Address,
+			&court.Timezone,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -106,6 +107,11 @@ func (r *CourtRepo) Patch(ctx context.Context, id string, court *domain.PatchCou
 		hasUpdates = true
 	}
 
+	if court.Timezone != nil {
+		s = s.Set("timezone", *court.Timezone)
+		hasUpdates = true
+	}
+
 	if !hasUpdates {
 		return fmt.Errorf("no fields to update")
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
 )
 
 type EventRepo struct {
@@ -54,8 +55,8 @@ func (r *EventRepo) Create(ctx context.Context, event *domain.CreateEvent) (stri
 	id := r.generateID(event.Type)
 
 	s := r.psql.Insert(`"event"`).
-		Columns("id", "name", "description", "start_time", "end_time", "rank_min", "rank_max", "price", "max_users", "type", "court_id", "organizer_id", "club_id", "data").
-		Values(id, event.Name, event.Description, event.StartTime, event.EndTime, event.RankMin, event.RankMax, event.Price, event.MaxUsers, event.Type, event.CourtID, event.OrganizerID, event.ClubID, event.Data)
+		Columns("id", "name", "description", "start_time", "end_time", "rank_min", "rank_max", "price", "max_users", "type", "court_id", "organizer_id", "club_id", "data", "refund_policy", "payment_deadline_hours", "series_id", "series_occurrence").
+		Values(id, event.Name, event.Description, event.StartTime, event.EndTime, event.RankMin, event.RankMax, event.Price, event.MaxUsers, event.Type, event.CourtID, event.OrganizerID, event.ClubID, event.Data, event.RefundPolicy, event.PaymentDeadlineHours, event.SeriesID, event.SeriesOccurrence)
 
 	sql, args, err := s.ToSql()
 	if err != nil {
@@ -73,8 +74,8 @@ func (r *EventRepo) Create(ctx context.Context, event *domain.CreateEvent) (stri
 func (r *EventRepo) Filter(ctx context.Context, filter *domain.FilterEvent) ([]*domain.Event, error) {
 	s := r.psql.Select(
 		`"e"."id"`, `"e"."name"`, `"e"."description"`, `"e"."start_time"`, `"e"."end_time"`, `"e"."rank_min"`, `"e"."rank_max"`,
-		`"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`, `"e"."created_at"`, `"e"."updated_at"`,
-		`"c"."id"`, `"c"."name"`, `"c"."address"`,
+		`"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`, `"e"."refund_policy"`, `"e"."payment_deadline_hours"`, `"e"."series_id"`, `"e"."created_at"`, `"e"."updated_at"`,
+		`"c"."id"`, `"c"."name"`, `"c"."address"`, `"c"."timezone"`,
 		`"u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 		`"u"."bio"`, `"u"."rank"`, `"u"."city"`, `"u"."birth_date"`, `"u"."playing_position"`, `"u"."padel_profiles"`, `"u"."is_registered"`,
 		`COUNT(CASE WHEN "r"."status" IN ('PENDING', 'CONFIRMED') THEN 1 END) AS active_registrations`,
@@ -109,6 +110,14 @@ func (r *EventRepo) Filter(ctx context.Context, filter *domain.FilterEvent) ([]*
 		s = s.Where(sq.Eq{`"e"."club_id"`: *filter.ClubID})
 	}
 
+	if filter.SeriesID != nil {
+		s = s.Where(sq.Eq{`"e"."series_id"`: *filter.SeriesID})
+	}
+
+	if filter.StartFrom != nil {
+		s = s.Where(sq.GtOrEq{`"e"."start_time"`: *filter.StartFrom})
+	}
+
 	if filter.NotFull != nil && *filter.NotFull {
 		s = s.Having(`COUNT(CASE WHEN "r"."status" IN ('PENDING', 'CONFIRMED') THEN 1 END) < "e"."max_users"`)
 	}
@@ -158,8 +167,8 @@ func (r *EventRepo) Filter(ctx context.Context, filter *domain.FilterEvent) ([]*
 func (r *EventRepo) GetEventsByUserID(ctx context.Context, userID string) ([]*domain.Event, error) {
 	s := r.psql.Select(
 		`"e"."id"`, `"e"."name"`, `"e"."description"`, `"e"."start_time"`, `"e"."end_time"`, `"e"."rank_min"`, `"e"."rank_max"`,
-		`"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`, `"e"."created_at"`, `"e"."updated_at"`,
-		`"c"."id"`, `"c"."name"`, `"c"."address"`,
+		`"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`, `"e"."refund_policy"`, `"e"."payment_deadline_hours"`, `"e"."series_id"`, `"e"."created_at"`, `"e"."updated_at"`,
+		`"c"."id"`, `"c"."name"`, `"c"."address"`, `"c"."timezone"`,
 		`"u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 		`"u"."bio"`, `"u"."rank"`, `"u"."city"`, `"u"."birth_date"`, `"u"."playing_position"`, `"u"."padel_profiles"`, `"u"."is_registered"`,
 		`COUNT(CASE WHEN "r"."status" IN ('PENDING', 'CONFIRMED') THEN 1 END) AS active_registrations`,
@@ -269,6 +278,16 @@ func (r *EventRepo) Patch(ctx context.Context, id string, event *domain.PatchEve
 		hasUpdates = true
 	}
 
+	if event.RefundPolicy != nil {
+		s = s.Set("refund_policy", event.RefundPolicy)
+		hasUpdates = true
+	}
+
+	if event.PaymentDeadlineHours != nil {
+		s = s.Set("payment_deadline_hours", *event.PaymentDeadlineHours)
+		hasUpdates = true
+	}
+
 	if !hasUpdates {
 		return fmt.Errorf("no fields to update")
 	}
@@ -317,8 +336,8 @@ func (r *EventRepo) Delete(ctx context.Context, id string) error {
 func (r *EventRepo) AdminFilter(ctx context.Context, filter *domain.AdminFilterEvent) ([]*domain.Event, error) {
 	s := r.psql.Select(
 		`"e"."id"`, `"e"."name"`, `"e"."description"`, `"e"."start_time"`, `"e"."end_time"`, `"e"."rank_min"`, `"e"."rank_max"`,
-		`"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`, `"e"."created_at"`, `"e"."updated_at"`,
-		`"c"."id"`, `"c"."name"`, `"c"."address"`,
+		`"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`, `"e"."refund_policy"`, `"e"."payment_deadline_hours"`, `"e"."series_id"`, `"e"."created_at"`, `"e"."updated_at"`,
+		`"c"."id"`, `"c"."name"`, `"c"."address"`, `"c"."timezone"`,
 		`"u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 		`"u"."bio"`, `"u"."rank"`, `"u"."city"`, `"u"."birth_date"`, `"u"."playing_position"`, `"u"."padel_profiles"`, `"u"."is_registered"`,
 		`COUNT(CASE WHEN "r"."status" IN ('PENDING', 'CONFIRMED') THEN 1 END) AS active_registrations`,
@@ -353,6 +372,10 @@ func (r *EventRepo) AdminFilter(ctx context.Context, filter *domain.AdminFilterE
 		s = s.Where(sq.Eq{`"e"."organizer_id"`: *filter.OrganizerID})
 	}
 
+	if filter.SeriesID != nil {
+		s = s.Where(sq.Eq{`"e"."series_id"`: *filter.SeriesID})
+	}
+
 	if filter.OrganizerTelegramID != nil {
 		s = s.Where(sq.Eq{`"u"."telegram_id"`: *filter.OrganizerTelegramID})
 	}
@@ -469,6 +492,16 @@ func (r *EventRepo) AdminPatch(ctx context.Context, id string, event *domain.Adm
 		hasUpdates = true
 	}
 
+	if event.RefundPolicy != nil {
+		s = s.Set("refund_policy", event.RefundPolicy)
+		hasUpdates = true
+	}
+
+	if event.PaymentDeadlineHours != nil {
+		s = s.Set("payment_deadline_hours", *event.PaymentDeadlineHours)
+		hasUpdates = true
+	}
+
 	if !hasUpdates {
 		return fmt.Errorf("no fields to update")
 	}
@@ -496,6 +529,29 @@ func (r *EventRepo) AdminDelete(ctx context.Context, id string) error {
 	return r.Delete(ctx, id)
 }
 
+// Lock блокирует строку события до конца текущей транзакции
+func (r *EventRepo) Lock(ctx context.Context, id string) error {
+	s := r.psql.Select("id").
+		From(`"event"`).
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var lockedID string
+	err = conn(ctx, r.db).QueryRow(ctx, sql, args...).Scan(&lockedID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock event: %w", err)
+	}
+	return nil
+}
+
 // scanEvent сканирует строку результата в структуру Event
 func (r *EventRepo) scanEvent(rows pgx.Rows) (*domain.Event, error) {
 	var event domain.Event
@@ -505,6 +561,9 @@ func (r *EventRepo) scanEvent(rows pgx.Rows) (*domain.Event, error) {
 	var description pgtype.Text
 	var clubID pgtype.Text
 	var data []byte
+	var refundPolicy []byte
+	var paymentDeadlineHours pgtype.Int4
+	var seriesID pgtype.Text
 	var telegramUsername, avatar, bio, city, padelProfiles pgtype.Text
 	var birthDate pgtype.Date
 	var playingPosition pgtype.Text
@@ -514,8 +573,8 @@ func (r *EventRepo) scanEvent(rows pgx.Rows) (*domain.Event, error) {
 
 	err := rows.Scan(
 		&event.ID, &event.Name, &description, &event.StartTime, &event.EndTime, &event.RankMin, &event.RankMax,
-		&event.Price, &event.MaxUsers, &event.Status, &event.Type, &clubID, &data, &event.CreatedAt, &event.UpdatedAt,
-		&court.ID, &court.Name, &court.Address,
+		&event.Price, &event.MaxUsers, &event.Status, &event.Type, &clubID, &data, &refundPolicy, &paymentDeadlineHours, &seriesID, &event.CreatedAt, &event.UpdatedAt,
+		&court.ID, &court.Name, &court.Address, &court.Timezone,
 		&organizer.ID, &organizer.TelegramID, &telegramUsername, &organizer.FirstName, &organizer.LastName, &avatar,
 		&bio, &rank, &city, &birthDate, &playingPosition, &padelProfiles, &isRegistered,
 		&activeRegistrations,
@@ -537,6 +596,23 @@ func (r *EventRepo) scanEvent(rows pgx.Rows) (*domain.Event, error) {
 		event.Data = json.RawMessage(data)
 	}
 
+	if len(refundPolicy) > 0 {
+		var policy domain.RefundPolicy
+		if err := json.Unmarshal(refundPolicy, &policy); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal refund policy: %w", err)
+		}
+		event.RefundPolicy = &policy
+	}
+
+	if paymentDeadlineHours.Valid {
+		hours := int(paymentDeadlineHours.Int32)
+		event.PaymentDeadlineHours = &hours
+	}
+
+	if seriesID.Valid {
+		event.SeriesID = &seriesID.String
+	}
+
 	if telegramUsername.Valid {
 		organizer.TelegramUsername = telegramUsername.String
 	}
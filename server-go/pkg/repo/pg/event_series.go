@@ -0,0 +1,163 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type EventSeriesRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewEventSeriesRepo(db *pgxpool.Pool) *EventSeriesRepo {
+	return &EventSeriesRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *EventSeriesRepo) Create(ctx context.Context, series *domain.CreateEventSeries) (string, error) {
+	template, err := json.Marshal(series.Template)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal series template: %w", err)
+	}
+	rule, err := json.Marshal(series.Rule)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal recurrence rule: %w", err)
+	}
+
+	s := r.psql.Insert(`"event_series"`).
+		Columns("template", "rule", "status").
+		Values(template, rule, domain.EventSeriesStatusActive).
+		Suffix("RETURNING id")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create event series: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *EventSeriesRepo) Patch(ctx context.Context, id string, patch *domain.PatchEventSeries) error {
+	s := r.psql.Update(`"event_series"`).Where(sq.Eq{"id": id})
+
+	hasUpdates := false
+
+	if patch.Template != nil {
+		template, err := json.Marshal(patch.Template)
+		if err != nil {
+			return fmt.Errorf("failed to marshal series template: %w", err)
+		}
+		s = s.Set("template", template)
+		hasUpdates = true
+	}
+
+	if patch.Rule != nil {
+		rule, err := json.Marshal(patch.Rule)
+		if err != nil {
+			return fmt.Errorf("failed to marshal recurrence rule: %w", err)
+		}
+		s = s.Set("rule", rule)
+		hasUpdates = true
+	}
+
+	if patch.Status != nil {
+		s = s.Set("status", *patch.Status)
+		hasUpdates = true
+	}
+
+	if patch.MaterializedUntil != nil {
+		s = s.Set("materialized_until", *patch.MaterializedUntil)
+		hasUpdates = true
+	}
+
+	if !hasUpdates {
+		return nil
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to patch event series: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EventSeriesRepo) Filter(ctx context.Context, filter *domain.FilterEventSeries) ([]*domain.EventSeries, error) {
+	s := r.psql.Select(
+		`"id"`, `"template"`, `"rule"`, `"status"`, `"materialized_until"`, `"created_at"`, `"updated_at"`,
+	).From(`"event_series"`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{`"id"`: *filter.ID})
+	}
+
+	if filter.Status != nil {
+		s = s.Where(sq.Eq{`"status"`: *filter.Status})
+	}
+
+	s = s.OrderBy(`"created_at" ASC`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.EventSeries{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	seriesList := []*domain.EventSeries{}
+	for rows.Next() {
+		var series domain.EventSeries
+		var template, rule []byte
+		var materializedUntil pgtype.Timestamp
+
+		err := rows.Scan(
+			&series.ID, &template, &rule, &series.Status, &materializedUntil, &series.CreatedAt, &series.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if err := json.Unmarshal(template, &series.Template); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal series template: %w", err)
+		}
+		if err := json.Unmarshal(rule, &series.Rule); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recurrence rule: %w", err)
+		}
+		if materializedUntil.Valid {
+			series.MaterializedUntil = &materializedUntil.Time
+		}
+
+		seriesList = append(seriesList, &series)
+	}
+
+	return seriesList, nil
+}
@@ -2,6 +2,7 @@ package pg
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -26,8 +27,8 @@ func NewLoyaltyRepo(db *pgxpool.Pool) *LoyaltyRepo {
 
 func (r *LoyaltyRepo) Create(ctx context.Context, loyalty *domain.CreateLoyalty) (string, error) {
 	s := r.psql.Insert(`"loyalties"`).
-		Columns("name", "discount", "description", "requirements").
-		Values(loyalty.Name, loyalty.Discount, loyalty.Description, loyalty.Requirements).
+		Columns("name", "discount", "description", "requirements", "rules").
+		Values(loyalty.Name, loyalty.Discount, loyalty.Description, loyalty.Requirements, loyalty.Rules).
 		Suffix("RETURNING id")
 
 	sql, args, err := s.ToSql()
@@ -41,7 +42,7 @@ func (r *LoyaltyRepo) Create(ctx context.Context, loyalty *domain.CreateLoyalty)
 }
 
 func (r *LoyaltyRepo) Filter(ctx context.Context, filter *domain.FilterLoyalty) ([]*domain.Loyalty, error) {
-	s := r.psql.Select("id", "name", "discount", "description", "requirements").From(`"loyalties"`)
+	s := r.psql.Select("id", "name", "discount", "description", "requirements", "rules").From(`"loyalties"`)
 
 	if filter.ID != nil {
 		s = s.Where(sq.Eq{"id": *filter.ID})
@@ -66,6 +67,7 @@ func (r *LoyaltyRepo) Filter(ctx context.Context, filter *domain.FilterLoyalty)
 		var loyalty domain.Loyalty
 		var description pgtype.Text
 		var requirements pgtype.Text
+		var rules []byte
 
 		err := rows.Scan(
 			&loyalty.ID,
@@ -73,6 +75,7 @@ func (r *LoyaltyRepo) Filter(ctx context.Context, filter *domain.FilterLoyalty)
 			&loyalty.Discount,
 			&description,
 			&requirements,
+			&rules,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -84,6 +87,13 @@ func (r *LoyaltyRepo) Filter(ctx context.Context, filter *domain.FilterLoyalty)
 		if requirements.Valid {
 			loyalty.Requirements = requirements.String
 		}
+		if len(rules) > 0 {
+			var loyaltyRules domain.LoyaltyRules
+			if err := json.Unmarshal(rules, &loyaltyRules); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal loyalty rules: %w", err)
+			}
+			loyalty.Rules = &loyaltyRules
+		}
 
 		loyalties = append(loyalties, &loyalty)
 	}
@@ -120,6 +130,11 @@ func (r *LoyaltyRepo) Patch(ctx context.Context, id int, loyalty *domain.PatchLo
 		hasUpdates = true
 	}
 
+	if loyalty.Rules != nil {
+		s = s.Set("rules", loyalty.Rules)
+		hasUpdates = true
+	}
+
 	if !hasUpdates {
 		return fmt.Errorf("no fields to update")
 	}
@@ -160,3 +175,60 @@ func (r *LoyaltyRepo) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// GetActivity считает подтвержденные участия и сумму успешных оплат за вычетом возвратов
+// для проверки условий уровня лояльности
+func (r *LoyaltyRepo) GetActivity(ctx context.Context, filter *domain.FilterLoyaltyActivity) (*domain.LoyaltyActivity, error) {
+	activity := &domain.LoyaltyActivity{}
+
+	events := r.psql.Select("COUNT(*)").
+		From(`"registrations" AS reg`).
+		Join(`"event" AS e ON "reg"."event_id" = "e"."id"`).
+		Where(sq.Eq{`"reg"."user_id"`: filter.UserID}).
+		Where(sq.Eq{`"reg"."status"`: domain.RegistrationStatusConfirmed}).
+		Where(sq.NotEq{`"e"."status"`: domain.EventStatusCancelled})
+
+	if filter.Since != nil {
+		events = events.Where(sq.GtOrEq{`"e"."start_time"`: *filter.Since})
+	}
+	if len(filter.EventTypes) > 0 {
+		events = events.Where(sq.Eq{`"e"."type"`: filter.EventTypes})
+	}
+
+	sql, args, err := events.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&activity.ConfirmedEvents); err != nil {
+		return nil, fmt.Errorf("failed to count confirmed events: %w", err)
+	}
+
+	paid := r.psql.Select(
+		`COALESCE(SUM("p"."amount" - COALESCE((` +
+			`SELECT SUM("rf"."amount") FROM "refunds" AS rf WHERE "rf"."payment_id" = "p"."id" AND "rf"."status" = 'succeeded'` +
+			`), 0)), 0)::BIGINT`,
+	).
+		From(`"payments" AS p`).
+		Join(`"event" AS e ON "p"."event_id" = "e"."id"`).
+		Where(sq.Eq{`"p"."user_id"`: filter.UserID}).
+		Where(sq.Eq{`"p"."status"`: domain.PaymentStatusSucceeded})
+
+	if filter.Since != nil {
+		paid = paid.Where(sq.GtOrEq{`"p"."date"`: *filter.Since})
+	}
+	if len(filter.EventTypes) > 0 {
+		paid = paid.Where(sq.Eq{`"e"."type"`: filter.EventTypes})
+	}
+
+	sql, args, err = paid.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&activity.TotalPaid); err != nil {
+		return nil, fmt.Errorf("failed to sum payments: %w", err)
+	}
+
+	return activity, nil
+}
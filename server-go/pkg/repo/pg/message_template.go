@@ -0,0 +1,133 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+type MessageTemplateRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewMessageTemplateRepo(db *pgxpool.Pool) *MessageTemplateRepo {
+	return &MessageTemplateRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *MessageTemplateRepo) Upsert(ctx context.Context, key, language string, template *domain.UpsertMessageTemplate) error {
+	description := ""
+	if template.Description != nil {
+		description = *template.Description
+	}
+
+	// Описание при обновлении меняется, только если оно передано
+	suffix := `ON CONFLICT ("key", "language") DO UPDATE SET
+		"body" = EXCLUDED."body",
+		"parse_mode" = EXCLUDED."parse_mode",
+		"updated_by" = EXCLUDED."updated_by"`
+	if template.Description != nil {
+		suffix += `, "description" = EXCLUDED."description"`
+	}
+
+	sql, args, err := r.psql.Insert(`"message_templates"`).
+		Columns("key", "language", "body", "parse_mode", "description", "updated_by").
+		Values(key, language, template.Body, template.ParseMode, description, template.UpdatedBy).
+		Suffix(suffix).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to save message template: %w", err)
+	}
+	return nil
+}
+
+func (r *MessageTemplateRepo) Filter(ctx context.Context, filter *domain.FilterMessageTemplate) ([]*domain.MessageTemplate, error) {
+	s := r.psql.Select(
+		"key", "language", "body", "parse_mode", "description", "updated_by", "created_at", "updated_at",
+	).From(`"message_templates"`)
+
+	if filter.Key != nil {
+		s = s.Where(sq.Eq{"key": *filter.Key})
+	}
+
+	if filter.Language != nil {
+		s = s.Where(sq.Eq{"language": *filter.Language})
+	}
+
+	if len(filter.Languages) > 0 {
+		s = s.Where(sq.Eq{"language": filter.Languages})
+	}
+
+	s = s.OrderBy("key", "language")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.MessageTemplate{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*domain.MessageTemplate{}
+	for rows.Next() {
+		var template domain.MessageTemplate
+		var parseMode, updatedBy pgtype.Text
+
+		err := rows.Scan(
+			&template.Key, &template.Language, &template.Body, &parseMode, &template.Description, &updatedBy,
+			&template.CreatedAt, &template.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if parseMode.Valid {
+			template.ParseMode = &parseMode.String
+		}
+		if updatedBy.Valid {
+			template.UpdatedBy = &updatedBy.String
+		}
+
+		templates = append(templates, &template)
+	}
+
+	return templates, nil
+}
+
+func (r *MessageTemplateRepo) Delete(ctx context.Context, key, language string) error {
+	sql, args, err := r.psql.Delete(`"message_templates"`).
+		Where(sq.Eq{"key": key, "language": language}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete message template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
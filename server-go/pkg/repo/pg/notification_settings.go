@@ -0,0 +1,98 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type NotificationSettingsRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewNotificationSettingsRepo(db *pgxpool.Pool) *NotificationSettingsRepo {
+	return &NotificationSettingsRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *NotificationSettingsRepo) GetByUserIDs(ctx context.Context, userIDs []string) (map[string]*domain.NotificationSettings, error) {
+	settings := make(map[string]*domain.NotificationSettings, len(userIDs))
+	if len(userIDs) == 0 {
+		return settings, nil
+	}
+
+	sql, args, err := r.psql.Select(
+		"user_id", "muted", "reminders", "payments", "waitlist", "broadcasts",
+		`to_char("quiet_hours_start", 'HH24:MI')`, `to_char("quiet_hours_end", 'HH24:MI')`, "timezone",
+	).
+		From(`"user_notification_settings"`).
+		Where(sq.Eq{"user_id": userIDs}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var s domain.NotificationSettings
+		var quietHoursStart, quietHoursEnd pgtype.Text
+
+		err := rows.Scan(
+			&userID, &s.Muted, &s.Reminders, &s.Payments, &s.Waitlist, &s.Broadcasts,
+			&quietHoursStart, &quietHoursEnd, &s.Timezone,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if quietHoursStart.Valid {
+			s.QuietHoursStart = &quietHoursStart.String
+		}
+		if quietHoursEnd.Valid {
+			s.QuietHoursEnd = &quietHoursEnd.String
+		}
+		settings[userID] = &s
+	}
+
+	return settings, rows.Err()
+}
+
+func (r *NotificationSettingsRepo) Upsert(ctx context.Context, userID string, settings *domain.NotificationSettings) error {
+	sql, args, err := r.psql.Insert(`"user_notification_settings"`).
+		Columns("user_id", "muted", "reminders", "payments", "waitlist", "broadcasts", "quiet_hours_start", "quiet_hours_end", "timezone").
+		Values(
+			userID, settings.Muted, settings.Reminders, settings.Payments, settings.Waitlist, settings.Broadcasts,
+			sq.Expr("?::time", settings.QuietHoursStart), sq.Expr("?::time", settings.QuietHoursEnd), settings.Timezone,
+		).
+		Suffix(`ON CONFLICT ("user_id") DO UPDATE SET
+			"muted" = EXCLUDED."muted",
+			"reminders" = EXCLUDED."reminders",
+			"payments" = EXCLUDED."payments",
+			"waitlist" = EXCLUDED."waitlist",
+			"broadcasts" = EXCLUDED."broadcasts",
+			"quiet_hours_start" = EXCLUDED."quiet_hours_start",
+			"quiet_hours_end" = EXCLUDED."quiet_hours_end",
+			"timezone" = EXCLUDED."timezone"`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to save notification settings: %w", err)
+	}
+	return nil
+}
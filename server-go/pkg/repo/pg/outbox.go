@@ -0,0 +1,162 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type OutboxRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewOutboxRepo(db *pgxpool.Pool) *OutboxRepo {
+	return &OutboxRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create записывает задачу в транзакции из контекста, если она есть
+func (r *OutboxRepo) Create(ctx context.Context, message *domain.CreateOutboxMessage) (string, error) {
+	s := r.psql.Insert(`"outbox"`).
+		Columns("task_type", "schema_version", "execute_at", "data").
+		Values(message.TaskType, message.SchemaVersion, message.ExecuteAt.UTC(), message.Data).
+		Suffix("RETURNING id")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = conn(ctx, r.db).QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create outbox message: %w", err)
+	}
+
+	return id, nil
+}
+
+// Claim забирает до limit неотправленных задач, время следующей попытки которых наступило, и откладывает
+// их на lease. Строки, захваченные другой репликой, пропускаются (FOR UPDATE SKIP LOCKED), а если реплика
+// упадет до MarkSent или MarkFailed, задачи снова станут доступны по истечении lease
+func (r *OutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	now := time.Now().UTC()
+
+	pending := r.psql.Select(`"id"`).
+		From(`"outbox"`).
+		Where(sq.Eq{"sent_at": nil}).
+		Where(sq.LtOrEq{"next_attempt_at": now}).
+		OrderBy(`"created_at"`).
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	s := r.psql.Update(`"outbox"`).
+		Set("next_attempt_at", now.Add(lease)).
+		Where(pending.Prefix(`"id" IN (`).Suffix(")")).
+		Suffix(`RETURNING "id", "task_type", "schema_version", "execute_at", "data", "attempts", "created_at"`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		message := &domain.OutboxMessage{}
+		err := rows.Scan(
+			&message.ID,
+			&message.TaskType,
+			&message.SchemaVersion,
+			&message.ExecuteAt,
+			&message.Data,
+			&message.Attempts,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox messages: %w", err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса, а задачи публикуются в порядке записи
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+
+	return messages, nil
+}
+
+func (r *OutboxRepo) MarkSent(ctx context.Context, id string) error {
+	s := r.psql.Update(`"outbox"`).
+		Set("sent_at", time.Now().UTC()).
+		Set("attempts", sq.Expr(`"attempts" + 1`)).
+		Set("last_error", nil).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to mark outbox message sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed сохраняет ошибку публикации и откладывает следующую попытку до nextAttemptAt
+func (r *OutboxRepo) MarkFailed(ctx context.Context, id string, lastError string, nextAttemptAt time.Time) error {
+	s := r.psql.Update(`"outbox"`).
+		Set("attempts", sq.Expr(`"attempts" + 1`)).
+		Set("last_error", lastError).
+		Set("next_attempt_at", nextAttemptAt.UTC()).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSentBefore удаляет задачи, опубликованные раньше before, и возвращает их количество
+func (r *OutboxRepo) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	s := r.psql.Delete(`"outbox"`).
+		Where(sq.NotEq{"sent_at": nil}).
+		Where(sq.Lt{"sent_at": before.UTC()})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox messages: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
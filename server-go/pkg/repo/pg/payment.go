@@ -26,8 +26,8 @@ func NewPaymentRepo(db *pgxpool.Pool) *PaymentRepo {
 
 func (r *PaymentRepo) Create(ctx context.Context, payment *domain.CreatePayment) (string, error) {
 	s := r.psql.Insert(`"payments"`).
-		Columns("payment_id", "amount", "status", "payment_link", "confirmation_token", "user_id", "event_id").
-		Values(payment.PaymentID, payment.Amount, payment.Status, payment.PaymentLink, payment.ConfirmationToken, payment.UserID, payment.EventID).
+		Columns("payment_id", "amount", "status", "payment_link", "confirmation_token", "user_id", "event_id", "original_amount", "promo_code_id").
+		Values(payment.PaymentID, payment.Amount, payment.Status, payment.PaymentLink, payment.ConfirmationToken, payment.UserID, payment.EventID, payment.OriginalAmount, payment.PromoCodeID).
 		Suffix("RETURNING id")
 
 	sql, args, err := s.ToSql()
@@ -36,7 +36,7 @@ func (r *PaymentRepo) Create(ctx context.Context, payment *domain.CreatePayment)
 	}
 
 	var id string
-	err = r.db.QueryRow(ctx, sql, args...).Scan(&id)
+	err = conn(ctx, r.db).QueryRow(ctx, sql, args...).Scan(&id)
 	if err != nil {
 		return "", fmt.Errorf("failed to create payment: %w", err)
 	}
@@ -48,10 +48,12 @@ func (r *PaymentRepo) Filter(ctx context.Context, filter *domain.FilterPayment)
 	s := r.psql.Select(
 		`"p"."id"`, `"p"."payment_id"`, `"p"."date"`, `"p"."amount"`, `"p"."status"`,
 		`"p"."payment_link"`, `"p"."confirmation_token"`, `"p"."user_id"`, `"p"."event_id"`,
+		`"p"."original_amount"`, `"p"."promo_code_id"`, `"pc"."code"`,
 		`"reg"."user_id"`, `"reg"."event_id"`, `"reg"."status"`, `"reg"."created_at"`, `"reg"."updated_at"`,
 		`"u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 	).
 		From(`"payments" AS p`).
+		LeftJoin(`"promo_codes" AS pc ON "p"."promo_code_id" = "pc"."id"`).
 		LeftJoin(`"registrations" AS reg ON "p"."user_id" = "reg"."user_id" AND "p"."event_id" = "reg"."event_id"`).
 		LeftJoin(`"users" AS u ON "reg"."user_id" = "u"."id"`)
 
@@ -67,6 +69,10 @@ func (r *PaymentRepo) Filter(ctx context.Context, filter *domain.FilterPayment)
 		s = s.Where(sq.Eq{`"p"."status"`: *filter.Status})
 	}
 
+	if filter.Statuses != nil {
+		s = s.Where(sq.Eq{`"p"."status"`: *filter.Statuses})
+	}
+
 	if filter.UserID != nil {
 		s = s.Where(sq.Eq{`"p"."user_id"`: *filter.UserID})
 	}
@@ -75,6 +81,14 @@ func (r *PaymentRepo) Filter(ctx context.Context, filter *domain.FilterPayment)
 		s = s.Where(sq.Eq{`"p"."event_id"`: *filter.EventID})
 	}
 
+	if filter.PromoCodeID != nil {
+		s = s.Where(sq.Eq{`"p"."promo_code_id"`: *filter.PromoCodeID})
+	}
+
+	if filter.CreatedBefore != nil {
+		s = s.Where(sq.Lt{`"p"."date"`: *filter.CreatedBefore})
+	}
+
 	s = s.OrderBy(`"p"."date" DESC`)
 
 	sql, args, err := s.ToSql()
@@ -136,7 +150,7 @@ func (r *PaymentRepo) Patch(ctx context.Context, id string, payment *domain.Patc
 		return fmt.Errorf("failed to build SQL: %w", err)
 	}
 
-	result, err := r.db.Exec(ctx, sql, args...)
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
@@ -148,6 +162,31 @@ func (r *PaymentRepo) Patch(ctx context.Context, id string, payment *domain.Patc
 	return nil
 }
 
+// TransitionStatus атомарно меняет статус платежа, только если текущий статус входит в from.
+// Возвращает false, если переход не выполнен (статус уже изменился или переход недопустим)
+func (r *PaymentRepo) TransitionStatus(ctx context.Context, id string, from []domain.PaymentStatus, to domain.PaymentStatus) (bool, error) {
+	if len(from) == 0 {
+		return false, nil
+	}
+
+	s := r.psql.Update(`"payments"`).
+		Set("status", to).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": from})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
 func (r *PaymentRepo) Delete(ctx context.Context, id string) error {
 	s := r.psql.Delete(`"payments"`).Where(sq.Eq{"id": id})
 
@@ -156,7 +195,7 @@ func (r *PaymentRepo) Delete(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to build SQL: %w", err)
 	}
 
-	result, err := r.db.Exec(ctx, sql, args...)
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete payment: %w", err)
 	}
@@ -174,6 +213,9 @@ func (r *PaymentRepo) scanPayment(rows pgx.Rows) (*domain.Payment, error) {
 	var registration *domain.Registration
 	var user *domain.User
 
+	var originalAmount pgtype.Int4
+	var promoCodeID, promoCode pgtype.Text
+
 	// Nullable fields
 	var regUserID, regEventID pgtype.Text
 	var regStatus pgtype.Text
@@ -185,6 +227,7 @@ func (r *PaymentRepo) scanPayment(rows pgx.Rows) (*domain.Payment, error) {
 	err := rows.Scan(
 		&payment.ID, &payment.PaymentID, &payment.Date, &payment.Amount, &payment.Status,
 		&payment.PaymentLink, &payment.ConfirmationToken, &payment.UserID, &payment.EventID,
+		&originalAmount, &promoCodeID, &promoCode,
 		&regUserID, &regEventID, &regStatus, &regCreatedAt, &regUpdatedAt,
 		&userID, &userTelegramID, &userTelegramUsername, &userFirstName, &userLastName, &userAvatar,
 	)
@@ -192,6 +235,17 @@ func (r *PaymentRepo) scanPayment(rows pgx.Rows) (*domain.Payment, error) {
 		return nil, fmt.Errorf("failed to scan row: %w", err)
 	}
 
+	if originalAmount.Valid {
+		amount := int(originalAmount.Int32)
+		payment.OriginalAmount = &amount
+	}
+	if promoCodeID.Valid {
+		payment.PromoCodeID = &promoCodeID.String
+	}
+	if promoCode.Valid {
+		payment.PromoCode = &promoCode.String
+	}
+
 	// Если есть связанная регистрация, заполняем её
 	if regUserID.Valid && regEventID.Valid {
 		registration = &domain.Registration{
@@ -4,13 +4,27 @@ import "github.com/shampsdev/go-telegram-template/pkg/repo"
 
 // to ensure pg implement the repo interfaces
 var (
-	_ repo.User         = &UserRepo{}
-	_ repo.AdminUser    = &AdminUserRepo{}
-	_ repo.Court        = &CourtRepo{}
-	_ repo.Event        = &EventRepo{}
-	_ repo.Loyalty      = &LoyaltyRepo{}
-	_ repo.Registration = &RegistrationRepo{}
-	_ repo.Payment      = &PaymentRepo{}
-	_ repo.Waitlist     = &WaitlistRepo{}
-	_ repo.AdminUser    = &AdminUserRepo{}
+	_ repo.User                 = &UserRepo{}
+	_ repo.AdminUser            = &AdminUserRepo{}
+	_ repo.Court                = &CourtRepo{}
+	_ repo.Event                = &EventRepo{}
+	_ repo.EventSeries          = &EventSeriesRepo{}
+	_ repo.Loyalty              = &LoyaltyRepo{}
+	_ repo.Registration         = &RegistrationRepo{}
+	_ repo.RegistrationPair     = &RegistrationPairRepo{}
+	_ repo.Payment              = &PaymentRepo{}
+	_ repo.Refund               = &RefundRepo{}
+	_ repo.PromoCode            = &PromoCodeRepo{}
+	_ repo.Task                 = &TaskRepo{}
+	_ repo.Broadcast            = &BroadcastRepo{}
+	_ repo.MessageTemplate      = &MessageTemplateRepo{}
+	_ repo.NotificationSettings = &NotificationSettingsRepo{}
+	_ repo.Tournament           = &TournamentRepo{}
+	_ repo.Rating               = &RatingRepo{}
+	_ repo.WebhookEvent         = &WebhookEventRepo{}
+	_ repo.Waitlist             = &WaitlistRepo{}
+	_ repo.WaitlistOffer        = &WaitlistOfferRepo{}
+	_ repo.AdminUser            = &AdminUserRepo{}
+	_ repo.Outbox               = &OutboxRepo{}
+	_ repo.Transactor           = &TxManager{}
 )
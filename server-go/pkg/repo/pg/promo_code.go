@@ -0,0 +1,317 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+// promoCodeUsageStatuses статусы платежей, которые считаются использованием промокода
+var promoCodeUsageStatuses = []domain.PaymentStatus{
+	domain.PaymentStatusPending,
+	domain.PaymentStatusWaitingForCapture,
+	domain.PaymentStatusSucceeded,
+}
+
+type PromoCodeRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewPromoCodeRepo(db *pgxpool.Pool) *PromoCodeRepo {
+	return &PromoCodeRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *PromoCodeRepo) Create(ctx context.Context, promoCode *domain.CreatePromoCode) (string, error) {
+	isActive := true
+	if promoCode.IsActive != nil {
+		isActive = *promoCode.IsActive
+	}
+
+	s := r.psql.Insert(`"promo_codes"`).
+		Columns(
+			"code", "discount_type", "discount_value", "max_uses", "max_uses_per_user",
+			"valid_from", "valid_until", "event_types", "club_ids", "is_active",
+		).
+		Values(
+			promoCode.Code, promoCode.DiscountType, promoCode.DiscountValue, promoCode.MaxUses, promoCode.MaxUsesPerUser,
+			promoCode.ValidFrom, promoCode.ValidUntil, eventTypesToStrings(promoCode.EventTypes), nilIfEmpty(promoCode.ClubIDs), isActive,
+		).
+		Suffix("RETURNING id")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create promo code: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *PromoCodeRepo) Filter(ctx context.Context, filter *domain.FilterPromoCode) ([]*domain.PromoCode, error) {
+	usesCount := r.psql.Select("COUNT(*)").
+		From(`"payments" AS p`).
+		Where(`"p"."promo_code_id" = "pc"."id"`).
+		Where(sq.Eq{`"p"."status"`: promoCodeUsageStatuses})
+
+	usesCountSQL, usesCountArgs, err := usesCount.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	s := r.psql.Select(
+		`"pc"."id"`, `"pc"."code"`, `"pc"."discount_type"`, `"pc"."discount_value"`,
+		`"pc"."max_uses"`, `"pc"."max_uses_per_user"`, `"pc"."valid_from"`, `"pc"."valid_until"`,
+		`"pc"."event_types"`, `"pc"."club_ids"`, `"pc"."is_active"`, `"pc"."created_at"`, `"pc"."updated_at"`,
+	).
+		Column(sq.Expr("("+usesCountSQL+")", usesCountArgs...)).
+		From(`"promo_codes" AS pc`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{`"pc"."id"`: *filter.ID})
+	}
+
+	if filter.Code != nil {
+		s = s.Where(sq.Expr(`UPPER("pc"."code") = UPPER(?)`, *filter.Code))
+	}
+
+	if filter.IsActive != nil {
+		s = s.Where(sq.Eq{`"pc"."is_active"`: *filter.IsActive})
+	}
+
+	s = s.OrderBy(`"pc"."created_at" DESC`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.PromoCode{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	promoCodes := []*domain.PromoCode{}
+	for rows.Next() {
+		var promoCode domain.PromoCode
+		var maxUses, maxUsesPerUser pgtype.Int4
+		var validFrom, validUntil pgtype.Timestamp
+		var eventTypes, clubIDs []string
+
+		err := rows.Scan(
+			&promoCode.ID, &promoCode.Code, &promoCode.DiscountType, &promoCode.DiscountValue,
+			&maxUses, &maxUsesPerUser, &validFrom, &validUntil,
+			&eventTypes, &clubIDs, &promoCode.IsActive, &promoCode.CreatedAt, &promoCode.UpdatedAt,
+			&promoCode.UsesCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if maxUses.Valid {
+			value := int(maxUses.Int32)
+			promoCode.MaxUses = &value
+		}
+		if maxUsesPerUser.Valid {
+			value := int(maxUsesPerUser.Int32)
+			promoCode.MaxUsesPerUser = &value
+		}
+		if validFrom.Valid {
+			promoCode.ValidFrom = &validFrom.Time
+		}
+		if validUntil.Valid {
+			promoCode.ValidUntil = &validUntil.Time
+		}
+		for _, eventType := range eventTypes {
+			promoCode.EventTypes = append(promoCode.EventTypes, domain.EventType(eventType))
+		}
+		promoCode.ClubIDs = clubIDs
+
+		promoCodes = append(promoCodes, &promoCode)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return promoCodes, nil
+}
+
+func (r *PromoCodeRepo) Patch(ctx context.Context, id string, promoCode *domain.PatchPromoCode) error {
+	s := r.psql.Update(`"promo_codes"`).Where(sq.Eq{"id": id})
+
+	hasUpdates := false
+
+	if promoCode.DiscountType != nil {
+		s = s.Set("discount_type", *promoCode.DiscountType)
+		hasUpdates = true
+	}
+
+	if promoCode.DiscountValue != nil {
+		s = s.Set("discount_value", *promoCode.DiscountValue)
+		hasUpdates = true
+	}
+
+	if promoCode.MaxUses != nil {
+		s = s.Set("max_uses", *promoCode.MaxUses)
+		hasUpdates = true
+	}
+
+	if promoCode.MaxUsesPerUser != nil {
+		s = s.Set("max_uses_per_user", *promoCode.MaxUsesPerUser)
+		hasUpdates = true
+	}
+
+	if promoCode.ValidFrom != nil {
+		s = s.Set("valid_from", *promoCode.ValidFrom)
+		hasUpdates = true
+	}
+
+	if promoCode.ValidUntil != nil {
+		s = s.Set("valid_until", *promoCode.ValidUntil)
+		hasUpdates = true
+	}
+
+	if promoCode.EventTypes != nil {
+		s = s.Set("event_types", eventTypesToStrings(*promoCode.EventTypes))
+		hasUpdates = true
+	}
+
+	if promoCode.ClubIDs != nil {
+		s = s.Set("club_ids", nilIfEmpty(*promoCode.ClubIDs))
+		hasUpdates = true
+	}
+
+	if promoCode.IsActive != nil {
+		s = s.Set("is_active", *promoCode.IsActive)
+		hasUpdates = true
+	}
+
+	if !hasUpdates {
+		return fmt.Errorf("no fields to update")
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update promo code: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("promo code with id %s not found", id)
+	}
+
+	return nil
+}
+
+func (r *PromoCodeRepo) Delete(ctx context.Context, id string) error {
+	s := r.psql.Delete(`"promo_codes"`).Where(sq.Eq{"id": id})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete promo code: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("promo code with id %s not found", id)
+	}
+
+	return nil
+}
+
+// CountUses считает использования промокода (платежи в pending, waiting_for_capture и succeeded).
+// Если userID задан, считаются только платежи этого пользователя
+func (r *PromoCodeRepo) CountUses(ctx context.Context, id string, userID *string) (int, error) {
+	s := r.psql.Select("COUNT(*)").
+		From(`"payments"`).
+		Where(sq.Eq{"promo_code_id": id}).
+		Where(sq.Eq{"status": promoCodeUsageStatuses})
+
+	if userID != nil {
+		s = s.Where(sq.Eq{"user_id": *userID})
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var count int
+	err = conn(ctx, r.db).QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count promo code uses: %w", err)
+	}
+
+	return count, nil
+}
+
+// LockForUse блокирует строку промокода до конца транзакции из контекста. Применения одного промокода
+// выполняются по очереди, и CountUses после блокировки видит платежи, созданные до нее
+func (r *PromoCodeRepo) LockForUse(ctx context.Context, id string) error {
+	s := r.psql.Select("id").
+		From(`"promo_codes"`).
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var locked string
+	err = conn(ctx, r.db).QueryRow(ctx, sql, args...).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("promo code %s not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock promo code: %w", err)
+	}
+
+	return nil
+}
+
+func eventTypesToStrings(eventTypes []domain.EventType) []string {
+	if len(eventTypes) == 0 {
+		return nil
+	}
+
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		result = append(result, string(eventType))
+	}
+	return result
+}
+
+func nilIfEmpty(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
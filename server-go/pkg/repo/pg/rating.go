@@ -0,0 +1,229 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type RatingRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewRatingRepo(db *pgxpool.Pool) *RatingRepo {
+	return &RatingRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create записывает изменение ранга в историю без изменения самого пользователя (ручная правка администратора)
+func (r *RatingRepo) Create(ctx context.Context, change *domain.CreateRatingChange) error {
+	s := r.psql.Insert(`"rating_history"`).
+		Columns("user_id", "source", "event_id", "match_id", "old_rank", "new_rank").
+		Values(change.UserID, change.Source, change.EventID, change.MatchID, change.OldRank, change.NewRank)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	if _, err := conn(ctx, r.db).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to create rating change: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceMatchChanges атомарно заменяет изменения ранга по матчу и обновляет ранги игроков.
+// При исправлении счета прежние записи удаляются, а счетчик рейтинговых матчей повторно не увеличивается
+func (r *RatingRepo) ReplaceMatchChanges(ctx context.Context, matchID string, changes []*domain.CreateRatingChange) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, `DELETE FROM "rating_history" WHERE "match_id" = $1 RETURNING "user_id"`, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to delete previous rating changes: %w", err)
+	}
+	rated := map[string]bool{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		rated[userID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to delete previous rating changes: %w", err)
+	}
+
+	for _, change := range changes {
+		increment := 1
+		if rated[change.UserID] {
+			increment = 0
+		}
+
+		updateUser := r.psql.Update(`"users"`).
+			Set("rank", change.NewRank).
+			Set("rated_matches", sq.Expr("rated_matches + ?", increment)).
+			Where(sq.Eq{"id": change.UserID})
+		if err := r.exec(ctx, tx, updateUser); err != nil {
+			return fmt.Errorf("failed to update user rank: %w", err)
+		}
+
+		insertChange := r.psql.Insert(`"rating_history"`).
+			Columns("user_id", "source", "event_id", "match_id", "old_rank", "new_rank").
+			Values(change.UserID, change.Source, change.EventID, change.MatchID, change.OldRank, change.NewRank)
+		if err := r.exec(ctx, tx, insertChange); err != nil {
+			return fmt.Errorf("failed to create rating change: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMatchChanges откатывает изменения ранга по матчу у игроков и удаляет их из истории
+func (r *RatingRepo) DeleteMatchChanges(ctx context.Context, matchID string) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "users" AS "u"
+		SET "rank" = GREATEST(0, "u"."rank" - ("h"."new_rank" - "h"."old_rank")),
+			"rated_matches" = GREATEST(0, "u"."rated_matches" - 1)
+		FROM "rating_history" AS "h"
+		WHERE "h"."match_id" = $1 AND "h"."user_id" = "u"."id"`, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to revert user ranks: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM "rating_history" WHERE "match_id" = $1`, matchID); err != nil {
+		return fmt.Errorf("failed to delete rating changes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// LockPlayers блокирует строки игроков до конца транзакции из контекста. Ранг пересчитывается от текущего значения,
+// поэтому матчи с общими игроками пересчитываются по очереди. Строки блокируются в порядке ID, чтобы не было взаимоблокировок
+func (r *RatingRepo) LockPlayers(ctx context.Context, userIDs []string) error {
+	s := r.psql.Select(`"id"`).
+		From(`"users"`).
+		Where(sq.Eq{`"id"`: userIDs}).
+		OrderBy(`"id"`).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to lock players: %w", err)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to lock players: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RatingRepo) Filter(ctx context.Context, filter *domain.FilterRatingChange) ([]*domain.RatingChange, error) {
+	s := r.psql.Select(
+		`"id"`, `"user_id"`, `"source"`, `"event_id"`, `"match_id"`, `"old_rank"`, `"new_rank"`, `"created_at"`,
+	).From(`"rating_history"`)
+
+	if filter.UserID != nil {
+		s = s.Where(sq.Eq{`"user_id"`: *filter.UserID})
+	}
+
+	if filter.MatchID != nil {
+		s = s.Where(sq.Eq{`"match_id"`: *filter.MatchID})
+	}
+
+	if filter.From != nil {
+		s = s.Where(sq.GtOrEq{`"created_at"`: *filter.From})
+	}
+
+	if filter.To != nil {
+		s = s.Where(sq.Lt{`"created_at"`: *filter.To})
+	}
+
+	s = s.OrderBy(`"created_at" ASC`, `"id" ASC`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.RatingChange{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*domain.RatingChange{}
+	for rows.Next() {
+		var change domain.RatingChange
+		var eventID, matchID pgtype.Text
+
+		err := rows.Scan(
+			&change.ID, &change.UserID, &change.Source, &eventID, &matchID,
+			&change.OldRank, &change.NewRank, &change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if eventID.Valid {
+			change.EventID = &eventID.String
+		}
+		if matchID.Valid {
+			change.MatchID = &matchID.String
+		}
+		change.Delta = change.NewRank - change.OldRank
+
+		changes = append(changes, &change)
+	}
+
+	return changes, nil
+}
+
+func (r *RatingRepo) exec(ctx context.Context, tx pgx.Tx, s sq.Sqlizer) error {
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
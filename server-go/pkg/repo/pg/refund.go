@@ -0,0 +1,214 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type RefundRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewRefundRepo(db *pgxpool.Pool) *RefundRepo {
+	return &RefundRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *RefundRepo) Create(ctx context.Context, refund *domain.CreateRefund) (string, error) {
+	s := r.psql.Insert(`"refunds"`).
+		Columns("refund_id", "payment_id", "amount", "status", "reason").
+		Values(refund.RefundID, refund.PaymentID, refund.Amount, refund.Status, refund.Reason).
+		Suffix("RETURNING id")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *RefundRepo) Filter(ctx context.Context, filter *domain.FilterRefund) ([]*domain.Refund, error) {
+	s := r.psql.Select(
+		`"rf"."id"`, `"rf"."refund_id"`, `"rf"."payment_id"`, `"rf"."amount"`, `"rf"."status"`, `"rf"."reason"`,
+		`"p"."user_id"`, `"p"."event_id"`, `"rf"."created_at"`, `"rf"."updated_at"`, `"p"."payment_id"`,
+	).
+		From(`"refunds" AS rf`).
+		Join(`"payments" AS p ON "rf"."payment_id" = "p"."id"`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{`"rf"."id"`: *filter.ID})
+	}
+
+	if filter.RefundID != nil {
+		s = s.Where(sq.Eq{`"rf"."refund_id"`: *filter.RefundID})
+	}
+
+	if filter.PaymentID != nil {
+		s = s.Where(sq.Eq{`"rf"."payment_id"`: *filter.PaymentID})
+	}
+
+	if filter.Status != nil {
+		s = s.Where(sq.Eq{`"rf"."status"`: *filter.Status})
+	}
+
+	if filter.UserID != nil {
+		s = s.Where(sq.Eq{`"p"."user_id"`: *filter.UserID})
+	}
+
+	if filter.EventID != nil {
+		s = s.Where(sq.Eq{`"p"."event_id"`: *filter.EventID})
+	}
+
+	if filter.Unsent != nil {
+		if *filter.Unsent {
+			s = s.Where(`"rf"."refund_id" IS NULL`)
+		} else {
+			s = s.Where(`"rf"."refund_id" IS NOT NULL`)
+		}
+	}
+
+	if filter.CreatedBefore != nil {
+		s = s.Where(sq.Lt{`"rf"."created_at"`: *filter.CreatedBefore})
+	}
+
+	s = s.OrderBy(`"rf"."created_at" DESC`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.Refund{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	refunds := []*domain.Refund{}
+	for rows.Next() {
+		var refund domain.Refund
+		var refundID, reason pgtype.Text
+
+		err := rows.Scan(
+			&refund.ID, &refundID, &refund.PaymentID, &refund.Amount, &refund.Status, &reason,
+			&refund.UserID, &refund.EventID, &refund.CreatedAt, &refund.UpdatedAt, &refund.ProviderPaymentID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if refundID.Valid {
+			refund.RefundID = refundID.String
+		}
+
+		if reason.Valid {
+			refund.Reason = reason.String
+		}
+
+		refunds = append(refunds, &refund)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return refunds, nil
+}
+
+func (r *RefundRepo) Patch(ctx context.Context, id string, refund *domain.PatchRefund) error {
+	s := r.psql.Update(`"refunds"`).Where(sq.Eq{"id": id})
+
+	hasUpdates := false
+
+	if refund.Status != nil {
+		s = s.Set("status", *refund.Status)
+		hasUpdates = true
+	}
+
+	if !hasUpdates {
+		return fmt.Errorf("no fields to update")
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("refund with id %s not found", id)
+	}
+
+	return nil
+}
+
+// TransitionStatus атомарно меняет статус возврата, только если текущий статус входит в from.
+// Возвращает false, если переход не выполнен (статус уже изменился или переход недопустим)
+func (r *RefundRepo) TransitionStatus(ctx context.Context, id string, from []domain.RefundStatus, to domain.RefundStatus) (bool, error) {
+	if len(from) == 0 {
+		return false, nil
+	}
+
+	s := r.psql.Update(`"refunds"`).
+		Set("status", to).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": from})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// SetProviderRefund сохраняет ID и статус возврата, принятого провайдером. Возвращает false,
+// если возврат уже отправлен провайдеру: ID провайдера не перезаписывается
+func (r *RefundRepo) SetProviderRefund(ctx context.Context, id, refundID string, status domain.RefundStatus) (bool, error) {
+	s := r.psql.Update(`"refunds"`).
+		Set("refund_id", refundID).
+		Set("status", status).
+		Where(sq.Eq{"id": id}).
+		Where(`"refund_id" IS NULL`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
 )
 
 type RegistrationRepo struct {
@@ -35,7 +37,7 @@ func (r *RegistrationRepo) Create(ctx context.Context, registration *domain.Crea
 		return fmt.Errorf("failed to build SQL: %w", err)
 	}
 
-	_, err = r.db.Exec(ctx, sql, args...)
+	_, err = conn(ctx, r.db).Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create registration: %w", err)
 	}
@@ -45,11 +47,11 @@ func (r *RegistrationRepo) Create(ctx context.Context, registration *domain.Crea
 
 func (r *RegistrationRepo) Filter(ctx context.Context, filter *domain.FilterRegistration) ([]*domain.Registration, error) {
 	s := r.psql.Select(
-		`"reg"."user_id"`, `"reg"."event_id"`, `"reg"."status"`, `"reg"."created_at"`, `"reg"."updated_at"`,
+		`"reg"."user_id"`, `"reg"."event_id"`, `"reg"."status"`, `"reg"."created_at"`, `"reg"."updated_at"`, `"reg"."pending_since"`,
 		`"u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 		`"u"."bio"`, `"u"."rank"`, `"u"."city"`, `"u"."birth_date"`, `"u"."playing_position"`, `"u"."padel_profiles"`, `"u"."is_registered"`,
 		`"e"."id"`, `"e"."name"`, `"e"."description"`, `"e"."start_time"`, `"e"."end_time"`, `"e"."rank_min"`, `"e"."rank_max"`, `"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`,
-		`"c"."id"`, `"c"."name"`, `"c"."address"`,
+		`"c"."id"`, `"c"."name"`, `"c"."address"`, `"c"."timezone"`,
 		`"org"."id"`, `"org"."telegram_id"`, `"org"."telegram_username"`, `"org"."first_name"`, `"org"."last_name"`, `"org"."avatar"`,
 	).
 		From(`"registrations" AS reg`).
@@ -124,7 +126,7 @@ func (r *RegistrationRepo) Patch(ctx context.Context, userID, eventID string, re
 		return fmt.Errorf("failed to build SQL: %w", err)
 	}
 
-	result, err := r.db.Exec(ctx, sql, args...)
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update registration: %w", err)
 	}
@@ -136,6 +138,145 @@ func (r *RegistrationRepo) Patch(ctx context.Context, userID, eventID string, re
 	return nil
 }
 
+// TransitionStatus атомарно меняет статус регистрации, только если текущий статус входит в from.
+// Возвращает false, если переход не выполнен (статус уже изменился или переход недопустим)
+func (r *RegistrationRepo) TransitionStatus(ctx context.Context, userID, eventID string, from []domain.RegistrationStatus, to domain.RegistrationStatus) (bool, error) {
+	if len(from) == 0 {
+		return false, nil
+	}
+
+	s := r.psql.Update(`"registrations"`).
+		Set("status", to).
+		Set("updated_at", "NOW()").
+		Where(sq.Eq{"user_id": userID, "event_id": eventID}).
+		Where(sq.Eq{"status": from})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update registration: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// OccupySeats создает или обновляет регистрации на событие в одной транзакции. Строка события блокируется
+// (SELECT ... FOR UPDATE), поэтому параллельные регистрации на одно событие выполняются по очереди и не могут
+// занять одно и то же место. Занятыми считаются регистрации в статусах domain.SeatStatuses и активные
+// предложения из листа ожидания, кроме принадлежащих самим регистрируемым. Если после вставки
+// все места заняты, событие в статусе registration переводится в full
+func (r *RegistrationRepo) OccupySeats(ctx context.Context, eventID string, registrations []*domain.CreateRegistration) error {
+	if len(registrations) == 0 {
+		return nil
+	}
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	sql, args, err := r.psql.Select(`"type"`, `"status"`, `"max_users"`).
+		From(`"event"`).
+		Where(sq.Eq{"id": eventID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var eventType domain.EventType
+	var eventStatus domain.EventStatus
+	var maxUsers int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&eventType, &eventStatus, &maxUsers)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock event: %w", err)
+	}
+
+	userIDs := make([]string, 0, len(registrations))
+	seats := 0
+	for _, registration := range registrations {
+		userIDs = append(userIDs, registration.UserID)
+		if registration.Status.OccupiesSeat(eventType) {
+			seats++
+		}
+	}
+
+	occupied, err := r.count(ctx, tx, r.psql.Select("COUNT(*)").
+		From(`"registrations"`).
+		Where(sq.Eq{"event_id": eventID, "status": domain.SeatStatuses(eventType)}).
+		Where(sq.NotEq{"user_id": userIDs}))
+	if err != nil {
+		return fmt.Errorf("failed to count occupied seats: %w", err)
+	}
+
+	held, err := r.count(ctx, tx, r.psql.Select("COUNT(*)").
+		From(`"waitlist_offers"`).
+		Where(sq.Eq{"event_id": eventID, "status": domain.WaitlistOfferStatusPending}).
+		Where(sq.Gt{"expires_at": time.Now().UTC()}).
+		Where(sq.NotEq{"user_id": userIDs}))
+	if err != nil {
+		return fmt.Errorf("failed to count held seats: %w", err)
+	}
+
+	if seats > 0 && occupied+held+seats > maxUsers {
+		return fmt.Errorf("%w (maximum %d users)", repo.ErrEventFull, maxUsers)
+	}
+
+	insert := r.psql.Insert(`"registrations"`).
+		Columns("user_id", "event_id", "status").
+		Suffix(`ON CONFLICT ("user_id", "event_id") DO UPDATE SET "status" = EXCLUDED."status", "updated_at" = NOW()`)
+	for _, registration := range registrations {
+		insert = insert.Values(registration.UserID, eventID, registration.Status)
+	}
+	sql, args, err = insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to create registrations: %w", err)
+	}
+
+	// Удерживаемые предложениями места на статус события не влияют, как и в подсчете активных регистраций
+	if eventStatus == domain.EventStatusRegistration && occupied+seats >= maxUsers {
+		sql, args, err = r.psql.Update(`"event"`).
+			Set("status", domain.EventStatusFull).
+			Set("updated_at", sq.Expr("NOW()")).
+			Where(sq.Eq{"id": eventID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build SQL: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("failed to update event status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *RegistrationRepo) count(ctx context.Context, tx pgx.Tx, s sq.SelectBuilder) (int, error) {
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build SQL: %w", err)
+	}
+	var count int
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *RegistrationRepo) Delete(ctx context.Context, userID, eventID string) error {
 	s := r.psql.Delete(`"registrations"`).
 		Where(sq.Eq{"user_id": userID, "event_id": eventID})
@@ -145,7 +286,7 @@ func (r *RegistrationRepo) Delete(ctx context.Context, userID, eventID string) e
 		return fmt.Errorf("failed to build SQL: %w", err)
 	}
 
-	result, err := r.db.Exec(ctx, sql, args...)
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete registration: %w", err)
 	}
@@ -159,11 +300,11 @@ func (r *RegistrationRepo) Delete(ctx context.Context, userID, eventID string) e
 
 func (r *RegistrationRepo) AdminFilter(ctx context.Context, filter *domain.AdminFilterRegistration) ([]*domain.RegistrationWithPayments, error) {
 	s := r.psql.Select(
-		`"reg"."user_id"`, `"reg"."event_id"`, `"reg"."status"`, `"reg"."created_at"`, `"reg"."updated_at"`,
+		`"reg"."user_id"`, `"reg"."event_id"`, `"reg"."status"`, `"reg"."created_at"`, `"reg"."updated_at"`, `"reg"."pending_since"`,
 		`"u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 		`"u"."bio"`, `"u"."rank"`, `"u"."city"`, `"u"."birth_date"`, `"u"."playing_position"`, `"u"."padel_profiles"`, `"u"."is_registered"`,
 		`"e"."id"`, `"e"."name"`, `"e"."description"`, `"e"."start_time"`, `"e"."end_time"`, `"e"."rank_min"`, `"e"."rank_max"`, `"e"."price"`, `"e"."max_users"`, `"e"."status"`, `"e"."type"`, `"e"."club_id"`, `"e"."data"`,
-		`"c"."id"`, `"c"."name"`, `"c"."address"`,
+		`"c"."id"`, `"c"."name"`, `"c"."address"`, `"c"."timezone"`,
 		`"org"."id"`, `"org"."telegram_id"`, `"org"."telegram_username"`, `"org"."first_name"`, `"org"."last_name"`, `"org"."avatar"`,
 	).
 		From(`"registrations" AS reg`).
@@ -250,7 +391,9 @@ func (r *RegistrationRepo) AdminFilter(ctx context.Context, filter *domain.Admin
 func (r *RegistrationRepo) getPaymentsForRegistration(ctx context.Context, userID, eventID string) ([]*domain.Payment, error) {
 	s := r.psql.Select(
 		`"p"."id"`, `"p"."payment_id"`, `"p"."date"`, `"p"."amount"`, `"p"."status"`, `"p"."payment_link"`, `"p"."confirmation_token"`, `"p"."user_id"`, `"p"."event_id"`,
+		`"p"."original_amount"`, `"p"."promo_code_id"`, `"pc"."code"`,
 	).From(`"payments" AS p`).
+		LeftJoin(`"promo_codes" AS pc ON "p"."promo_code_id" = "pc"."id"`).
 		Where(sq.Eq{"p.user_id": userID, "p.event_id": eventID})
 
 	sql, args, err := s.ToSql()
@@ -270,15 +413,29 @@ func (r *RegistrationRepo) getPaymentsForRegistration(ctx context.Context, userI
 	payments := []*domain.Payment{}
 	for rows.Next() {
 		var payment domain.Payment
+		var originalAmount pgtype.Int4
+		var promoCodeID, promoCode pgtype.Text
 
 		err := rows.Scan(
 			&payment.ID, &payment.PaymentID, &payment.Date, &payment.Amount, &payment.Status,
 			&payment.PaymentLink, &payment.ConfirmationToken, &payment.UserID, &payment.EventID,
+			&originalAmount, &promoCodeID, &promoCode,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan payment row: %w", err)
 		}
 
+		if originalAmount.Valid {
+			amount := int(originalAmount.Int32)
+			payment.OriginalAmount = &amount
+		}
+		if promoCodeID.Valid {
+			payment.PromoCodeID = &promoCodeID.String
+		}
+		if promoCode.Valid {
+			payment.PromoCode = &promoCode.String
+		}
+
 		payments = append(payments, &payment)
 	}
 
@@ -304,13 +461,14 @@ func (r *RegistrationRepo) scanRegistration(rows pgx.Rows) (*domain.Registration
 	var eventDescription, eventClubID pgtype.Text
 	var eventData []byte
 	var orgTelegramUsername, orgAvatar pgtype.Text
+	var pendingSince pgtype.Timestamp
 
 		err := rows.Scan(
-		&registration.UserID, &registration.EventID, &registration.Status, &registration.CreatedAt, &registration.UpdatedAt,
+		&registration.UserID, &registration.EventID, &registration.Status, &registration.CreatedAt, &registration.UpdatedAt, &pendingSince,
 		&user.ID, &user.TelegramID, &userTelegramUsername, &user.FirstName, &user.LastName, &userAvatar,
 		&userBio, &userRank, &userCity, &userBirthDate, &userPlayingPosition, &userPadelProfiles, &userIsRegistered,
 		&event.ID, &event.Name, &eventDescription, &event.StartTime, &event.EndTime, &event.RankMin, &event.RankMax, &event.Price, &event.MaxUsers, &event.Status, &event.Type, &eventClubID, &eventData,
-		&court.ID, &court.Name, &court.Address,
+		&court.ID, &court.Name, &court.Address, &court.Timezone,
 		&organizer.ID, &organizer.TelegramID, &orgTelegramUsername, &organizer.FirstName, &organizer.LastName, &orgAvatar,
 		)
 		if err != nil {
@@ -366,6 +524,10 @@ func (r *RegistrationRepo) scanRegistration(rows pgx.Rows) (*domain.Registration
 		organizer.Avatar = orgAvatar.String
 		}
 
+	if pendingSince.Valid {
+		registration.PendingSince = &pendingSince.Time
+	}
+
 	event.Court = court
 	event.Organizer = organizer
 
@@ -0,0 +1,125 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type RegistrationPairRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewRegistrationPairRepo(db *pgxpool.Pool) *RegistrationPairRepo {
+	return &RegistrationPairRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *RegistrationPairRepo) Create(ctx context.Context, pair *domain.CreateRegistrationPair) (string, error) {
+	s := r.psql.Insert(`"registration_pairs"`).
+		Columns("event_id", "captain_id", "partner_id", "status", "payment_mode").
+		Values(pair.EventID, pair.CaptainID, pair.PartnerID, domain.RegistrationPairStatusInvited, pair.PaymentMode).
+		Suffix("RETURNING id")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create registration pair: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *RegistrationPairRepo) Filter(ctx context.Context, filter *domain.FilterRegistrationPair) ([]*domain.RegistrationPair, error) {
+	s := r.psql.Select(
+		`"id"`, `"event_id"`, `"captain_id"`, `"partner_id"`, `"status"`, `"payment_mode"`, `"created_at"`, `"updated_at"`,
+	).From(`"registration_pairs"`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{`"id"`: *filter.ID})
+	}
+
+	if filter.EventID != nil {
+		s = s.Where(sq.Eq{`"event_id"`: *filter.EventID})
+	}
+
+	if filter.UserID != nil {
+		s = s.Where(sq.Or{
+			sq.Eq{`"captain_id"`: *filter.UserID},
+			sq.Eq{`"partner_id"`: *filter.UserID},
+		})
+	}
+
+	if len(filter.Statuses) > 0 {
+		s = s.Where(sq.Eq{`"status"`: filter.Statuses})
+	}
+
+	s = s.OrderBy(`"created_at" DESC`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.RegistrationPair{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := []*domain.RegistrationPair{}
+	for rows.Next() {
+		var pair domain.RegistrationPair
+		err := rows.Scan(
+			&pair.ID, &pair.EventID, &pair.CaptainID, &pair.PartnerID,
+			&pair.Status, &pair.PaymentMode, &pair.CreatedAt, &pair.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pairs = append(pairs, &pair)
+	}
+
+	return pairs, nil
+}
+
+// TransitionStatus атомарно меняет статус пары, только если текущий статус входит в from.
+// Возвращает false, если пару уже перевел другой запрос
+func (r *RegistrationPairRepo) TransitionStatus(ctx context.Context, id string, from []domain.RegistrationPairStatus, to domain.RegistrationPairStatus) (bool, error) {
+	if len(from) == 0 {
+		return false, nil
+	}
+
+	s := r.psql.Update(`"registration_pairs"`).
+		Set("status", to).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": from})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update registration pair: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
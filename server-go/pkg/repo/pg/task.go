@@ -0,0 +1,154 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+// TaskRepo работает с таблицей tasks воркера уведомлений
+type TaskRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewTaskRepo(db *pgxpool.Pool) *TaskRepo {
+	return &TaskRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *TaskRepo) Filter(ctx context.Context, filter *domain.FilterTask) ([]*domain.Task, error) {
+	s := r.psql.Select(
+		"id", "task_type", "status", "execute_at", "data", "retry_count", "max_retries",
+		"last_error", "locked_by", "locked_until", "created_at", "updated_at",
+	).From("tasks")
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{"id": *filter.ID})
+	}
+
+	if filter.TaskType != nil {
+		s = s.Where(sq.Eq{"task_type": *filter.TaskType})
+	}
+
+	if filter.Status != nil {
+		s = s.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	if len(filter.Statuses) > 0 {
+		s = s.Where(sq.Eq{"status": filter.Statuses})
+	}
+
+	if filter.UserTelegramID != nil {
+		s = s.Where(sq.Expr("data->>'user_telegram_id' = ?", strconv.FormatInt(*filter.UserTelegramID, 10)))
+	}
+
+	if filter.TournamentID != nil {
+		// Задачи event.* хранят событие в event_id, задачи tournament.* — в tournament_id
+		s = s.Where(sq.Expr("COALESCE(data->>'event_id', data->>'tournament_id') = ?", *filter.TournamentID))
+	}
+
+	// execute_at хранится в UTC без часового пояса
+	if filter.ExecuteFrom != nil {
+		s = s.Where(sq.GtOrEq{"execute_at": filter.ExecuteFrom.UTC()})
+	}
+
+	if filter.ExecuteTo != nil {
+		s = s.Where(sq.Lt{"execute_at": filter.ExecuteTo.UTC()})
+	}
+
+	s = s.OrderBy("execute_at DESC")
+
+	if filter.Limit != nil {
+		s = s.Limit(*filter.Limit)
+	}
+
+	if filter.Offset != nil {
+		s = s.Offset(*filter.Offset)
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.Task{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*domain.Task{}
+	for rows.Next() {
+		var task domain.Task
+		var lastError, lockedBy pgtype.Text
+		var lockedUntil pgtype.Timestamp
+
+		err := rows.Scan(
+			&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.Data, &task.RetryCount, &task.MaxRetries,
+			&lastError, &lockedBy, &lockedUntil, &task.CreatedAt, &task.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if lastError.Valid {
+			task.LastError = &lastError.String
+		}
+		if lockedBy.Valid {
+			task.LockedBy = &lockedBy.String
+		}
+		if lockedUntil.Valid {
+			task.LockedUntil = &lockedUntil.Time
+		}
+
+		tasks = append(tasks, &task)
+	}
+
+	return tasks, nil
+}
+
+// Transition изменяет задачу, только если она в одном из статусов from. Аренда воркера сбрасывается,
+// чтобы задачу можно было снова захватить
+func (r *TaskRepo) Transition(ctx context.Context, id string, from []domain.TaskStatus, patch *domain.PatchTask) (bool, error) {
+	s := r.psql.Update("tasks").
+		Set("locked_by", nil).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id, "status": from})
+
+	if patch.Status != nil {
+		s = s.Set("status", *patch.Status)
+	}
+
+	if patch.ExecuteAt != nil {
+		s = s.Set("execute_at", patch.ExecuteAt.UTC())
+	}
+
+	if patch.ResetRetries {
+		s = s.Set("retry_count", 0).Set("last_error", nil)
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,421 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+type TournamentRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewTournamentRepo(db *pgxpool.Pool) *TournamentRepo {
+	return &TournamentRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Save сохраняет турнир вместе с парами и матчами, заменяя существующую сетку события.
+// ID пар и матчей должны быть заполнены заранее, чтобы матчи могли ссылаться друг на друга
+func (r *TournamentRepo) Save(ctx context.Context, tournament *domain.Tournament) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := r.exec(ctx, tx, r.psql.Delete(`"tournaments"`).Where(sq.Eq{"event_id": tournament.EventID})); err != nil {
+		return fmt.Errorf("failed to delete previous tournament: %w", err)
+	}
+
+	insertTournament := r.psql.Insert(`"tournaments"`).
+		Columns("event_id", "format", "courts", "points_per_match", "rounds", "status").
+		Values(tournament.EventID, tournament.Format, tournament.Courts, tournament.PointsPerMatch, tournament.Rounds, tournament.Status)
+	if err := r.exec(ctx, tx, insertTournament); err != nil {
+		return fmt.Errorf("failed to create tournament: %w", err)
+	}
+
+	if len(tournament.Teams) > 0 {
+		insertTeams := r.psql.Insert(`"tournament_teams"`).
+			Columns("id", "event_id", "name", "seed", "player_ids")
+		for _, team := range tournament.Teams {
+			insertTeams = insertTeams.Values(team.ID, tournament.EventID, team.Name, team.Seed, team.PlayerIDs)
+		}
+		if err := r.exec(ctx, tx, insertTeams); err != nil {
+			return fmt.Errorf("failed to create tournament teams: %w", err)
+		}
+	}
+
+	if err := r.insertMatches(ctx, tx, tournament.EventID, tournament.Matches); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// AddMatches добавляет матчи к существующему турниру (следующий раунд mexicano)
+func (r *TournamentRepo) AddMatches(ctx context.Context, eventID string, matches []*domain.TournamentMatch) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := r.insertMatches(ctx, tx, eventID, matches); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TournamentRepo) Get(ctx context.Context, eventID string) (*domain.Tournament, error) {
+	s := r.psql.Select(
+		"event_id", "format", "courts", "points_per_match", "rounds", "status", "created_at", "updated_at",
+	).
+		From(`"tournaments"`).
+		Where(sq.Eq{"event_id": eventID})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var tournament domain.Tournament
+	err = r.db.QueryRow(ctx, sql, args...).Scan(
+		&tournament.EventID, &tournament.Format, &tournament.Courts, &tournament.PointsPerMatch,
+		&tournament.Rounds, &tournament.Status, &tournament.CreatedAt, &tournament.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tournament: %w", err)
+	}
+
+	tournament.Teams, err = r.getTeams(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	tournament.Matches, err = r.FilterMatches(ctx, &domain.FilterTournamentMatch{EventID: &eventID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tournament, nil
+}
+
+// Lock блокирует строку турнира до конца транзакции из контекста, чтобы результаты матчей
+// одного турнира записывались по очереди
+func (r *TournamentRepo) Lock(ctx context.Context, eventID string) error {
+	s := r.psql.Select("event_id").
+		From(`"tournaments"`).
+		Where(sq.Eq{"event_id": eventID}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = conn(ctx, r.db).QueryRow(ctx, sql, args...).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock tournament: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TournamentRepo) FilterMatches(ctx context.Context, filter *domain.FilterTournamentMatch) ([]*domain.TournamentMatch, error) {
+	s := r.psql.Select(
+		"id", "round", "court", "position", "team1_id", "team2_id", "team1_players", "team2_players",
+		"score1", "score2", "status", "next_match_id", "next_match_slot",
+	).
+		From(`"tournament_matches"`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{"id": *filter.ID})
+	}
+
+	if filter.EventID != nil {
+		s = s.Where(sq.Eq{"event_id": *filter.EventID})
+	}
+
+	if filter.Round != nil {
+		s = s.Where(sq.Eq{"round": *filter.Round})
+	}
+
+	s = s.OrderBy("round", "position")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []*domain.TournamentMatch{}
+	for rows.Next() {
+		var match domain.TournamentMatch
+		var team1ID, team2ID, nextMatchID pgtype.Text
+		var score1, score2 pgtype.Int4
+		var nextMatchSlot pgtype.Int2
+
+		err := rows.Scan(
+			&match.ID, &match.Round, &match.Court, &match.Position, &team1ID, &team2ID,
+			&match.Team1Players, &match.Team2Players, &score1, &score2, &match.Status,
+			&nextMatchID, &nextMatchSlot,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if team1ID.Valid {
+			match.Team1ID = &team1ID.String
+		}
+		if team2ID.Valid {
+			match.Team2ID = &team2ID.String
+		}
+		if score1.Valid {
+			value := int(score1.Int32)
+			match.Score1 = &value
+		}
+		if score2.Valid {
+			value := int(score2.Int32)
+			match.Score2 = &value
+		}
+		if nextMatchID.Valid {
+			match.NextMatchID = &nextMatchID.String
+		}
+		if nextMatchSlot.Valid {
+			value := int(nextMatchSlot.Int16)
+			match.NextMatchSlot = &value
+		}
+
+		matches = append(matches, &match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return matches, nil
+}
+
+func (r *TournamentRepo) PatchMatch(ctx context.Context, id string, match *domain.PatchTournamentMatch) error {
+	s := r.psql.Update(`"tournament_matches"`).Where(sq.Eq{"id": id})
+
+	hasUpdates := false
+
+	if match.Team1ID != nil {
+		s = s.Set("team1_id", *match.Team1ID)
+		hasUpdates = true
+	}
+
+	if match.Team2ID != nil {
+		s = s.Set("team2_id", *match.Team2ID)
+		hasUpdates = true
+	}
+
+	if match.Team1Players != nil {
+		s = s.Set("team1_players", *match.Team1Players)
+		hasUpdates = true
+	}
+
+	if match.Team2Players != nil {
+		s = s.Set("team2_players", *match.Team2Players)
+		hasUpdates = true
+	}
+
+	if match.Score1 != nil {
+		s = s.Set("score1", *match.Score1)
+		hasUpdates = true
+	}
+
+	if match.Score2 != nil {
+		s = s.Set("score2", *match.Score2)
+		hasUpdates = true
+	}
+
+	if match.Status != nil {
+		s = s.Set("status", *match.Status)
+		hasUpdates = true
+	}
+
+	if !hasUpdates {
+		return fmt.Errorf("no fields to update")
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update match: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("match with id %s not found", id)
+	}
+
+	return nil
+}
+
+func (r *TournamentRepo) SetStatus(ctx context.Context, eventID string, status domain.TournamentStatus) error {
+	s := r.psql.Update(`"tournaments"`).
+		Set("status", status).
+		Where(sq.Eq{"event_id": eventID})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update tournament: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TournamentRepo) Delete(ctx context.Context, eventID string) error {
+	s := r.psql.Delete(`"tournaments"`).Where(sq.Eq{"event_id": eventID})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete tournament: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TournamentRepo) getTeams(ctx context.Context, eventID string) ([]*domain.TournamentTeam, error) {
+	s := r.psql.Select("id", "name", "seed", "player_ids").
+		From(`"tournament_teams"`).
+		Where(sq.Eq{"event_id": eventID}).
+		OrderBy("seed")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []*domain.TournamentTeam{}
+	for rows.Next() {
+		var team domain.TournamentTeam
+		if err := rows.Scan(&team.ID, &team.Name, &team.Seed, &team.PlayerIDs); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		teams = append(teams, &team)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return teams, nil
+}
+
+// insertMatches вставляет матчи в два шага: сначала без ссылок на следующий матч, затем проставляет ссылки,
+// потому что матчи сетки на выбывание ссылаются на матчи из той же вставки
+func (r *TournamentRepo) insertMatches(ctx context.Context, tx pgx.Tx, eventID string, matches []*domain.TournamentMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	insert := r.psql.Insert(`"tournament_matches"`).
+		Columns(
+			"id", "event_id", "round", "court", "position", "team1_id", "team2_id",
+			"team1_players", "team2_players", "score1", "score2", "status",
+		)
+	for _, match := range matches {
+		insert = insert.Values(
+			match.ID, eventID, match.Round, match.Court, match.Position, match.Team1ID, match.Team2ID,
+			nonNilStrings(match.Team1Players), nonNilStrings(match.Team2Players), match.Score1, match.Score2, match.Status,
+		)
+	}
+	if err := r.exec(ctx, tx, insert); err != nil {
+		return fmt.Errorf("failed to create tournament matches: %w", err)
+	}
+
+	for _, match := range matches {
+		if match.NextMatchID == nil {
+			continue
+		}
+
+		update := r.psql.Update(`"tournament_matches"`).
+			Set("next_match_id", *match.NextMatchID).
+			Set("next_match_slot", match.NextMatchSlot).
+			Where(sq.Eq{"id": match.ID})
+		if err := r.exec(ctx, tx, update); err != nil {
+			return fmt.Errorf("failed to link tournament matches: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *TournamentRepo) exec(ctx context.Context, tx pgx.Tx, s sq.Sqlizer) error {
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
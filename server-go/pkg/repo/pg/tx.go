@@ -0,0 +1,64 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type txKey struct{}
+
+// querier общее у пула и транзакции. Begin внутри транзакции создает savepoint,
+// поэтому методы репозиториев со своей транзакцией можно вызывать внутри TxManager.WithinTx
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// conn транзакция из контекста, если она есть, иначе пул. Через conn выполняются изменяющие
+// запросы репозиториев, которые должны попадать в одну транзакцию с записью в outbox.
+// Чтение идет через пул: вложенные запросы при чтении строк на одном соединении невозможны
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+type TxManager struct {
+	db *pgxpool.Pool
+}
+
+func NewTxManager(db *pgxpool.Pool) *TxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+// WithinTx выполняет fn в транзакции, которая передается репозиториям через контекст.
+// Вложенный вызов выполняется в уже открытой транзакции
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -34,8 +34,8 @@ func (r *UserRepo) Create(ctx context.Context, user *domain.CreateUser) (string,
 	}
 	
 	s := r.psql.Insert(`"users"`).
-		Columns("telegram_id", "telegram_username", "first_name", "last_name", "avatar").
-		Values(user.TelegramID, telegramUsername, user.FirstName, user.LastName, user.Avatar).
+		Columns("telegram_id", "telegram_username", "first_name", "last_name", "avatar", "language_code").
+		Values(user.TelegramID, telegramUsername, user.FirstName, user.LastName, user.Avatar, nullIfEmpty(user.LanguageCode)).
 		Suffix("RETURNING id")
 
 	sql, args, err := s.ToSql()
@@ -52,7 +52,8 @@ func (r *UserRepo) Filter(ctx context.Context, filter *domain.FilterUser) ([]*do
 	s := r.psql.Select(
 		`DISTINCT "u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 		`"u"."bio"`, `"u"."rank"`, `"u"."city"`, `"u"."birth_date"`, `"u"."playing_position"`, `"u"."padel_profiles"`,
-		`"u"."is_registered"`, `"l"."id"`, `"l"."name"`, `"l"."discount"`, `"l"."description"`,
+		`"u"."is_registered"`, `"l"."id"`, `"l"."name"`, `"l"."discount"`, `"l"."description"`, `"u"."loyalty_locked"`,
+		`"u"."rated_matches"`, `"u"."language_code"`,
 	).Join(`"loyalties" AS l ON "u"."loyalty_id" = "l"."id"`).From(`"users" AS u`)
 
 	if filter.ID != nil {
@@ -83,6 +84,11 @@ func (r *UserRepo) Filter(ctx context.Context, filter *domain.FilterUser) ([]*do
 			Where(sq.NotEq{`"u"."id"`: *filter.FilterByUserClubs}) // исключаем самого пользователя
 	}
 
+	if filter.ClubID != nil {
+		s = s.Join(`"clubs_users" AS cu_club ON "u"."id" = "cu_club"."user_id"`).
+			Where(sq.Eq{`"cu_club"."club_id"`: *filter.ClubID})
+	}
+
 	sql, args, err := s.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build SQL: %w", err)
@@ -109,6 +115,7 @@ func (r *UserRepo) Filter(ctx context.Context, filter *domain.FilterUser) ([]*do
 		var loyaltyName pgtype.Text
 		var loyaltyDiscount pgtype.Int4
 		var loyaltyDescription pgtype.Text
+		var languageCode pgtype.Text
 
 		err := rows.Scan(
 			&user.ID,
@@ -128,6 +135,9 @@ func (r *UserRepo) Filter(ctx context.Context, filter *domain.FilterUser) ([]*do
 			&loyaltyName,
 			&loyaltyDiscount,
 			&loyaltyDescription,
+			&user.LoyaltyLocked,
+			&user.RatedMatches,
+			&languageCode,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -160,6 +170,9 @@ func (r *UserRepo) Filter(ctx context.Context, filter *domain.FilterUser) ([]*do
 		if isRegistered.Valid {
 			user.IsRegistered = isRegistered.Bool
 		}
+		if languageCode.Valid {
+			user.LanguageCode = languageCode.String
+		}
 
 		if loyaltyName.Valid {
 			user.Loyalty = &domain.Loyalty{
@@ -220,12 +233,18 @@ func (r *UserRepo) Patch(ctx context.Context, id string, user *domain.PatchUser)
 	if user.LoyaltyID != nil {
 		s = s.Set("loyalty_id", *user.LoyaltyID)
 	}
+	if user.LoyaltyLocked != nil {
+		s = s.Set("loyalty_locked", *user.LoyaltyLocked)
+	}
+	if user.LanguageCode != nil {
+		s = s.Set("language_code", nullIfEmpty(*user.LanguageCode))
+	}
 	sql, args, err := s.ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build SQL: %w", err)
 	}
 
-	result, err := r.db.Exec(ctx, sql, args...)
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -247,3 +266,11 @@ func (r *UserRepo) Delete(ctx context.Context, id string) error {
 	_, err = r.db.Exec(ctx, sql, args...)
 	return err
 }
+
+// nullIfEmpty пустая строка сохраняется как NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -26,8 +26,8 @@ func NewWaitlistRepo(db *pgxpool.Pool) *WaitlistRepo {
 
 func (r *WaitlistRepo) Create(ctx context.Context, waitlist *domain.CreateWaitlist) (int, error) {
 	s := r.psql.Insert(`"waitlists"`).
-		Columns("user_id", "event_id").
-		Values(waitlist.UserID, waitlist.EventID).
+		Columns("user_id", "event_id", "pair_id").
+		Values(waitlist.UserID, waitlist.EventID, waitlist.PairID).
 		Suffix("RETURNING id")
 
 	sql, args, err := s.ToSql()
@@ -42,7 +42,7 @@ func (r *WaitlistRepo) Create(ctx context.Context, waitlist *domain.CreateWaitli
 
 func (r *WaitlistRepo) Filter(ctx context.Context, filter *domain.FilterWaitlist) ([]*domain.Waitlist, error) {
 	s := r.psql.Select(
-		`"w"."id"`, `"w"."user_id"`, `"w"."event_id"`, `"w"."date"`,
+		`"w"."id"`, `"w"."user_id"`, `"w"."event_id"`, `"w"."date"`, `"w"."pair_id"`,
 		`"u"."id"`, `"u"."telegram_id"`, `"u"."telegram_username"`, `"u"."first_name"`, `"u"."last_name"`, `"u"."avatar"`,
 		`"u"."bio"`, `"u"."rank"`, `"u"."city"`, `"u"."birth_date"`, `"u"."playing_position"`, `"u"."padel_profiles"`, `"u"."is_registered"`,
 	).
@@ -61,6 +61,10 @@ func (r *WaitlistRepo) Filter(ctx context.Context, filter *domain.FilterWaitlist
 		s = s.Where(sq.Eq{`"w"."event_id"`: *filter.EventID})
 	}
 
+	if filter.PairID != nil {
+		s = s.Where(sq.Eq{`"w"."pair_id"`: *filter.PairID})
+	}
+
 	s = s.OrderBy(`"w"."date" ASC`)
 
 	sql, args, err := s.ToSql()
@@ -88,12 +92,14 @@ func (r *WaitlistRepo) Filter(ctx context.Context, filter *domain.FilterWaitlist
 		var userPlayingPosition pgtype.Text
 		var userRank pgtype.Float8
 		var userIsRegistered pgtype.Bool
+		var pairID pgtype.Text
 
 		err := rows.Scan(
 			&waitlist.ID,
 			&waitlist.UserID,
 			&waitlist.EventID,
 			&waitlist.Date,
+			&pairID,
 			&user.ID,
 			&user.TelegramID,
 			&userTelegramUsername,
@@ -112,6 +118,10 @@ func (r *WaitlistRepo) Filter(ctx context.Context, filter *domain.FilterWaitlist
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
+		if pairID.Valid {
+			waitlist.PairID = &pairID.String
+		}
+
 		// Fill nullable user fields
 		if userTelegramUsername.Valid {
 			user.TelegramUsername = userTelegramUsername.String
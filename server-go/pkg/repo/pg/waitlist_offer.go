@@ -0,0 +1,126 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type WaitlistOfferRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewWaitlistOfferRepo(db *pgxpool.Pool) *WaitlistOfferRepo {
+	return &WaitlistOfferRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *WaitlistOfferRepo) Create(ctx context.Context, offer *domain.CreateWaitlistOffer) (string, error) {
+	s := r.psql.Insert(`"waitlist_offers"`).
+		Columns("event_id", "user_id", "status", "expires_at").
+		Values(offer.EventID, offer.UserID, domain.WaitlistOfferStatusPending, offer.ExpiresAt.UTC()).
+		Suffix("RETURNING id")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = conn(ctx, r.db).QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create waitlist offer: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *WaitlistOfferRepo) Filter(ctx context.Context, filter *domain.FilterWaitlistOffer) ([]*domain.WaitlistOffer, error) {
+	s := r.psql.Select(
+		`"id"`, `"event_id"`, `"user_id"`, `"status"`, `"expires_at"`, `"created_at"`, `"updated_at"`,
+	).From(`"waitlist_offers"`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{`"id"`: *filter.ID})
+	}
+
+	if filter.EventID != nil {
+		s = s.Where(sq.Eq{`"event_id"`: *filter.EventID})
+	}
+
+	if filter.UserID != nil {
+		s = s.Where(sq.Eq{`"user_id"`: *filter.UserID})
+	}
+
+	if len(filter.Statuses) > 0 {
+		s = s.Where(sq.Eq{`"status"`: filter.Statuses})
+	}
+
+	if filter.ExpiresBefore != nil {
+		s = s.Where(sq.LtOrEq{`"expires_at"`: filter.ExpiresBefore.UTC()})
+	}
+
+	s = s.OrderBy(`"created_at" ASC`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.WaitlistOffer{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	offers := []*domain.WaitlistOffer{}
+	for rows.Next() {
+		var offer domain.WaitlistOffer
+		err := rows.Scan(
+			&offer.ID, &offer.EventID, &offer.UserID, &offer.Status,
+			&offer.ExpiresAt, &offer.CreatedAt, &offer.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		offers = append(offers, &offer)
+	}
+
+	return offers, nil
+}
+
+// TransitionStatus атомарно меняет статус предложения, только если текущий статус входит в from.
+// Возвращает false, если предложение уже закрыл другой запрос (ответ пользователя или истечение)
+func (r *WaitlistOfferRepo) TransitionStatus(ctx context.Context, id string, from []domain.WaitlistOfferStatus, to domain.WaitlistOfferStatus) (bool, error) {
+	if len(from) == 0 {
+		return false, nil
+	}
+
+	s := r.psql.Update(`"waitlist_offers"`).
+		Set("status", to).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": from})
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := conn(ctx, r.db).Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update waitlist offer: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
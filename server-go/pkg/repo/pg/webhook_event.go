@@ -0,0 +1,156 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+type WebhookEventRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewWebhookEventRepo(db *pgxpool.Pool) *WebhookEventRepo {
+	return &WebhookEventRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create сохраняет уведомление в журнал. Повторная доставка того же (event, object_id)
+// не создает новую запись, а увеличивает счетчик попыток и возвращает id существующей
+func (r *WebhookEventRepo) Create(ctx context.Context, event *domain.CreateWebhookEvent) (string, error) {
+	var payload []byte
+	if len(event.Payload) > 0 {
+		payload = event.Payload
+	}
+
+	s := r.psql.Insert(`"webhook_events"`).
+		Columns("event", "object_id", "status", "payload").
+		Values(event.Event, event.ObjectID, event.Status, payload).
+		Suffix(`ON CONFLICT (event, object_id) DO UPDATE SET attempts = "webhook_events"."attempts" + 1, status = EXCLUDED.status RETURNING id`)
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	var id string
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to create webhook event: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *WebhookEventRepo) Filter(ctx context.Context, filter *domain.FilterWebhookEvent) ([]*domain.WebhookEvent, error) {
+	s := r.psql.Select(
+		"id", "event", "object_id", "status", "payload", "attempts", "last_error", "processed_at", "created_at", "updated_at",
+	).From(`"webhook_events"`)
+
+	if filter.ID != nil {
+		s = s.Where(sq.Eq{"id": *filter.ID})
+	}
+
+	if filter.Event != nil {
+		s = s.Where(sq.Eq{"event": *filter.Event})
+	}
+
+	if filter.ObjectID != nil {
+		s = s.Where(sq.Eq{"object_id": *filter.ObjectID})
+	}
+
+	s = s.OrderBy("created_at DESC")
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []*domain.WebhookEvent{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*domain.WebhookEvent{}
+	for rows.Next() {
+		var event domain.WebhookEvent
+		var payload []byte
+		var lastError pgtype.Text
+		var processedAt pgtype.Timestamp
+
+		err := rows.Scan(
+			&event.ID, &event.Event, &event.ObjectID, &event.Status, &payload, &event.Attempts,
+			&lastError, &processedAt, &event.CreatedAt, &event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if len(payload) > 0 {
+			event.Payload = payload
+		}
+		if lastError.Valid {
+			event.LastError = &lastError.String
+		}
+		if processedAt.Valid {
+			event.ProcessedAt = &processedAt.Time
+		}
+
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *WebhookEventRepo) Patch(ctx context.Context, id string, event *domain.PatchWebhookEvent) error {
+	s := r.psql.Update(`"webhook_events"`).Where(sq.Eq{"id": id})
+
+	hasUpdates := false
+
+	if event.LastError != nil {
+		s = s.Set("last_error", *event.LastError)
+		hasUpdates = true
+	}
+
+	if event.ProcessedAt != nil {
+		s = s.Set("processed_at", *event.ProcessedAt)
+		hasUpdates = true
+	}
+
+	if !hasUpdates {
+		return fmt.Errorf("no fields to update")
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook event: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook event with id %s not found", id)
+	}
+
+	return nil
+}
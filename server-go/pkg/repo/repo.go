@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"io"
+	"time"
 
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
 )
 
 var (
 	ErrNotFound = errors.New("not found")
+	// ErrEventFull на событии не хватает свободных мест
+	ErrEventFull = errors.New("event is full")
 )
 
 type User interface {
@@ -49,6 +52,7 @@ type Loyalty interface {
 	Patch(ctx context.Context, id int, loyalty *domain.PatchLoyalty) error
 	Filter(ctx context.Context, filter *domain.FilterLoyalty) ([]*domain.Loyalty, error)
 	Delete(ctx context.Context, id int) error
+	GetActivity(ctx context.Context, filter *domain.FilterLoyaltyActivity) (*domain.LoyaltyActivity, error)
 }
 
 type Event interface {
@@ -60,6 +64,14 @@ type Event interface {
 	AdminFilter(ctx context.Context, filter *domain.AdminFilterEvent) ([]*domain.Event, error)
 	AdminPatch(ctx context.Context, id string, event *domain.AdminPatchEvent) error
 	AdminDelete(ctx context.Context, id string) error
+	// Lock блокирует строку события до конца транзакции, как и OccupySeats
+	Lock(ctx context.Context, id string) error
+}
+
+type EventSeries interface {
+	Create(ctx context.Context, series *domain.CreateEventSeries) (string, error)
+	Patch(ctx context.Context, id string, patch *domain.PatchEventSeries) error
+	Filter(ctx context.Context, filter *domain.FilterEventSeries) ([]*domain.EventSeries, error)
 }
 
 type Registration interface {
@@ -68,6 +80,9 @@ type Registration interface {
 	Filter(ctx context.Context, filter *domain.FilterRegistration) ([]*domain.Registration, error)
 	AdminFilter(ctx context.Context, filter *domain.AdminFilterRegistration) ([]*domain.RegistrationWithPayments, error)
 	Delete(ctx context.Context, userID, eventID string) error
+	TransitionStatus(ctx context.Context, userID, eventID string, from []domain.RegistrationStatus, to domain.RegistrationStatus) (bool, error)
+	// OccupySeats атомарно создает или обновляет регистрации на событие, если для них хватает мест
+	OccupySeats(ctx context.Context, eventID string, registrations []*domain.CreateRegistration) error
 }
 
 type Payment interface {
@@ -75,6 +90,81 @@ type Payment interface {
 	Patch(ctx context.Context, id string, payment *domain.PatchPayment) error
 	Filter(ctx context.Context, filter *domain.FilterPayment) ([]*domain.Payment, error)
 	Delete(ctx context.Context, id string) error
+	TransitionStatus(ctx context.Context, id string, from []domain.PaymentStatus, to domain.PaymentStatus) (bool, error)
+}
+
+type Refund interface {
+	Create(ctx context.Context, refund *domain.CreateRefund) (string, error)
+	Patch(ctx context.Context, id string, refund *domain.PatchRefund) error
+	Filter(ctx context.Context, filter *domain.FilterRefund) ([]*domain.Refund, error)
+	TransitionStatus(ctx context.Context, id string, from []domain.RefundStatus, to domain.RefundStatus) (bool, error)
+	SetProviderRefund(ctx context.Context, id, refundID string, status domain.RefundStatus) (bool, error)
+}
+
+type PromoCode interface {
+	Create(ctx context.Context, promoCode *domain.CreatePromoCode) (string, error)
+	Patch(ctx context.Context, id string, promoCode *domain.PatchPromoCode) error
+	Filter(ctx context.Context, filter *domain.FilterPromoCode) ([]*domain.PromoCode, error)
+	Delete(ctx context.Context, id string) error
+	CountUses(ctx context.Context, id string, userID *string) (int, error)
+	LockForUse(ctx context.Context, id string) error
+}
+
+type WebhookEvent interface {
+	Create(ctx context.Context, event *domain.CreateWebhookEvent) (string, error)
+	Patch(ctx context.Context, id string, event *domain.PatchWebhookEvent) error
+	Filter(ctx context.Context, filter *domain.FilterWebhookEvent) ([]*domain.WebhookEvent, error)
+}
+
+type Task interface {
+	Filter(ctx context.Context, filter *domain.FilterTask) ([]*domain.Task, error)
+	// Transition изменяет задачу, только если она в одном из статусов from
+	Transition(ctx context.Context, id string, from []domain.TaskStatus, patch *domain.PatchTask) (bool, error)
+}
+
+type Broadcast interface {
+	// Create сохраняет рассылку вместе со списком получателей
+	Create(ctx context.Context, broadcast *domain.CreateBroadcast, recipients []*domain.CreateBroadcastRecipient) (string, error)
+	Filter(ctx context.Context, filter *domain.FilterBroadcast) ([]*domain.Broadcast, error)
+	FilterRecipients(ctx context.Context, filter *domain.FilterBroadcastRecipient) ([]*domain.BroadcastRecipient, error)
+	TransitionStatus(ctx context.Context, id string, from []domain.BroadcastStatus, to domain.BroadcastStatus) (bool, error)
+}
+
+type MessageTemplate interface {
+	Upsert(ctx context.Context, key, language string, template *domain.UpsertMessageTemplate) error
+	Filter(ctx context.Context, filter *domain.FilterMessageTemplate) ([]*domain.MessageTemplate, error)
+	Delete(ctx context.Context, key, language string) error
+}
+
+// NotificationSettings настройки уведомлений. Для пользователей без сохраненных настроек записей нет
+type NotificationSettings interface {
+	GetByUserIDs(ctx context.Context, userIDs []string) (map[string]*domain.NotificationSettings, error)
+	Upsert(ctx context.Context, userID string, settings *domain.NotificationSettings) error
+}
+
+type Tournament interface {
+	Save(ctx context.Context, tournament *domain.Tournament) error
+	AddMatches(ctx context.Context, eventID string, matches []*domain.TournamentMatch) error
+	Get(ctx context.Context, eventID string) (*domain.Tournament, error)
+	Lock(ctx context.Context, eventID string) error
+	FilterMatches(ctx context.Context, filter *domain.FilterTournamentMatch) ([]*domain.TournamentMatch, error)
+	PatchMatch(ctx context.Context, id string, match *domain.PatchTournamentMatch) error
+	SetStatus(ctx context.Context, eventID string, status domain.TournamentStatus) error
+	Delete(ctx context.Context, eventID string) error
+}
+
+type Rating interface {
+	Create(ctx context.Context, change *domain.CreateRatingChange) error
+	ReplaceMatchChanges(ctx context.Context, matchID string, changes []*domain.CreateRatingChange) error
+	DeleteMatchChanges(ctx context.Context, matchID string) error
+	LockPlayers(ctx context.Context, userIDs []string) error
+	Filter(ctx context.Context, filter *domain.FilterRatingChange) ([]*domain.RatingChange, error)
+}
+
+type RegistrationPair interface {
+	Create(ctx context.Context, pair *domain.CreateRegistrationPair) (string, error)
+	Filter(ctx context.Context, filter *domain.FilterRegistrationPair) ([]*domain.RegistrationPair, error)
+	TransitionStatus(ctx context.Context, id string, from []domain.RegistrationPairStatus, to domain.RegistrationPairStatus) (bool, error)
 }
 
 type Waitlist interface {
@@ -83,6 +173,28 @@ type Waitlist interface {
 	Delete(ctx context.Context, id int) error
 }
 
+type WaitlistOffer interface {
+	Create(ctx context.Context, offer *domain.CreateWaitlistOffer) (string, error)
+	Filter(ctx context.Context, filter *domain.FilterWaitlistOffer) ([]*domain.WaitlistOffer, error)
+	TransitionStatus(ctx context.Context, id string, from []domain.WaitlistOfferStatus, to domain.WaitlistOfferStatus) (bool, error)
+}
+
+// Outbox задачи воркера, которые публикуются в NATS после коммита транзакции, в которой записаны
+type Outbox interface {
+	Create(ctx context.Context, message *domain.CreateOutboxMessage) (string, error)
+	// Claim забирает неотправленные задачи на время lease, чтобы их не опубликовала другая реплика
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, lastError string, nextAttemptAt time.Time) error
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Transactor выполняет fn в транзакции. Изменения репозиториев, вызванных с переданным контекстом,
+// фиксируются или откатываются вместе
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type ImageStorage interface {
 	SaveImageByURL(ctx context.Context, url, key string) (string, error)
 	SaveImageByBytes(ctx context.Context, bytes []byte, key string) (string, error)
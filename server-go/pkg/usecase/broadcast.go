@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+var (
+	ErrBroadcastNotFound      = errors.New("broadcast not found")
+	ErrBroadcastInvalidStatus = errors.New("broadcast status does not allow this action")
+	ErrInvalidBroadcast       = errors.New("invalid broadcast")
+)
+
+// cancellableBroadcastStatuses статусы, в которых рассылку еще можно отменить
+var cancellableBroadcastStatuses = []domain.BroadcastStatus{domain.BroadcastStatusScheduled, domain.BroadcastStatusSending}
+
+// Broadcast рассылки админов. Получатели определяются при создании и сохраняются в БД,
+// отправляет воркер по задаче broadcast.send с ограничением скорости
+type Broadcast struct {
+	broadcastRepo       repo.Broadcast
+	tx                  repo.Transactor
+	notificationService *notifications.NotificationService
+	cases               *Cases
+}
+
+func NewBroadcast(ctx context.Context, broadcastRepo repo.Broadcast, tx repo.Transactor, notificationService *notifications.NotificationService, cases *Cases) *Broadcast {
+	return &Broadcast{
+		broadcastRepo:       broadcastRepo,
+		tx:                  tx,
+		notificationService: notificationService,
+		cases:               cases,
+	}
+}
+
+func (b *Broadcast) Create(ctx Context, create *domain.CreateBroadcast) (*domain.Broadcast, error) {
+	if b.notificationService == nil {
+		return nil, fmt.Errorf("notification service is not configured")
+	}
+	if err := validateBroadcastMessage(&create.Message); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if create.ScheduledAt == nil || create.ScheduledAt.Before(now) {
+		create.ScheduledAt = &now
+	}
+	if ctx.User != nil {
+		create.CreatedBy = &ctx.User.ID
+	}
+
+	recipients, err := b.resolveRecipients(ctx, &create.Target)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: target has no recipients", ErrInvalidBroadcast)
+	}
+
+	// Без задачи воркер рассылку не отправит, поэтому рассылка и задача сохраняются вместе
+	var id string
+	err = b.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		id, err = b.broadcastRepo.Create(txCtx, create, recipients)
+		if err != nil {
+			return fmt.Errorf("failed to create broadcast: %w", err)
+		}
+		if err := b.notificationService.SendBroadcast(txCtx, id, *create.ScheduledAt); err != nil {
+			return fmt.Errorf("failed to schedule broadcast: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log := slog.With(
+		slog.String("broadcast_id", id),
+		slog.String("target", string(create.Target.Type)),
+		slog.Int("recipients", len(recipients)),
+		slog.Time("scheduled_at", *create.ScheduledAt))
+	if ctx.User != nil {
+		log = log.With(slog.String("admin_user_id", ctx.User.ID))
+	}
+	log.Info("Broadcast created")
+	return b.Get(ctx, id)
+}
+
+func (b *Broadcast) Get(ctx context.Context, id string) (*domain.Broadcast, error) {
+	broadcast, err := repo.First(b.broadcastRepo.Filter)(ctx, &domain.FilterBroadcast{ID: &id})
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrBroadcastNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast: %w", err)
+	}
+	return broadcast, nil
+}
+
+func (b *Broadcast) Filter(ctx context.Context, filter *domain.FilterBroadcast) ([]*domain.Broadcast, error) {
+	return b.broadcastRepo.Filter(ctx, filter)
+}
+
+// Recipients возвращает статусы доставки по получателям рассылки
+func (b *Broadcast) Recipients(ctx context.Context, filter *domain.FilterBroadcastRecipient) ([]*domain.BroadcastRecipient, error) {
+	if _, err := b.Get(ctx, filter.BroadcastID); err != nil {
+		return nil, err
+	}
+	return b.broadcastRepo.FilterRecipients(ctx, filter)
+}
+
+// Cancel отменяет запланированную рассылку или останавливает отправку. Задачу воркера не трогаем:
+// воркер пропустит отмененную рассылку, а при отправке остановится после текущей пачки получателей
+func (b *Broadcast) Cancel(ctx Context, id string) (*domain.Broadcast, error) {
+	broadcast, err := b.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := b.broadcastRepo.TransitionStatus(ctx, id, cancellableBroadcastStatuses, domain.BroadcastStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot cancel broadcast in status %s", ErrBroadcastInvalidStatus, broadcast.Status)
+	}
+
+	log := slog.With(slog.String("broadcast_id", id))
+	if ctx.User != nil {
+		log = log.With(slog.String("admin_user_id", ctx.User.ID))
+	}
+	log.Info("Broadcast cancelled")
+
+	return b.Get(ctx, id)
+}
+
+// resolveRecipients определяет получателей по аудитории. Пользователи без Telegram ID и отключившие
+// рассылки пропускаются, повторы убираются
+func (b *Broadcast) resolveRecipients(ctx context.Context, target *domain.BroadcastTarget) ([]*domain.CreateBroadcastRecipient, error) {
+	var users []*domain.User
+
+	switch target.Type {
+	case domain.BroadcastTargetEvent:
+		if target.EventID == nil {
+			return nil, fmt.Errorf("%w: eventId is required for event target", ErrInvalidBroadcast)
+		}
+		registrations, err := b.cases.Registration.GetEventRegistrations(ctx, *target.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event registrations: %w", err)
+		}
+		for _, registration := range registrations {
+			if registration.Status == domain.RegistrationStatusConfirmed && registration.User != nil {
+				users = append(users, registration.User)
+			}
+		}
+
+	case domain.BroadcastTargetWaitlist:
+		if target.EventID == nil {
+			return nil, fmt.Errorf("%w: eventId is required for waitlist target", ErrInvalidBroadcast)
+		}
+		waitlistUsers, err := b.cases.Waitlist.GetEventWaitlistUsers(ctx, *target.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event waitlist: %w", err)
+		}
+		for _, waitlistUser := range waitlistUsers {
+			if waitlistUser.User != nil {
+				users = append(users, waitlistUser.User)
+			}
+		}
+
+	case domain.BroadcastTargetClub:
+		if target.ClubID == nil {
+			return nil, fmt.Errorf("%w: clubId is required for club target", ErrInvalidBroadcast)
+		}
+		clubUsers, err := b.cases.User.AdminFilter(ctx, &domain.FilterUser{ClubID: target.ClubID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get club members: %w", err)
+		}
+		users = clubUsers
+
+	case domain.BroadcastTargetUsers:
+		if target.UserFilter == nil {
+			return nil, fmt.Errorf("%w: userFilter is required for users target", ErrInvalidBroadcast)
+		}
+		filtered, err := b.cases.User.AdminFilter(ctx, target.UserFilter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter users: %w", err)
+		}
+		users = filtered
+
+	default:
+		return nil, fmt.Errorf("%w: unknown target type %q", ErrInvalidBroadcast, target.Type)
+	}
+
+	users, err := b.cases.NotificationSettings.FilterAllowed(ctx, users, domain.NotificationCategoryBroadcasts)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]struct{}, len(users))
+	recipients := make([]*domain.CreateBroadcastRecipient, 0, len(users))
+	for _, user := range users {
+		if user.TelegramID == 0 {
+			continue
+		}
+		if _, ok := seen[user.TelegramID]; ok {
+			continue
+		}
+		seen[user.TelegramID] = struct{}{}
+		recipients = append(recipients, &domain.CreateBroadcastRecipient{
+			UserID:     user.ID,
+			TelegramID: user.TelegramID,
+		})
+	}
+
+	return recipients, nil
+}
+
+// validateBroadcastMessage проверяет, что заполнено содержимое, соответствующее типу сообщения
+func validateBroadcastMessage(message *domain.BroadcastMessage) error {
+	switch message.Type {
+	case domain.BroadcastMessageTypeText:
+		if message.Text == nil || strings.TrimSpace(message.Text.Text) == "" {
+			return fmt.Errorf("%w: text is required for text message", ErrInvalidBroadcast)
+		}
+		message.Photo, message.MediaGroup = nil, nil
+	case domain.BroadcastMessageTypePhoto:
+		if message.Photo == nil || message.Photo.URL == "" {
+			return fmt.Errorf("%w: photo url is required for photo message", ErrInvalidBroadcast)
+		}
+		message.Text, message.MediaGroup = nil, nil
+	case domain.BroadcastMessageTypeMediaGroup:
+		// Telegram принимает в альбоме от 2 до 10 элементов
+		if message.MediaGroup == nil || len(message.MediaGroup.URLS) < 2 || len(message.MediaGroup.URLS) > 10 {
+			return fmt.Errorf("%w: media group must contain from 2 to 10 urls", ErrInvalidBroadcast)
+		}
+		message.Text, message.Photo = nil, nil
+	default:
+		return fmt.Errorf("%w: unknown message type %q", ErrInvalidBroadcast, message.Type)
+	}
+	return nil
+}
@@ -2,11 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
 	"github.com/shampsdev/go-telegram-template/pkg/repo"
 )
 
+var ErrInvalidCourt = errors.New("invalid court")
+
 type Court struct {
 	ctx       context.Context
 	courtRepo repo.Court
@@ -20,10 +25,21 @@ func NewCourt(ctx context.Context, courtRepo repo.Court) *Court {
 }
 
 func (c *Court) Create(ctx Context, court *domain.CreateCourt) (string, error) {
+	if court.Timezone == "" {
+		court.Timezone = domain.DefaultCourtTimezone
+	}
+	if err := validateCourtTimezone(court.Timezone); err != nil {
+		return "", err
+	}
 	return c.courtRepo.Create(ctx.Context, court)
 }
 
 func (c *Court) Update(ctx Context, id string, court *domain.PatchCourt) error {
+	if court.Timezone != nil {
+		if err := validateCourtTimezone(*court.Timezone); err != nil {
+			return err
+		}
+	}
 	return c.courtRepo.Patch(ctx.Context, id, court)
 }
 
@@ -47,4 +63,11 @@ func (c *Court) GetByID(ctx Context, id string) (*domain.Court, error) {
 
 func (c *Court) Delete(ctx Context, id string) error {
 	return c.courtRepo.Delete(ctx.Context, id)
-} 
\ No newline at end of file
+} 
+
+func validateCourtTimezone(timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidCourt, timezone)
+	}
+	return nil
+}
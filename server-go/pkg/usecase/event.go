@@ -2,12 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
 
 	"github.com/go-telegram/bot"
-	"github.com/go-telegram/bot/models"
 	"github.com/shampsdev/go-telegram-template/pkg/config"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
 	"github.com/shampsdev/go-telegram-template/pkg/repo"
@@ -127,6 +127,14 @@ func (e *Event) Patch(ctx context.Context, id string, patch *domain.PatchEvent)
 		}
 	}
 
+	// Регистрации, ожидающие оплаты, получают новый срок: он зависит от срока оплаты события
+	// и не может быть позже его начала
+	if (patch.PaymentDeadlineHours != nil || patch.StartTime != nil) && e.cases.Registration != nil {
+		if err := e.cases.Registration.reschedulePaymentDeadlines(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
 	err = e.cases.Event.TryRegisterFromWaitlist(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to try register from waitlist: %w", err)
@@ -147,6 +155,9 @@ func (e *Event) Patch(ctx context.Context, id string, patch *domain.PatchEvent)
 
 // Удаляет событие
 func (e *Event) Delete(ctx context.Context, id string) error {
+	if err := e.deleteTournament(ctx, id); err != nil {
+		return err
+	}
 	return e.eventRepo.Delete(ctx, id)
 }
 
@@ -306,6 +317,14 @@ func (e *Event) AdminPatch(ctx *Context, id string, patch *domain.AdminPatchEven
 		}
 	}
 
+	// Регистрации, ожидающие оплаты, получают новый срок: он зависит от срока оплаты события
+	// и не может быть позже его начала
+	if (patch.PaymentDeadlineHours != nil || patch.StartTime != nil) && e.cases.Registration != nil {
+		if err := e.cases.Registration.reschedulePaymentDeadlines(ctx.Context, id); err != nil {
+			return nil, err
+		}
+	}
+
 	err = e.cases.Event.TryRegisterFromWaitlist(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to try register from waitlist: %w", err)
@@ -326,9 +345,22 @@ func (e *Event) AdminPatch(ctx *Context, id string, patch *domain.AdminPatchEven
 
 // Удаляет событие для админов
 func (e *Event) AdminDelete(ctx *Context, id string) error {
+	if err := e.deleteTournament(ctx.Context, id); err != nil {
+		return err
+	}
 	return e.eventRepo.AdminDelete(ctx.Context, id)
 }
 
+// deleteTournament удаляет турнир события до удаления самого события, чтобы откатить изменения ранга по его матчам:
+// история ранга не дает удалить сыгранные матчи каскадом
+func (e *Event) deleteTournament(ctx context.Context, eventID string) error {
+	err := e.cases.Tournament.delete(ctx, eventID)
+	if err != nil && !errors.Is(err, ErrTournamentNotFound) {
+		return fmt.Errorf("failed to delete tournament: %w", err)
+	}
+	return nil
+}
+
 // Получает стратегию для типа события
 func (e *Event) GetRegistrationStrategy(eventType domain.EventType) EventStrategy {
 	return GetEventStrategy(eventType)
@@ -417,7 +449,20 @@ func (e *Event) TryRegisterFromWaitlist(ctx context.Context, eventID string) err
 		return a.Date.Compare(b.Date)
 	})
 
+	// Просроченные предложения освобождают удерживаемые места до подсчета свободных
+	if err := e.cases.WaitlistOffer.ExpireOverdue(ctx, eventID); err != nil {
+		slog.Error("Failed to expire overdue waitlist offers",
+			"event_id", eventID,
+			"error", err)
+	}
+
+	freeSeats, err := e.freeSeats(ctx, event)
+	if err != nil {
+		return err
+	}
+
 	registeredCount := 0
+	offeredCount := 0
 	for i, waitlistUser := range waitlist {
 		slog.Info("Attempting to register user from waitlist",
 			"event_id", eventID,
@@ -428,59 +473,79 @@ func (e *Event) TryRegisterFromWaitlist(ctx context.Context, eventID string) err
 			"waitlist_position", i+1,
 			"waitlist_date", waitlistUser.Date)
 
-		_, err := e.cases.Registration.RegisterForEvent(ctx, waitlistUser.User, eventID)
-		if err != nil {
-			slog.Warn("Failed to register user from waitlist",
-				"event_id", eventID,
-				"user_id", waitlistUser.User.ID,
-				"user_telegram_id", waitlistUser.User.TelegramID,
-				"waitlist_position", i+1,
-				"error", err)
+		// Пара продвигается целиком и только при наличии двух свободных мест
+		if waitlistUser.PairID != nil {
+			err := e.cases.RegistrationPair.RegisterFromWaitlist(ctx, event, *waitlistUser.PairID)
+			if err != nil {
+				slog.Warn("Failed to register pair from waitlist",
+					"event_id", eventID,
+					"pair_id", *waitlistUser.PairID,
+					"waitlist_position", i+1,
+					"error", err)
+				continue
+			}
+
+			if err := e.cases.Waitlist.Delete(ctx, waitlistUser.ID); err != nil {
+				slog.Error("Failed to delete pair from waitlist after successful registration",
+					"event_id", eventID,
+					"pair_id", *waitlistUser.PairID,
+					"waitlist_id", waitlistUser.ID,
+					"error", err)
+				return fmt.Errorf("failed to delete from waitlist")
+			}
+
+			registeredCount += 2
+			freeSeats -= 2
 			continue
 		}
 
-		slog.Info("Successfully registered user from waitlist",
-			"event_id", eventID,
-			"user_id", waitlistUser.User.ID,
-			"user_telegram_id", waitlistUser.User.TelegramID,
-			"waitlist_position", i+1)
-
-		err = e.cases.Waitlist.Delete(ctx, waitlistUser.ID)
-		if err != nil {
-			slog.Error("Failed to delete user from waitlist after successful registration",
-				"event_id", eventID,
-				"user_id", waitlistUser.User.ID,
-				"waitlist_id", waitlistUser.ID,
-				"error", err)
-			return fmt.Errorf("failed to delete from waitlist")
+		// Одиночному игроку место не отдается сразу, а предлагается на время окна ответа
+		if freeSeats <= 0 {
+			continue
 		}
-
-		registeredCount++
-
-		_, err = e.bot.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: waitlistUser.User.TelegramID,
-			Text: fmt.Sprintf(`Вы были успешно перемещены из листа ожидания и зарегистрированы на событие "%s"!
-Перейдите на <a href="https://t.me/%s/app?startapp=%s">страницу события</a> и проверьте, требуется ли оплата.`,
-				event.Name, e.cfg.TG.BotUsername, event.ID),
-			ParseMode: models.ParseModeHTML,
-		})
+		offered, err := e.cases.WaitlistOffer.Offer(ctx, event, waitlistUser)
 		if err != nil {
-			slog.Warn("Failed to send notification to user registered from waitlist",
+			slog.Warn("Failed to offer seat to user from waitlist",
 				"event_id", eventID,
 				"user_id", waitlistUser.User.ID,
 				"user_telegram_id", waitlistUser.User.TelegramID,
+				"waitlist_position", i+1,
 				"error", err)
+			continue
+		}
+		if offered {
+			freeSeats--
+			offeredCount++
 		}
 	}
 
 	slog.Info("Completed waitlist registration process",
 		"event_id", eventID,
 		"total_in_waitlist", len(waitlist),
-		"successfully_registered", registeredCount)
+		"successfully_registered", registeredCount,
+		"offered", offeredCount)
 
 	return nil
 }
 
+// Lock блокирует строку события до конца транзакции. Под этой же блокировкой OccupySeats занимает места
+func (e *Event) Lock(ctx context.Context, eventID string) error {
+	return e.eventRepo.Lock(ctx, eventID)
+}
+
+// freeSeats свободные места события с учетом мест, удерживаемых предложениями из листа ожидания
+func (e *Event) freeSeats(ctx context.Context, event *domain.Event) (int, error) {
+	activeCount, err := e.cases.Registration.GetActiveRegistrationsCount(ctx, event.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active registrations count: %w", err)
+	}
+	held, err := e.cases.WaitlistOffer.HeldSeats(ctx, event.ID)
+	if err != nil {
+		return 0, err
+	}
+	return event.MaxUsers - activeCount - held, nil
+}
+
 // updateEventStatusAfterCapacityChange обновляет статус события после изменения его ёмкости (MaxUsers)
 func (e *Event) updateEventStatusAfterCapacityChange(ctx context.Context, eventID string) error {
 	// Получаем событие
@@ -0,0 +1,485 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+)
+
+var (
+	ErrEventSeriesNotFound = errors.New("event series not found")
+	ErrInvalidEventSeries  = errors.New("invalid event series")
+)
+
+// upcomingEventStatuses статусы занятий, которые еще можно изменить или отменить
+var upcomingEventStatuses = []domain.EventStatus{domain.EventStatusRegistration, domain.EventStatusFull}
+
+// EventSeries повторяющиеся события: занятия создаются по шаблону и правилу повторения
+// на горизонт Series.Horizon вперед и дальше живут как обычные события
+type EventSeries struct {
+	seriesRepo          repo.EventSeries
+	notificationService *notifications.NotificationService
+	bot                 *bot.Bot
+	config              *config.Config
+	cases               *Cases
+}
+
+func NewEventSeries(ctx context.Context, seriesRepo repo.EventSeries, notificationService *notifications.NotificationService, cfg *config.Config, b *bot.Bot, cases *Cases) *EventSeries {
+	return &EventSeries{
+		seriesRepo:          seriesRepo,
+		notificationService: notificationService,
+		bot:                 b,
+		config:              cfg,
+		cases:               cases,
+	}
+}
+
+// Create создает серию и сразу создает занятия на горизонт вперед
+func (s *EventSeries) Create(ctx Context, create *domain.CreateEventSeries) (*domain.EventSeries, error) {
+	template := &create.Template
+	if !template.EndTime.After(template.StartTime) {
+		return nil, fmt.Errorf("%w: endTime must be after startTime", ErrInvalidEventSeries)
+	}
+	if err := ValidateRecurrenceRule(&create.Rule, template.StartTime); err != nil {
+		return nil, err
+	}
+
+	id, err := s.seriesRepo.Create(ctx.Context, create)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.Get(ctx.Context, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.materialize(ctx.Context, series); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Event series created",
+		"series_id", series.ID,
+		"frequency", series.Rule.Frequency,
+		"first_start", template.StartTime)
+
+	return s.Get(ctx.Context, id)
+}
+
+func (s *EventSeries) Get(ctx context.Context, id string) (*domain.EventSeries, error) {
+	series, err := repo.First(s.seriesRepo.Filter)(ctx, &domain.FilterEventSeries{ID: &id})
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrEventSeriesNotFound
+	}
+	return series, err
+}
+
+func (s *EventSeries) Filter(ctx context.Context, filter *domain.FilterEventSeries) ([]*domain.EventSeries, error) {
+	return s.seriesRepo.Filter(ctx, filter)
+}
+
+// UpdateFuture изменяет шаблон серии и все ее предстоящие занятия, начинающиеся не раньше update.From.
+// Отдельное занятие изменяется как обычное событие
+func (s *EventSeries) UpdateFuture(ctx Context, id string, update *domain.UpdateEventSeries) (*domain.EventSeries, error) {
+	series, err := s.Get(ctx.Context, id)
+	if err != nil {
+		return nil, err
+	}
+	if series.Status == domain.EventSeriesStatusCancelled {
+		return nil, fmt.Errorf("%w: series is cancelled", ErrInvalidEventSeries)
+	}
+
+	from := time.Now()
+	if update.From != nil {
+		from = *update.From
+	}
+
+	template := series.Template
+	applySeriesUpdate(&template, update)
+
+	// Время суток меняется у первого занятия шаблона, от него отсчитываются все следующие
+	start := template.StartTime
+	if update.StartTime != nil {
+		start = withTimeOfDay(template.StartTime, *update.StartTime)
+	}
+	duration := template.EndTime.Sub(template.StartTime)
+	if update.EndTime != nil {
+		end := withTimeOfDay(start, *update.EndTime)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		duration = end.Sub(start)
+	}
+	shift := start.Sub(template.StartTime)
+	template.StartTime = start
+	template.EndTime = start.Add(duration)
+
+	patch := &domain.PatchEventSeries{Template: &template}
+	// Уже созданные занятия сдвигаются вместе со временем, поэтому граница созданных сдвигается так же
+	if shift != 0 && series.MaterializedUntil != nil {
+		materializedUntil := series.MaterializedUntil.Add(shift)
+		patch.MaterializedUntil = &materializedUntil
+	}
+	if err := s.seriesRepo.Patch(ctx.Context, id, patch); err != nil {
+		return nil, err
+	}
+
+	occurrences, err := s.upcomingOccurrences(ctx.Context, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	timeChanged := update.StartTime != nil || update.EndTime != nil
+	for _, event := range occurrences {
+		eventPatch := &domain.AdminPatchEvent{
+			Name:                 update.Name,
+			Description:          update.Description,
+			RankMin:              update.RankMin,
+			RankMax:              update.RankMax,
+			Price:                update.Price,
+			MaxUsers:             update.MaxUsers,
+			CourtID:              update.CourtID,
+			OrganizerID:          update.OrganizerID,
+			ClubID:               update.ClubID,
+			Data:                 update.Data,
+			RefundPolicy:         update.RefundPolicy,
+			PaymentDeadlineHours: update.PaymentDeadlineHours,
+		}
+		if timeChanged {
+			eventStart := event.StartTime
+			if update.StartTime != nil {
+				eventStart = withTimeOfDay(event.StartTime, *update.StartTime)
+			}
+			eventEnd := eventStart.Add(duration)
+			eventPatch.StartTime = &eventStart
+			eventPatch.EndTime = &eventEnd
+		}
+
+		if _, err := s.cases.Event.AdminPatch(&ctx, event.ID, eventPatch); err != nil {
+			return nil, fmt.Errorf("failed to update occurrence %s: %w", event.ID, err)
+		}
+	}
+
+	slog.Info("Event series updated",
+		"series_id", id,
+		"from", from,
+		"occurrences_updated", len(occurrences))
+
+	return s.Get(ctx.Context, id)
+}
+
+// Cancel отменяет серию начиная с cancel.From: новые занятия больше не создаются,
+// предстоящие занятия отменяются вместе с регистрациями, напоминаниями и листом ожидания
+func (s *EventSeries) Cancel(ctx Context, id string, cancel *domain.CancelEventSeries) (*domain.EventSeries, error) {
+	series, err := s.Get(ctx.Context, id)
+	if err != nil {
+		return nil, err
+	}
+	if series.Status == domain.EventSeriesStatusCancelled {
+		return nil, fmt.Errorf("%w: series is already cancelled", ErrInvalidEventSeries)
+	}
+
+	now := time.Now()
+	from := now
+	if cancel.From != nil && cancel.From.After(now) {
+		from = *cancel.From
+	}
+
+	patch := &domain.PatchEventSeries{}
+	if from.After(now) {
+		// Занятия до From остаются, поэтому серия не отменяется, а заканчивается, как UNTIL в RRULE
+		rule := series.Rule
+		until := from.Add(-time.Second)
+		rule.Until = &until
+		patch.Rule = &rule
+	} else {
+		status := domain.EventSeriesStatusCancelled
+		patch.Status = &status
+	}
+	if err := s.seriesRepo.Patch(ctx.Context, id, patch); err != nil {
+		return nil, err
+	}
+
+	occurrences, err := s.upcomingOccurrences(ctx.Context, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range occurrences {
+		if err := s.cancelOccurrence(ctx.Context, event); err != nil {
+			return nil, err
+		}
+	}
+
+	slog.Info("Event series cancelled",
+		"series_id", id,
+		"from", from,
+		"occurrences_cancelled", len(occurrences))
+
+	return s.Get(ctx.Context, id)
+}
+
+// CancelOccurrence отменяет одно событие (в том числе занятие серии) вместе с регистрациями
+func (s *EventSeries) CancelOccurrence(ctx Context, eventID string) (*domain.Event, error) {
+	event, err := s.cases.Event.GetEventByID(ctx.Context, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != domain.EventStatusRegistration && event.Status != domain.EventStatusFull {
+		return nil, fmt.Errorf("%w: event is already %s", ErrInvalidEventSeries, event.Status)
+	}
+
+	if err := s.cancelOccurrence(ctx.Context, event); err != nil {
+		return nil, err
+	}
+
+	return s.cases.Event.GetEventByID(ctx.Context, eventID)
+}
+
+// MaterializeAll создает недостающие занятия всех активных серий
+func (s *EventSeries) MaterializeAll(ctx context.Context) error {
+	status := domain.EventSeriesStatusActive
+	seriesList, err := s.seriesRepo.Filter(ctx, &domain.FilterEventSeries{Status: &status})
+	if err != nil {
+		return fmt.Errorf("failed to get active series: %w", err)
+	}
+
+	for _, series := range seriesList {
+		if err := s.materialize(ctx, series); err != nil {
+			slog.Error("Failed to materialize event series",
+				"series_id", series.ID,
+				"error", err)
+		}
+	}
+
+	return nil
+}
+
+// materialize создает занятия серии от последнего созданного до now+Horizon
+func (s *EventSeries) materialize(ctx context.Context, series *domain.EventSeries) error {
+	now := time.Now()
+	after := now
+	if series.MaterializedUntil != nil && series.MaterializedUntil.After(after) {
+		after = *series.MaterializedUntil
+	}
+	until := now.Add(s.config.Series.Horizon)
+	if !until.After(after) {
+		return nil
+	}
+
+	duration := series.Template.EndTime.Sub(series.Template.StartTime)
+	starts := RecurrenceOccurrences(&series.Rule, series.Template.StartTime, after, until)
+	for _, start := range starts {
+		create := series.Template
+		create.StartTime = start
+		create.EndTime = start.Add(duration)
+		create.SeriesID = &series.ID
+		create.SeriesOccurrence = &start
+
+		event, err := s.cases.Event.Create(ctx, &create)
+		if err != nil {
+			// Сохраняем прогресс, чтобы созданные занятия не создавались повторно
+			if patchErr := s.seriesRepo.Patch(ctx, series.ID, &domain.PatchEventSeries{MaterializedUntil: &after}); patchErr != nil {
+				slog.Error("Failed to save series progress", "series_id", series.ID, "error", patchErr)
+			}
+			return fmt.Errorf("failed to create occurrence at %s: %w", start, err)
+		}
+		after = start
+
+		slog.Info("Event series occurrence created",
+			"series_id", series.ID,
+			"event_id", event.ID,
+			"start_time", start)
+	}
+
+	return s.seriesRepo.Patch(ctx, series.ID, &domain.PatchEventSeries{MaterializedUntil: &until})
+}
+
+// cancelOccurrence отменяет занятие: лист ожидания очищается, регистрации отменяются с полным возвратом оплаты,
+// запланированные воркером напоминания снимаются, участники получают уведомление
+func (s *EventSeries) cancelOccurrence(ctx context.Context, event *domain.Event) error {
+	status := domain.EventStatusCancelled
+	if _, err := s.cases.Event.Patch(ctx, event.ID, &domain.PatchEvent{Status: &status}); err != nil {
+		return fmt.Errorf("failed to cancel event %s: %w", event.ID, err)
+	}
+
+	waitlist, err := s.cases.Waitlist.GetEventWaitlist(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get event waitlist: %w", err)
+	}
+	for _, entry := range waitlist {
+		if err := s.cases.Waitlist.Delete(ctx, entry.ID); err != nil {
+			return fmt.Errorf("failed to clear event waitlist: %w", err)
+		}
+	}
+
+	if err := s.cases.RegistrationPair.CancelEventPairs(ctx, event.ID); err != nil {
+		return err
+	}
+
+	cancelled, err := s.cases.Registration.CancelRegistrationsForCancelledEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	for _, registration := range cancelled {
+		if registration.User == nil {
+			continue
+		}
+		s.notifyCancelled(ctx, registration, event)
+	}
+
+	slog.Info("Event cancelled with registrations",
+		"event_id", event.ID,
+		"series_id", event.SeriesID,
+		"registrations_cancelled", len(cancelled),
+		"waitlist_cleared", len(waitlist))
+
+	return nil
+}
+
+func (s *EventSeries) notifyCancelled(ctx context.Context, registration *domain.Registration, event *domain.Event) {
+	user := registration.User
+
+	if s.notificationService != nil {
+		if err := s.notificationService.SendEventTasksCancel(ctx, user.TelegramID, event.ID); err != nil {
+			slog.Warn("Failed to cancel scheduled tasks for cancelled event",
+				"user_id", user.ID,
+				"event_id", event.ID,
+				"error", err)
+		}
+	}
+
+	text := fmt.Sprintf(`Событие "%s" %s отменено организатором.`,
+		event.Name, event.StartTime.Format("02.01.2006 15:04"))
+	if registration.Status == domain.RegistrationStatusCancelledAfterPayment {
+		text += "\n" + s.refundNotice(ctx, user, event)
+	}
+
+	_, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    user.TelegramID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+	if err != nil {
+		slog.Warn("Failed to send event cancellation notification",
+			"user_id", user.ID,
+			"user_telegram_id", user.TelegramID,
+			"event_id", event.ID,
+			"error", err)
+	}
+}
+
+// refundNotice сообщает участнику состояние возврата: полный возврат обещается, только если провайдер его принял
+func (s *EventSeries) refundNotice(ctx context.Context, user *domain.User, event *domain.Event) string {
+	refunds, err := s.cases.Refund.GetRefundsByUserAndEvent(ctx, user.ID, event.ID)
+	if err != nil {
+		slog.Warn("Failed to get refunds for cancellation notification",
+			"user_id", user.ID,
+			"event_id", event.ID,
+			"error", err)
+	}
+
+	accepted := false
+	for _, refund := range refunds {
+		switch {
+		case refund.Status == domain.RefundStatusSucceeded:
+			return "Оплата возвращена полностью."
+		case refund.Status == domain.RefundStatusPending && refund.RefundID != "":
+			accepted = true
+		}
+	}
+	if accepted {
+		return "Оплата будет возвращена полностью."
+	}
+	return "Возврат оплаты пока не прошел, мы повторим его автоматически."
+}
+
+func (s *EventSeries) upcomingOccurrences(ctx context.Context, seriesID string, from time.Time) ([]*domain.Event, error) {
+	statuses := upcomingEventStatuses
+	occurrences, err := s.cases.Event.Filter(ctx, &domain.FilterEvent{
+		SeriesID:  &seriesID,
+		StartFrom: &from,
+		Statuses:  &statuses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series occurrences: %w", err)
+	}
+	return occurrences, nil
+}
+
+// RunMaterializer периодически создает занятия серий на горизонт вперед. Запускается один раз, из cmd/server
+func (s *EventSeries) RunMaterializer(ctx context.Context) {
+	log := slogx.FromCtx(ctx)
+
+	interval := s.config.Series.MaterializeInterval
+	if interval <= 0 {
+		log.Info("event series materializer disabled")
+		return
+	}
+
+	log.Info("event series materializer started", "interval", interval, "horizon", s.config.Series.Horizon)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.MaterializeAll(ctx); err != nil {
+				log.Error("event series materialization failed", "error", err)
+			}
+		}
+	}
+}
+
+// applySeriesUpdate переносит в шаблон серии все поля изменения, кроме времени
+func applySeriesUpdate(template *domain.CreateEvent, update *domain.UpdateEventSeries) {
+	if update.Name != nil {
+		template.Name = *update.Name
+	}
+	if update.Description != nil {
+		template.Description = update.Description
+	}
+	if update.RankMin != nil {
+		template.RankMin = *update.RankMin
+	}
+	if update.RankMax != nil {
+		template.RankMax = *update.RankMax
+	}
+	if update.Price != nil {
+		template.Price = *update.Price
+	}
+	if update.MaxUsers != nil {
+		template.MaxUsers = *update.MaxUsers
+	}
+	if update.CourtID != nil {
+		template.CourtID = *update.CourtID
+	}
+	if update.OrganizerID != nil {
+		template.OrganizerID = *update.OrganizerID
+	}
+	if update.ClubID != nil {
+		template.ClubID = update.ClubID
+	}
+	if len(update.Data) > 0 {
+		template.Data = update.Data
+	}
+	if update.RefundPolicy != nil {
+		template.RefundPolicy = update.RefundPolicy
+	}
+	if update.PaymentDeadlineHours != nil {
+		template.PaymentDeadlineHours = update.PaymentDeadlineHours
+	}
+}
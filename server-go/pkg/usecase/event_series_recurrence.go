@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ValidateRecurrenceRule проверяет правило повторения относительно первого занятия
+func ValidateRecurrenceRule(rule *domain.RecurrenceRule, first time.Time) error {
+	switch rule.Frequency {
+	case domain.RecurrenceFrequencyDaily, domain.RecurrenceFrequencyMonthly:
+		if len(rule.ByWeekday) > 0 {
+			return fmt.Errorf("%w: byWeekday is supported only for weekly series", ErrInvalidEventSeries)
+		}
+	case domain.RecurrenceFrequencyWeekly:
+		for _, day := range rule.ByWeekday {
+			if _, ok := recurrenceWeekdays[day]; !ok {
+				return fmt.Errorf("%w: unknown weekday %s", ErrInvalidEventSeries, day)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown frequency %s", ErrInvalidEventSeries, rule.Frequency)
+	}
+
+	if rule.Interval < 0 {
+		return fmt.Errorf("%w: interval must be positive", ErrInvalidEventSeries)
+	}
+	if rule.Count != nil && *rule.Count < 1 {
+		return fmt.Errorf("%w: count must be positive", ErrInvalidEventSeries)
+	}
+	if rule.Until != nil && rule.Until.Before(first) {
+		return fmt.Errorf("%w: until is before the first occurrence", ErrInvalidEventSeries)
+	}
+
+	return nil
+}
+
+// RecurrenceOccurrences возвращает начала занятий серии в интервале (after, until].
+// Занятия считаются от первого, чтобы COUNT учитывал уже созданные; время суток берется из first
+// в его часовом поясе, поэтому при переходе через даты оно не смещается
+func RecurrenceOccurrences(rule *domain.RecurrenceRule, first, after, until time.Time) []time.Time {
+	interval := max(rule.Interval, 1)
+	occurrences := []time.Time{}
+	number := 0
+
+	// emit учитывает очередное занятие и возвращает false, когда серия закончилась или вышла за until
+	emit := func(start time.Time) bool {
+		if start.Before(first) {
+			return true
+		}
+		if start.After(until) || (rule.Until != nil && start.After(*rule.Until)) {
+			return false
+		}
+		number++
+		if rule.Count != nil && number > *rule.Count {
+			return false
+		}
+		if start.After(after) {
+			occurrences = append(occurrences, start)
+		}
+		return true
+	}
+
+	switch rule.Frequency {
+	case domain.RecurrenceFrequencyDaily:
+		for i := 0; emit(first.AddDate(0, 0, i*interval)); i++ {
+		}
+
+	case domain.RecurrenceFrequencyWeekly:
+		offsets := weekdayOffsets(rule.ByWeekday, first.Weekday())
+		weekStart := first.AddDate(0, 0, -mondayOffset(first.Weekday()))
+		for week := 0; ; week++ {
+			base := weekStart.AddDate(0, 0, 7*week*interval)
+			for _, offset := range offsets {
+				if !emit(base.AddDate(0, 0, offset)) {
+					return occurrences
+				}
+			}
+		}
+
+	case domain.RecurrenceFrequencyMonthly:
+		for i := 0; ; i++ {
+			start := first.AddDate(0, i*interval, 0)
+			// Как и в RRULE, месяцы без такого числа (31-е, 30 февраля) пропускаются
+			if start.Day() != first.Day() {
+				if start.After(until) {
+					break
+				}
+				continue
+			}
+			if !emit(start) {
+				break
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// weekdayOffsets возвращает смещения дней недели от понедельника в порядке возрастания
+func weekdayOffsets(byWeekday []string, fallback time.Weekday) []int {
+	if len(byWeekday) == 0 {
+		return []int{mondayOffset(fallback)}
+	}
+
+	offsets := make([]int, 0, len(byWeekday))
+	for _, day := range byWeekday {
+		offset := mondayOffset(recurrenceWeekdays[day])
+		if !slices.Contains(offsets, offset) {
+			offsets = append(offsets, offset)
+		}
+	}
+	slices.Sort(offsets)
+	return offsets
+}
+
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// withTimeOfDay переносит время суток clock на дату day, сохраняя часовой пояс clock
+func withTimeOfDay(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), 0, clock.Location())
+}
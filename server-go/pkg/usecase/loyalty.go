@@ -3,19 +3,29 @@ package usecase
 import (
 	"context"
 
+	"github.com/shampsdev/go-telegram-template/pkg/config"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
 	"github.com/shampsdev/go-telegram-template/pkg/repo"
 )
 
 type Loyalty struct {
-	ctx         context.Context
-	loyaltyRepo repo.Loyalty
+	ctx                 context.Context
+	loyaltyRepo         repo.Loyalty
+	tx                  repo.Transactor
+	notificationService *notifications.NotificationService
+	config              *config.Config
+	cases               *Cases
 }
 
-func NewLoyalty(ctx context.Context, loyaltyRepo repo.Loyalty) *Loyalty {
+func NewLoyalty(ctx context.Context, loyaltyRepo repo.Loyalty, tx repo.Transactor, notificationService *notifications.NotificationService, cfg *config.Config, cases *Cases) *Loyalty {
 	return &Loyalty{
-		ctx:         ctx,
-		loyaltyRepo: loyaltyRepo,
+		ctx:                 ctx,
+		loyaltyRepo:         loyaltyRepo,
+		tx:                  tx,
+		notificationService: notificationService,
+		config:              cfg,
+		cases:               cases,
 	}
 }
 
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+)
+
+// RunRecalculator периодически пересчитывает уровни лояльности всех пользователей,
+// чтобы учитывать выход старых участий и оплат из скользящего окна. Запускается один раз, из cmd/server
+func (l *Loyalty) RunRecalculator(ctx context.Context) {
+	log := slogx.FromCtx(ctx)
+
+	interval := l.config.Loyalty.RecalculateInterval
+	if interval <= 0 {
+		log.Info("loyalty recalculator disabled")
+		return
+	}
+
+	log.Info("loyalty recalculator started", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.RecalculateAll(ctx); err != nil {
+				log.Error("loyalty recalculation failed", "error", err)
+			}
+		}
+	}
+}
+
+// RecalculateAll пересчитывает уровни лояльности всех пользователей
+func (l *Loyalty) RecalculateAll(ctx context.Context) error {
+	users, err := l.cases.User.AdminFilter(ctx, &domain.FilterUser{})
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	levels, err := l.loyaltyRepo.Filter(ctx, &domain.FilterLoyalty{})
+	if err != nil {
+		return fmt.Errorf("failed to get loyalty levels: %w", err)
+	}
+
+	changed := 0
+	for _, user := range users {
+		ok, err := l.recalculateUser(ctx, user, levels)
+		if err != nil {
+			slog.Error("Failed to recalculate user loyalty", "user_id", user.ID, "error", err)
+			continue
+		}
+		if ok {
+			changed++
+		}
+	}
+
+	slog.Info("Loyalty recalculation finished", "users", len(users), "changed", changed)
+	return nil
+}
+
+// RecalculateUserLoyalty пересчитывает уровень лояльности пользователя по условиям уровней
+// и отправляет уведомление, если уровень изменился
+func (l *Loyalty) RecalculateUserLoyalty(ctx context.Context, userID string) error {
+	user, err := l.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	levels, err := l.loyaltyRepo.Filter(ctx, &domain.FilterLoyalty{})
+	if err != nil {
+		return fmt.Errorf("failed to get loyalty levels: %w", err)
+	}
+
+	_, err = l.recalculateUser(ctx, user, levels)
+	return err
+}
+
+// RecalculateUserLoyaltyAsync запускает пересчет в фоне, чтобы не задерживать ответ пользователю.
+// Ошибки только логируются
+func (l *Loyalty) RecalculateUserLoyaltyAsync(ctx context.Context, userID string) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := l.RecalculateUserLoyalty(ctx, userID); err != nil {
+			slog.Error("Failed to recalculate user loyalty", "user_id", userID, "error", err)
+		}
+	}()
+}
+
+func (l *Loyalty) recalculateUser(ctx context.Context, user *domain.User, levels []*domain.Loyalty) (bool, error) {
+	if user.LoyaltyLocked {
+		return false, nil
+	}
+
+	target, err := l.findEligibleLevel(ctx, user.ID, levels)
+	if err != nil {
+		return false, err
+	}
+
+	// Уровень пользователя загружается без условий, поэтому берем его из списка уровней
+	var current *domain.Loyalty
+	if user.Loyalty != nil {
+		current = findLoyaltyLevel(levels, user.Loyalty.ID)
+		if current == nil {
+			current = user.Loyalty
+		}
+	}
+
+	// Уровни без условий назначаются вручную: не понижаем пользователя с такого уровня
+	if current != nil && current.Rules == nil {
+		if target == nil || target.Discount <= current.Discount {
+			return false, nil
+		}
+	}
+
+	// Ни одно условие не выполнено — возвращаем на базовый уровень
+	if target == nil {
+		target = findLoyaltyLevel(levels, l.config.Loyalty.DefaultLevelID)
+		if target == nil {
+			return false, fmt.Errorf("default loyalty level %d not found", l.config.Loyalty.DefaultLevelID)
+		}
+	}
+
+	if current != nil && current.ID == target.ID {
+		return false, nil
+	}
+
+	oldLevel := ""
+	if current != nil {
+		oldLevel = current.Name
+	}
+
+	err = l.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if err := l.cases.User.SetLoyalty(txCtx, user, target.ID); err != nil {
+			return err
+		}
+		if l.notificationService == nil {
+			return nil
+		}
+		if err := l.notificationService.SendTournamentLoyaltyChanged(txCtx, user.TelegramID, oldLevel, target.Name); err != nil {
+			return fmt.Errorf("failed to send loyalty changed notification: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	slog.Info("User loyalty level changed",
+		"user_id", user.ID,
+		"old_level", oldLevel,
+		"new_level", target.Name)
+
+	return true, nil
+}
+
+// findEligibleLevel выбирает уровень с наибольшей скидкой среди тех, условия которых выполнены
+func (l *Loyalty) findEligibleLevel(ctx context.Context, userID string, levels []*domain.Loyalty) (*domain.Loyalty, error) {
+	now := time.Now()
+
+	var best *domain.Loyalty
+	for _, level := range levels {
+		if level.Rules == nil {
+			continue
+		}
+
+		activity, err := l.loyaltyRepo.GetActivity(ctx, &domain.FilterLoyaltyActivity{
+			UserID:     userID,
+			Since:      level.Rules.Since(now),
+			EventTypes: level.Rules.EventTypes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loyalty activity: %w", err)
+		}
+
+		if !level.Rules.IsSatisfied(activity) {
+			continue
+		}
+
+		if best == nil || level.Discount > best.Discount || (level.Discount == best.Discount && level.ID > best.ID) {
+			best = level
+		}
+	}
+
+	return best, nil
+}
+
+func (l *Loyalty) getUser(ctx context.Context, userID string) (*domain.User, error) {
+	users, err := l.cases.User.AdminFilter(ctx, &domain.FilterUser{ID: &userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return users[0], nil
+}
+
+func findLoyaltyLevel(levels []*domain.Loyalty, id int) *domain.Loyalty {
+	for _, level := range levels {
+		if level.ID == id {
+			return level
+		}
+	}
+	return nil
+}
@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"gopadel/taskcontract"
+)
+
+var (
+	ErrMessageTemplateNotFound = errors.New("message template not found")
+	ErrInvalidMessageTemplate  = errors.New("invalid message template")
+)
+
+// Ключи шаблонов уведомлений, которые отправляет сервер
+const (
+	MessageTemplateEventWaitlistRegistered = "event.waitlist.registered"
+	MessageTemplatePairWaitlistRegistered  = "pair.waitlist.registered"
+	MessageTemplateWaitlistOffer           = "waitlist.offer"
+	MessageTemplateWaitlistOfferExpired    = "waitlist.offer.expired"
+)
+
+var languageCodeRe = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]+)?$`)
+
+// messageTemplateKeys шаблоны, которые можно редактировать. Ключи задач воркера совпадают с типами задач,
+// tournament_url, event_url и start_time_local воркер добавляет к данным задачи сам
+var messageTemplateKeys = map[string]domain.MessageTemplateKey{
+	string(taskcontract.TaskTypeEventRegistrationConfirmed): {
+		Description: "Регистрация на событие",
+		SampleData:  eventTaskSampleData(map[string]any{"is_paid": false}),
+	},
+	string(taskcontract.TaskTypeEventReminder48Hours): {
+		Description: "Напоминание за 2 дня до события",
+		SampleData:  eventTaskSampleData(map[string]any{"is_paid": false}),
+	},
+	string(taskcontract.TaskTypeEventReminder24Hours): {
+		Description: "Напоминание за день до события",
+		SampleData:  eventTaskSampleData(map[string]any{"is_paid": true}),
+	},
+	string(taskcontract.TaskTypeEventRegistrationCanceled): {
+		Description: "Отмена регистрации на событие",
+		SampleData:  eventTaskSampleData(nil),
+	},
+	string(taskcontract.TaskTypeEventRegistrationRequested): {
+		Description: "Заявка на игру для организатора",
+		SampleData: eventTaskSampleData(map[string]any{
+			"applicant_telegram_id": 987654321,
+			"applicant_name":        "Иван Петров",
+		}),
+	},
+	string(taskcontract.TaskTypeEventPaymentExpired): {
+		Description: "Отмена неоплаченной регистрации на событие",
+		SampleData:  eventTaskSampleData(nil),
+	},
+	string(taskcontract.TaskTypeEventRegistrationApproved): {
+		Description: "Заявка на игру принята",
+		SampleData:  eventTaskSampleData(nil),
+	},
+	string(taskcontract.TaskTypeEventRegistrationRejected): {
+		Description: "Заявка на игру отклонена",
+		SampleData:  eventTaskSampleData(nil),
+	},
+	string(taskcontract.TaskTypeTournamentRegistrationSuccess): {
+		Description: "Регистрация на турнир",
+		SampleData:  tournamentSampleData(map[string]any{"is_free": false}),
+	},
+	string(taskcontract.TaskTypeTournamentReminder48Hours): {
+		Description: "Напоминание за 2 дня до турнира",
+		SampleData:  tournamentSampleData(map[string]any{"is_paid": false}),
+	},
+	string(taskcontract.TaskTypeTournamentReminder24Hours): {
+		Description: "Напоминание за день до турнира",
+		SampleData:  tournamentSampleData(map[string]any{"is_paid": true}),
+	},
+	string(taskcontract.TaskTypeTournamentFreeReminder48Hours): {
+		Description: "Напоминание за 2 дня до бесплатного турнира",
+		SampleData:  tournamentSampleData(nil),
+	},
+	string(taskcontract.TaskTypeTournamentPaymentSuccess): {
+		Description: "Успешная оплата турнира",
+		SampleData:  tournamentSampleData(nil),
+	},
+	string(taskcontract.TaskTypeTournamentRegistrationCanceled): {
+		Description: "Отмена регистрации на турнир",
+		SampleData:  tournamentSampleData(nil),
+	},
+	string(taskcontract.TaskTypeTournamentRegistrationAutoDeleteUnpaid): {
+		Description: "Отмена неоплаченной регистрации",
+		SampleData:  tournamentSampleData(map[string]any{"registration_id": "00000000-0000-0000-0000-000000000000"}),
+	},
+	string(taskcontract.TaskTypeTournamentLoyaltyChanged): {
+		Description: "Повышение уровня лояльности",
+		SampleData:  map[string]any{"user_telegram_id": 123456789, "old_level": "Silver", "new_level": "Gold"},
+	},
+	"default": {
+		Description: "Уведомление без своего шаблона",
+		SampleData:  map[string]any{},
+	},
+	MessageTemplateEventWaitlistRegistered: {
+		Description: "Регистрация из листа ожидания",
+		SampleData:  eventSampleData(),
+	},
+	MessageTemplatePairWaitlistRegistered: {
+		Description: "Регистрация пары из листа ожидания",
+		SampleData:  eventSampleData(),
+	},
+	MessageTemplateWaitlistOffer: {
+		Description: "Предложение места из листа ожидания",
+		SampleData:  waitlistOfferSampleData(),
+	},
+	MessageTemplateWaitlistOfferExpired: {
+		Description: "Предложение места истекло",
+		SampleData:  eventSampleData(),
+	},
+}
+
+func tournamentSampleData(extra map[string]any) map[string]any {
+	data := map[string]any{
+		"user_telegram_id": 123456789,
+		"tournament_id":    "00000000-0000-0000-0000-000000000000",
+		"tournament_name":  "Кубок выходного дня",
+		"tournament_url":   "https://t.me/gopadel_bot/app?startapp=tour-00000000-0000-0000-0000-000000000000",
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	return data
+}
+
+func eventSampleData() map[string]any {
+	return map[string]any{
+		"event_id":   "00000000-0000-0000-0000-000000000000",
+		"event_name": "Игра в субботу",
+		"event_url":  "https://t.me/gopadel_bot/app?startapp=00000000-0000-0000-0000-000000000000",
+	}
+}
+
+// eventTaskSampleData данные задачи воркера о событии вместе с полями, которые воркер добавляет при отправке
+func eventTaskSampleData(extra map[string]any) map[string]any {
+	data := eventSampleData()
+	data["user_telegram_id"] = 123456789
+	data["event_type"] = string(domain.EventTypeGame)
+	data["start_time"] = "2026-10-18T18:00:00Z"
+	data["start_time_local"] = "18.10.2026 21:00"
+	data["court_name"] = "Корт №1"
+	data["court_address"] = "ул. Спортивная, 1"
+	data["court_timezone"] = domain.DefaultCourtTimezone
+	for k, v := range extra {
+		data[k] = v
+	}
+	return data
+}
+
+func waitlistOfferSampleData() map[string]any {
+	data := eventSampleData()
+	data["expires_at"] = "18.10 21:00"
+	return data
+}
+
+// MessageTemplate шаблоны уведомлений в БД. Вариант выбирается по language_code пользователя:
+// сначала точный язык, затем базовый (pt для pt-br), затем язык по умолчанию
+type MessageTemplate struct {
+	templateRepo    repo.MessageTemplate
+	defaultLanguage string
+	cases           *Cases
+}
+
+func NewMessageTemplate(ctx context.Context, templateRepo repo.MessageTemplate, cfg *config.Config, cases *Cases) *MessageTemplate {
+	return &MessageTemplate{
+		templateRepo:    templateRepo,
+		defaultLanguage: normalizeLanguage(cfg.Messages.DefaultLanguage),
+		cases:           cases,
+	}
+}
+
+// Keys возвращает ключи шаблонов с примерами данных
+func (m *MessageTemplate) Keys() []domain.MessageTemplateKey {
+	keys := make([]domain.MessageTemplateKey, 0, len(messageTemplateKeys))
+	for key, templateKey := range messageTemplateKeys {
+		templateKey.Key = key
+		keys = append(keys, templateKey)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys
+}
+
+func (m *MessageTemplate) Filter(ctx context.Context, filter *domain.FilterMessageTemplate) ([]*domain.MessageTemplate, error) {
+	return m.templateRepo.Filter(ctx, filter)
+}
+
+// Upsert сохраняет вариант шаблона. Шаблон должен разбираться и выполняться на данных из примера
+func (m *MessageTemplate) Upsert(ctx Context, key, language string, upsert *domain.UpsertMessageTemplate) (*domain.MessageTemplate, error) {
+	templateKey, ok := messageTemplateKeys[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidMessageTemplate, key)
+	}
+	language = normalizeLanguage(language)
+	if !languageCodeRe.MatchString(language) {
+		return nil, fmt.Errorf("%w: invalid language %q", ErrInvalidMessageTemplate, language)
+	}
+	if _, err := renderMessageTemplate(key, upsert.Body, templateKey.SampleData, true); err != nil {
+		return nil, err
+	}
+
+	if upsert.Description == nil {
+		upsert.Description = &templateKey.Description
+	}
+	if ctx.User != nil {
+		upsert.UpdatedBy = &ctx.User.ID
+	}
+
+	if err := m.templateRepo.Upsert(ctx, key, language, upsert); err != nil {
+		return nil, err
+	}
+
+	log := slog.With(slog.String("key", key), slog.String("language", language))
+	if ctx.User != nil {
+		log = log.With(slog.String("admin_user_id", ctx.User.ID))
+	}
+	log.Info("Message template saved")
+
+	return repo.First(m.templateRepo.Filter)(ctx, &domain.FilterMessageTemplate{Key: &key, Language: &language})
+}
+
+// Delete удаляет вариант шаблона. Вариант на языке по умолчанию удалить нельзя, на нем заканчивается цепочка языков
+func (m *MessageTemplate) Delete(ctx Context, key, language string) error {
+	language = normalizeLanguage(language)
+	if language == m.defaultLanguage {
+		return fmt.Errorf("%w: template in default language %q cannot be deleted", ErrInvalidMessageTemplate, language)
+	}
+
+	err := m.templateRepo.Delete(ctx, key, language)
+	if errors.Is(err, repo.ErrNotFound) {
+		return ErrMessageTemplateNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	log := slog.With(slog.String("key", key), slog.String("language", language))
+	if ctx.User != nil {
+		log = log.With(slog.String("admin_user_id", ctx.User.ID))
+	}
+	log.Info("Message template deleted")
+	return nil
+}
+
+// Preview отображает шаблон на данных из запроса или из примера для ключа
+func (m *MessageTemplate) Preview(ctx context.Context, preview *domain.PreviewMessageTemplate) (*domain.RenderedMessage, error) {
+	templateKey, ok := messageTemplateKeys[preview.Key]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidMessageTemplate, preview.Key)
+	}
+
+	data := preview.Data
+	if data == nil {
+		data = templateKey.SampleData
+	}
+
+	if preview.Body != nil {
+		text, err := renderMessageTemplate(preview.Key, *preview.Body, data, true)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.RenderedMessage{
+			Text:      text,
+			ParseMode: preview.ParseMode,
+			Language:  normalizeLanguage(preview.Language),
+		}, nil
+	}
+
+	return m.render(ctx, preview.Key, preview.Language, data, true)
+}
+
+// Render строит сообщение по шаблону для языка languageCode с учетом цепочки языков
+func (m *MessageTemplate) Render(ctx context.Context, key, languageCode string, data map[string]any) (*domain.RenderedMessage, error) {
+	return m.render(ctx, key, languageCode, data, false)
+}
+
+// RenderForUser строит сообщение на языке пользователя. Пользователь из выборок регистраций
+// и листа ожидания приходит без языка, тогда язык берется из профиля
+func (m *MessageTemplate) RenderForUser(ctx context.Context, key string, user *domain.User, data map[string]any) (*domain.RenderedMessage, error) {
+	languageCode := user.LanguageCode
+	if languageCode == "" {
+		if profile, err := m.cases.User.GetByTelegramID(ctx, user.TelegramID); err == nil {
+			languageCode = profile.LanguageCode
+		}
+	}
+	return m.Render(ctx, key, languageCode, data)
+}
+
+func (m *MessageTemplate) render(ctx context.Context, key, languageCode string, data map[string]any, strict bool) (*domain.RenderedMessage, error) {
+	languages := m.languageChain(languageCode)
+
+	templates, err := m.templateRepo.Filter(ctx, &domain.FilterMessageTemplate{Key: &key, Languages: languages})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message template: %w", err)
+	}
+
+	for _, language := range languages {
+		for _, t := range templates {
+			if t.Language != language {
+				continue
+			}
+			text, err := renderMessageTemplate(key, t.Body, data, strict)
+			if err != nil {
+				return nil, err
+			}
+			return &domain.RenderedMessage{Text: text, ParseMode: t.ParseMode, Language: t.Language}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s for languages %v", ErrMessageTemplateNotFound, key, languages)
+}
+
+// languageChain язык пользователя, его базовый язык и язык по умолчанию без повторов
+func (m *MessageTemplate) languageChain(languageCode string) []string {
+	languageCode = normalizeLanguage(languageCode)
+
+	chain := make([]string, 0, 3)
+	add := func(language string) {
+		for _, l := range chain {
+			if l == language {
+				return
+			}
+		}
+		chain = append(chain, language)
+	}
+
+	if languageCode != "" {
+		add(languageCode)
+		if base, _, ok := strings.Cut(languageCode, "-"); ok {
+			add(base)
+		}
+	}
+	add(m.defaultLanguage)
+	return chain
+}
+
+// sendMessageParams параметры отправки готового сообщения в чат
+func sendMessageParams(chatID int64, message *domain.RenderedMessage) *bot.SendMessageParams {
+	params := &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   message.Text,
+	}
+	if message.ParseMode != nil {
+		params.ParseMode = models.ParseMode(*message.ParseMode)
+	}
+	return params
+}
+
+func normalizeLanguage(language string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(language)), "_", "-")
+}
+
+// renderMessageTemplate выполняет шаблон. strict — ошибка на отсутствующий ключ данных,
+// чтобы опечатки в шаблоне находились при сохранении, а не при отправке
+func renderMessageTemplate(key, body string, data map[string]any, strict bool) (string, error) {
+	t := template.New(key)
+	if strict {
+		t = t.Option("missingkey=error")
+	}
+
+	t, err := t.Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidMessageTemplate, err)
+	}
+
+	var text strings.Builder
+	if err := t.Execute(&text, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidMessageTemplate, err)
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", fmt.Errorf("%w: rendered message is empty", ErrInvalidMessageTemplate)
+	}
+	return text.String(), nil
+}
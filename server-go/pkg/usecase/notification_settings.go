@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+var ErrInvalidNotificationSettings = errors.New("invalid notification settings")
+
+// NotificationSettings настройки уведомлений пользователей. Сервер по ним фильтрует свои уведомления
+// и получателей рассылок, воркер читает их из той же таблицы и откладывает уведомления на тихие часы
+type NotificationSettings struct {
+	settingsRepo repo.NotificationSettings
+}
+
+func NewNotificationSettings(ctx context.Context, settingsRepo repo.NotificationSettings) *NotificationSettings {
+	return &NotificationSettings{
+		settingsRepo: settingsRepo,
+	}
+}
+
+// Get возвращает настройки пользователя или настройки по умолчанию
+func (n *NotificationSettings) Get(ctx context.Context, userID string) (*domain.NotificationSettings, error) {
+	settings, err := n.settingsRepo.GetByUserIDs(ctx, []string{userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+	if s, ok := settings[userID]; ok {
+		return s, nil
+	}
+	defaults := domain.DefaultNotificationSettings()
+	return &defaults, nil
+}
+
+func (n *NotificationSettings) Patch(ctx context.Context, userID string, patch *domain.PatchNotificationSettings) (*domain.NotificationSettings, error) {
+	current, err := n.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := patch.Apply(*current)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotificationSettings, err)
+	}
+
+	if err := n.settingsRepo.Upsert(ctx, userID, &settings); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Notification settings updated", slog.String("user_id", userID), slog.Bool("muted", settings.Muted))
+	return &settings, nil
+}
+
+// Allows можно ли отправить пользователю уведомление категории category. Если настройки
+// прочитать не удалось, уведомление отправляется
+func (n *NotificationSettings) Allows(ctx context.Context, userID string, category domain.NotificationCategory) bool {
+	settings, err := n.Get(ctx, userID)
+	if err != nil {
+		slog.Warn("Failed to get notification settings, sending anyway", slog.String("user_id", userID), slog.Any("error", err))
+		return true
+	}
+	return settings.Allows(category)
+}
+
+// FilterAllowed оставляет пользователей, которые получают уведомления категории category
+func (n *NotificationSettings) FilterAllowed(ctx context.Context, users []*domain.User, category domain.NotificationCategory) ([]*domain.User, error) {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	settings, err := n.settingsRepo.GetByUserIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	allowed := make([]*domain.User, 0, len(users))
+	for _, user := range users {
+		if s, ok := settings[user.ID]; ok && !s.Allows(category) {
+			continue
+		}
+		allowed = append(allowed, user)
+	}
+	return allowed, nil
+}
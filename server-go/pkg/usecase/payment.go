@@ -1,81 +1,41 @@
 package usecase
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"regexp"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/shampsdev/go-telegram-template/pkg/config"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/payments"
 	"github.com/shampsdev/go-telegram-template/pkg/repo"
 )
 
-type Payment struct {
-	paymentRepo repo.Payment
-	config      *config.Config
-	cases       *Cases
-}
-
-type YooKassaAmount struct {
-	Value    string `json:"value"`
-	Currency string `json:"currency"`
-}
-
-type YooKassaConfirmation struct {
-	Type      string `json:"type"`
-	ReturnURL string `json:"return_url"`
-}
-
-type YooKassaCustomer struct {
-	Email string `json:"email"`
-}
-
-type YooKassaItem struct {
-	Description    string         `json:"description"`
-	PaymentSubject string         `json:"payment_subject"`
-	Amount         YooKassaAmount `json:"amount"`
-	VatCode        int            `json:"vat_code"`
-	Quantity       int            `json:"quantity"`
-	Measure        string         `json:"measure"`
-	PaymentMode    string         `json:"payment_mode"`
-}
+var ErrPendingPaymentExists = errors.New("pending payment already exists, complete it or wait until it expires")
 
-type YooKassaReceipt struct {
-	Customer YooKassaCustomer `json:"customer"`
-	Items    []YooKassaItem   `json:"items"`
-}
-
-type YooKassaPaymentRequest struct {
-	Amount       YooKassaAmount       `json:"amount"`
-	Confirmation YooKassaConfirmation `json:"confirmation"`
-	Capture      bool                 `json:"capture"`
-	Description  string               `json:"description"`
-	Receipt      YooKassaReceipt      `json:"receipt"`
-}
-
-type YooKassaPaymentResponse struct {
-	ID           string                   `json:"id"`
-	Status       string                   `json:"status"`
-	Confirmation YooKassaConfirmationResp `json:"confirmation"`
-}
-
-type YooKassaConfirmationResp struct {
-	ConfirmationURL string `json:"confirmation_url"`
+type Payment struct {
+	paymentRepo         repo.Payment
+	provider            payments.PaymentProvider
+	tx                  repo.Transactor
+	notificationService *notifications.NotificationService
+	config              *config.Config
+	cases               *Cases
 }
 
-func NewPayment(ctx context.Context, paymentRepo repo.Payment, cfg *config.Config, cases *Cases) *Payment {
+func NewPayment(ctx context.Context, paymentRepo repo.Payment, provider payments.PaymentProvider, tx repo.Transactor, notificationService *notifications.NotificationService, cfg *config.Config, cases *Cases) *Payment {
 	return &Payment{
-		paymentRepo: paymentRepo,
-		config:      cfg,
-		cases:       cases,
+		paymentRepo:         paymentRepo,
+		provider:            provider,
+		tx:                  tx,
+		notificationService: notificationService,
+		config:              cfg,
+		cases:               cases,
 	}
 }
 
@@ -106,6 +66,210 @@ func (p *Payment) UpdatePaymentStatus(ctx context.Context, paymentID string, sta
 	return p.paymentRepo.Patch(ctx, paymentID, patch)
 }
 
+// ApplyPaymentStatus применяет статус платежа, полученный от провайдера.
+// Переходы монотонны: финальный статус (succeeded, canceled) не может быть изменен,
+// а подтверждение регистрации выполняется не более одного раза
+func (p *Payment) ApplyPaymentStatus(ctx context.Context, payment *domain.Payment, status domain.PaymentStatus) error {
+	previousStatus := payment.Status
+
+	// Статус платежа, подтверждение регистраций и уведомление об оплате фиксируются вместе
+	var confirmedUserIDs []string
+	err := p.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if payment.Status != status {
+			changed, err := p.paymentRepo.TransitionStatus(txCtx, payment.ID, domain.PaymentStatusesBefore(status), status)
+			if err != nil {
+				return fmt.Errorf("failed to update payment status: %w", err)
+			}
+
+			if !changed {
+				slog.Warn("Payment status transition skipped",
+					"payment_id", payment.PaymentID,
+					"current_status", payment.Status,
+					"new_status", status)
+				return nil
+			}
+
+			slog.Info("Payment status changed",
+				"payment_id", payment.PaymentID,
+				"old_status", payment.Status,
+				"new_status", status)
+			payment.Status = status
+		}
+
+		// При отмененном платеже регистрация остается в статусе PENDING,
+		// чтобы пользователь мог создать новый платеж.
+		// Регистрация переходит в CANCELED только при отмене пользователем
+		if payment.Status != domain.PaymentStatusSucceeded {
+			return nil
+		}
+
+		var err error
+		confirmedUserIDs, err = p.confirmPaidRegistration(txCtx, payment)
+		return err
+	})
+	if err != nil {
+		payment.Status = previousStatus
+		return err
+	}
+
+	// Пересчет идет в фоне и должен видеть подтвержденные регистрации, поэтому запускается после коммита
+	for _, userID := range confirmedUserIDs {
+		p.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, userID)
+	}
+
+	if previousStatus != domain.PaymentStatusSucceeded && payment.Status == domain.PaymentStatusSucceeded {
+		if err := p.refundDuplicatePayments(ctx, payment); err != nil {
+			slog.Error("Failed to refund duplicate payment",
+				"payment_id", payment.PaymentID,
+				"user_id", payment.UserID,
+				"event_id", payment.EventID,
+				"error", err)
+		}
+	}
+
+	return nil
+}
+
+// refundDuplicatePayments возвращает лишние платежи, если регистрация оплачена несколько раз.
+// Так бывает, когда пользователь оплатил и старую ссылку в pending, и новую с другим промокодом.
+// Остается самый ранний успешный платеж, поэтому результат не зависит от порядка вебхуков
+func (p *Payment) refundDuplicatePayments(ctx context.Context, payment *domain.Payment) error {
+	payments, err := p.GetPaymentsByUserAndEvent(ctx, payment.UserID, payment.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to get payments: %w", err)
+	}
+
+	var succeeded []*domain.Payment
+	for _, other := range payments {
+		if other.Status == domain.PaymentStatusSucceeded {
+			succeeded = append(succeeded, other)
+		}
+	}
+	if len(succeeded) < 2 {
+		return nil
+	}
+
+	kept := succeeded[0]
+	for _, other := range succeeded[1:] {
+		if other.Date.Before(kept.Date) {
+			kept = other
+		}
+	}
+
+	for _, duplicate := range succeeded {
+		if duplicate.ID == kept.ID {
+			continue
+		}
+
+		slog.Warn("Registration is already paid, refunding duplicate payment",
+			"payment_id", duplicate.PaymentID,
+			"paid_by", kept.PaymentID,
+			"user_id", duplicate.UserID,
+			"event_id", duplicate.EventID)
+
+		if _, err := p.cases.Refund.RefundDuplicatePayment(ctx, duplicate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirmPaidRegistration подтверждает оплаченную регистрацию и возвращает пользователей, чьи регистрации подтверждены
+func (p *Payment) confirmPaidRegistration(ctx context.Context, payment *domain.Payment) ([]string, error) {
+	event, err := p.cases.Event.GetEventByID(ctx, payment.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	// Активируем регистрацию только для турниров, не для игр
+	if event.Type != domain.EventTypeTournament {
+		return nil, nil
+	}
+
+	confirmed, err := p.cases.Registration.TransitionRegistrationStatus(
+		ctx,
+		payment.UserID,
+		payment.EventID,
+		[]domain.RegistrationStatus{domain.RegistrationStatusPending},
+		domain.RegistrationStatusConfirmed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update registration status: %w", err)
+	}
+
+	var confirmedUserIDs []string
+	if confirmed {
+		slog.Info("Registration confirmed after payment",
+			"user_id", payment.UserID,
+			"event_id", payment.EventID,
+			"payment_id", payment.PaymentID)
+
+		if err := p.notifyPaymentSuccess(ctx, payment, event); err != nil {
+			return nil, err
+		}
+		confirmedUserIDs = append(confirmedUserIDs, payment.UserID)
+	}
+
+	partnerID, err := p.confirmPairPartner(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	if partnerID != "" {
+		confirmedUserIDs = append(confirmedUserIDs, partnerID)
+	}
+
+	return confirmedUserIDs, nil
+}
+
+// confirmPairPartner подтверждает регистрацию партнера, за которого заплатил капитан, и возвращает ID партнера
+func (p *Payment) confirmPairPartner(ctx context.Context, payment *domain.Payment) (string, error) {
+	pair, err := p.cases.RegistrationPair.GetRegisteredPair(ctx, payment.UserID, payment.EventID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get registration pair: %w", err)
+	}
+	if pair == nil || pair.PaymentMode != domain.PairPaymentModeCaptain || pair.CaptainID != payment.UserID {
+		return "", nil
+	}
+
+	confirmed, err := p.cases.Registration.TransitionRegistrationStatus(
+		ctx,
+		pair.PartnerID,
+		payment.EventID,
+		[]domain.RegistrationStatus{domain.RegistrationStatusPending},
+		domain.RegistrationStatusConfirmed,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to update partner registration status: %w", err)
+	}
+	if !confirmed {
+		return "", nil
+	}
+
+	slog.Info("Partner registration confirmed after captain payment",
+		"user_id", pair.PartnerID,
+		"event_id", payment.EventID,
+		"payment_id", payment.PaymentID)
+
+	return pair.PartnerID, nil
+}
+
+// notifyPaymentSuccess ставит уведомление об оплате в outbox в транзакции подтверждения регистрации
+func (p *Payment) notifyPaymentSuccess(ctx context.Context, payment *domain.Payment, event *domain.Event) error {
+	if p.notificationService == nil {
+		return nil
+	}
+
+	user, err := repo.First(p.cases.User.AdminFilter)(ctx, &domain.FilterUser{ID: &payment.UserID})
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := p.notificationService.SendTournamentPaymentSuccess(ctx, user.TelegramID, event.ID, event.Name); err != nil {
+		return fmt.Errorf("failed to send payment success notification: %w", err)
+	}
+	return nil
+}
+
 func (p *Payment) GetPaymentsByRegistration(ctx context.Context, userID, eventID string) ([]*domain.Payment, error) {
 	filter := &domain.FilterPayment{
 		UserID:  &userID,
@@ -137,8 +301,9 @@ func (p *Payment) GetPaymentByPaymentID(ctx context.Context, paymentID string) (
 	return payments[0], nil
 }
 
-// CreateYooKassaPayment создает платеж в YooKassa для регистрации на событие
-func (p *Payment) CreateYooKassaPayment(ctx context.Context, user *domain.User, eventID string, returnURL string) (*domain.Payment, error) {
+// CreateProviderPayment создает платеж у платежного провайдера для регистрации на событие.
+// Если передан промокод, он проверяется и применяется вместе со скидкой лояльности по политике DiscountStacking
+func (p *Payment) CreateProviderPayment(ctx context.Context, user *domain.User, eventID string, returnURL string, promoCode string) (*domain.Payment, error) {
 	event, err := p.cases.Event.GetEventByID(ctx, eventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get event: %w", err)
@@ -157,159 +322,252 @@ func (p *Payment) CreateYooKassaPayment(ctx context.Context, user *domain.User,
 		return nil, err
 	}
 
+	// Если капитан пары оплачивает оба места, партнер не платит, а капитан платит и за его место
+	originalPrice := event.Price
+	var partner *domain.User
+	pair, err := p.cases.RegistrationPair.GetRegisteredPair(ctx, user.ID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if pair != nil && pair.PaymentMode == domain.PairPaymentModeCaptain {
+		if pair.PartnerID == user.ID {
+			return nil, ErrPartnerPaysForPair
+		}
+		partner, err = repo.First(p.cases.User.AdminFilter)(ctx, &domain.FilterUser{ID: &pair.PartnerID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partner: %w", err)
+		}
+		originalPrice = event.Price * 2
+	}
+
 	existingPayments, err := p.GetPaymentsByRegistration(ctx, registration.UserID, registration.EventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get existing payments: %w", err)
 	}
 
-	// если есть пендинг или успешный, то его
+	// если есть успешный или пендинг с тем же промокодом, то его
 	for _, payment := range existingPayments {
-		if payment.Status == domain.PaymentStatusSucceeded || payment.Status == domain.PaymentStatusPending {
+		if payment.Status == domain.PaymentStatusSucceeded {
+			return payment, nil
+		}
+		if payment.Status == domain.PaymentStatusPending && samePromoCode(payment.PromoCode, promoCode) {
 			return payment, nil
 		}
 	}
 
-	yooPayment, err := p.createYooKassaPayment(event, user, returnURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create YooKassa payment: %w", err)
+	var promo *domain.PromoCode
+	if strings.TrimSpace(promoCode) != "" {
+		promo, err = p.cases.PromoCode.ResolvePromoCode(ctx, promoCode, user, event)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Платеж в waiting_for_capture с другим промокодом отменяем, чтобы не было двух активных ссылок на оплату.
+	// Платеж в pending YooKassa отменить не дает: он истечет у провайдера сам, а если оплатят оба платежа,
+	// лишний вернется (refundDuplicatePayment)
+	for _, payment := range existingPayments {
+		if payment.Status != domain.PaymentStatusWaitingForCapture {
+			continue
+		}
+		if err := p.cancelPendingPayment(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	// Каждое место считается со скидкой своего игрока: лояльность партнера — к его месту,
+	// промокод капитана — только к месту капитана
+	finalPrice := p.calculateFinalPrice(event.Price, user, promo)
+	if partner != nil {
+		finalPrice += p.calculateFinalPrice(event.Price, partner, nil)
 	}
 
-	finalPrice := p.calculateFinalPrice(event.Price, user)
-	
 	createPayment := &domain.CreatePayment{
-		PaymentID:         yooPayment.ID,
-		Amount:            finalPrice,
-		Status:            domain.PaymentStatus(yooPayment.Status),
-		PaymentLink:       yooPayment.Confirmation.ConfirmationURL,
 		ConfirmationToken: "",
 		UserID:            user.ID,
 		EventID:           eventID,
+		OriginalAmount:    &originalPrice,
+	}
+	if promo != nil {
+		createPayment.PromoCodeID = &promo.ID
 	}
 
-	return p.CreatePayment(ctx, createPayment)
+	var paymentID string
+	var confirmedUserIDs []string
+	create := func(txCtx context.Context) error {
+		if promo != nil {
+			if err := p.cases.PromoCode.ReserveUse(txCtx, promo, user); err != nil {
+				return err
+			}
+		}
+
+		// Скидка покрыла всю цену: платить провайдеру нечего, регистрация подтверждается сразу
+		if finalPrice == 0 {
+			createPayment.PaymentID = "free-" + uuid.New().String()
+			createPayment.Status = domain.PaymentStatusSucceeded
+			createPayment.PaymentLink = returnURL
+
+			id, err := p.paymentRepo.Create(txCtx, createPayment)
+			if err != nil {
+				return fmt.Errorf("failed to create payment: %w", err)
+			}
+			paymentID = id
+
+			confirmedUserIDs, err = p.confirmPaidRegistration(txCtx, &domain.Payment{
+				ID:        id,
+				PaymentID: createPayment.PaymentID,
+				Status:    createPayment.Status,
+				UserID:    user.ID,
+				EventID:   eventID,
+			})
+			return err
+		}
+
+		// Ключ зависит от номера попытки оплаты регистрации и суммы: повтор того же запроса не создаст второй платеж
+		idempotenceKey := payments.IdempotenceKey("payment", user.ID, eventID, strconv.Itoa(len(existingPayments)), strconv.Itoa(finalPrice))
+		providerPayment, err := p.createProviderPayment(txCtx, idempotenceKey, event, user, finalPrice, returnURL)
+		if err != nil {
+			return fmt.Errorf("failed to create provider payment: %w", err)
+		}
+
+		createPayment.PaymentID = providerPayment.ID
+		createPayment.Amount = providerPayment.Amount
+		createPayment.Status = providerPayment.Status
+		createPayment.PaymentLink = providerPayment.ConfirmationURL
+
+		paymentID, err = p.paymentRepo.Create(txCtx, createPayment)
+		if err != nil {
+			return fmt.Errorf("failed to create payment: %w", err)
+		}
+		return nil
+	}
+
+	// Лимиты промокода проверяются под блокировкой в одной транзакции с созданием платежа,
+	// бесплатный платеж создается вместе с подтверждением регистрации. Обычный платеж не держит
+	// соединение с БД, пока идет запрос к провайдеру
+	if promo == nil && finalPrice > 0 {
+		err = create(ctx)
+	} else {
+		err = p.tx.WithinTx(ctx, create)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range confirmedUserIDs {
+		p.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, userID)
+	}
+
+	return repo.First(p.paymentRepo.Filter)(ctx, &domain.FilterPayment{ID: &paymentID})
+}
+
+// cancelPendingPayment отменяет у провайдера платеж в waiting_for_capture перед созданием нового
+func (p *Payment) cancelPendingPayment(ctx context.Context, payment *domain.Payment) error {
+	canceledPayment, err := p.provider.CancelPayment(ctx, payment.PaymentID)
+	if err != nil {
+		slog.Warn("Failed to cancel pending payment",
+			"payment_id", payment.PaymentID,
+			"error", err)
+		return ErrPendingPaymentExists
+	}
+
+	return p.ApplyPaymentStatus(ctx, payment, canceledPayment.Status)
+}
+
+// GetPaymentsByPromoCode получает все платежи, к которым был применен промокод
+func (p *Payment) GetPaymentsByPromoCode(ctx context.Context, promoCodeID string) ([]*domain.Payment, error) {
+	return p.paymentRepo.Filter(ctx, &domain.FilterPayment{PromoCodeID: &promoCodeID})
 }
 
 func (p *Payment) findPendingRegistration(ctx context.Context, userID, eventID string) (*domain.Registration, error) {
 	return p.cases.Registration.FindPendingRegistration(ctx, userID, eventID)
 	}
 
-func (p *Payment) createYooKassaPayment(event *domain.Event, user *domain.User, returnURL string) (*YooKassaPaymentResponse, error) {
-	finalPrice := p.calculateFinalPrice(event.Price, user)
-	
+func (p *Payment) createProviderPayment(ctx context.Context, idempotenceKey string, event *domain.Event, user *domain.User, finalPrice int, returnURL string) (*payments.ProviderPayment, error) {
 	if finalPrice <= 0 {
 		return nil, fmt.Errorf("invalid payment amount: %d", finalPrice)
 	}
 
-	customerEmail := p.generateCustomerEmail(user)
-	
-	amountStr := fmt.Sprintf("%d.00", finalPrice)
-
-	paymentData := YooKassaPaymentRequest{
-		Amount: YooKassaAmount{
-			Value:    amountStr,
-			Currency: "RUB",
-		},
-		Confirmation: YooKassaConfirmation{
-			Type:      "redirect",
-			ReturnURL: returnURL,
-		},
-		Capture:     true,
-		Description: fmt.Sprintf("Оплата события `%s`", event.Name),
-		Receipt: YooKassaReceipt{
-			Customer: YooKassaCustomer{
-				Email: customerEmail,
-			},
-			Items: []YooKassaItem{
-				{
-					Description:    "GoPadel Tournament",
-					PaymentSubject: "service",
-					Amount: YooKassaAmount{
-						Value:    amountStr,
-						Currency: "RUB",
-					},
-					VatCode:     1,
-					Quantity:    1,
-					Measure:     "piece",
-					PaymentMode: "full_payment",
-				},
-			},
-		},
-	}
-
-	jsonData, err := json.Marshal(paymentData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payment data: %w", err)
-	}
+	return p.provider.CreatePayment(ctx, &payments.CreatePaymentRequest{
+		IdempotenceKey:  idempotenceKey,
+		Amount:          finalPrice,
+		Description:     fmt.Sprintf("Оплата события `%s`", event.Name),
+		ItemDescription: "GoPadel Tournament",
+		ReturnURL:       returnURL,
+		CustomerEmail:   p.generateCustomerEmail(user),
+	})
+}
 
-	req, err := http.NewRequest("POST", "https://api.yookassa.ru/v3/payments", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+// CreateProviderRefund создает возврат у платежного провайдера по платежу на указанную сумму.
+// Повтор с тем же ключом идемпотентности не создает второй возврат
+func (p *Payment) CreateProviderRefund(ctx context.Context, idempotenceKey, paymentID string, amount int, user *domain.User, description string) (*payments.ProviderRefund, error) {
+	return p.provider.CreateRefund(ctx, &payments.CreateRefundRequest{
+		IdempotenceKey:  idempotenceKey,
+		PaymentID:       paymentID,
+		Amount:          amount,
+		Description:     description,
+		ItemDescription: "GoPadel Tournament",
+		CustomerEmail:   p.generateCustomerEmail(user),
+	})
+}
 
-	idempotencyKey := uuid.New().String()
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Idempotence-Key", idempotencyKey)
-	
-	auth := base64.StdEncoding.EncodeToString([]byte(p.config.YooKassa.ShopID + ":" + p.config.YooKassa.SecretKey))
-	req.Header.Set("Authorization", "Basic "+auth)
+// GetProviderRefund получает актуальное состояние возврата у платежного провайдера
+func (p *Payment) GetProviderRefund(ctx context.Context, refundID string) (*payments.ProviderRefund, error) {
+	return p.provider.GetRefund(ctx, refundID)
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+// SyncPaymentStatus получает актуальный статус платежа у провайдера и применяет его
+// по той же логике переходов, что и вебхук
+func (p *Payment) SyncPaymentStatus(ctx context.Context, paymentID string) error {
+	providerPayment, err := p.provider.GetPayment(ctx, paymentID)
 	if err != nil {
-		slog.Error("Failed to send request to YooKassa", 
-			"error", err.Error(),
-			"shop_id", p.config.YooKassa.ShopID,
-			"amount", amountStr,
-			"email", customerEmail,
-		)
-		return nil, fmt.Errorf("failed to send request to YooKassa: %w", err)
+		return fmt.Errorf("failed to get provider payment: %w", err)
 	}
-	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	payment, err := p.GetPaymentByPaymentID(ctx, paymentID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("payment not found in database: %w", err)
 	}
 
-	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("YooKassa API returned error", 
-			"status_code", resp.StatusCode,
-			"response_body", string(bodyBytes),
-			"shop_id", p.config.YooKassa.ShopID,
-			"amount", amountStr,
-			"email", customerEmail,
-		)
-		return nil, fmt.Errorf("YooKassa API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	return p.ApplyPaymentStatus(ctx, payment, providerPayment.Status)
+}
+
+// calculateFinalPrice считает цену со скидкой лояльности и промокодом.
+// Способ сочетания скидок задается в конфиге (PAYMENT_DISCOUNT_STACKING)
+func (p *Payment) calculateFinalPrice(originalPrice int, user *domain.User, promo *domain.PromoCode) int {
+	loyaltyDiscount := 0
+	if user.Loyalty != nil && user.Loyalty.Discount > 0 {
+		loyaltyDiscount = int(float64(originalPrice)*float64(user.Loyalty.Discount)/100 + 0.5)
 	}
 
-	var paymentResponse YooKassaPaymentResponse
-	if err := json.Unmarshal(bodyBytes, &paymentResponse); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if promo == nil {
+		return originalPrice - loyaltyDiscount
 	}
 
-	slog.Info("YooKassa payment created successfully", 
-		"payment_id", paymentResponse.ID,
-		"status", paymentResponse.Status,
-		"confirmation_url", paymentResponse.Confirmation.ConfirmationURL,
-	)
+	promoDiscount := promo.DiscountAmount(originalPrice)
+
+	discount := 0
+	switch domain.DiscountStacking(p.config.Payments.DiscountStacking) {
+	case domain.DiscountStackingSum:
+		discount = loyaltyDiscount + promoDiscount
+	case domain.DiscountStackingSequential:
+		discount = loyaltyDiscount + promo.DiscountAmount(originalPrice-loyaltyDiscount)
+	case domain.DiscountStackingPromoOnly:
+		discount = promoDiscount
+	default:
+		discount = max(loyaltyDiscount, promoDiscount)
+	}
 
-	return &paymentResponse, nil
+	return max(originalPrice-discount, 0)
 }
 
-func (p *Payment) calculateFinalPrice(originalPrice int, user *domain.User) int {
-	if user.Loyalty == nil {
-		return originalPrice
+// samePromoCode сравнивает промокод платежа с переданным пользователем без учета регистра
+func samePromoCode(paymentPromoCode *string, promoCode string) bool {
+	promoCode = strings.TrimSpace(promoCode)
+	if paymentPromoCode == nil {
+		return promoCode == ""
 	}
-
-	discount := user.Loyalty.Discount
-	if discount <= 0 {
-		return originalPrice
-	}
-
-	finalPrice := float64(originalPrice) * (1 - float64(discount)/100)
-	
-	return int(finalPrice + 0.5)
+	return strings.EqualFold(*paymentPromoCode, promoCode)
 }
 
 func (p *Payment) isValidEmail(email string) bool {
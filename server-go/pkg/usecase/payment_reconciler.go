@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/utils/slogx"
+)
+
+// RunReconciler периодически сверяет зависшие платежи с провайдером на случай потерянных вебхуков
+// и повторяет возвраты, которые провайдер не принял. Запускается один раз, из cmd/server
+func (p *Payment) RunReconciler(ctx context.Context) {
+	log := slogx.FromCtx(ctx)
+
+	interval := p.config.Payments.ReconcileInterval
+	if interval <= 0 {
+		log.Info("payment reconciler disabled")
+		return
+	}
+
+	log.Info("payment reconciler started", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.ReconcileStalePayments(ctx); err != nil {
+				log.Error("payment reconciliation failed", "error", err)
+			}
+			if err := p.cases.Refund.RetryUnsentRefunds(ctx, time.Now().Add(-p.config.Payments.ReconcileStaleAfter)); err != nil {
+				log.Error("refund retry failed", "error", err)
+			}
+		}
+	}
+}
+
+// ReconcileStalePayments применяет актуальный статус провайдера ко всем платежам в pending/waiting_for_capture,
+// созданным раньше ReconcileStaleAfter, и отменяет у провайдера платежи в waiting_for_capture, у которых истекло
+// окно оплаты регистрации. Платежи в pending YooKassa не отменяет: они истекают у провайдера сами,
+// и сверка применяет итоговый статус
+func (p *Payment) ReconcileStalePayments(ctx context.Context) error {
+	statuses := []domain.PaymentStatus{domain.PaymentStatusPending, domain.PaymentStatusWaitingForCapture}
+	createdBefore := time.Now().Add(-p.config.Payments.ReconcileStaleAfter)
+
+	stalePayments, err := p.paymentRepo.Filter(ctx, &domain.FilterPayment{
+		Statuses:      &statuses,
+		CreatedBefore: &createdBefore,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get stale payments: %w", err)
+	}
+
+	if len(stalePayments) == 0 {
+		return nil
+	}
+
+	slog.Info("Reconciling stale payments", "count", len(stalePayments))
+
+	for _, payment := range stalePayments {
+		if err := p.reconcilePayment(ctx, payment); err != nil {
+			slog.Error("Failed to reconcile payment",
+				"payment_id", payment.PaymentID,
+				"user_id", payment.UserID,
+				"event_id", payment.EventID,
+				"error", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Payment) reconcilePayment(ctx context.Context, payment *domain.Payment) error {
+	providerPayment, err := p.provider.GetPayment(ctx, payment.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to get provider payment: %w", err)
+	}
+
+	if err := p.ApplyPaymentStatus(ctx, payment, providerPayment.Status); err != nil {
+		return err
+	}
+
+	if providerPayment.Status != domain.PaymentStatusWaitingForCapture {
+		return nil
+	}
+
+	windowEnd, err := p.paymentWindowEnd(ctx, payment)
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(windowEnd) {
+		return nil
+	}
+
+	slog.Info("Payment window expired, cancelling payment",
+		"payment_id", payment.PaymentID,
+		"status", providerPayment.Status,
+		"created_at", payment.Date,
+		"window_end", windowEnd)
+
+	canceledPayment, err := p.provider.CancelPayment(ctx, payment.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel provider payment: %w", err)
+	}
+
+	return p.ApplyPaymentStatus(ctx, payment, canceledPayment.Status)
+}
+
+// paymentWindowEnd окончание окна оплаты регистрации, к которой относится платеж: срок оплаты регистрации
+// на событие, а если срок не задан — PaymentWindow от создания платежа. Окно не длится дольше начала события
+func (p *Payment) paymentWindowEnd(ctx context.Context, payment *domain.Payment) (time.Time, error) {
+	event, err := p.cases.Event.GetEventByID(ctx, payment.EventID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	windowEnd := payment.Date.Add(p.config.Payments.PaymentWindow)
+	if deadline := p.cases.Registration.paymentDeadline(event); deadline > 0 {
+		registrations, err := p.cases.Registration.GetRegistrationsByUserAndEvent(ctx, payment.UserID, payment.EventID)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to get registration: %w", err)
+		}
+		// Тот же срок, что у задачи event.payment.deadline: от перехода регистрации в ожидание оплаты
+		if len(registrations) > 0 {
+			if dueAt, ok := p.cases.Registration.registrationPaymentDueAt(event, registrations[0]); ok {
+				windowEnd = dueAt
+			}
+		}
+	}
+
+	if event.StartTime.Before(windowEnd) {
+		windowEnd = event.StartTime
+	}
+	return windowEnd, nil
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+var (
+	ErrPromoCodeNotFound      = errors.New("promo code not found")
+	ErrPromoCodeNotApplicable = errors.New("promo code is not applicable")
+)
+
+type PromoCode struct {
+	promoCodeRepo repo.PromoCode
+	cases         *Cases
+}
+
+func NewPromoCode(ctx context.Context, promoCodeRepo repo.PromoCode, cases *Cases) *PromoCode {
+	return &PromoCode{
+		promoCodeRepo: promoCodeRepo,
+		cases:         cases,
+	}
+}
+
+func (p *PromoCode) Create(ctx Context, promoCode *domain.CreatePromoCode) (*domain.PromoCode, error) {
+	promoCode.Code = normalizePromoCode(promoCode.Code)
+	if promoCode.Code == "" {
+		return nil, fmt.Errorf("promo code is empty")
+	}
+
+	if err := validatePromoDiscount(promoCode.DiscountType, promoCode.DiscountValue); err != nil {
+		return nil, err
+	}
+
+	if promoCode.ValidFrom != nil && promoCode.ValidUntil != nil && !promoCode.ValidFrom.Before(*promoCode.ValidUntil) {
+		return nil, fmt.Errorf("validFrom must be before validUntil")
+	}
+
+	existing, err := p.promoCodeRepo.Filter(ctx.Context, &domain.FilterPromoCode{Code: &promoCode.Code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check promo code: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("promo code %s already exists", promoCode.Code)
+	}
+
+	id, err := p.promoCodeRepo.Create(ctx.Context, promoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.GetByID(ctx, id)
+}
+
+func (p *PromoCode) Filter(ctx Context, filter *domain.FilterPromoCode) ([]*domain.PromoCode, error) {
+	return p.promoCodeRepo.Filter(ctx.Context, filter)
+}
+
+func (p *PromoCode) GetByID(ctx Context, id string) (*domain.PromoCode, error) {
+	promoCodes, err := p.promoCodeRepo.Filter(ctx.Context, &domain.FilterPromoCode{ID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+
+	if len(promoCodes) == 0 {
+		return nil, ErrPromoCodeNotFound
+	}
+
+	return promoCodes[0], nil
+}
+
+func (p *PromoCode) Patch(ctx Context, id string, patch *domain.PatchPromoCode) (*domain.PromoCode, error) {
+	current, err := p.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	discountType := current.DiscountType
+	if patch.DiscountType != nil {
+		discountType = *patch.DiscountType
+	}
+	discountValue := current.DiscountValue
+	if patch.DiscountValue != nil {
+		discountValue = *patch.DiscountValue
+	}
+	if err := validatePromoDiscount(discountType, discountValue); err != nil {
+		return nil, err
+	}
+
+	validFrom := current.ValidFrom
+	if patch.ValidFrom != nil {
+		validFrom = patch.ValidFrom
+	}
+	validUntil := current.ValidUntil
+	if patch.ValidUntil != nil {
+		validUntil = patch.ValidUntil
+	}
+	if validFrom != nil && validUntil != nil && !validFrom.Before(*validUntil) {
+		return nil, fmt.Errorf("validFrom must be before validUntil")
+	}
+
+	if err := p.promoCodeRepo.Patch(ctx.Context, id, patch); err != nil {
+		return nil, err
+	}
+
+	return p.GetByID(ctx, id)
+}
+
+// Delete удаляет промокод, если он ни разу не применялся.
+// Примененные промокоды остаются в отчетах по платежам, их можно только деактивировать
+func (p *PromoCode) Delete(ctx Context, id string) error {
+	if _, err := p.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	payments, err := p.cases.Payment.GetPaymentsByPromoCode(ctx.Context, id)
+	if err != nil {
+		return err
+	}
+	if len(payments) > 0 {
+		return fmt.Errorf("promo code is already used in payments, deactivate it instead")
+	}
+
+	return p.promoCodeRepo.Delete(ctx.Context, id)
+}
+
+// ResolvePromoCode находит промокод и проверяет, что его можно применить к оплате события пользователем
+func (p *PromoCode) ResolvePromoCode(ctx context.Context, code string, user *domain.User, event *domain.Event) (*domain.PromoCode, error) {
+	code = normalizePromoCode(code)
+
+	promoCodes, err := p.promoCodeRepo.Filter(ctx, &domain.FilterPromoCode{Code: &code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+
+	if len(promoCodes) == 0 {
+		return nil, ErrPromoCodeNotFound
+	}
+	promoCode := promoCodes[0]
+
+	if !promoCode.IsActive {
+		return nil, fmt.Errorf("%w: promo code is inactive", ErrPromoCodeNotApplicable)
+	}
+
+	now := time.Now()
+	if promoCode.ValidFrom != nil && now.Before(*promoCode.ValidFrom) {
+		return nil, fmt.Errorf("%w: promo code is not active yet", ErrPromoCodeNotApplicable)
+	}
+	if promoCode.ValidUntil != nil && now.After(*promoCode.ValidUntil) {
+		return nil, fmt.Errorf("%w: promo code has expired", ErrPromoCodeNotApplicable)
+	}
+
+	if len(promoCode.EventTypes) > 0 && !slices.Contains(promoCode.EventTypes, event.Type) {
+		return nil, fmt.Errorf("%w: promo code is not valid for this event type", ErrPromoCodeNotApplicable)
+	}
+
+	if len(promoCode.ClubIDs) > 0 && (event.ClubID == nil || !slices.Contains(promoCode.ClubIDs, *event.ClubID)) {
+		return nil, fmt.Errorf("%w: promo code is not valid for this club", ErrPromoCodeNotApplicable)
+	}
+
+	if promoCode.MaxUses != nil && promoCode.UsesCount >= *promoCode.MaxUses {
+		return nil, fmt.Errorf("%w: promo code usage limit reached", ErrPromoCodeNotApplicable)
+	}
+
+	if err := p.checkUserLimit(ctx, promoCode, user); err != nil {
+		return nil, err
+	}
+
+	return promoCode, nil
+}
+
+// ReserveUse проверяет лимиты промокода под блокировкой его строки. Вызывается в транзакции, которая создает
+// платеж с промокодом: конкурентные оплаты ждут ее завершения и видят созданный платеж, поэтому лимит не превышается
+func (p *PromoCode) ReserveUse(ctx context.Context, promoCode *domain.PromoCode, user *domain.User) error {
+	if promoCode.MaxUses == nil && promoCode.MaxUsesPerUser == nil {
+		return nil
+	}
+
+	if err := p.promoCodeRepo.LockForUse(ctx, promoCode.ID); err != nil {
+		return err
+	}
+
+	if promoCode.MaxUses != nil {
+		uses, err := p.promoCodeRepo.CountUses(ctx, promoCode.ID, nil)
+		if err != nil {
+			return err
+		}
+		if uses >= *promoCode.MaxUses {
+			return fmt.Errorf("%w: promo code usage limit reached", ErrPromoCodeNotApplicable)
+		}
+	}
+
+	return p.checkUserLimit(ctx, promoCode, user)
+}
+
+func (p *PromoCode) checkUserLimit(ctx context.Context, promoCode *domain.PromoCode, user *domain.User) error {
+	if promoCode.MaxUsesPerUser == nil {
+		return nil
+	}
+
+	userUses, err := p.promoCodeRepo.CountUses(ctx, promoCode.ID, &user.ID)
+	if err != nil {
+		return err
+	}
+	if userUses >= *promoCode.MaxUsesPerUser {
+		return fmt.Errorf("%w: promo code usage limit per user reached", ErrPromoCodeNotApplicable)
+	}
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validatePromoDiscount(discountType domain.PromoDiscountType, discountValue int) error {
+	switch discountType {
+	case domain.PromoDiscountTypePercent:
+		if discountValue <= 0 || discountValue > 100 {
+			return fmt.Errorf("percent discount must be between 1 and 100")
+		}
+	case domain.PromoDiscountTypeFixed:
+		if discountValue <= 0 {
+			return fmt.Errorf("fixed discount must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown discount type: %s", discountType)
+	}
+	return nil
+}
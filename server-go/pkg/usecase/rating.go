@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+var ErrRankLocked = errors.New("rank is calculated from match results and cannot be changed manually")
+
+// Rating пересчитывает ранг игроков по результатам матчей по модели Эло для пар:
+// сила пары — средний ранг игроков, изменение ранга каждого игрока пропорционально
+// разнице между фактическим и ожидаемым результатом
+type Rating struct {
+	ratingRepo repo.Rating
+	tx         repo.Transactor
+	config     *config.Config
+	cases      *Cases
+}
+
+func NewRating(ctx context.Context, ratingRepo repo.Rating, tx repo.Transactor, cfg *config.Config, cases *Cases) *Rating {
+	return &Rating{
+		ratingRepo: ratingRepo,
+		tx:         tx,
+		config:     cfg,
+		cases:      cases,
+	}
+}
+
+// ApplyMatchResult пересчитывает ранги участников сыгранного матча.
+// При исправлении счета предыдущий пересчет по этому матчу откатывается.
+// Ранги читаются и записываются в одной транзакции под блокировкой строк игроков
+func (r *Rating) ApplyMatchResult(ctx context.Context, eventID string, match *domain.TournamentMatch) error {
+	if match.Score1 == nil || match.Score2 == nil {
+		return fmt.Errorf("match %s has no score", match.ID)
+	}
+
+	return r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		userIDs := append(append([]string{}, match.Team1Players...), match.Team2Players...)
+		if err := r.ratingRepo.LockPlayers(txCtx, userIDs); err != nil {
+			return err
+		}
+
+		return r.applyMatchResult(txCtx, eventID, match)
+	})
+}
+
+// RevertMatchResult откатывает изменения ранга по матчу, например при удалении турнира
+func (r *Rating) RevertMatchResult(ctx context.Context, matchID string) error {
+	return r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		previous, err := r.ratingRepo.Filter(txCtx, &domain.FilterRatingChange{MatchID: &matchID})
+		if err != nil {
+			return fmt.Errorf("failed to get rating changes: %w", err)
+		}
+		if len(previous) == 0 {
+			return nil
+		}
+
+		userIDs := make([]string, 0, len(previous))
+		for _, change := range previous {
+			userIDs = append(userIDs, change.UserID)
+		}
+		if err := r.ratingRepo.LockPlayers(txCtx, userIDs); err != nil {
+			return err
+		}
+
+		if err := r.ratingRepo.DeleteMatchChanges(txCtx, matchID); err != nil {
+			return err
+		}
+
+		slog.Info("Player ratings reverted", "match_id", matchID, "players", len(userIDs))
+		return nil
+	})
+}
+
+func (r *Rating) applyMatchResult(ctx context.Context, eventID string, match *domain.TournamentMatch) error {
+	previous, err := r.ratingRepo.Filter(ctx, &domain.FilterRatingChange{MatchID: &match.ID})
+	if err != nil {
+		return fmt.Errorf("failed to get previous rating changes: %w", err)
+	}
+	previousDelta := make(map[string]float64, len(previous))
+	for _, change := range previous {
+		previousDelta[change.UserID] = change.Delta
+	}
+
+	side1, err := r.getRatedPlayers(ctx, match.Team1Players, previousDelta)
+	if err != nil {
+		return err
+	}
+	side2, err := r.getRatedPlayers(ctx, match.Team2Players, previousDelta)
+	if err != nil {
+		return err
+	}
+
+	actual := 0.5
+	switch {
+	case *match.Score1 > *match.Score2:
+		actual = 1
+	case *match.Score1 < *match.Score2:
+		actual = 0
+	}
+
+	expected := r.expectedScore(averageRank(side1), averageRank(side2))
+
+	changes := make([]*domain.CreateRatingChange, 0, len(side1)+len(side2))
+	changes = append(changes, r.calculateChanges(side1, actual-expected, eventID, match.ID)...)
+	changes = append(changes, r.calculateChanges(side2, expected-actual, eventID, match.ID)...)
+
+	if err := r.ratingRepo.ReplaceMatchChanges(ctx, match.ID, changes); err != nil {
+		return err
+	}
+
+	slog.Info("Player ratings recalculated",
+		"event_id", eventID,
+		"match_id", match.ID,
+		"expected", expected,
+		"actual", actual,
+		"corrected", len(previous) > 0)
+
+	return nil
+}
+
+// RecordAdminChange записывает в историю ручное изменение ранга администратором
+func (r *Rating) RecordAdminChange(ctx context.Context, userID string, oldRank, newRank float64) error {
+	return r.ratingRepo.Create(ctx, &domain.CreateRatingChange{
+		UserID:  userID,
+		Source:  domain.RatingSourceAdmin,
+		OldRank: oldRank,
+		NewRank: newRank,
+	})
+}
+
+// GetHistory возвращает историю ранга пользователя в хронологическом порядке
+func (r *Rating) GetHistory(ctx context.Context, filter *domain.FilterRatingChange) ([]*domain.RatingChange, error) {
+	return r.ratingRepo.Filter(ctx, filter)
+}
+
+// ratedPlayer ранг игрока до матча и количество его рейтинговых матчей без учета текущего
+type ratedPlayer struct {
+	UserID       string
+	Rank         float64
+	RatedMatches int
+}
+
+func (r *Rating) getRatedPlayers(ctx context.Context, userIDs []string, previousDelta map[string]float64) ([]*ratedPlayer, error) {
+	players := make([]*ratedPlayer, 0, len(userIDs))
+	for _, userID := range userIDs {
+		user, err := repo.First(r.cases.User.AdminFilter)(ctx, &domain.FilterUser{ID: &userID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get player %s: %w", userID, err)
+		}
+
+		player := &ratedPlayer{
+			UserID:       user.ID,
+			Rank:         user.Rank,
+			RatedMatches: user.RatedMatches,
+		}
+		if delta, ok := previousDelta[user.ID]; ok {
+			player.Rank -= delta
+			player.RatedMatches--
+		}
+
+		players = append(players, player)
+	}
+
+	if len(players) == 0 {
+		return nil, fmt.Errorf("match side has no players")
+	}
+
+	return players, nil
+}
+
+func (r *Rating) calculateChanges(players []*ratedPlayer, surprise float64, eventID, matchID string) []*domain.CreateRatingChange {
+	changes := make([]*domain.CreateRatingChange, 0, len(players))
+	for _, player := range players {
+		k := r.config.Rating.KFactor
+		if player.RatedMatches < r.config.Rating.ProvisionalMatches {
+			k = r.config.Rating.ProvisionalKFactor
+		}
+
+		newRank := math.Round((player.Rank+k*surprise)*100) / 100
+		newRank = math.Max(0, math.Min(r.config.Rating.MaxRank, newRank))
+
+		changes = append(changes, &domain.CreateRatingChange{
+			UserID:  player.UserID,
+			Source:  domain.RatingSourceMatch,
+			EventID: &eventID,
+			MatchID: &matchID,
+			OldRank: player.Rank,
+			NewRank: newRank,
+		})
+	}
+	return changes
+}
+
+// expectedScore вероятность победы первой пары по формуле Эло
+func (r *Rating) expectedScore(rank1, rank2 float64) float64 {
+	return 1 / (1 + math.Pow(10, (rank2-rank1)/r.config.Rating.Scale))
+}
+
+func averageRank(players []*ratedPlayer) float64 {
+	total := 0.0
+	for _, player := range players {
+		total += player.Rank
+	}
+	return total / float64(len(players))
+}
@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/payments"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+type Refund struct {
+	refundRepo repo.Refund
+	cases      *Cases
+}
+
+func NewRefund(ctx context.Context, refundRepo repo.Refund, cases *Cases) *Refund {
+	return &Refund{
+		refundRepo: refundRepo,
+		cases:      cases,
+	}
+}
+
+// RefundCancelledRegistration оформляет возврат по оплаченной регистрации согласно политике возвратов события.
+// Если по политике возврат не положен, возвращает nil без ошибки.
+func (r *Refund) RefundCancelledRegistration(ctx context.Context, user *domain.User, event *domain.Event) (*domain.Refund, error) {
+	percent := event.RefundPolicy.PercentAt(event.StartTime, time.Now())
+	if percent <= 0 {
+		slog.Info("Refund is not available by event policy",
+			"user_id", user.ID,
+			"event_id", event.ID)
+		return nil, nil
+	}
+
+	return r.refundPayment(ctx, user, event, percent, fmt.Sprintf("registration cancelled, refund %d%%", percent))
+}
+
+// RefundCancelledEvent полностью возвращает оплату участия в событии, отмененном организатором
+func (r *Refund) RefundCancelledEvent(ctx context.Context, user *domain.User, event *domain.Event) (*domain.Refund, error) {
+	return r.refundPayment(ctx, user, event, 100, "event cancelled by organizer")
+}
+
+// RefundDuplicatePayment полностью возвращает лишний платеж: регистрация уже оплачена другим платежом
+func (r *Refund) RefundDuplicatePayment(ctx context.Context, payment *domain.Payment) (*domain.Refund, error) {
+	user, err := repo.First(r.cases.User.AdminFilter)(ctx, &domain.FilterUser{ID: &payment.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	event, err := r.cases.Event.GetEventByID(ctx, payment.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	return r.refund(ctx, user, event, payment, 100, "duplicate payment")
+}
+
+// refundPayment возвращает оплату участия: берется успешный платеж, по которому еще нет возврата
+func (r *Refund) refundPayment(ctx context.Context, user *domain.User, event *domain.Event, percent int, reason string) (*domain.Refund, error) {
+	payments, err := r.cases.Payment.GetPaymentsByUserAndEvent(ctx, user.ID, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+
+	var refunded *domain.Refund
+	for _, payment := range payments {
+		if payment.Status != domain.PaymentStatusSucceeded {
+			continue
+		}
+
+		existing, err := r.activeRefund(ctx, payment)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return r.refund(ctx, user, event, payment, percent, reason)
+		}
+		if refunded == nil {
+			refunded = existing
+		}
+	}
+
+	if refunded == nil {
+		return nil, fmt.Errorf("succeeded payment not found")
+	}
+	return refunded, nil
+}
+
+// activeRefund возврат по платежу, если он уже оформлен и не отменен провайдером
+func (r *Refund) activeRefund(ctx context.Context, payment *domain.Payment) (*domain.Refund, error) {
+	existing, err := r.refundRepo.Filter(ctx, &domain.FilterRefund{PaymentID: &payment.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
+	}
+
+	for _, refund := range existing {
+		if refund.Status != domain.RefundStatusCanceled {
+			return refund, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Refund) refund(ctx context.Context, user *domain.User, event *domain.Event, payment *domain.Payment, percent int, reason string) (*domain.Refund, error) {
+	existing, err := r.activeRefund(ctx, payment)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	amount := (payment.Amount*percent + 50) / 100
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	// Возврат сохраняется до запроса к провайдеру: если провайдер не ответит, сверка платежей повторит его
+	id, err := r.refundRepo.Create(ctx, &domain.CreateRefund{
+		PaymentID: payment.ID,
+		Amount:    amount,
+		Status:    domain.RefundStatusPending,
+		Reason:    reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save refund: %w", err)
+	}
+
+	refund, err := r.getRefund(ctx, &domain.FilterRefund{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.sendRefund(ctx, refund, user, event); err != nil {
+		return refund, err
+	}
+
+	slog.Info("Refund created",
+		"user_id", user.ID,
+		"event_id", event.ID,
+		"refund_id", refund.RefundID,
+		"amount", amount,
+		"percent", percent,
+		"status", refund.Status)
+
+	return refund, nil
+}
+
+// sendRefund отправляет сохраненный возврат провайдеру. Ключ идемпотентности — ID возврата,
+// поэтому повтор после сбоя не вернет деньги дважды. Если провайдер не принял возврат, он помечается failed
+func (r *Refund) sendRefund(ctx context.Context, refund *domain.Refund, user *domain.User, event *domain.Event) error {
+	providerRefund, err := r.cases.Payment.CreateProviderRefund(
+		ctx,
+		payments.IdempotenceKey("refund", refund.ID),
+		refund.ProviderPaymentID,
+		refund.Amount,
+		user,
+		fmt.Sprintf("Возврат за событие `%s`", event.Name),
+	)
+	if err != nil {
+		if refund.Status != domain.RefundStatusFailed {
+			if _, transitionErr := r.refundRepo.TransitionStatus(ctx, refund.ID, []domain.RefundStatus{refund.Status}, domain.RefundStatusFailed); transitionErr != nil {
+				slog.Error("Failed to mark refund as failed", "refund_id", refund.ID, "error", transitionErr)
+			} else {
+				refund.Status = domain.RefundStatusFailed
+			}
+		}
+		return fmt.Errorf("failed to create provider refund: %w", err)
+	}
+
+	sent, err := r.refundRepo.SetProviderRefund(ctx, refund.ID, providerRefund.ID, providerRefund.Status)
+	if err != nil {
+		return fmt.Errorf("failed to save provider refund: %w", err)
+	}
+	if !sent {
+		return nil
+	}
+	refund.RefundID = providerRefund.ID
+	refund.Status = providerRefund.Status
+
+	// Провайдер может сразу вернуть успешный возврат
+	if refund.Status == domain.RefundStatusSucceeded {
+		if err := r.ApplyRefundStatus(ctx, refund, refund.Status); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RetryUnsentRefunds повторяет возвраты, созданные раньше createdBefore и так и не принятые провайдером
+func (r *Refund) RetryUnsentRefunds(ctx context.Context, createdBefore time.Time) error {
+	unsent := true
+	refunds, err := r.refundRepo.Filter(ctx, &domain.FilterRefund{Unsent: &unsent, CreatedBefore: &createdBefore})
+	if err != nil {
+		return fmt.Errorf("failed to get unsent refunds: %w", err)
+	}
+
+	for _, refund := range refunds {
+		if refund.Status != domain.RefundStatusPending && refund.Status != domain.RefundStatusFailed {
+			continue
+		}
+		if err := r.retryRefund(ctx, refund); err != nil {
+			slog.Error("Failed to retry refund",
+				"refund_id", refund.ID,
+				"user_id", refund.UserID,
+				"event_id", refund.EventID,
+				"error", err)
+			continue
+		}
+		slog.Info("Refund retried",
+			"refund_id", refund.ID,
+			"provider_refund_id", refund.RefundID,
+			"status", refund.Status)
+	}
+
+	return nil
+}
+
+func (r *Refund) retryRefund(ctx context.Context, refund *domain.Refund) error {
+	user, err := repo.First(r.cases.User.AdminFilter)(ctx, &domain.FilterUser{ID: &refund.UserID})
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	event, err := r.cases.Event.GetEventByID(ctx, refund.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+
+	return r.sendRefund(ctx, refund, user, event)
+}
+
+// ApplyRefundStatus сохраняет статус возврата и переводит регистрацию в REFUNDED после успешного возврата.
+// Повторные и запоздавшие уведомления не меняют финальный статус
+func (r *Refund) ApplyRefundStatus(ctx context.Context, refund *domain.Refund, status domain.RefundStatus) error {
+	if refund.Status != status {
+		changed, err := r.refundRepo.TransitionStatus(ctx, refund.ID, domain.RefundStatusesBefore(status), status)
+		if err != nil {
+			return fmt.Errorf("failed to update refund status: %w", err)
+		}
+
+		if !changed {
+			slog.Warn("Refund status transition skipped",
+				"refund_id", refund.RefundID,
+				"current_status", refund.Status,
+				"new_status", status)
+			return nil
+		}
+		refund.Status = status
+	}
+
+	if refund.Status != domain.RefundStatusSucceeded {
+		return nil
+	}
+
+	_, err := r.cases.Registration.TransitionRegistrationStatus(
+		ctx,
+		refund.UserID,
+		refund.EventID,
+		[]domain.RegistrationStatus{domain.RegistrationStatusCancelledAfterPayment},
+		domain.RegistrationStatusRefunded,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update registration status: %w", err)
+	}
+
+	// Возврат уменьшает сумму оплат, уровень лояльности может понизиться
+	r.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, refund.UserID)
+
+	return nil
+}
+
+func (r *Refund) GetRefundByRefundID(ctx context.Context, refundID string) (*domain.Refund, error) {
+	return r.getRefund(ctx, &domain.FilterRefund{RefundID: &refundID})
+}
+
+func (r *Refund) GetRefundsByUserAndEvent(ctx context.Context, userID, eventID string) ([]*domain.Refund, error) {
+	return r.refundRepo.Filter(ctx, &domain.FilterRefund{UserID: &userID, EventID: &eventID})
+}
+
+func (r *Refund) getRefund(ctx context.Context, filter *domain.FilterRefund) (*domain.Refund, error) {
+	refunds, err := r.refundRepo.Filter(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+
+	if len(refunds) == 0 {
+		return nil, fmt.Errorf("refund not found")
+	}
+
+	return refunds[0], nil
+}
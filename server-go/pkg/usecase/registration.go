@@ -2,23 +2,32 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/shampsdev/go-telegram-template/pkg/config"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
 	"github.com/shampsdev/go-telegram-template/pkg/repo"
 )
 
 type Registration struct {
-	registrationRepo repo.Registration
-	cases            *Cases
+	registrationRepo    repo.Registration
+	tx                  repo.Transactor
+	notificationService *notifications.NotificationService
+	config              *config.Config
+	cases               *Cases
 }
 
-func NewRegistration(ctx context.Context, registrationRepo repo.Registration, cases *Cases) *Registration {
+func NewRegistration(ctx context.Context, registrationRepo repo.Registration, tx repo.Transactor, notificationService *notifications.NotificationService, cfg *config.Config, cases *Cases) *Registration {
 	return &Registration{
-		registrationRepo: registrationRepo,
-		cases:            cases,
+		registrationRepo:    registrationRepo,
+		tx:                  tx,
+		notificationService: notificationService,
+		config:              cfg,
+		cases:               cases,
 	}
 }
 
@@ -57,9 +66,11 @@ func (r *Registration) AdminUpdateRegistrationStatus(ctx context.Context, userID
 	currentRegistration := currentRegistrations[0]
 	oldStatus := currentRegistration.Status
 
-	// Если переводим в статус CONFIRMED, проверяем свободные места
-	if status == domain.RegistrationStatusConfirmed && oldStatus != domain.RegistrationStatusConfirmed {
-		// Получаем событие для проверки мест
+	// Решение по заявке на игру (INVITED) сообщается участнику, для этого и для проверки мест нужно событие
+	decided := oldStatus == domain.RegistrationStatusInvited &&
+		(status == domain.RegistrationStatusConfirmed || status == domain.RegistrationStatusCancelled)
+	var event *domain.Event
+	if decided || (status == domain.RegistrationStatusConfirmed && oldStatus != domain.RegistrationStatusConfirmed) {
 		eventFilter := &domain.FilterEvent{ID: &eventID}
 		events, err := r.cases.Event.Filter(ctx, eventFilter)
 		if err != nil {
@@ -70,24 +81,36 @@ func (r *Registration) AdminUpdateRegistrationStatus(ctx context.Context, userID
 			return nil, fmt.Errorf("event not found")
 		}
 
-		event := events[0]
+		event = events[0]
+	}
 
-		// Для игр проверяем свободные места (так как INVITED не занимает места, а CONFIRMED занимает)
-		if event.Type == domain.EventTypeGame {
-			if err := r.validateAvailableSlots(ctx, eventID); err != nil {
-				return nil, fmt.Errorf("cannot approve registration: %w", err)
+	// Если переводим игру в статус CONFIRMED, проверяем свободные места (так как INVITED не занимает места, а CONFIRMED занимает)
+	occupiesSeat := event != nil && event.Type == domain.EventTypeGame &&
+		status == domain.RegistrationStatusConfirmed && oldStatus != domain.RegistrationStatusConfirmed
+
+	// Обновляем статус вместе с уведомлением о решении
+	err = r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if occupiesSeat {
+			if err := r.occupySeat(txCtx, userID, eventID, status); err != nil {
+				return fmt.Errorf("cannot approve registration: %w", err)
+			}
+		} else {
+			patch := &domain.PatchRegistration{
+				Status: &status,
 			}
-		}
-	}
 
-	// Обновляем статус
-	patch := &domain.PatchRegistration{
-		Status: &status,
-	}
+			if err := r.registrationRepo.Patch(txCtx, userID, eventID, patch); err != nil {
+				return fmt.Errorf("failed to update registration status: %w", err)
+			}
+		}
 
-	err = r.registrationRepo.Patch(ctx, userID, eventID, patch)
+		if decided && currentRegistration.User != nil {
+			return r.scheduleDecisionNotifications(txCtx, event, currentRegistration.User, status)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update registration status: %w", err)
+		return nil, err
 	}
 
 	// Обновляем статус события после изменения регистрации
@@ -102,6 +125,10 @@ func (r *Registration) AdminUpdateRegistrationStatus(ctx context.Context, userID
 		}
 	}
 
+	if status != oldStatus {
+		r.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, userID)
+	}
+
 	err = r.cases.Event.TryRegisterFromWaitlist(ctx, eventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to try register from waitlist: %w", err)
@@ -142,6 +169,12 @@ func (r *Registration) GetRegistrationWithPayments(ctx context.Context, userID s
 
 // RegisterForEvent - регистрация на событие с использованием стратегий
 func (r *Registration) RegisterForEvent(ctx context.Context, user *domain.User, eventID string) (*domain.Registration, error) {
+	return r.registerForEvent(ctx, user, eventID, nil)
+}
+
+// registerForEvent регистрация на событие. inTx, если задан, выполняется в транзакции регистрации
+// после того, как место занято, и его ошибка отменяет регистрацию
+func (r *Registration) registerForEvent(ctx context.Context, user *domain.User, eventID string, inTx func(context.Context) error) (*domain.Registration, error) {
 	slog.Info("User attempting to register for event",
 		"user_id", user.ID,
 		"user_telegram_id", user.TelegramID,
@@ -236,7 +269,17 @@ func (r *Registration) RegisterForEvent(ctx context.Context, user *domain.User,
 				Status: &newStatus,
 			}
 
-			err := r.registrationRepo.Patch(ctx, reg.UserID, reg.EventID, patch)
+			err := r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+				if err := r.registrationRepo.Patch(txCtx, reg.UserID, reg.EventID, patch); err != nil {
+					return err
+				}
+				if inTx != nil {
+					if err := inTx(txCtx); err != nil {
+						return err
+					}
+				}
+				return r.scheduleRegistrationNotifications(txCtx, event, user, newStatus)
+			})
 			if err != nil {
 				slog.Error("Failed to update registration status",
 					"user_id", user.ID,
@@ -270,20 +313,22 @@ func (r *Registration) RegisterForEvent(ctx context.Context, user *domain.User,
 				return nil, fmt.Errorf("user cancelled registration after payment. Use reactivation endpoint")
 			case domain.RegistrationStatusCancelledBeforePayment, domain.RegistrationStatusRefunded:
 				// Можем восстановить регистрацию
-				if err := r.validateAvailableSlots(ctx, eventID); err != nil {
-					return nil, err
-				}
-
 				// Определяем новый статус с использованием стратегии с учетом пользователя
 				newStatus := strategy.DetermineRegistrationStatusForUser(ctx, event, user)
 
-				patch := &domain.PatchRegistration{
-					Status: &newStatus,
-				}
-
-				err := r.registrationRepo.Patch(ctx, reg.UserID, reg.EventID, patch)
+				err := r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+					if err := r.occupySeat(txCtx, reg.UserID, reg.EventID, newStatus); err != nil {
+						return err
+					}
+					if inTx != nil {
+						if err := inTx(txCtx); err != nil {
+							return err
+						}
+					}
+					return r.scheduleRegistrationNotifications(txCtx, event, user, newStatus)
+				})
 				if err != nil {
-					return nil, fmt.Errorf("failed to update registration status: %w", err)
+					return nil, err
 				}
 
 				// Обновляем статус события после восстановления регистрации
@@ -310,24 +355,6 @@ func (r *Registration) RegisterForEvent(ctx context.Context, user *domain.User,
 		"event_type", event.Type,
 		"is_organizer", user.ID == event.Organizer.ID)
 
-	// Проверяем доступные слоты только если статус регистрации занимает место
-	// Для игр INVITED статус не занимает места, поэтому проверка не нужна
-	// Для организаторов статус CONFIRMED занимает место, но они имеют приоритет
-	if status == domain.RegistrationStatusPending || (status == domain.RegistrationStatusConfirmed && user.ID != event.Organizer.ID) {
-		slog.Info("Checking available slots for registration",
-			"user_id", user.ID,
-			"event_id", eventID,
-			"status", status)
-		if err := r.validateAvailableSlots(ctx, eventID); err != nil {
-			slog.Warn("Registration failed - no available slots",
-				"user_id", user.ID,
-				"event_id", eventID,
-				"status", status,
-				"error", err)
-			return nil, err
-		}
-	}
-
 	// Создаем регистрацию
 	createReg := &domain.CreateRegistration{
 		UserID:  user.ID,
@@ -340,7 +367,32 @@ func (r *Registration) RegisterForEvent(ctx context.Context, user *domain.User,
 		"event_id", eventID,
 		"status", status)
 
-	err = r.registrationRepo.Create(ctx, createReg)
+	// Место проверяется и занимается атомарно только если статус регистрации занимает место
+	// Для игр INVITED статус не занимает места, поэтому проверка не нужна
+	// Для организаторов статус CONFIRMED занимает место, но они имеют приоритет
+	err = r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if status == domain.RegistrationStatusPending || (status == domain.RegistrationStatusConfirmed && user.ID != event.Organizer.ID) {
+			if err := r.registrationRepo.OccupySeats(txCtx, eventID, []*domain.CreateRegistration{createReg}); err != nil {
+				return err
+			}
+		} else if err := r.registrationRepo.Create(txCtx, createReg); err != nil {
+			return err
+		}
+		if inTx != nil {
+			if err := inTx(txCtx); err != nil {
+				return err
+			}
+		}
+		return r.scheduleRegistrationNotifications(txCtx, event, user, status)
+	})
+	if errors.Is(err, repo.ErrEventFull) {
+		slog.Warn("Registration failed - no available slots",
+			"user_id", user.ID,
+			"event_id", eventID,
+			"status", status,
+			"error", err)
+		return nil, err
+	}
 	if err != nil {
 		slog.Error("Failed to create registration",
 			"user_id", user.ID,
@@ -363,10 +415,79 @@ func (r *Registration) RegisterForEvent(ctx context.Context, user *domain.User,
 			"error", err)
 	}
 
+	if status == domain.RegistrationStatusConfirmed {
+		r.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, user.ID)
+	}
 	// Возвращаем созданную регистрацию
 	return r.getRegistrationByID(ctx, user.ID, eventID)
 }
 
+// RegisterPair регистрирует пару игроков вместе: оба места проверяются и занимаются одновременно.
+// Статус каждой регистрации определяется стратегией события, ранг проверяется на уровне пары
+func (r *Registration) RegisterPair(ctx context.Context, event *domain.Event, members []*domain.User) error {
+	for _, member := range members {
+		registrations, err := r.GetRegistrationsByUserAndEvent(ctx, member.ID, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check existing registrations: %w", err)
+		}
+
+		for _, reg := range registrations {
+			switch reg.Status {
+			case domain.RegistrationStatusPending,
+				domain.RegistrationStatusConfirmed,
+				domain.RegistrationStatusInvited,
+				domain.RegistrationStatusCancelledAfterPayment:
+				return fmt.Errorf("user %s %s is already registered for this event", member.FirstName, member.LastName)
+			}
+		}
+	}
+
+	// Оба места занимаются одной транзакцией, отмененная ранее регистрация переиспользуется,
+	// как и при одиночной повторной заявке
+	strategy := GetEventStrategy(event.Type)
+	registrations := make([]*domain.CreateRegistration, 0, len(members))
+	for _, member := range members {
+		registrations = append(registrations, &domain.CreateRegistration{
+			UserID:  member.ID,
+			EventID: event.ID,
+			Status:  strategy.DetermineRegistrationStatusForUser(ctx, event, member),
+		})
+	}
+	err := r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if err := r.registrationRepo.OccupySeats(txCtx, event.ID, registrations); err != nil {
+			return err
+		}
+		for i, member := range members {
+			if err := r.scheduleRegistrationNotifications(txCtx, event, member, registrations[i].Status); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, registration := range registrations {
+		slog.Info("Registered pair member",
+			"user_id", registration.UserID,
+			"event_id", event.ID,
+			"status", registration.Status)
+
+		if registration.Status == domain.RegistrationStatusConfirmed {
+			r.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, registration.UserID)
+		}
+	}
+
+	if err := r.updateEventStatusAfterRegistration(ctx, event.ID); err != nil {
+		slog.Warn("Failed to update event status after pair registration",
+			"event_id", event.ID,
+			"error", err)
+	}
+
+	return nil
+}
+
 // CancelEventRegistration - отмена регистрации на событие
 func (r *Registration) CancelEventRegistration(ctx context.Context, user *domain.User, eventID string) (*domain.Registration, error) {
 	slog.Info("User attempting to cancel registration",
@@ -421,6 +542,9 @@ func (r *Registration) CancelEventRegistration(ctx context.Context, user *domain
 			"user_id", user.ID,
 			"event_id", eventID,
 			"current_status", registration.Status)
+		if err := r.cases.RegistrationPair.FinishPairCancellation(ctx, user, event); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("registration is already cancelled")
 	}
 
@@ -455,7 +579,12 @@ func (r *Registration) CancelEventRegistration(ctx context.Context, user *domain
 		Status: &newStatus,
 	}
 
-	err = r.registrationRepo.Patch(ctx, registration.UserID, registration.EventID, patch)
+	err = r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if err := r.registrationRepo.Patch(txCtx, registration.UserID, registration.EventID, patch); err != nil {
+			return err
+		}
+		return r.scheduleCancellationNotifications(txCtx, event, user)
+	})
 	if err != nil {
 		slog.Error("Failed to update registration status during cancellation",
 			"user_id", user.ID,
@@ -471,6 +600,33 @@ func (r *Registration) CancelEventRegistration(ctx context.Context, user *domain
 		"final_status", newStatus,
 		"has_paid", hasPaid)
 
+	// Для оплаченных регистраций оформляем возврат согласно политике возвратов события
+	if hasPaid {
+		refund, err := r.cases.Refund.RefundCancelledRegistration(ctx, user, event)
+		if err != nil {
+			slog.Error("Failed to refund payment after cancellation",
+				"user_id", user.ID,
+				"event_id", eventID,
+				"error", err)
+		} else if refund != nil {
+			slog.Info("Refund requested after cancellation",
+				"user_id", user.ID,
+				"event_id", eventID,
+				"refund_id", refund.RefundID,
+				"refund_status", refund.Status)
+		}
+	}
+
+	// Пара снимается целиком: вместе с пользователем отменяется регистрация партнера.
+	// При ошибке пара остается зарегистрированной, и повторная отмена снимет партнера
+	if err := r.cases.RegistrationPair.CancelPairWithMember(ctx, user, event); err != nil {
+		slog.Error("Failed to cancel partner registration",
+			"user_id", user.ID,
+			"event_id", eventID,
+			"error", err)
+		return nil, err
+	}
+
 	// Обновляем статус события после отмены регистрации
 	// Только если регистрация была активной (занимала место)
 	wasActive := registration.Status == domain.RegistrationStatusPending || 
@@ -507,6 +663,67 @@ func (r *Registration) CancelEventRegistration(ctx context.Context, user *domain
 	return r.getRegistrationByID(ctx, registration.UserID, registration.EventID)
 }
 
+// CancelRegistrationsForCancelledEvent отменяет все активные регистрации на событие, отмененное организатором.
+// Оплаченное участие возвращается полностью, независимо от политики возвратов; лист ожидания не продвигается.
+// Возвращает отмененные регистрации для уведомления участников
+func (r *Registration) CancelRegistrationsForCancelledEvent(ctx context.Context, event *domain.Event) ([]*domain.Registration, error) {
+	registrations, err := r.GetEventRegistrations(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event registrations: %w", err)
+	}
+
+	strategy := GetEventStrategy(event.Type)
+	cancelled := make([]*domain.Registration, 0, len(registrations))
+	for _, registration := range registrations {
+		if registration.Status != domain.RegistrationStatusPending &&
+			registration.Status != domain.RegistrationStatusConfirmed &&
+			registration.Status != domain.RegistrationStatusInvited {
+			continue
+		}
+
+		hasPaid := false
+		if registration.Status == domain.RegistrationStatusConfirmed {
+			payments, err := r.cases.Payment.GetPaymentsByUserAndEvent(ctx, registration.UserID, event.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get payments: %w", err)
+			}
+			for _, payment := range payments {
+				if payment.Status == domain.PaymentStatusSucceeded {
+					hasPaid = true
+					break
+				}
+			}
+		}
+
+		newStatus := strategy.HandleCancellation(ctx, registration, event, hasPaid)
+		err := r.registrationRepo.Patch(ctx, registration.UserID, event.ID, &domain.PatchRegistration{Status: &newStatus})
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel registration: %w", err)
+		}
+
+		slog.Info("Registration cancelled together with event",
+			"user_id", registration.UserID,
+			"event_id", event.ID,
+			"old_status", registration.Status,
+			"new_status", newStatus,
+			"has_paid", hasPaid)
+
+		if hasPaid && registration.User != nil {
+			if _, err := r.cases.Refund.RefundCancelledEvent(ctx, registration.User, event); err != nil {
+				slog.Error("Failed to refund payment for cancelled event",
+					"user_id", registration.UserID,
+					"event_id", event.ID,
+					"error", err)
+			}
+		}
+
+		registration.Status = newStatus
+		cancelled = append(cancelled, registration)
+	}
+
+	return cancelled, nil
+}
+
 // ReactivateRegistration - реактивация отмененной регистрации
 func (r *Registration) ReactivateRegistration(ctx context.Context, user *domain.User, eventID string) (*domain.Registration, error) {
 	// Получаем событие напрямую через репозиторий
@@ -539,16 +756,24 @@ func (r *Registration) ReactivateRegistration(ctx context.Context, user *domain.
 		return nil, fmt.Errorf("can only reactivate registrations cancelled after payment")
 	}
 
-	if err := r.validateAvailableSlots(ctx, eventID); err != nil {
-		return nil, err
+	// После оформления возврата реактивация невозможна
+	refunds, err := r.cases.Refund.GetRefundsByUserAndEvent(ctx, user.ID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
 	}
-
-	confirmedStatus := domain.RegistrationStatusConfirmed
-	patch := &domain.PatchRegistration{
-		Status: &confirmedStatus,
+	for _, refund := range refunds {
+		if refund.Status != domain.RefundStatusCanceled {
+			return nil, fmt.Errorf("registration payment has been refunded")
+		}
 	}
 
-	err = r.registrationRepo.Patch(ctx, registration.UserID, registration.EventID, patch)
+	// Напоминания были сняты при отмене, ставим их заново
+	err = r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if err := r.occupySeat(txCtx, registration.UserID, registration.EventID, domain.RegistrationStatusConfirmed); err != nil {
+			return err
+		}
+		return r.scheduleRegistrationNotifications(txCtx, event, user, domain.RegistrationStatusConfirmed)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to reactivate registration: %w", err)
 	}
@@ -558,6 +783,8 @@ func (r *Registration) ReactivateRegistration(ctx context.Context, user *domain.
 		fmt.Printf("Warning: failed to update event status after reactivation: %v\n", err)
 	}
 
+	r.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, registration.UserID)
+
 	return r.getRegistrationByID(ctx, registration.UserID, registration.EventID)
 }
 
@@ -594,19 +821,19 @@ func (r *Registration) ActivateRegistration(ctx context.Context, user *domain.Us
 	// Для турниров PENDING уже занимает место, поэтому проверка не нужна
 	// Для игр INVITED не занимает место, поэтому нужна проверка
 	if registration.Status == domain.RegistrationStatusInvited {
-		if err := r.validateAvailableSlots(ctx, eventID); err != nil {
+		if err := r.occupySeat(ctx, registration.UserID, registration.EventID, domain.RegistrationStatusConfirmed); err != nil {
 			return nil, fmt.Errorf("cannot activate registration: %w", err)
 		}
-	}
-
-	confirmedStatus := domain.RegistrationStatusConfirmed
-	patch := &domain.PatchRegistration{
-		Status: &confirmedStatus,
-	}
+	} else {
+		confirmedStatus := domain.RegistrationStatusConfirmed
+		patch := &domain.PatchRegistration{
+			Status: &confirmedStatus,
+		}
 
-	err = r.registrationRepo.Patch(ctx, registration.UserID, registration.EventID, patch)
-	if err != nil {
-		return nil, fmt.Errorf("failed to activate registration: %w", err)
+		err = r.registrationRepo.Patch(ctx, registration.UserID, registration.EventID, patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to activate registration: %w", err)
+		}
 	}
 
 	// Обновляем статус события после активации регистрации
@@ -614,6 +841,8 @@ func (r *Registration) ActivateRegistration(ctx context.Context, user *domain.Us
 		fmt.Printf("Warning: failed to update event status after activation: %v\n", err)
 	}
 
+	r.cases.Loyalty.RecalculateUserLoyaltyAsync(ctx, registration.UserID)
+
 	return r.getRegistrationByID(ctx, registration.UserID, registration.EventID)
 }
 
@@ -748,7 +977,20 @@ func (r *Registration) validateUserRank(user *domain.User, event *domain.Event)
 	return nil
 }
 
-func (r *Registration) validateAvailableSlots(ctx context.Context, eventID string) error {
+// occupySeat переводит регистрацию в статус, занимающий место. Свободные места проверяются под блокировкой
+// события, поэтому последнее место не достанется двум пользователям одновременно
+func (r *Registration) occupySeat(ctx context.Context, userID, eventID string, status domain.RegistrationStatus) error {
+	return r.registrationRepo.OccupySeats(ctx, eventID, []*domain.CreateRegistration{{
+		UserID:  userID,
+		EventID: eventID,
+		Status:  status,
+	}})
+}
+
+// validateAvailableSeats проверяет, что на событии есть seats свободных мест (пара занимает два места сразу).
+// Места, удерживаемые предложениями из листа ожидания, заняты для всех, кроме userIDs. Проверка без блокировки,
+// сами места занимаются через occupySeat / registrationRepo.OccupySeats
+func (r *Registration) validateAvailableSeats(ctx context.Context, eventID string, seats int, userIDs ...string) error {
 	eventFilter := &domain.FilterEvent{ID: &eventID}
 	events, err := r.cases.Event.Filter(ctx, eventFilter)
 	if err != nil {
@@ -772,27 +1014,21 @@ func (r *Registration) validateAvailableSlots(ctx context.Context, eventID strin
 
 	activeCount := 0
 	for _, reg := range registrations {
-		// Для турниров: PENDING и CONFIRMED занимают места
+		// Для турниров и тренировок: PENDING и CONFIRMED занимают места
 		// Для игр: только CONFIRMED занимает место, INVITED не занимает место
-		if event.Type == domain.EventTypeTournament {
-			if reg.Status == domain.RegistrationStatusPending || reg.Status == domain.RegistrationStatusConfirmed {
-				activeCount++
-			}
-		} else if event.Type == domain.EventTypeGame {
-			if reg.Status == domain.RegistrationStatusConfirmed {
-				activeCount++
-			}
-			// INVITED статус НЕ занимает место
-		} else {
-			// Для других типов используем старую логику
-			if reg.Status == domain.RegistrationStatusPending || reg.Status == domain.RegistrationStatusConfirmed {
-				activeCount++
-			}
+		if reg.Status.OccupiesSeat(event.Type) {
+			activeCount++
 		}
 	}
 
-	if activeCount >= event.MaxUsers {
-		return fmt.Errorf("event is full (maximum %d users)", event.MaxUsers)
+	held, err := r.cases.WaitlistOffer.HeldSeats(ctx, eventID, userIDs...)
+	if err != nil {
+		return err
+	}
+	activeCount += held
+
+	if activeCount+seats > event.MaxUsers {
+		return fmt.Errorf("%w (maximum %d users)", repo.ErrEventFull, event.MaxUsers)
 	}
 
 	return nil
@@ -807,6 +1043,12 @@ func (r *Registration) UpdateRegistrationStatus(ctx context.Context, userID, eve
 	return r.registrationRepo.Patch(ctx, userID, eventID, patch)
 }
 
+// TransitionRegistrationStatus переводит регистрацию в status, только если текущий статус входит в from.
+// Возвращает false, если регистрация уже была переведена ранее
+func (r *Registration) TransitionRegistrationStatus(ctx context.Context, userID, eventID string, from []domain.RegistrationStatus, status domain.RegistrationStatus) (bool, error) {
+	return r.registrationRepo.TransitionStatus(ctx, userID, eventID, from, status)
+}
+
 // FindPendingRegistration находит ожидающую регистрацию пользователя на событие
 func (r *Registration) FindPendingRegistration(ctx context.Context, userID, eventID string) (*domain.Registration, error) {
 	pendingStatus := domain.RegistrationStatusPending
@@ -1002,22 +1244,10 @@ func (r *Registration) GetActiveRegistrationsCount(ctx context.Context, eventID
 
 	activeCount := 0
 	for _, reg := range registrations {
-		// Для турниров: PENDING и CONFIRMED занимают места
+		// Для турниров и тренировок: PENDING и CONFIRMED занимают места
 		// Для игр: только CONFIRMED занимает место, INVITED не занимает место
-		if event.Type == domain.EventTypeTournament {
-			if reg.Status == domain.RegistrationStatusPending || reg.Status == domain.RegistrationStatusConfirmed {
-				activeCount++
-			}
-		} else if event.Type == domain.EventTypeGame {
-			if reg.Status == domain.RegistrationStatusConfirmed {
-				activeCount++
-			}
-			// INVITED статус НЕ занимает место
-		} else {
-			// Для других типов используем старую логику
-			if reg.Status == domain.RegistrationStatusPending || reg.Status == domain.RegistrationStatusConfirmed {
-				activeCount++
-			}
+		if reg.Status.OccupiesSeat(event.Type) {
+			activeCount++
 		}
 	}
 
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+var (
+	ErrRegistrationNotFound = errors.New("registration not found")
+	ErrNotGameEvent         = errors.New("approve/reject functionality is only available for games")
+	ErrDecisionForbidden    = errors.New("registration decision is not allowed")
+)
+
+// ConfirmAttendance подтверждение участия кнопкой «Я приду» из напоминания. Статус регистрации
+// не меняется, проверяется только, что она еще активна
+func (r *Registration) ConfirmAttendance(ctx context.Context, user *domain.User, eventID string) (*domain.Registration, error) {
+	registrations, err := r.GetRegistrationsByUserAndEvent(ctx, user.ID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration: %w", err)
+	}
+
+	for _, registration := range registrations {
+		switch registration.Status {
+		case domain.RegistrationStatusPending, domain.RegistrationStatusInvited, domain.RegistrationStatusConfirmed:
+			slog.Info("User confirmed attendance",
+				"user_id", user.ID,
+				"event_id", eventID,
+				"status", registration.Status)
+			return registration, nil
+		}
+	}
+	return nil, ErrRegistrationNotFound
+}
+
+// ApproveGameRegistration организатор принимает заявку на игру, проверки те же, что у PUT /registrations/{event_id}/{user_id}/approve
+func (r *Registration) ApproveGameRegistration(ctx context.Context, organizer *domain.User, userID, eventID string) (*domain.RegistrationWithPayments, error) {
+	event, registration, err := r.gameRegistration(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := (&GameEventStrategy{}).CanApprove(organizer, event, registration); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecisionForbidden, err)
+	}
+	return r.AdminUpdateRegistrationStatus(ctx, userID, eventID, domain.RegistrationStatusConfirmed)
+}
+
+// RejectGameRegistration организатор отклоняет заявку на игру, проверки те же, что у PUT /registrations/{event_id}/{user_id}/reject
+func (r *Registration) RejectGameRegistration(ctx context.Context, organizer *domain.User, userID, eventID string) (*domain.RegistrationWithPayments, error) {
+	event, registration, err := r.gameRegistration(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := (&GameEventStrategy{}).CanReject(organizer, event, registration); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecisionForbidden, err)
+	}
+	return r.AdminUpdateRegistrationStatus(ctx, userID, eventID, domain.RegistrationStatusCancelled)
+}
+
+func (r *Registration) gameRegistration(ctx context.Context, userID, eventID string) (*domain.Event, *domain.Registration, error) {
+	events, err := r.cases.Event.Filter(ctx, &domain.FilterEvent{ID: &eventID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, nil, ErrRegistrationNotFound
+	}
+	event := events[0]
+	if event.Type != domain.EventTypeGame {
+		return nil, nil, ErrNotGameEvent
+	}
+
+	registrations, err := r.GetRegistrationsByUserAndEvent(ctx, userID, eventID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get registration: %w", err)
+	}
+	if len(registrations) == 0 {
+		return nil, nil, ErrRegistrationNotFound
+	}
+	return event, registrations[0], nil
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+// Напоминания о событии перед началом
+const (
+	reminder48HoursBefore = 48 * time.Hour
+	reminder24HoursBefore = 24 * time.Hour
+)
+
+// scheduleRegistrationNotifications ставит в outbox уведомления о регистрации в статусе status.
+// Заявка на игру (INVITED) места не занимает: организатор получает ее на рассмотрение, а подтверждение
+// и напоминания участник получит, когда заявку примут. Вызывается в транзакции регистрации:
+// если она откатится, уведомления не уйдут
+func (r *Registration) scheduleRegistrationNotifications(ctx context.Context, event *domain.Event, user *domain.User, status domain.RegistrationStatus) error {
+	if r.notificationService == nil {
+		return nil
+	}
+
+	switch status {
+	case domain.RegistrationStatusInvited:
+		if err := r.notificationService.SendEventRegistrationRequested(ctx, event.Organizer.TelegramID, event, user); err != nil {
+			return fmt.Errorf("failed to send registration request to organizer: %w", err)
+		}
+		return nil
+	case domain.RegistrationStatusPending, domain.RegistrationStatusConfirmed:
+	default:
+		return nil
+	}
+
+	isPaid := isRegistrationPaid(event, status)
+	if err := r.notificationService.SendEventRegistrationConfirmed(ctx, user.TelegramID, event, isPaid); err != nil {
+		return fmt.Errorf("failed to send registration notification: %w", err)
+	}
+	if !isPaid {
+		if err := r.schedulePaymentDeadline(ctx, event, user); err != nil {
+			return err
+		}
+	}
+	return r.scheduleReminders(ctx, event, user, isPaid)
+}
+
+// scheduleDecisionNotifications сообщает участнику о решении организатора по заявке на игру.
+// После принятия заявки участнику ставятся напоминания, после отклонения — снимаются
+func (r *Registration) scheduleDecisionNotifications(ctx context.Context, event *domain.Event, user *domain.User, status domain.RegistrationStatus) error {
+	if r.notificationService == nil {
+		return nil
+	}
+
+	if status == domain.RegistrationStatusConfirmed {
+		if err := r.notificationService.SendEventRegistrationApproved(ctx, user.TelegramID, event); err != nil {
+			return fmt.Errorf("failed to send registration approval: %w", err)
+		}
+		return r.scheduleReminders(ctx, event, user, isRegistrationPaid(event, status))
+	}
+
+	if err := r.notificationService.SendEventTasksCancel(ctx, user.TelegramID, event.ID); err != nil {
+		return fmt.Errorf("failed to cancel scheduled reminders: %w", err)
+	}
+	if err := r.notificationService.SendEventRegistrationRejected(ctx, user.TelegramID, event); err != nil {
+		return fmt.Errorf("failed to send registration rejection: %w", err)
+	}
+	return nil
+}
+
+// scheduleCancellationNotifications снимает напоминания об отмененной регистрации и ставит
+// в outbox уведомление об отмене. Вызывается в транзакции отмены
+func (r *Registration) scheduleCancellationNotifications(ctx context.Context, event *domain.Event, user *domain.User) error {
+	if r.notificationService == nil {
+		return nil
+	}
+
+	if err := r.notificationService.SendEventTasksCancel(ctx, user.TelegramID, event.ID); err != nil {
+		return fmt.Errorf("failed to cancel scheduled reminders: %w", err)
+	}
+	if err := r.notificationService.SendEventRegistrationCanceled(ctx, user.TelegramID, event); err != nil {
+		return fmt.Errorf("failed to send cancellation notification: %w", err)
+	}
+
+	return nil
+}
+
+// scheduleReminders ставит напоминания за 48 и 24 часа до начала, если это время еще не прошло
+func (r *Registration) scheduleReminders(ctx context.Context, event *domain.Event, user *domain.User, isPaid bool) error {
+	now := time.Now()
+	if remindAt := event.StartTime.Add(-reminder48HoursBefore); remindAt.After(now) {
+		if err := r.notificationService.SendEventReminder48Hours(ctx, user.TelegramID, event, isPaid, remindAt); err != nil {
+			return fmt.Errorf("failed to schedule 48 hours reminder: %w", err)
+		}
+	}
+	if remindAt := event.StartTime.Add(-reminder24HoursBefore); remindAt.After(now) {
+		if err := r.notificationService.SendEventReminder24Hours(ctx, user.TelegramID, event, isPaid, remindAt); err != nil {
+			return fmt.Errorf("failed to schedule 24 hours reminder: %w", err)
+		}
+	}
+	return nil
+}
+
+// isRegistrationPaid участие не требует оплаты: событие бесплатное или регистрация уже подтверждена
+func isRegistrationPaid(event *domain.Event, status domain.RegistrationStatus) bool {
+	return event.Price == 0 || status == domain.RegistrationStatusConfirmed
+}
@@ -0,0 +1,590 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+var (
+	ErrPairNotFound       = errors.New("registration pair not found")
+	ErrInvalidPair        = errors.New("invalid registration pair")
+	ErrPartnerPaysForPair = errors.New("registration is paid by the pair captain")
+)
+
+// RegistrationPair регистрация на турнир парой: капитан приглашает партнера,
+// после принятия приглашения пара занимает два места одновременно или встает в лист ожидания
+type RegistrationPair struct {
+	pairRepo repo.RegistrationPair
+	bot      *bot.Bot
+	config   *config.Config
+	cases    *Cases
+}
+
+func NewRegistrationPair(ctx context.Context, pairRepo repo.RegistrationPair, cfg *config.Config, b *bot.Bot, cases *Cases) *RegistrationPair {
+	return &RegistrationPair{
+		pairRepo: pairRepo,
+		bot:      b,
+		config:   cfg,
+		cases:    cases,
+	}
+}
+
+// Invite создает пару и отправляет партнеру приглашение в Telegram
+func (p *RegistrationPair) Invite(ctx context.Context, captain *domain.User, eventID string, invite *domain.InvitePartner) (*domain.RegistrationPair, error) {
+	event, err := p.getPairEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	partner, err := p.resolvePartner(ctx, invite)
+	if err != nil {
+		return nil, err
+	}
+
+	if partner.ID == captain.ID {
+		return nil, fmt.Errorf("%w: cannot invite yourself", ErrInvalidPair)
+	}
+
+	for _, member := range []*domain.User{captain, partner} {
+		if err := p.validateFreeForPair(ctx, member, eventID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validatePairRank(captain, partner, event); err != nil {
+		return nil, err
+	}
+
+	paymentMode := invite.PaymentMode
+	if paymentMode == "" {
+		paymentMode = domain.PairPaymentModeSplit
+	}
+
+	pairID, err := p.pairRepo.Create(ctx, &domain.CreateRegistrationPair{
+		EventID:     eventID,
+		CaptainID:   captain.ID,
+		PartnerID:   partner.ID,
+		PaymentMode: paymentMode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registration pair: %w", err)
+	}
+
+	slog.Info("Partner invited to tournament",
+		"pair_id", pairID,
+		"event_id", eventID,
+		"captain_id", captain.ID,
+		"partner_id", partner.ID,
+		"payment_mode", paymentMode)
+
+	paymentNote := "Каждый из вас оплачивает свое участие."
+	if paymentMode == domain.PairPaymentModeCaptain {
+		paymentNote = "Участие пары оплачивает пригласивший."
+	}
+	p.notify(ctx, partner, fmt.Sprintf(`%s %s приглашает вас сыграть в паре на турнире "%s". %s
+Перейдите на <a href="%s">страницу турнира</a>, чтобы принять или отклонить приглашение.`,
+		captain.FirstName, captain.LastName, event.Name, paymentNote, p.eventLink(event.ID)))
+
+	return p.getPair(ctx, pairID)
+}
+
+// Accept принимает приглашение: при наличии двух свободных мест регистрирует пару,
+// иначе ставит ее в лист ожидания целиком
+func (p *RegistrationPair) Accept(ctx context.Context, partner *domain.User, eventID string) (*domain.RegistrationPair, error) {
+	pair, err := p.findPair(ctx, partner.ID, eventID, domain.RegistrationPairStatusInvited)
+	if err != nil {
+		return nil, err
+	}
+	if pair.PartnerID != partner.ID {
+		return nil, fmt.Errorf("%w: only the invited partner can accept the invitation", ErrInvalidPair)
+	}
+
+	event, err := p.getPairEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	captain, err := p.getUser(ctx, pair.CaptainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ранги и регистрации могли измениться с момента приглашения
+	for _, member := range []*domain.User{captain, partner} {
+		if err := p.validateNotRegistered(ctx, member, eventID); err != nil {
+			return nil, err
+		}
+	}
+	if err := validatePairRank(captain, partner, event); err != nil {
+		return nil, err
+	}
+
+	// Пара занимает два места сразу; если их нет, пара встает в лист ожидания целиком
+	if err := p.cases.Registration.validateAvailableSeats(ctx, eventID, 2, captain.ID, partner.ID); err != nil {
+		return p.moveToWaitlist(ctx, pair, event, captain, partner)
+	}
+
+	ok, err := p.pairRepo.TransitionStatus(ctx, pair.ID,
+		[]domain.RegistrationPairStatus{domain.RegistrationPairStatusInvited}, domain.RegistrationPairStatusRegistered)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: invitation is no longer active", ErrInvalidPair)
+	}
+
+	if err := p.cases.Registration.RegisterPair(ctx, event, []*domain.User{captain, partner}); err != nil {
+		// Возвращаем приглашение, чтобы партнер мог принять его повторно
+		if _, revertErr := p.pairRepo.TransitionStatus(ctx, pair.ID,
+			[]domain.RegistrationPairStatus{domain.RegistrationPairStatusRegistered}, domain.RegistrationPairStatusInvited); revertErr != nil {
+			slog.Error("Failed to revert pair status",
+				"pair_id", pair.ID,
+				"error", revertErr)
+		}
+		return nil, err
+	}
+
+	p.notify(ctx, captain, fmt.Sprintf(`%s %s принял(а) приглашение, ваша пара зарегистрирована на турнир "%s".
+Перейдите на <a href="%s">страницу турнира</a> и проверьте, требуется ли оплата.`,
+		partner.FirstName, partner.LastName, event.Name, p.eventLink(event.ID)))
+
+	return p.getPair(ctx, pair.ID)
+}
+
+func (p *RegistrationPair) moveToWaitlist(ctx context.Context, pair *domain.RegistrationPair, event *domain.Event, captain, partner *domain.User) (*domain.RegistrationPair, error) {
+	ok, err := p.pairRepo.TransitionStatus(ctx, pair.ID,
+		[]domain.RegistrationPairStatus{domain.RegistrationPairStatusInvited}, domain.RegistrationPairStatusWaitlisted)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: invitation is no longer active", ErrInvalidPair)
+	}
+
+	// Пара стоит в листе ожидания одной записью капитана
+	_, err = p.cases.Waitlist.Create(ctx, &domain.CreateWaitlist{
+		UserID:  captain.ID,
+		EventID: event.ID,
+		PairID:  &pair.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add pair to waitlist: %w", err)
+	}
+
+	slog.Info("Registration pair added to waitlist",
+		"pair_id", pair.ID,
+		"event_id", event.ID)
+
+	p.notify(ctx, captain, fmt.Sprintf(`%s %s принял(а) приглашение на турнир "%s", но свободных мест для пары нет.
+Ваша пара добавлена в лист ожидания, мы сообщим, когда освободятся места.`,
+		partner.FirstName, partner.LastName, event.Name))
+
+	return p.getPair(ctx, pair.ID)
+}
+
+// Decline отклоняет приглашение партнером
+func (p *RegistrationPair) Decline(ctx context.Context, partner *domain.User, eventID string) error {
+	pair, err := p.findPair(ctx, partner.ID, eventID, domain.RegistrationPairStatusInvited)
+	if err != nil {
+		return err
+	}
+	if pair.PartnerID != partner.ID {
+		return fmt.Errorf("%w: only the invited partner can decline the invitation", ErrInvalidPair)
+	}
+
+	ok, err := p.pairRepo.TransitionStatus(ctx, pair.ID,
+		[]domain.RegistrationPairStatus{domain.RegistrationPairStatusInvited}, domain.RegistrationPairStatusDeclined)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: invitation is no longer active", ErrInvalidPair)
+	}
+
+	if captain, err := p.getUser(ctx, pair.CaptainID); err == nil {
+		p.notify(ctx, captain, fmt.Sprintf(`%s %s отклонил(а) приглашение сыграть в паре на турнире "%s".`,
+			partner.FirstName, partner.LastName, p.eventName(ctx, eventID)))
+	}
+
+	return nil
+}
+
+// Cancel отменяет приглашение или заявку пары в листе ожидания.
+// Зарегистрированная пара отменяется через отмену регистрации любого из партнеров
+func (p *RegistrationPair) Cancel(ctx context.Context, user *domain.User, eventID string) error {
+	pair, err := p.findPair(ctx, user.ID, eventID,
+		domain.RegistrationPairStatusInvited, domain.RegistrationPairStatusWaitlisted)
+	if err != nil {
+		return err
+	}
+
+	ok, err := p.pairRepo.TransitionStatus(ctx, pair.ID,
+		[]domain.RegistrationPairStatus{pair.Status}, domain.RegistrationPairStatusCancelled)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: pair status has changed", ErrInvalidPair)
+	}
+
+	if err := p.removeFromWaitlist(ctx, pair.ID); err != nil {
+		return err
+	}
+
+	slog.Info("Registration pair cancelled",
+		"pair_id", pair.ID,
+		"event_id", eventID,
+		"cancelled_by", user.ID)
+
+	if other, err := p.getUser(ctx, pair.PartnerOf(user.ID)); err == nil {
+		p.notify(ctx, other, fmt.Sprintf(`%s %s отменил(а) участие пары в турнире "%s".`,
+			user.FirstName, user.LastName, p.eventName(ctx, eventID)))
+	}
+
+	return nil
+}
+
+// GetMyPair возвращает активную пару пользователя на событие
+func (p *RegistrationPair) GetMyPair(ctx context.Context, user *domain.User, eventID string) (*domain.RegistrationPair, error) {
+	pair, err := p.findPair(ctx, user.ID, eventID, domain.RegistrationPairActiveStatuses...)
+	if err != nil {
+		return nil, err
+	}
+	return p.getPair(ctx, pair.ID)
+}
+
+// GetRegisteredPair возвращает зарегистрированную пару пользователя или nil, если он играет не в паре
+func (p *RegistrationPair) GetRegisteredPair(ctx context.Context, userID, eventID string) (*domain.RegistrationPair, error) {
+	pair, err := p.findPair(ctx, userID, eventID, domain.RegistrationPairStatusRegistered)
+	if errors.Is(err, ErrPairNotFound) {
+		return nil, nil
+	}
+	return pair, err
+}
+
+// CancelPairWithMember вызывается при отмене регистрации одного из партнеров
+// и отменяет регистрацию второго, чтобы пара освобождала места целиком.
+// Если регистрацию партнера отменить не удалось, пара снова считается зарегистрированной,
+// и повторная отмена (пользователем или задачей воркера) повторит снятие партнера
+func (p *RegistrationPair) CancelPairWithMember(ctx context.Context, user *domain.User, event *domain.Event) error {
+	pair, err := p.GetRegisteredPair(ctx, user.ID, event.ID)
+	if err != nil || pair == nil {
+		return err
+	}
+
+	other, err := p.getUser(ctx, pair.PartnerOf(user.ID))
+	if err != nil {
+		return err
+	}
+
+	// Переводим пару до отмены партнера, иначе его отмена снова вызовет этот обработчик
+	ok, err := p.pairRepo.TransitionStatus(ctx, pair.ID,
+		[]domain.RegistrationPairStatus{domain.RegistrationPairStatusRegistered}, domain.RegistrationPairStatusCancelled)
+	if err != nil || !ok {
+		return err
+	}
+
+	if _, err := p.cases.Registration.CancelEventRegistration(ctx, other, event.ID); err != nil {
+		// Регистрация партнера могла быть отменена раньше, тогда снимать больше нечего
+		active, activeErr := p.hasActiveRegistration(ctx, other.ID, event.ID)
+		if activeErr != nil || active {
+			if _, revertErr := p.pairRepo.TransitionStatus(ctx, pair.ID,
+				[]domain.RegistrationPairStatus{domain.RegistrationPairStatusCancelled}, domain.RegistrationPairStatusRegistered); revertErr != nil {
+				slog.Error("Failed to revert pair status",
+					"pair_id", pair.ID,
+					"error", revertErr)
+			}
+			return fmt.Errorf("failed to cancel partner registration: %w", err)
+		}
+	}
+
+	slog.Info("Partner registration cancelled together with pair",
+		"pair_id", pair.ID,
+		"event_id", event.ID,
+		"cancelled_by", user.ID,
+		"partner_id", other.ID)
+
+	p.notify(ctx, other, fmt.Sprintf(`%s %s отменил(а) участие в турнире "%s", поэтому регистрация вашей пары тоже отменена.`,
+		user.FirstName, user.LastName, event.Name))
+
+	return nil
+}
+
+// FinishPairCancellation снимает партнера, если регистрация пользователя уже отменена, а пара осталась
+// зарегистрированной: прошлая попытка отменить регистрацию партнера могла не пройти
+func (p *RegistrationPair) FinishPairCancellation(ctx context.Context, user *domain.User, event *domain.Event) error {
+	active, err := p.hasActiveRegistration(ctx, user.ID, event.ID)
+	if err != nil || active {
+		return err
+	}
+	return p.CancelPairWithMember(ctx, user, event)
+}
+
+// RegisterFromWaitlist регистрирует пару из листа ожидания, если освободилось два места
+func (p *RegistrationPair) RegisterFromWaitlist(ctx context.Context, event *domain.Event, pairID string) error {
+	pair, err := p.getPair(ctx, pairID)
+	if err != nil {
+		return err
+	}
+	if pair.Status != domain.RegistrationPairStatusWaitlisted {
+		return fmt.Errorf("%w: pair is not in the waitlist", ErrInvalidPair)
+	}
+
+	if err := p.cases.Registration.RegisterPair(ctx, event, []*domain.User{pair.Captain, pair.Partner}); err != nil {
+		return err
+	}
+
+	if _, err := p.pairRepo.TransitionStatus(ctx, pair.ID,
+		[]domain.RegistrationPairStatus{domain.RegistrationPairStatusWaitlisted}, domain.RegistrationPairStatusRegistered); err != nil {
+		return err
+	}
+
+	for _, member := range []*domain.User{pair.Captain, pair.Partner} {
+		if !p.cases.NotificationSettings.Allows(ctx, member.ID, domain.NotificationCategoryWaitlist) {
+			continue
+		}
+		message, err := p.cases.MessageTemplate.RenderForUser(ctx, MessageTemplatePairWaitlistRegistered, member, map[string]any{
+			"event_id":   event.ID,
+			"event_name": event.Name,
+			"event_url":  p.eventLink(event.ID),
+		})
+		if err != nil {
+			slog.Warn("Failed to render pair notification",
+				"user_id", member.ID,
+				"event_id", event.ID,
+				"error", err)
+			continue
+		}
+		p.notifyMessage(ctx, member, message)
+	}
+
+	return nil
+}
+
+// CancelWaitlistedPair отменяет пару, когда ее запись удалили из листа ожидания напрямую
+func (p *RegistrationPair) CancelWaitlistedPair(ctx context.Context, pairID string) error {
+	_, err := p.pairRepo.TransitionStatus(ctx, pairID,
+		[]domain.RegistrationPairStatus{domain.RegistrationPairStatusWaitlisted}, domain.RegistrationPairStatusCancelled)
+	return err
+}
+
+// CancelEventPairs отменяет все активные пары события, отмененного организатором
+func (p *RegistrationPair) CancelEventPairs(ctx context.Context, eventID string) error {
+	pairs, err := p.pairRepo.Filter(ctx, &domain.FilterRegistrationPair{
+		EventID:  &eventID,
+		Statuses: domain.RegistrationPairActiveStatuses,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get event pairs: %w", err)
+	}
+
+	for _, pair := range pairs {
+		if _, err := p.pairRepo.TransitionStatus(ctx, pair.ID,
+			domain.RegistrationPairActiveStatuses, domain.RegistrationPairStatusCancelled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *RegistrationPair) getPairEvent(ctx context.Context, eventID string) (*domain.Event, error) {
+	event, err := p.cases.Event.GetEventByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Type != domain.EventTypeTournament {
+		return nil, fmt.Errorf("%w: pair registration is available only for tournaments", ErrInvalidPair)
+	}
+	if event.Status == domain.EventStatusCompleted || event.Status == domain.EventStatusCancelled {
+		return nil, fmt.Errorf("%w: event is %s", ErrInvalidPair, event.Status)
+	}
+	if err := p.cases.Registration.validateEventNotEnded(event); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPair, err)
+	}
+
+	return event, nil
+}
+
+func (p *RegistrationPair) resolvePartner(ctx context.Context, invite *domain.InvitePartner) (*domain.User, error) {
+	if invite.PartnerID != nil && *invite.PartnerID != "" {
+		return p.getUser(ctx, *invite.PartnerID)
+	}
+
+	if invite.PartnerUsername == nil || *invite.PartnerUsername == "" {
+		return nil, fmt.Errorf("%w: partner id or username is required", ErrInvalidPair)
+	}
+
+	username := strings.TrimPrefix(*invite.PartnerUsername, "@")
+	users, err := p.cases.User.AdminFilter(ctx, &domain.FilterUser{TelegramUsername: &username})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find partner: %w", err)
+	}
+
+	// Фильтр по username ищет по подстроке, поэтому выбираем точное совпадение
+	for _, user := range users {
+		if strings.EqualFold(user.TelegramUsername, username) {
+			return user, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: partner @%s not found", ErrInvalidPair, username)
+}
+
+// validateFreeForPair проверяет, что пользователь еще не зарегистрирован и не состоит в другой паре на событие
+func (p *RegistrationPair) validateFreeForPair(ctx context.Context, user *domain.User, eventID string) error {
+	if err := p.validateNotRegistered(ctx, user, eventID); err != nil {
+		return err
+	}
+
+	pairs, err := p.pairRepo.Filter(ctx, &domain.FilterRegistrationPair{
+		EventID:  &eventID,
+		UserID:   &user.ID,
+		Statuses: domain.RegistrationPairActiveStatuses,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check existing pairs: %w", err)
+	}
+	if len(pairs) > 0 {
+		return fmt.Errorf("%w: %s %s already has a partner for this event", ErrInvalidPair, user.FirstName, user.LastName)
+	}
+
+	return nil
+}
+
+func (p *RegistrationPair) validateNotRegistered(ctx context.Context, user *domain.User, eventID string) error {
+	active, err := p.hasActiveRegistration(ctx, user.ID, eventID)
+	if err != nil {
+		return err
+	}
+	if active {
+		return fmt.Errorf("%w: %s %s is already registered for this event", ErrInvalidPair, user.FirstName, user.LastName)
+	}
+
+	return nil
+}
+
+// hasActiveRegistration проверяет, занимает ли пользователь место на событии
+func (p *RegistrationPair) hasActiveRegistration(ctx context.Context, userID, eventID string) (bool, error) {
+	registrations, err := p.cases.Registration.GetRegistrationsByUserAndEvent(ctx, userID, eventID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check existing registrations: %w", err)
+	}
+
+	for _, reg := range registrations {
+		switch reg.Status {
+		case domain.RegistrationStatusPending,
+			domain.RegistrationStatusConfirmed,
+			domain.RegistrationStatusInvited:
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// validatePairRank проверяет средний ранг пары по диапазону события
+func validatePairRank(captain, partner *domain.User, event *domain.Event) error {
+	rank := (captain.Rank + partner.Rank) / 2
+	if rank < event.RankMin || rank > event.RankMax {
+		return fmt.Errorf("%w: pair average rank %.1f does not fit event range %.1f-%.1f",
+			ErrInvalidPair, rank, event.RankMin, event.RankMax)
+	}
+	return nil
+}
+
+func (p *RegistrationPair) findPair(ctx context.Context, userID, eventID string, statuses ...domain.RegistrationPairStatus) (*domain.RegistrationPair, error) {
+	pairs, err := p.pairRepo.Filter(ctx, &domain.FilterRegistrationPair{
+		EventID:  &eventID,
+		UserID:   &userID,
+		Statuses: statuses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration pair: %w", err)
+	}
+	if len(pairs) == 0 {
+		return nil, ErrPairNotFound
+	}
+	return pairs[0], nil
+}
+
+// getPair возвращает пару вместе с данными обоих игроков
+func (p *RegistrationPair) getPair(ctx context.Context, pairID string) (*domain.RegistrationPair, error) {
+	pair, err := repo.First(p.pairRepo.Filter)(ctx, &domain.FilterRegistrationPair{ID: &pairID})
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrPairNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if pair.Captain, err = p.getUser(ctx, pair.CaptainID); err != nil {
+		return nil, err
+	}
+	if pair.Partner, err = p.getUser(ctx, pair.PartnerID); err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+func (p *RegistrationPair) getUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := repo.First(p.cases.User.AdminFilter)(ctx, &domain.FilterUser{ID: &userID})
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, fmt.Errorf("%w: user %s not found", ErrInvalidPair, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (p *RegistrationPair) removeFromWaitlist(ctx context.Context, pairID string) error {
+	entries, err := p.cases.Waitlist.Filter(ctx, &domain.FilterWaitlist{PairID: &pairID})
+	if err != nil {
+		return fmt.Errorf("failed to get pair waitlist entry: %w", err)
+	}
+	for _, entry := range entries {
+		if err := p.cases.Waitlist.Delete(ctx, entry.ID); err != nil {
+			return fmt.Errorf("failed to remove pair from waitlist: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *RegistrationPair) eventLink(eventID string) string {
+	return fmt.Sprintf("https://t.me/%s/app?startapp=%s", p.config.TG.BotUsername, eventID)
+}
+
+func (p *RegistrationPair) eventName(ctx context.Context, eventID string) string {
+	event, err := p.cases.Event.GetEventByID(ctx, eventID)
+	if err != nil {
+		return eventID
+	}
+	return event.Name
+}
+
+func (p *RegistrationPair) notify(ctx context.Context, user *domain.User, text string) {
+	parseMode := string(models.ParseModeHTML)
+	p.notifyMessage(ctx, user, &domain.RenderedMessage{Text: text, ParseMode: &parseMode})
+}
+
+func (p *RegistrationPair) notifyMessage(ctx context.Context, user *domain.User, message *domain.RenderedMessage) {
+	_, err := p.bot.SendMessage(ctx, sendMessageParams(user.TelegramID, message))
+	if err != nil {
+		slog.Warn("Failed to send pair notification",
+			"user_id", user.ID,
+			"user_telegram_id", user.TelegramID,
+			"error", err)
+	}
+}
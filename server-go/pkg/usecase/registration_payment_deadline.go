@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+// paymentDeadline срок оплаты регистрации на событие, 0 — неоплаченная регистрация не отменяется
+func (r *Registration) paymentDeadline(event *domain.Event) time.Duration {
+	if event.PaymentDeadlineHours != nil {
+		return time.Duration(*event.PaymentDeadlineHours) * time.Hour
+	}
+	return r.config.Payments.RegistrationDeadline
+}
+
+// schedulePaymentDeadline ставит воркеру срок оплаты регистрации, ожидающей оплаты. Срок не позже начала события
+func (r *Registration) schedulePaymentDeadline(ctx context.Context, event *domain.Event, user *domain.User) error {
+	deadline := r.paymentDeadline(event)
+	if deadline <= 0 {
+		return nil
+	}
+
+	if err := r.notificationService.SendEventPaymentDeadline(ctx, user.TelegramID, event.ID, r.paymentDueAt(event, time.Now())); err != nil {
+		return fmt.Errorf("failed to schedule payment deadline: %w", err)
+	}
+	return nil
+}
+
+// paymentDueAt окончание срока оплаты регистрации, перешедшей в ожидание оплаты в from. Не позже начала события
+func (r *Registration) paymentDueAt(event *domain.Event, from time.Time) time.Time {
+	dueAt := from.Add(r.paymentDeadline(event))
+	if event.StartTime.Before(dueAt) {
+		dueAt = event.StartTime
+	}
+	return dueAt
+}
+
+// registrationPaymentDueAt окончание срока оплаты регистрации от ее перехода в ожидание оплаты.
+// false — регистрация не ожидает оплаты
+func (r *Registration) registrationPaymentDueAt(event *domain.Event, registration *domain.Registration) (time.Time, bool) {
+	if registration.PendingSince == nil {
+		return time.Time{}, false
+	}
+	return r.paymentDueAt(event, *registration.PendingSince), true
+}
+
+// reschedulePaymentDeadlines ставит заново сроки оплаты регистраций события после изменения срока оплаты или начала события.
+// Задачи со старым сроком остаются: сработав раньше нового срока, они ничего не отменяют
+func (r *Registration) reschedulePaymentDeadlines(ctx context.Context, eventID string) error {
+	if r.notificationService == nil {
+		return nil
+	}
+
+	event, err := r.cases.Event.GetEventByID(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+	if r.paymentDeadline(event) <= 0 {
+		return nil
+	}
+
+	pendingStatus := domain.RegistrationStatusPending
+	registrations, err := r.registrationRepo.Filter(ctx, &domain.FilterRegistration{
+		EventID: &eventID,
+		Status:  &pendingStatus,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get registrations awaiting payment: %w", err)
+	}
+
+	for _, registration := range registrations {
+		dueAt, ok := r.registrationPaymentDueAt(event, registration)
+		if !ok {
+			continue
+		}
+		if err := r.notificationService.SendEventPaymentDeadline(ctx, registration.User.TelegramID, eventID, dueAt); err != nil {
+			return fmt.Errorf("failed to reschedule payment deadline: %w", err)
+		}
+	}
+
+	slog.Info("Payment deadlines rescheduled", "event_id", eventID, "registrations", len(registrations))
+	return nil
+}
+
+// ExpireUnpaidRegistration отменяет регистрацию, которую не оплатили в срок. Вызывается по задаче воркера
+// event.payment.deadline: место освобождается так же, как при отмене пользователем, и предлагается листу ожидания.
+// Оплаченная или уже отмененная регистрация не меняется. Задача, сработавшая раньше срока, ставится на срок заново.
+// Если пользователь начал оплату, срок продлевается до окончания окна оплаты
+func (r *Registration) ExpireUnpaidRegistration(ctx context.Context, userTelegramID int64, eventID string) error {
+	log := slog.With("user_telegram_id", userTelegramID, "event_id", eventID)
+
+	user, err := r.cases.User.GetByTelegramID(ctx, userTelegramID)
+	if errors.Is(err, repo.ErrNotFound) {
+		log.Warn("User of unpaid registration not found")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	event, err := r.cases.Event.GetEventByID(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+
+	pendingStatus := domain.RegistrationStatusPending
+	registrations, err := r.registrationRepo.Filter(ctx, &domain.FilterRegistration{
+		UserID:  &user.ID,
+		EventID: &eventID,
+		Status:  &pendingStatus,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find registration: %w", err)
+	}
+	if len(registrations) == 0 {
+		log.Info("Registration is no longer awaiting payment, nothing to expire")
+		// Повтор задачи после ошибки: регистрация уже отменена, но партнер мог остаться зарегистрированным
+		return r.cases.RegistrationPair.FinishPairCancellation(ctx, user, event)
+	}
+	registration := registrations[0]
+
+	if r.paymentDeadline(event) <= 0 {
+		log.Info("Payment deadline is disabled for event, nothing to expire")
+		return nil
+	}
+
+	dueAt, ok := r.registrationPaymentDueAt(event, registration)
+	if !ok {
+		log.Warn("Registration awaiting payment has no pending_since, nothing to expire")
+		return nil
+	}
+	// Задача могла остаться от прошлой регистрации или срок продлили: срок ставится заново
+	if time.Now().Before(dueAt) {
+		log.Info("Payment deadline of registration has not passed yet", "pending_since", *registration.PendingSince, "due_at", dueAt)
+		if r.notificationService == nil {
+			return nil
+		}
+		return r.notificationService.SendEventPaymentDeadline(ctx, user.TelegramID, eventID, dueAt)
+	}
+
+	if extendUntil, err := r.paymentInProgressUntil(ctx, user.ID, eventID); err != nil {
+		return err
+	} else if !extendUntil.IsZero() {
+		log.Info("Payment is in progress, payment deadline extended", "until", extendUntil)
+		if r.notificationService == nil {
+			return nil
+		}
+		return r.notificationService.SendEventPaymentDeadline(ctx, user.TelegramID, eventID, extendUntil)
+	}
+
+	expired := false
+	err = r.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		// Оплата могла пройти после проверки: регистрация отменяется, только если все еще ожидает оплаты
+		ok, err := r.registrationRepo.TransitionStatus(txCtx, user.ID, eventID,
+			[]domain.RegistrationStatus{domain.RegistrationStatusPending}, domain.RegistrationStatusCancelledBeforePayment)
+		if err != nil || !ok {
+			return err
+		}
+		expired = true
+
+		if r.notificationService == nil {
+			return nil
+		}
+		if err := r.notificationService.SendEventTasksCancel(txCtx, user.TelegramID, eventID); err != nil {
+			return fmt.Errorf("failed to cancel scheduled reminders: %w", err)
+		}
+		if err := r.notificationService.SendEventPaymentExpired(txCtx, user.TelegramID, event); err != nil {
+			return fmt.Errorf("failed to send payment expired notification: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel unpaid registration: %w", err)
+	}
+	if !expired {
+		log.Info("Registration was paid or cancelled concurrently, nothing to expire")
+		return nil
+	}
+
+	log.Info("Unpaid registration cancelled after payment deadline", "user_id", user.ID)
+
+	// Пара без одного участника не играет, как и при отмене пользователем
+	// При ошибке воркер повторит задачу, и партнер будет снят при повторе
+	if err := r.cases.RegistrationPair.CancelPairWithMember(ctx, user, event); err != nil {
+		return err
+	}
+
+	if err := r.updateEventStatusAfterCancellation(ctx, eventID); err != nil {
+		log.Warn("Failed to update event status after unpaid registration cancellation", "error", err)
+	}
+
+	if err := r.cases.Event.TryRegisterFromWaitlist(ctx, eventID); err != nil {
+		return fmt.Errorf("failed to try register from waitlist: %w", err)
+	}
+	return nil
+}
+
+// paymentInProgressUntil окончание окна оплаты платежа, который пользователь начал и еще не завершил.
+// Нулевое время — незавершенных платежей нет
+func (r *Registration) paymentInProgressUntil(ctx context.Context, userID, eventID string) (time.Time, error) {
+	payments, err := r.cases.Payment.GetPaymentsByUserAndEvent(ctx, userID, eventID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get payments: %w", err)
+	}
+
+	var until time.Time
+	now := time.Now()
+	for _, payment := range payments {
+		if payment.Status != domain.PaymentStatusPending && payment.Status != domain.PaymentStatusWaitingForCapture {
+			continue
+		}
+		if windowEnd := payment.Date.Add(r.config.Payments.PaymentWindow); windowEnd.After(now) && windowEnd.After(until) {
+			until = windowEnd
+		}
+	}
+	return until, nil
+}
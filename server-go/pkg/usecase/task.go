@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"gopadel/taskcontract"
+)
+
+var (
+	ErrTaskNotFound      = errors.New("task not found")
+	ErrTaskInvalidStatus = errors.New("task status does not allow this action")
+	ErrInvalidTask       = errors.New("invalid task")
+)
+
+var (
+	// redrivableTaskStatuses статусы, из которых админ может перезапустить задачу со сбросом попыток
+	redrivableTaskStatuses = []domain.TaskStatus{domain.TaskStatusDead, domain.TaskStatusFailed}
+	// rerunnableTaskStatuses статусы, из которых задачу можно выполнить еще раз. Выполняющуюся задачу
+	// держит воркер, ее менять нельзя
+	rerunnableTaskStatuses = []domain.TaskStatus{
+		domain.TaskStatusPending, domain.TaskStatusCompleted, domain.TaskStatusFailed,
+		domain.TaskStatusCancelled, domain.TaskStatusDead,
+	}
+	pendingTaskStatuses = []domain.TaskStatus{domain.TaskStatusPending}
+)
+
+// Task задачи воркера уведомлений для админки. Изменения пишутся в БД, после чего воркерам
+// отправляется команда через NATS, чтобы они обновили свое расписание
+type Task struct {
+	taskRepo            repo.Task
+	notificationService *notifications.NotificationService
+}
+
+func NewTask(ctx context.Context, taskRepo repo.Task, notificationService *notifications.NotificationService) *Task {
+	return &Task{
+		taskRepo:            taskRepo,
+		notificationService: notificationService,
+	}
+}
+
+func (t *Task) Filter(ctx context.Context, filter *domain.FilterTask) ([]*domain.Task, error) {
+	return t.taskRepo.Filter(ctx, filter)
+}
+
+func (t *Task) Get(ctx context.Context, id string) (*domain.Task, error) {
+	tasks, err := t.taskRepo.Filter(ctx, &domain.FilterTask{ID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil, ErrTaskNotFound
+	}
+	return tasks[0], nil
+}
+
+// Cancel отменяет ожидающую задачу
+func (t *Task) Cancel(ctx Context, id string) (*domain.Task, error) {
+	status := domain.TaskStatusCancelled
+	return t.transition(ctx, id, "cancel", pendingTaskStatuses, &domain.PatchTask{Status: &status}, taskcontract.ControlCancel)
+}
+
+// Reschedule переносит ожидающую задачу на другое время
+func (t *Task) Reschedule(ctx Context, id string, reschedule *domain.RescheduleTask) (*domain.Task, error) {
+	if reschedule.ExecuteAt.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: executeAt is in the past, use run to execute the task now", ErrInvalidTask)
+	}
+
+	executeAt := reschedule.ExecuteAt
+	return t.transition(ctx, id, "reschedule", pendingTaskStatuses, &domain.PatchTask{ExecuteAt: &executeAt}, taskcontract.ControlReschedule)
+}
+
+// Run ставит задачу на немедленное выполнение, в том числе уже выполненную или отмененную
+func (t *Task) Run(ctx Context, id string) (*domain.Task, error) {
+	return t.runNow(ctx, id, "run", rerunnableTaskStatuses)
+}
+
+// Redrive перезапускает задачу в статусе dead или failed
+func (t *Task) Redrive(ctx Context, id string) (*domain.Task, error) {
+	return t.runNow(ctx, id, "redrive", redrivableTaskStatuses)
+}
+
+func (t *Task) runNow(ctx Context, id, action string, from []domain.TaskStatus) (*domain.Task, error) {
+	status := domain.TaskStatusPending
+	now := time.Now()
+	return t.transition(ctx, id, action, from, &domain.PatchTask{
+		Status:       &status,
+		ExecuteAt:    &now,
+		ResetRetries: true,
+	}, taskcontract.ControlReschedule)
+}
+
+func (t *Task) transition(
+	ctx Context,
+	id, action string,
+	from []domain.TaskStatus,
+	patch *domain.PatchTask,
+	control taskcontract.ControlAction,
+) (*domain.Task, error) {
+	task, err := t.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := t.taskRepo.Transition(ctx, id, from, patch)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot %s task in status %s", ErrTaskInvalidStatus, action, task.Status)
+	}
+
+	log := slog.With(
+		slog.String("task_id", id),
+		slog.String("task_type", task.TaskType),
+		slog.String("action", action),
+		slog.String("previous_status", string(task.Status)))
+	if ctx.User != nil {
+		log = log.With(slog.String("admin_user_id", ctx.User.ID))
+	}
+	log.Info("Task changed by admin")
+
+	if t.notificationService != nil {
+		if err := t.notificationService.SendTaskControl(control, id); err != nil {
+			// Задача уже изменена в БД, воркер подхватит ее при опросе
+			log.Error("Failed to send task control", slog.Any("error", err))
+		}
+	}
+
+	return t.Get(ctx, id)
+}
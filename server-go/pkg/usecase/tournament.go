@@ -0,0 +1,627 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+var (
+	ErrTournamentNotFound   = errors.New("tournament not found")
+	ErrInvalidTournament    = errors.New("invalid tournament")
+	ErrMatchAlreadyRecorded = errors.New("match score is already recorded")
+)
+
+type Tournament struct {
+	tournamentRepo repo.Tournament
+	tx             repo.Transactor
+	cases          *Cases
+}
+
+func NewTournament(ctx context.Context, tournamentRepo repo.Tournament, tx repo.Transactor, cases *Cases) *Tournament {
+	return &Tournament{
+		tournamentRepo: tournamentRepo,
+		tx:             tx,
+		cases:          cases,
+	}
+}
+
+// Create составляет расписание турнира по подтвержденным участникам события.
+// Повторный вызов пересоздает расписание, пока не записан ни один результат
+func (t *Tournament) Create(ctx Context, eventID string, create *domain.CreateTournament) (*domain.Tournament, error) {
+	event, err := t.cases.Event.GetEventByID(ctx.Context, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Type != domain.EventTypeTournament {
+		return nil, fmt.Errorf("%w: event is not a tournament", ErrInvalidTournament)
+	}
+
+	// Проверка результатов и сохранение идут под блокировкой: событие блокируется, чтобы параллельные
+	// вызовы не создавали турнир одновременно, турнир — чтобы между проверкой и сохранением не записали счет
+	var tournament *domain.Tournament
+	var players []*tournamentPlayer
+	err = t.tx.WithinTx(ctx.Context, func(txCtx context.Context) error {
+		if err := t.cases.Event.Lock(txCtx, eventID); err != nil {
+			return fmt.Errorf("failed to lock event: %w", err)
+		}
+		if err := t.tournamentRepo.Lock(txCtx, eventID); err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return err
+		}
+
+		existing, err := t.tournamentRepo.Get(txCtx, eventID)
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return fmt.Errorf("failed to get tournament: %w", err)
+		}
+		if existing != nil {
+			for _, match := range existing.Matches {
+				if match.Status == domain.MatchStatusCompleted {
+					return fmt.Errorf("%w: tournament already has match results", ErrInvalidTournament)
+				}
+			}
+		}
+
+		players, err = t.getConfirmedPlayers(txCtx, eventID)
+		if err != nil {
+			return err
+		}
+
+		tournament, err = scheduleTournament(eventID, players, create)
+		if err != nil {
+			return err
+		}
+
+		if err := t.tournamentRepo.Save(txCtx, tournament); err != nil {
+			return fmt.Errorf("failed to save tournament: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Tournament schedule created",
+		"event_id", eventID,
+		"format", tournament.Format,
+		"players", len(players),
+		"matches", len(tournament.Matches))
+
+	return t.Get(ctx.Context, eventID)
+}
+
+// Get возвращает турнир с расписанием и текущей таблицей
+func (t *Tournament) Get(ctx context.Context, eventID string) (*domain.Tournament, error) {
+	tournament, err := t.tournamentRepo.Get(ctx, eventID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrTournamentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tournament: %w", err)
+	}
+
+	names, err := t.getPlayerNames(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	tournament.Standings = CalculateStandings(tournament, names)
+	return tournament, nil
+}
+
+// GetStandings возвращает турнирную таблицу
+func (t *Tournament) GetStandings(ctx context.Context, eventID string) ([]*domain.TournamentStanding, error) {
+	tournament, err := t.Get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return tournament.Standings, nil
+}
+
+// Delete удаляет турнир. Изменения ранга по сыгранным матчам откатываются в той же транзакции
+func (t *Tournament) Delete(ctx Context, eventID string) error {
+	return t.delete(ctx.Context, eventID)
+}
+
+func (t *Tournament) delete(ctx context.Context, eventID string) error {
+	return t.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		err := t.tournamentRepo.Lock(txCtx, eventID)
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrTournamentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		tournament, err := t.tournamentRepo.Get(txCtx, eventID)
+		if err != nil {
+			return fmt.Errorf("failed to get tournament: %w", err)
+		}
+
+		for _, match := range tournament.Matches {
+			if match.Status != domain.MatchStatusCompleted {
+				continue
+			}
+			if err := t.cases.Rating.RevertMatchResult(txCtx, match.ID); err != nil {
+				return fmt.Errorf("failed to revert ratings: %w", err)
+			}
+		}
+
+		err = t.tournamentRepo.Delete(txCtx, eventID)
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrTournamentNotFound
+		}
+		return err
+	})
+}
+
+// RecordMatchScore записывает счет матча. В игре на выбывание победитель сразу проходит в следующий матч сетки.
+// Записанный счет меняется только явным исправлением (Correction). Запись идет в транзакции под блокировкой
+// турнира вместе с пересчетом рангов, поэтому параллельные записи не перезаписывают друг друга
+func (t *Tournament) RecordMatchScore(ctx Context, eventID, matchID string, score *domain.RecordMatchScore) (*domain.Tournament, error) {
+	err := t.tx.WithinTx(ctx.Context, func(txCtx context.Context) error {
+		err := t.tournamentRepo.Lock(txCtx, eventID)
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrTournamentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return t.recordMatchScore(txCtx, eventID, matchID, score)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Get(ctx.Context, eventID)
+}
+
+func (t *Tournament) recordMatchScore(ctx context.Context, eventID, matchID string, score *domain.RecordMatchScore) error {
+	tournament, err := t.Get(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	match := findMatch(tournament.Matches, matchID)
+	if match == nil {
+		return fmt.Errorf("%w: match not found", ErrTournamentNotFound)
+	}
+
+	if match.Status == domain.MatchStatusCompleted && !score.Correction {
+		return fmt.Errorf("%w: pass correction to change it", ErrMatchAlreadyRecorded)
+	}
+	if match.Status != domain.MatchStatusCompleted && score.Correction {
+		return fmt.Errorf("%w: match has no score to correct", ErrInvalidTournament)
+	}
+
+	if err := validateMatchScore(tournament, match, *score.Score1, *score.Score2); err != nil {
+		return err
+	}
+	score1, score2 := *score.Score1, *score.Score2
+
+	var next *domain.TournamentMatch
+	if tournament.Format == domain.TournamentFormatSingleElimination && match.NextMatchID != nil {
+		next = findMatch(tournament.Matches, *match.NextMatchID)
+		if next != nil && next.Status == domain.MatchStatusCompleted {
+			return fmt.Errorf("%w: next match is already played", ErrInvalidTournament)
+		}
+	}
+
+	completed := domain.MatchStatusCompleted
+	err = t.tournamentRepo.PatchMatch(ctx, match.ID, &domain.PatchTournamentMatch{
+		Score1: &score1,
+		Score2: &score2,
+		Status: &completed,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record match score: %w", err)
+	}
+
+	match.Score1, match.Score2, match.Status = &score1, &score2, completed
+	if err := t.cases.Rating.ApplyMatchResult(ctx, eventID, match); err != nil {
+		return fmt.Errorf("failed to recalculate ratings: %w", err)
+	}
+
+	if next != nil && match.NextMatchSlot != nil {
+		winnerID, winnerPlayers := match.Team1ID, match.Team1Players
+		if score2 > score1 {
+			winnerID, winnerPlayers = match.Team2ID, match.Team2Players
+		}
+
+		patch := &domain.PatchTournamentMatch{}
+		if *match.NextMatchSlot == 1 {
+			patch.Team1ID, patch.Team1Players = winnerID, &winnerPlayers
+		} else {
+			patch.Team2ID, patch.Team2Players = winnerID, &winnerPlayers
+		}
+		if err := t.tournamentRepo.PatchMatch(ctx, next.ID, patch); err != nil {
+			return fmt.Errorf("failed to advance winner: %w", err)
+		}
+	}
+
+	if tournament.Status != domain.TournamentStatusCompleted && isTournamentFinished(tournament) {
+		if err := t.tournamentRepo.SetStatus(ctx, eventID, domain.TournamentStatusCompleted); err != nil {
+			return fmt.Errorf("failed to complete tournament: %w", err)
+		}
+		slog.Info("Tournament completed", "event_id", eventID)
+	}
+
+	if score.Correction {
+		slog.Info("Match score corrected", "event_id", eventID, "match_id", matchID)
+	}
+
+	return nil
+}
+
+// GenerateNextRound составляет следующий раунд mexicano по текущей таблице. Проверка завершенности раунда
+// и добавление матчей идут под блокировкой турнира, поэтому параллельные вызовы не создадут раунд дважды
+func (t *Tournament) GenerateNextRound(ctx Context, eventID string) (*domain.Tournament, error) {
+	err := t.tx.WithinTx(ctx.Context, func(txCtx context.Context) error {
+		err := t.tournamentRepo.Lock(txCtx, eventID)
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrTournamentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return t.generateNextRound(txCtx, eventID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Get(ctx.Context, eventID)
+}
+
+func (t *Tournament) generateNextRound(ctx context.Context, eventID string) error {
+	tournament, err := t.Get(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	if tournament.Format != domain.TournamentFormatMexicano {
+		return fmt.Errorf("%w: rounds are generated only for mexicano", ErrInvalidTournament)
+	}
+
+	for _, match := range tournament.Matches {
+		if match.Status != domain.MatchStatusCompleted {
+			return fmt.Errorf("%w: previous round is not completed", ErrInvalidTournament)
+		}
+	}
+
+	round := maxRound(tournament.Matches) + 1
+	if round > tournament.Rounds {
+		return fmt.Errorf("%w: all %d rounds are already played", ErrInvalidTournament, tournament.Rounds)
+	}
+
+	players, err := t.getConfirmedPlayers(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if len(players) < 4 {
+		return fmt.Errorf("%w: at least 4 confirmed players are required", ErrInvalidTournament)
+	}
+
+	// Игроки в порядке таблицы, новые участники без матчей — в конце по рангу
+	positions := map[string]int{}
+	for _, standing := range tournament.Standings {
+		if standing.UserID != nil {
+			positions[*standing.UserID] = standing.Position
+		}
+	}
+	ordered := sortPlayersByRank(players)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, oki := positions[ordered[i].ID]
+		pj, okj := positions[ordered[j].ID]
+		if oki != okj {
+			return oki
+		}
+		return pi < pj
+	})
+
+	games := map[string]int{}
+	for _, standing := range tournament.Standings {
+		if standing.UserID != nil {
+			games[*standing.UserID] = standing.Played
+		}
+	}
+
+	matches := scheduleMexicanoRound(ordered, games, tournament.Courts, round)
+	if err := t.tournamentRepo.AddMatches(ctx, eventID, matches); err != nil {
+		return fmt.Errorf("failed to add round: %w", err)
+	}
+	return nil
+}
+
+func (t *Tournament) getConfirmedPlayers(ctx context.Context, eventID string) ([]*tournamentPlayer, error) {
+	participants, err := t.cases.Registration.GetEventParticipants(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event participants: %w", err)
+	}
+
+	players := make([]*tournamentPlayer, 0, len(participants))
+	for _, reg := range participants {
+		if reg.Status != domain.RegistrationStatusConfirmed || reg.User == nil {
+			continue
+		}
+		players = append(players, newTournamentPlayer(reg.User))
+	}
+
+	return players, nil
+}
+
+func (t *Tournament) getPlayerNames(ctx context.Context, eventID string) (map[string]string, error) {
+	participants, err := t.cases.Registration.GetEventParticipants(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event participants: %w", err)
+	}
+
+	names := make(map[string]string, len(participants))
+	for _, reg := range participants {
+		if reg.User != nil {
+			names[reg.User.ID] = newTournamentPlayer(reg.User).Name
+		}
+	}
+
+	return names, nil
+}
+
+// validateMatchScore проверяет, что участники матча определены и счет допустим для формата турнира
+func validateMatchScore(tournament *domain.Tournament, match *domain.TournamentMatch, score1, score2 int) error {
+	if len(match.Team1Players) == 0 || len(match.Team2Players) == 0 {
+		return fmt.Errorf("%w: match participants are not determined yet", ErrInvalidTournament)
+	}
+
+	if tournament.Format.IsIndividual() && tournament.PointsPerMatch > 0 && score1+score2 != tournament.PointsPerMatch {
+		return fmt.Errorf("%w: total score must be %d", ErrInvalidTournament, tournament.PointsPerMatch)
+	}
+
+	if tournament.Format == domain.TournamentFormatSingleElimination && score1 == score2 {
+		return fmt.Errorf("%w: draws are not allowed in elimination", ErrInvalidTournament)
+	}
+
+	return nil
+}
+
+// ScheduleTournament составляет расписание турнира для участников без сохранения: пары, матчи и число раундов
+func ScheduleTournament(eventID string, users []*domain.User, create *domain.CreateTournament) (*domain.Tournament, error) {
+	players := make([]*tournamentPlayer, 0, len(users))
+	for _, user := range users {
+		players = append(players, newTournamentPlayer(user))
+	}
+	return scheduleTournament(eventID, players, create)
+}
+
+func scheduleTournament(eventID string, players []*tournamentPlayer, create *domain.CreateTournament) (*domain.Tournament, error) {
+	tournament := &domain.Tournament{
+		EventID:        eventID,
+		Format:         create.Format,
+		Courts:         create.Courts,
+		PointsPerMatch: create.PointsPerMatch,
+		Rounds:         create.Rounds,
+		Status:         domain.TournamentStatusInProgress,
+		Teams:          []*domain.TournamentTeam{},
+	}
+
+	switch create.Format {
+	case domain.TournamentFormatAmericano, domain.TournamentFormatMexicano:
+		if len(players) < 4 {
+			return nil, fmt.Errorf("%w: at least 4 confirmed players are required", ErrInvalidTournament)
+		}
+		if tournament.Rounds == 0 {
+			tournament.Rounds = len(players) - 1
+		}
+
+		ordered := sortPlayersByRank(players)
+		if create.Format == domain.TournamentFormatAmericano {
+			tournament.Matches = scheduleAmericano(ordered, create.Courts, tournament.Rounds)
+		} else {
+			// Следующие раунды mexicano составляются по таблице после завершения предыдущего
+			tournament.Matches = scheduleMexicanoRound(ordered, map[string]int{}, create.Courts, 1)
+		}
+
+	case domain.TournamentFormatRoundRobin, domain.TournamentFormatSingleElimination:
+		pairing := create.Pairing
+		if pairing == "" {
+			pairing = domain.TournamentPairingBalanced
+		}
+
+		teams, err := formTeams(players, pairing, create.Teams)
+		if err != nil {
+			return nil, err
+		}
+		tournament.Teams = teams
+
+		if create.Format == domain.TournamentFormatRoundRobin {
+			tournament.Matches = scheduleRoundRobin(teams, create.Courts)
+		} else {
+			tournament.Matches = scheduleSingleElimination(teams, create.Courts)
+		}
+		tournament.Rounds = maxRound(tournament.Matches)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown format %s", ErrInvalidTournament, create.Format)
+	}
+
+	return tournament, nil
+}
+
+// isTournamentFinished проверяет, что все матчи сыграны, а для mexicano — еще и что сыграны все раунды
+func isTournamentFinished(tournament *domain.Tournament) bool {
+	for _, match := range tournament.Matches {
+		if match.Status != domain.MatchStatusCompleted {
+			return false
+		}
+	}
+
+	if tournament.Format == domain.TournamentFormatMexicano {
+		return maxRound(tournament.Matches) >= tournament.Rounds
+	}
+	return true
+}
+
+// CalculateStandings считает таблицу по сыгранным матчам.
+// В americano/mexicano места определяются по сумме очков, в круговом турнире — по победам,
+// на выбывание — по раунду, до которого дошла пара
+func CalculateStandings(tournament *domain.Tournament, names map[string]string) []*domain.TournamentStanding {
+	rows := map[string]*domain.TournamentStanding{}
+	order := []string{}
+
+	row := func(key string) *domain.TournamentStanding {
+		if standing, ok := rows[key]; ok {
+			return standing
+		}
+		standing := &domain.TournamentStanding{}
+		rows[key] = standing
+		order = append(order, key)
+		return standing
+	}
+
+	teams := map[string]*domain.TournamentTeam{}
+	for _, team := range tournament.Teams {
+		teams[team.ID] = team
+		key := team.ID
+		standing := row(key)
+		standing.TeamID = &key
+		standing.Name = team.Name
+	}
+
+	sideKeys := func(teamID *string, players []string) []string {
+		if !tournament.Format.IsIndividual() {
+			if teamID == nil {
+				return nil
+			}
+			return []string{*teamID}
+		}
+		return players
+	}
+
+	if tournament.Format.IsIndividual() {
+		for _, match := range tournament.Matches {
+			for _, userID := range append(append([]string{}, match.Team1Players...), match.Team2Players...) {
+				standing := row(userID)
+				standing.UserID = &userID
+				standing.Name = names[userID]
+			}
+		}
+	}
+
+	reached := map[string]int{}
+	for _, match := range tournament.Matches {
+		side1 := sideKeys(match.Team1ID, match.Team1Players)
+		side2 := sideKeys(match.Team2ID, match.Team2Players)
+
+		for _, key := range append(append([]string{}, side1...), side2...) {
+			reached[key] = max(reached[key], match.Round)
+		}
+
+		if match.Status != domain.MatchStatusCompleted || match.Score1 == nil || match.Score2 == nil {
+			continue
+		}
+
+		apply := func(keys []string, pointsFor, pointsAgainst int) {
+			for _, key := range keys {
+				standing := row(key)
+				standing.Played++
+				standing.PointsFor += pointsFor
+				standing.PointsAgainst += pointsAgainst
+				switch {
+				case pointsFor > pointsAgainst:
+					standing.Won++
+				case pointsFor < pointsAgainst:
+					standing.Lost++
+				default:
+					standing.Drawn++
+				}
+			}
+		}
+		apply(side1, *match.Score1, *match.Score2)
+		apply(side2, *match.Score2, *match.Score1)
+
+		// Победитель матча считается дошедшим до следующего раунда, победитель финала — выше всех
+		winners := side1
+		if *match.Score2 > *match.Score1 {
+			winners = side2
+		}
+		for _, key := range winners {
+			reached[key] = max(reached[key], match.Round+1)
+		}
+	}
+
+	standings := make([]*domain.TournamentStanding, 0, len(order))
+	for _, key := range order {
+		standing := rows[key]
+		standing.PointsDiff = standing.PointsFor - standing.PointsAgainst
+		standings = append(standings, standing)
+	}
+
+	seed := func(standing *domain.TournamentStanding) int {
+		if standing.TeamID != nil && teams[*standing.TeamID] != nil {
+			return teams[*standing.TeamID].Seed
+		}
+		return 0
+	}
+	key := func(standing *domain.TournamentStanding) string {
+		if standing.TeamID != nil {
+			return *standing.TeamID
+		}
+		return *standing.UserID
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		switch tournament.Format {
+		case domain.TournamentFormatSingleElimination:
+			if reached[key(a)] != reached[key(b)] {
+				return reached[key(a)] > reached[key(b)]
+			}
+			return seed(a) < seed(b)
+		case domain.TournamentFormatRoundRobin:
+			if a.Won != b.Won {
+				return a.Won > b.Won
+			}
+			if a.PointsDiff != b.PointsDiff {
+				return a.PointsDiff > b.PointsDiff
+			}
+			return a.PointsFor > b.PointsFor
+		default:
+			if a.PointsFor != b.PointsFor {
+				return a.PointsFor > b.PointsFor
+			}
+			if a.Won != b.Won {
+				return a.Won > b.Won
+			}
+			return a.PointsDiff > b.PointsDiff
+		}
+	})
+
+	for i, standing := range standings {
+		standing.Position = i + 1
+	}
+
+	return standings
+}
+
+func findMatch(matches []*domain.TournamentMatch, id string) *domain.TournamentMatch {
+	for _, match := range matches {
+		if match.ID == id {
+			return match
+		}
+	}
+	return nil
+}
+
+func maxRound(matches []*domain.TournamentMatch) int {
+	result := 0
+	for _, match := range matches {
+		result = max(result, match.Round)
+	}
+	return result
+}
@@ -0,0 +1,421 @@
+package usecase
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+// tournamentPlayer участник турнира для составления пар и расписания
+type tournamentPlayer struct {
+	ID   string
+	Name string
+	Rank float64
+}
+
+func newTournamentPlayer(user *domain.User) *tournamentPlayer {
+	return &tournamentPlayer{
+		ID:   user.ID,
+		Name: strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Rank: user.Rank,
+	}
+}
+
+// formTeams составляет постоянные пары и проставляет посев по суммарному рангу
+func formTeams(players []*tournamentPlayer, pairing domain.TournamentPairing, manual [][]string) ([]*domain.TournamentTeam, error) {
+	var pairs [][]*tournamentPlayer
+
+	switch pairing {
+	case domain.TournamentPairingManual:
+		byID := make(map[string]*tournamentPlayer, len(players))
+		for _, player := range players {
+			byID[player.ID] = player
+		}
+
+		used := map[string]bool{}
+		for _, team := range manual {
+			if len(team) != 2 {
+				return nil, fmt.Errorf("%w: each team must have exactly 2 players", ErrInvalidTournament)
+			}
+
+			pair := make([]*tournamentPlayer, 0, 2)
+			for _, playerID := range team {
+				player, ok := byID[playerID]
+				if !ok {
+					return nil, fmt.Errorf("%w: player %s has no confirmed registration", ErrInvalidTournament, playerID)
+				}
+				if used[playerID] {
+					return nil, fmt.Errorf("%w: player %s is in more than one team", ErrInvalidTournament, playerID)
+				}
+				used[playerID] = true
+				pair = append(pair, player)
+			}
+			pairs = append(pairs, pair)
+		}
+
+	case domain.TournamentPairingRandom:
+		if len(players)%2 != 0 {
+			return nil, fmt.Errorf("%w: odd number of confirmed players", ErrInvalidTournament)
+		}
+
+		shuffled := append([]*tournamentPlayer{}, players...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		for i := 0; i < len(shuffled); i += 2 {
+			pairs = append(pairs, []*tournamentPlayer{shuffled[i], shuffled[i+1]})
+		}
+
+	default:
+		if len(players)%2 != 0 {
+			return nil, fmt.Errorf("%w: odd number of confirmed players", ErrInvalidTournament)
+		}
+
+		// Сильнейший по рангу играет с самым слабым, чтобы пары были равными по силе
+		sorted := sortPlayersByRank(players)
+		for i := 0; i < len(sorted)/2; i++ {
+			pairs = append(pairs, []*tournamentPlayer{sorted[i], sorted[len(sorted)-1-i]})
+		}
+	}
+
+	if len(pairs) < 2 {
+		return nil, fmt.Errorf("%w: at least 2 teams are required", ErrInvalidTournament)
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i][0].Rank+pairs[i][1].Rank > pairs[j][0].Rank+pairs[j][1].Rank
+	})
+
+	teams := make([]*domain.TournamentTeam, 0, len(pairs))
+	for i, pair := range pairs {
+		teams = append(teams, &domain.TournamentTeam{
+			ID:        uuid.New().String(),
+			Name:      pair[0].Name + " / " + pair[1].Name,
+			Seed:      i + 1,
+			PlayerIDs: []string{pair[0].ID, pair[1].ID},
+		})
+	}
+
+	return teams, nil
+}
+
+// scheduleRoundRobin составляет круговое расписание методом вращения.
+// Круг, в котором матчей больше, чем кортов, делится на несколько раундов
+func scheduleRoundRobin(teams []*domain.TournamentTeam, courts int) []*domain.TournamentMatch {
+	slots := make([]*domain.TournamentTeam, len(teams))
+	copy(slots, teams)
+	if len(slots)%2 != 0 {
+		slots = append(slots, nil) // bye
+	}
+
+	n := len(slots)
+	matches := []*domain.TournamentMatch{}
+	round := 0
+
+	for circle := 0; circle < n-1; circle++ {
+		pairs := [][2]*domain.TournamentTeam{}
+		for i := 0; i < n/2; i++ {
+			home, away := slots[i], slots[n-1-i]
+			if home == nil || away == nil {
+				continue
+			}
+			pairs = append(pairs, [2]*domain.TournamentTeam{home, away})
+		}
+
+		for i, pair := range pairs {
+			if i%courts == 0 {
+				round++
+			}
+			matches = append(matches, newTeamMatch(round, i%courts+1, i%courts+1, pair[0], pair[1]))
+		}
+
+		// Первая пара остается на месте, остальные сдвигаются по кругу
+		last := slots[n-1]
+		copy(slots[2:], slots[1:n-1])
+		slots[1] = last
+	}
+
+	return matches
+}
+
+// scheduleSingleElimination составляет сетку на выбывание со стандартным посевом.
+// Сильнейшие посевы при неполной сетке проходят в следующий раунд без игры
+func scheduleSingleElimination(teams []*domain.TournamentTeam, courts int) []*domain.TournamentMatch {
+	size := 1
+	for size < len(teams) {
+		size *= 2
+	}
+
+	// Заранее создаем все матчи сетки, чтобы связать их через NextMatchID
+	bracket := [][]*domain.TournamentMatch{}
+	for round, count := 1, size/2; count >= 1; round, count = round+1, count/2 {
+		roundMatches := make([]*domain.TournamentMatch, count)
+		for i := range roundMatches {
+			roundMatches[i] = &domain.TournamentMatch{
+				ID:           uuid.New().String(),
+				Round:        round,
+				Court:        i%courts + 1,
+				Position:     i + 1,
+				Team1Players: []string{},
+				Team2Players: []string{},
+				Status:       domain.MatchStatusScheduled,
+			}
+		}
+		bracket = append(bracket, roundMatches)
+	}
+
+	for r := 0; r < len(bracket)-1; r++ {
+		for i, match := range bracket[r] {
+			slot := i%2 + 1
+			match.NextMatchID = &bracket[r+1][i/2].ID
+			match.NextMatchSlot = &slot
+		}
+	}
+
+	order := bracketSeedOrder(size)
+	seedTeam := func(seed int) *domain.TournamentTeam {
+		if seed > len(teams) {
+			return nil
+		}
+		return teams[seed-1]
+	}
+
+	matches := []*domain.TournamentMatch{}
+	court := 0
+	for i, match := range bracket[0] {
+		team1, team2 := seedTeam(order[2*i]), seedTeam(order[2*i+1])
+		if team1 != nil && team2 != nil {
+			setMatchSide(match, 1, team1)
+			setMatchSide(match, 2, team2)
+			match.Court = court%courts + 1
+			court++
+			matches = append(matches, match)
+			continue
+		}
+
+		// Пара без соперника сразу попадает в следующий раунд, матч первого раунда не создается
+		winner := team1
+		if winner == nil {
+			winner = team2
+		}
+		if len(bracket) > 1 && winner != nil {
+			setMatchSide(bracket[1][i/2], i%2+1, winner)
+		}
+	}
+
+	for _, roundMatches := range bracket[1:] {
+		matches = append(matches, roundMatches...)
+	}
+
+	return matches
+}
+
+// bracketSeedOrder возвращает порядок посевов в первом раунде: 1 против size, 2 против size-1 и т.д.,
+// так что сильнейшие посевы могут встретиться только в финале
+func bracketSeedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		n := len(order) * 2
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// scheduleAmericano составляет все раунды americano: в каждом раунде игроки получают новых партнеров и соперников,
+// а при нехватке кортов или игроков отдыхают по очереди те, кто сыграл больше
+func scheduleAmericano(players []*tournamentPlayer, courts, rounds int) []*domain.TournamentMatch {
+	n := len(players)
+	perRound := min(courts, n/4)
+
+	partners := map[[2]string]int{}
+	opponents := map[[2]string]int{}
+	games := map[string]int{}
+
+	matches := []*domain.TournamentMatch{}
+	for round := 1; round <= rounds; round++ {
+		// Порядок сдвигается каждый раунд, чтобы при равенстве отдыхали разные игроки
+		rotated := make([]*tournamentPlayer, n)
+		for i := range players {
+			rotated[i] = players[(i+round-1)%n]
+		}
+		sort.SliceStable(rotated, func(i, j int) bool {
+			return games[rotated[i].ID] < games[rotated[j].ID]
+		})
+		active := rotated[:perRound*4]
+
+		pairs := greedyPairs(active, func(a, b *tournamentPlayer) int {
+			return partners[pairKey(a.ID, b.ID)]
+		})
+
+		for position, sides := range greedyMatchups(pairs, opponents) {
+			matches = append(matches, newPlayersMatch(round, position+1, sides[0], sides[1]))
+			recordPairing(sides[0], sides[1], partners, opponents, games)
+		}
+	}
+
+	return matches
+}
+
+// scheduleMexicanoRound составляет раунд mexicano: игроки в порядке таблицы делятся на четверки,
+// в четверке первый играет с третьим против второго с четвертым
+func scheduleMexicanoRound(ordered []*tournamentPlayer, games map[string]int, courts, round int) []*domain.TournamentMatch {
+	perRound := min(courts, len(ordered)/4)
+
+	// Отдыхают игроки, сыгравшие больше всех, при равенстве — нижние в таблице
+	byGames := append([]*tournamentPlayer{}, ordered...)
+	sort.SliceStable(byGames, func(i, j int) bool {
+		return games[byGames[i].ID] < games[byGames[j].ID]
+	})
+	active := map[string]bool{}
+	for _, player := range byGames[:perRound*4] {
+		active[player.ID] = true
+	}
+
+	playing := []*tournamentPlayer{}
+	for _, player := range ordered {
+		if active[player.ID] {
+			playing = append(playing, player)
+		}
+	}
+
+	matches := []*domain.TournamentMatch{}
+	for i := 0; i+3 < len(playing); i += 4 {
+		group := playing[i : i+4]
+		side1 := []*tournamentPlayer{group[0], group[2]}
+		side2 := []*tournamentPlayer{group[1], group[3]}
+		if round == 1 {
+			// В первом раунде таблицы еще нет, пары выравниваются по рангу
+			side1 = []*tournamentPlayer{group[0], group[3]}
+			side2 = []*tournamentPlayer{group[1], group[2]}
+		}
+		matches = append(matches, newPlayersMatch(round, i/4+1, side1, side2))
+	}
+
+	return matches
+}
+
+func greedyPairs(players []*tournamentPlayer, cost func(a, b *tournamentPlayer) int) [][]*tournamentPlayer {
+	remaining := append([]*tournamentPlayer{}, players...)
+	pairs := [][]*tournamentPlayer{}
+
+	for len(remaining) >= 2 {
+		first := remaining[0]
+		best := 1
+		for i := 2; i < len(remaining); i++ {
+			if cost(first, remaining[i]) < cost(first, remaining[best]) {
+				best = i
+			}
+		}
+		pairs = append(pairs, []*tournamentPlayer{first, remaining[best]})
+		remaining = append(remaining[1:best], remaining[best+1:]...)
+	}
+
+	return pairs
+}
+
+func greedyMatchups(pairs [][]*tournamentPlayer, opponents map[[2]string]int) [][2][]*tournamentPlayer {
+	cost := func(a, b []*tournamentPlayer) int {
+		total := 0
+		for _, x := range a {
+			for _, y := range b {
+				total += opponents[pairKey(x.ID, y.ID)]
+			}
+		}
+		return total
+	}
+
+	remaining := append([][]*tournamentPlayer{}, pairs...)
+	matchups := [][2][]*tournamentPlayer{}
+
+	for len(remaining) >= 2 {
+		first := remaining[0]
+		best := 1
+		for i := 2; i < len(remaining); i++ {
+			if cost(first, remaining[i]) < cost(first, remaining[best]) {
+				best = i
+			}
+		}
+		matchups = append(matchups, [2][]*tournamentPlayer{first, remaining[best]})
+		remaining = append(remaining[1:best], remaining[best+1:]...)
+	}
+
+	return matchups
+}
+
+func recordPairing(side1, side2 []*tournamentPlayer, partners, opponents map[[2]string]int, games map[string]int) {
+	for _, side := range [][]*tournamentPlayer{side1, side2} {
+		partners[pairKey(side[0].ID, side[1].ID)]++
+		for _, player := range side {
+			games[player.ID]++
+		}
+	}
+	for _, x := range side1 {
+		for _, y := range side2 {
+			opponents[pairKey(x.ID, y.ID)]++
+		}
+	}
+}
+
+func pairKey(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+func sortPlayersByRank(players []*tournamentPlayer) []*tournamentPlayer {
+	sorted := append([]*tournamentPlayer{}, players...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Rank > sorted[j].Rank
+	})
+	return sorted
+}
+
+func newTeamMatch(round, court, position int, team1, team2 *domain.TournamentTeam) *domain.TournamentMatch {
+	match := &domain.TournamentMatch{
+		ID:       uuid.New().String(),
+		Round:    round,
+		Court:    court,
+		Position: position,
+		Status:   domain.MatchStatusScheduled,
+	}
+	setMatchSide(match, 1, team1)
+	setMatchSide(match, 2, team2)
+	return match
+}
+
+func newPlayersMatch(round, position int, side1, side2 []*tournamentPlayer) *domain.TournamentMatch {
+	ids := func(side []*tournamentPlayer) []string {
+		result := make([]string, 0, len(side))
+		for _, player := range side {
+			result = append(result, player.ID)
+		}
+		return result
+	}
+
+	return &domain.TournamentMatch{
+		ID:           uuid.New().String(),
+		Round:        round,
+		Court:        position,
+		Position:     position,
+		Team1Players: ids(side1),
+		Team2Players: ids(side2),
+		Status:       domain.MatchStatusScheduled,
+	}
+}
+
+func setMatchSide(match *domain.TournamentMatch, slot int, team *domain.TournamentTeam) {
+	if slot == 1 {
+		match.Team1ID = &team.ID
+		match.Team1Players = team.PlayerIDs
+		return
+	}
+	match.Team2ID = &team.ID
+	match.Team2Players = team.PlayerIDs
+}
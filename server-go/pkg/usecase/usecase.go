@@ -2,42 +2,55 @@ package usecase
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/go-telegram/bot"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/payments"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
 	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
 	"github.com/shampsdev/go-telegram-template/pkg/repo/s3"
 )
 
 type Cases struct {
-	User         *User
-	AdminUser    *AdminUser
-	Image        *Image
-	Court        *Court
-	Club         *Club
-	Loyalty      *Loyalty
-	Event        *Event
-	Registration *Registration
-	Payment      *Payment
-	Waitlist     *Waitlist
+	User                 *User
+	AdminUser            *AdminUser
+	Image                *Image
+	Court                *Court
+	Club                 *Club
+	Loyalty              *Loyalty
+	Event                *Event
+	EventSeries          *EventSeries
+	Registration         *Registration
+	RegistrationPair     *RegistrationPair
+	Payment              *Payment
+	Refund               *Refund
+	PromoCode            *PromoCode
+	Tournament           *Tournament
+	Rating               *Rating
+	Task                 *Task
+	Broadcast            *Broadcast
+	MessageTemplate      *MessageTemplate
+	NotificationSettings *NotificationSettings
+	Waitlist             *Waitlist
+	WaitlistOffer        *WaitlistOffer
+	WebhookEvent         *WebhookEvent
 }
 
-func Setup(ctx context.Context, cfg *config.Config, db *pgxpool.Pool) Cases {
-	userRepo := pg.NewUserRepo(db)
-	adminUserRepo := pg.NewAdminUserRepo(db)
-	courtRepo := pg.NewCourtRepo(db)
-	clubRepo := pg.NewClubRepo(db)
-	loyaltyRepo := pg.NewLoyaltyRepo(db)
-	registrationRepo := pg.NewRegistrationRepo(db)
-	paymentRepo := pg.NewPaymentRepo(db)
-	waitlistRepo := pg.NewWaitlistRepo(db)
-	eventRepo := pg.NewEventRepo(db)
+// Setup создает сценарии с хранилищем, платежным провайдером и ботом из конфига
+func Setup(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, notificationService *notifications.NotificationService) Cases {
 	storage, err := s3.NewStorage(cfg.S3)
 	if err != nil {
 		panic(err)
 	}
 
+	paymentProvider, err := payments.NewProvider(cfg)
+	if err != nil {
+		panic(err)
+	}
+
 	opts := []bot.Option{}
 	if cfg.Debug {
 		opts = append(opts, bot.WithDebug())
@@ -47,34 +60,100 @@ func Setup(ctx context.Context, cfg *config.Config, db *pgxpool.Pool) Cases {
 		panic(err)
 	}
 
-	// NATS и уведомления пока не используются
-	_ = cfg
+	return NewCases(ctx, cfg, db, notificationService, storage, paymentProvider, b)
+}
+
+// NewCases связывает сценарии между собой с переданными внешними зависимостями
+func NewCases(
+	ctx context.Context,
+	cfg *config.Config,
+	db *pgxpool.Pool,
+	notificationService *notifications.NotificationService,
+	storage repo.ImageStorage,
+	paymentProvider payments.PaymentProvider,
+	b *bot.Bot,
+) Cases {
+	userRepo := pg.NewUserRepo(db)
+	adminUserRepo := pg.NewAdminUserRepo(db)
+	courtRepo := pg.NewCourtRepo(db)
+	clubRepo := pg.NewClubRepo(db)
+	loyaltyRepo := pg.NewLoyaltyRepo(db)
+	registrationRepo := pg.NewRegistrationRepo(db)
+	registrationPairRepo := pg.NewRegistrationPairRepo(db)
+	paymentRepo := pg.NewPaymentRepo(db)
+	refundRepo := pg.NewRefundRepo(db)
+	promoCodeRepo := pg.NewPromoCodeRepo(db)
+	tournamentRepo := pg.NewTournamentRepo(db)
+	ratingRepo := pg.NewRatingRepo(db)
+	webhookEventRepo := pg.NewWebhookEventRepo(db)
+	taskRepo := pg.NewTaskRepo(db)
+	broadcastRepo := pg.NewBroadcastRepo(db)
+	messageTemplateRepo := pg.NewMessageTemplateRepo(db)
+	notificationSettingsRepo := pg.NewNotificationSettingsRepo(db)
+	waitlistRepo := pg.NewWaitlistRepo(db)
+	waitlistOfferRepo := pg.NewWaitlistOfferRepo(db)
+	eventRepo := pg.NewEventRepo(db)
+	eventSeriesRepo := pg.NewEventSeriesRepo(db)
+	txManager := pg.NewTxManager(db)
 
 	cases := &Cases{}
 
-	userCase := NewUser(ctx, userRepo, storage)
+	userCase := NewUser(ctx, userRepo, storage, cases) // нужен Rating
 	adminUserCase := NewAdminUser(ctx, adminUserRepo, cfg)
 	imageCase := NewImage(ctx, storage)
 	courtCase := NewCourt(ctx, courtRepo)
 	clubCase := NewClub(ctx, clubRepo)
-	loyaltyCase := NewLoyalty(ctx, loyaltyRepo)
+	webhookEventCase := NewWebhookEvent(ctx, webhookEventRepo)
+	taskCase := NewTask(ctx, taskRepo, notificationService)
+	messageTemplateCase := NewMessageTemplate(ctx, messageTemplateRepo, cfg, cases) // нужен User
+	notificationSettingsCase := NewNotificationSettings(ctx, notificationSettingsRepo)
 
-	eventCase := NewEvent(ctx, eventRepo, cfg, b, cases)              // нужен Registration
-	registrationCase := NewRegistration(ctx, registrationRepo, cases) // нужен Payment
-	paymentCase := NewPayment(ctx, paymentRepo, cfg, cases)           // нужен Event, Registration
-	waitlistCase := NewWaitlist(ctx, waitlistRepo, cases)             // нужен Event
+	loyaltyCase := NewLoyalty(ctx, loyaltyRepo, txManager, notificationService, cfg, cases)                      // нужен User
+	eventCase := NewEvent(ctx, eventRepo, cfg, b, cases)                                                         // нужен Registration, Tournament
+	eventSeriesCase := NewEventSeries(ctx, eventSeriesRepo, notificationService, cfg, b, cases)                  // нужен Event, Registration, Waitlist
+	registrationCase := NewRegistration(ctx, registrationRepo, txManager, notificationService, cfg, cases)       // нужен Payment
+	registrationPairCase := NewRegistrationPair(ctx, registrationPairRepo, cfg, b, cases)                        // нужен Event, Registration, Waitlist
+	paymentCase := NewPayment(ctx, paymentRepo, paymentProvider, txManager, notificationService, cfg, cases)     // нужен Event, Registration
+	refundCase := NewRefund(ctx, refundRepo, cases)                                                              // нужен Payment, Registration
+	promoCodeCase := NewPromoCode(ctx, promoCodeRepo, cases)                                                     // нужен Payment
+	tournamentCase := NewTournament(ctx, tournamentRepo, txManager, cases)                                       // нужен Event, Registration, Rating
+	ratingCase := NewRating(ctx, ratingRepo, txManager, cfg, cases)                                              // нужен User
+	waitlistCase := NewWaitlist(ctx, waitlistRepo, cases)                                                        // нужен Event
+	waitlistOfferCase := NewWaitlistOffer(ctx, waitlistOfferRepo, txManager, notificationService, cfg, b, cases) // нужен Event, Registration, Waitlist
+	broadcastCase := NewBroadcast(ctx, broadcastRepo, txManager, notificationService, cases)                     // нужен Registration, Waitlist, User
 
 	*cases = Cases{
-		User:         userCase,
-		AdminUser:    adminUserCase,
-		Image:        imageCase,
-		Court:        courtCase,
-		Club:         clubCase,
-		Loyalty:      loyaltyCase,
-		Event:        eventCase,
-		Registration: registrationCase,
-		Payment:      paymentCase,
-		Waitlist:     waitlistCase,
+		User:                 userCase,
+		AdminUser:            adminUserCase,
+		Image:                imageCase,
+		Court:                courtCase,
+		Club:                 clubCase,
+		Loyalty:              loyaltyCase,
+		Event:                eventCase,
+		EventSeries:          eventSeriesCase,
+		Registration:         registrationCase,
+		RegistrationPair:     registrationPairCase,
+		Payment:              paymentCase,
+		Refund:               refundCase,
+		PromoCode:            promoCodeCase,
+		Tournament:           tournamentCase,
+		Rating:               ratingCase,
+		Task:                 taskCase,
+		Broadcast:            broadcastCase,
+		MessageTemplate:      messageTemplateCase,
+		NotificationSettings: notificationSettingsCase,
+		Waitlist:             waitlistCase,
+		WaitlistOffer:        waitlistOfferCase,
+		WebhookEvent:         webhookEventCase,
+	}
+
+	// Локальный провайдер не присылает вебхуки, поэтому статус применяем сразу после автоподтверждения
+	if fakeProvider, ok := paymentProvider.(*payments.FakeProvider); ok {
+		fakeProvider.OnPaymentStatusChange(func(paymentID string) {
+			if err := paymentCase.SyncPaymentStatus(context.Background(), paymentID); err != nil {
+				slog.Error("Failed to sync fake payment status", "payment_id", paymentID, "error", err)
+			}
+		})
 	}
 
 	return *cases
@@ -20,6 +20,7 @@ import (
 type User struct {
 	userRepo repo.User
 	storage  repo.ImageStorage
+	cases    *Cases
 
 	tgDataCache sync.Map
 }
@@ -28,10 +29,12 @@ func NewUser(
 	ctx context.Context,
 	userRepo repo.User,
 	storage repo.ImageStorage,
+	cases *Cases,
 ) *User {
 	u := &User{
 		userRepo: userRepo,
 		storage:  storage,
+		cases:    cases,
 	}
 
 	go u.cacheCleaner(ctx)
@@ -62,7 +65,20 @@ func (u *User) GetByTelegramID(ctx context.Context, id int64) (*domain.User, err
 }
 
 func (u *User) GetMe(ctx Context) (*domain.User, error) {
-	return repo.First(u.userRepo.Filter)(ctx, &domain.FilterUser{ID: &ctx.User.ID})
+	return u.getMe(ctx)
+}
+
+// getMe профиль текущего пользователя вместе с настройками уведомлений
+func (u *User) getMe(ctx Context) (*domain.User, error) {
+	user, err := repo.First(u.userRepo.Filter)(ctx, &domain.FilterUser{ID: &ctx.User.ID})
+	if err != nil {
+		return nil, err
+	}
+	user.NotificationSettings, err = u.cases.NotificationSettings.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (u *User) PatchMe(ctx Context, patch *domain.PatchUser) (*domain.User, error) {
@@ -76,6 +92,10 @@ func (u *User) PatchMe(ctx Context, patch *domain.PatchUser) (*domain.User, erro
 	if patch.Rank != nil && *patch.Rank < 0 {
 		return nil, fmt.Errorf("rank cannot be negative")
 	}
+	// После первого рейтингового матча ранг считается по результатам, сохранение того же значения не считаем изменением
+	if patch.Rank != nil && ctx.User.RatedMatches > 0 && *patch.Rank != ctx.User.Rank {
+		return nil, ErrRankLocked
+	}
 	
 	// Преобразование пустых строк в nil для опциональных полей
 	if patch.BirthDate != nil && strings.TrimSpace(*patch.BirthDate) == "" {
@@ -98,13 +118,24 @@ func (u *User) PatchMe(ctx Context, patch *domain.PatchUser) (*domain.User, erro
 	if patch.PadelProfiles != nil && strings.TrimSpace(*patch.PadelProfiles) == "" {
 		patch.PadelProfiles = nil
 	}
+
+	// Настройки уведомлений хранятся отдельно, сохраняем их первыми: они могут не пройти проверку
+	if patch.NotificationSettings != nil {
+		if _, err := u.cases.NotificationSettings.Patch(ctx, ctx.User.ID, patch.NotificationSettings); err != nil {
+			return nil, err
+		}
+		// Изменились только настройки уведомлений, профиль обновлять не нужно
+		if *patch == (domain.PatchUser{NotificationSettings: patch.NotificationSettings}) {
+			return u.getMe(ctx)
+		}
+	}
 	
 	err := u.userRepo.Patch(ctx, ctx.User.ID, patch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to patch user: %w", err)
 	}
 	u.tgDataCache.Delete(ctx.User.TelegramID)
-	return repo.First(u.userRepo.Filter)(ctx, &domain.FilterUser{ID: &ctx.User.ID})
+	return u.getMe(ctx)
 }
 
 func (u *User) Filter(ctx Context, filter *domain.FilterUser) ([]*domain.User, error) {
@@ -137,6 +168,13 @@ func (u *User) AdminPatchUser(ctx context.Context, userID string, patch *domain.
 		PadelProfiles:   patch.PadelProfiles,
 		IsRegistered:    patch.IsRegistered,
 		LoyaltyID:       patch.LoyaltyID,
+		LoyaltyLocked:   patch.LoyaltyLocked,
+	}
+
+	// Уровень, назначенный вручную, закрепляем от автоматического пересчета
+	if patch.LoyaltyID != nil && patch.LoyaltyLocked == nil {
+		locked := true
+		patchUser.LoyaltyLocked = &locked
 	}
 	
 	if patch.FirstName != nil && strings.TrimSpace(*patch.FirstName) == "" {
@@ -168,11 +206,23 @@ func (u *User) AdminPatchUser(ctx context.Context, userID string, patch *domain.
 	if patch.PadelProfiles != nil && strings.TrimSpace(*patch.PadelProfiles) == "" {
 		patchUser.PadelProfiles = nil
 	}
+
+	current, err := repo.First(u.userRepo.Filter)(ctx, &domain.FilterUser{ID: &userID})
+	if err != nil {
+		return nil, err
+	}
 	
-	err := u.userRepo.Patch(ctx, userID, patchUser)
+	err = u.userRepo.Patch(ctx, userID, patchUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to patch user: %w", err)
 	}
+
+	// Ручная правка ранга попадает в историю, чтобы график ранга не расходился с текущим значением
+	if patch.Rank != nil && *patch.Rank != current.Rank {
+		if err := u.cases.Rating.RecordAdminChange(ctx, userID, current.Rank, *patch.Rank); err != nil {
+			return nil, err
+		}
+	}
 	
 	user, err := repo.First(u.userRepo.Filter)(ctx, &domain.FilterUser{ID: &userID})
 	if err != nil {
@@ -183,6 +233,16 @@ func (u *User) AdminPatchUser(ctx context.Context, userID string, patch *domain.
 	return user, nil
 }
 
+// SetLoyalty меняет уровень лояльности пользователя без закрепления (используется автоматическим пересчетом)
+func (u *User) SetLoyalty(ctx context.Context, user *domain.User, loyaltyID int) error {
+	err := u.userRepo.Patch(ctx, user.ID, &domain.PatchUser{LoyaltyID: &loyaltyID})
+	if err != nil {
+		return fmt.Errorf("failed to set user loyalty: %w", err)
+	}
+	u.tgDataCache.Delete(user.TelegramID)
+	return nil
+}
+
 func (u *User) GetByTGData(ctx context.Context, tgData *domain.UserTGData) (*domain.User, error) {
 	if u, ok := u.tgDataCache.Load(tgData.TelegramID); ok {
 		//nolint:errcheck// because sure
@@ -200,11 +260,20 @@ func (u *User) GetByTGData(ctx context.Context, tgData *domain.UserTGData) (*dom
 	if tgData.TelegramUsername != user.TelegramUsername {
 		needUpdate = true
 	}
+	// Язык обновляем, только если Telegram его прислал
+	if tgData.LanguageCode != "" && tgData.LanguageCode != user.LanguageCode {
+		needUpdate = true
+	}
 
 	if needUpdate {
-		err = u.userRepo.Patch(ctx, user.ID, &domain.PatchUser{
+		patch := &domain.PatchUser{
 			TelegramUsername: &tgData.TelegramUsername,
-		})
+		}
+		if tgData.LanguageCode != "" {
+			patch.LanguageCode = &tgData.LanguageCode
+			user.LanguageCode = tgData.LanguageCode
+		}
+		err = u.userRepo.Patch(ctx, user.ID, patch)
 		if err != nil {
 			return nil, fmt.Errorf("failed to update user: %w", err)
 		}
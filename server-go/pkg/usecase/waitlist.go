@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
@@ -60,6 +61,43 @@ func (w *Waitlist) GetEventWaitlistUsers(ctx context.Context, eventID string) ([
 	return waitlistUsers, nil
 }
 
+// GetUserPositions возвращает места пользователя в листах ожидания предстоящих событий
+func (w *Waitlist) GetUserPositions(ctx context.Context, userID string) ([]*domain.WaitlistPosition, error) {
+	entries, err := w.waitlistRepo.Filter(ctx, &domain.FilterWaitlist{UserID: &userID})
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]*domain.WaitlistPosition, 0, len(entries))
+	for _, entry := range entries {
+		event, err := w.cases.Event.GetEventByID(ctx, entry.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event: %w", err)
+		}
+		if !event.EndTime.IsZero() && event.EndTime.Before(time.Now()) {
+			continue
+		}
+
+		waitlist, err := w.GetEventWaitlist(ctx, entry.EventID)
+		if err != nil {
+			return nil, err
+		}
+		position := &domain.WaitlistPosition{Event: event, Total: len(waitlist)}
+		for i, item := range waitlist {
+			if item.ID == entry.ID {
+				position.Position = i + 1
+				break
+			}
+		}
+		positions = append(positions, position)
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Event.StartTime.Before(positions[j].Event.StartTime)
+	})
+	return positions, nil
+}
+
 func (w *Waitlist) AddToWaitlist(ctx context.Context, userID, eventID string) (*domain.Waitlist, error) {
 	slog.Info("User attempting to join waitlist",
 		"user_id", userID,
@@ -223,6 +261,25 @@ func (w *Waitlist) RemoveFromWaitlist(ctx context.Context, userID, eventID strin
 		return fmt.Errorf("failed to remove from waitlist: %w", err)
 	}
 
+	// Запись пары удаляется вместе с заявкой пары
+	if waitlists[0].PairID != nil {
+		if err := w.cases.RegistrationPair.CancelWaitlistedPair(ctx, *waitlists[0].PairID); err != nil {
+			slog.Error("Failed to cancel waitlisted pair",
+				"user_id", userID,
+				"event_id", eventID,
+				"pair_id", *waitlists[0].PairID,
+				"error", err)
+		}
+	}
+
+	// Удерживаемое за пользователем место предлагается следующему
+	if err := w.cases.WaitlistOffer.Withdraw(ctx, userID, eventID); err != nil {
+		slog.Error("Failed to withdraw waitlist offer",
+			"user_id", userID,
+			"event_id", eventID,
+			"error", err)
+	}
+
 	slog.Info("Successfully removed user from waitlist",
 		"user_id", userID,
 		"event_id", eventID,
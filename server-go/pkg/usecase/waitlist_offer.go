@@ -0,0 +1,449 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/notifications"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"gopadel/taskcontract/tgcallback"
+)
+
+var (
+	ErrWaitlistOfferNotFound = errors.New("waitlist offer not found")
+	// ErrWaitlistOfferClosed на предложение уже ответили, оно истекло или отозвано
+	ErrWaitlistOfferClosed = errors.New("waitlist offer is no longer available")
+)
+
+// waitlistOfferTimeLayout время окончания предложения в тексте уведомления
+const waitlistOfferTimeLayout = "02.01 15:04"
+
+// WaitlistOffer предложения освободившихся мест из листа ожидания. Место предлагается следующему
+// в очереди и удерживается за ним, пока он не ответит или не истечет окно ответа. После отказа
+// или истечения место предлагается следующему. Истечение отслеживает воркер по задаче
+// waitlist.offer.expire, кроме того просроченные предложения закрываются при каждом разборе листа ожидания
+type WaitlistOffer struct {
+	offerRepo           repo.WaitlistOffer
+	tx                  repo.Transactor
+	notificationService *notifications.NotificationService
+	config              *config.Config
+	bot                 *bot.Bot
+	cases               *Cases
+}
+
+func NewWaitlistOffer(ctx context.Context, offerRepo repo.WaitlistOffer, tx repo.Transactor, notificationService *notifications.NotificationService, cfg *config.Config, b *bot.Bot, cases *Cases) *WaitlistOffer {
+	return &WaitlistOffer{
+		offerRepo:           offerRepo,
+		tx:                  tx,
+		notificationService: notificationService,
+		config:              cfg,
+		bot:                 b,
+		cases:               cases,
+	}
+}
+
+func (w *WaitlistOffer) GetByID(ctx context.Context, id string) (*domain.WaitlistOffer, error) {
+	offer, err := repo.First(w.offerRepo.Filter)(ctx, &domain.FilterWaitlistOffer{ID: &id})
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrWaitlistOfferNotFound
+	}
+	return offer, err
+}
+
+// HeldSeats количество мест, удерживаемых активными предложениями. Предложения пользователей
+// exceptUserIDs не учитываются: они занимают свое же место при регистрации
+func (w *WaitlistOffer) HeldSeats(ctx context.Context, eventID string, exceptUserIDs ...string) (int, error) {
+	offers, err := w.offerRepo.Filter(ctx, &domain.FilterWaitlistOffer{
+		EventID:  &eventID,
+		Statuses: []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get waitlist offers: %w", err)
+	}
+
+	now := time.Now()
+	held := 0
+	for _, offer := range offers {
+		if offer.ExpiresAt.Before(now) || slices.Contains(exceptUserIDs, offer.UserID) {
+			continue
+		}
+		held++
+	}
+	return held, nil
+}
+
+// Offer предлагает место пользователю из листа ожидания. false, если у него уже есть активное
+// предложение или свободных мест не осталось
+func (w *WaitlistOffer) Offer(ctx context.Context, event *domain.Event, entry *domain.Waitlist) (bool, error) {
+	settings, err := w.cases.NotificationSettings.Get(ctx, entry.UserID)
+	if err != nil {
+		slog.Warn("Failed to get notification settings for waitlist offer, using defaults", "user_id", entry.UserID, "error", err)
+		defaults := domain.DefaultNotificationSettings()
+		settings = &defaults
+	}
+
+	// В тихие часы окно ответа отсчитывается от их окончания, но не дольше, чем до начала события
+	windowStart := time.Now()
+	quietUntil, quiet := settings.QuietUntil(windowStart)
+	if quiet && settings.Allows(domain.NotificationCategoryWaitlist) {
+		windowStart = quietUntil
+	}
+	expiresAt := windowStart.Add(w.config.Waitlist.OfferWindow)
+	if !event.StartTime.IsZero() && event.StartTime.Before(expiresAt) {
+		expiresAt = event.StartTime
+	}
+
+	// Предложение создается под блокировкой события, как и регистрация, поэтому два параллельных
+	// разбора листа ожидания не предложат одно место дважды. Задача истечения сохраняется вместе
+	// с предложением. Без сервиса уведомлений предложение закроется при следующем разборе листа ожидания
+	var id string
+	err = w.tx.WithinTx(ctx, func(txCtx context.Context) error {
+		if err := w.cases.Event.Lock(txCtx, event.ID); err != nil {
+			return fmt.Errorf("failed to lock event: %w", err)
+		}
+
+		pending, err := w.offerRepo.Filter(txCtx, &domain.FilterWaitlistOffer{
+			EventID:  &event.ID,
+			UserID:   &entry.UserID,
+			Statuses: []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get waitlist offers: %w", err)
+		}
+		if len(pending) > 0 {
+			return nil
+		}
+		freeSeats, err := w.cases.Event.freeSeats(txCtx, event)
+		if err != nil {
+			return err
+		}
+		if freeSeats <= 0 {
+			return nil
+		}
+
+		id, err = w.offerRepo.Create(txCtx, &domain.CreateWaitlistOffer{
+			EventID:   event.ID,
+			UserID:    entry.UserID,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		if w.notificationService == nil {
+			return nil
+		}
+		if err := w.notificationService.SendWaitlistOfferExpire(txCtx, id, expiresAt); err != nil {
+			return fmt.Errorf("failed to schedule waitlist offer expiration: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if id == "" {
+		return false, nil
+	}
+
+	slog.Info("Waitlist offer created",
+		"offer_id", id,
+		"event_id", event.ID,
+		"user_id", entry.UserID,
+		"expires_at", expiresAt)
+
+	w.sendOffer(ctx, id, event, entry.User, settings, expiresAt)
+	return true, nil
+}
+
+// Accept регистрирует пользователя на место из предложения. Регистрация и закрытие предложения
+// выполняются в одной транзакции под блокировкой события: если предложение успело истечь
+// или его отозвали, регистрация откатывается
+func (w *WaitlistOffer) Accept(ctx context.Context, user *domain.User, offerID string) (*domain.Registration, error) {
+	offer, err := w.openOffer(ctx, user, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пока предложение активно, место удерживается за пользователем и регистрация его не учитывает
+	registration, err := w.cases.Registration.registerForEvent(ctx, user, offer.EventID, func(txCtx context.Context) error {
+		if err := w.cases.Event.Lock(txCtx, offer.EventID); err != nil {
+			return fmt.Errorf("failed to lock event: %w", err)
+		}
+		ok, err := w.offerRepo.TransitionStatus(txCtx, offer.ID, []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending}, domain.WaitlistOfferStatusAccepted)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrWaitlistOfferClosed
+		}
+		return nil
+	})
+	if errors.Is(err, ErrWaitlistOfferClosed) {
+		slog.Warn("Waitlist offer was closed while registering",
+			"offer_id", offer.ID,
+			"event_id", offer.EventID,
+			"user_id", user.ID)
+		return nil, ErrWaitlistOfferClosed
+	}
+	if err != nil {
+		slog.Warn("Failed to register user from waitlist offer",
+			"offer_id", offer.ID,
+			"event_id", offer.EventID,
+			"user_id", user.ID,
+			"error", err)
+		if _, closeErr := w.close(ctx, offer, domain.WaitlistOfferStatusCancelled); closeErr != nil {
+			return nil, closeErr
+		}
+		w.cascade(ctx, offer.EventID)
+		return nil, err
+	}
+	w.removeFromWaitlist(ctx, offer)
+
+	slog.Info("Waitlist offer accepted",
+		"offer_id", offer.ID,
+		"event_id", offer.EventID,
+		"user_id", user.ID)
+	return registration, nil
+}
+
+// Decline отказ от предложения, место предлагается следующему
+func (w *WaitlistOffer) Decline(ctx context.Context, user *domain.User, offerID string) error {
+	offer, err := w.openOffer(ctx, user, offerID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := w.close(ctx, offer, domain.WaitlistOfferStatusDeclined)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWaitlistOfferClosed
+	}
+
+	slog.Info("Waitlist offer declined",
+		"offer_id", offer.ID,
+		"event_id", offer.EventID,
+		"user_id", user.ID)
+	w.cascade(ctx, offer.EventID)
+	return nil
+}
+
+// Expire закрывает предложение, на которое не ответили, и предлагает место следующему.
+// Вызывается по запросу воркера, уже закрытые предложения пропускаются
+func (w *WaitlistOffer) Expire(ctx context.Context, offerID string) error {
+	offer, err := w.GetByID(ctx, offerID)
+	if errors.Is(err, ErrWaitlistOfferNotFound) {
+		slog.Warn("Waitlist offer to expire not found", "offer_id", offerID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	expired, err := w.expire(ctx, offer)
+	if err != nil || !expired {
+		return err
+	}
+	w.cascade(ctx, offer.EventID)
+	return nil
+}
+
+// ExpireOverdue закрывает просроченные предложения события на случай, если задача воркера не выполнилась.
+// Место следующему не предлагается: вызывается из разбора листа ожидания
+func (w *WaitlistOffer) ExpireOverdue(ctx context.Context, eventID string) error {
+	now := time.Now()
+	offers, err := w.offerRepo.Filter(ctx, &domain.FilterWaitlistOffer{
+		EventID:       &eventID,
+		Statuses:      []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending},
+		ExpiresBefore: &now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get overdue waitlist offers: %w", err)
+	}
+
+	for _, offer := range offers {
+		if _, err := w.expire(ctx, offer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Withdraw отзывает активное предложение пользователя, который сам вышел из листа ожидания
+func (w *WaitlistOffer) Withdraw(ctx context.Context, userID, eventID string) error {
+	offers, err := w.offerRepo.Filter(ctx, &domain.FilterWaitlistOffer{
+		EventID:  &eventID,
+		UserID:   &userID,
+		Statuses: []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get waitlist offers: %w", err)
+	}
+
+	withdrawn := false
+	for _, offer := range offers {
+		ok, err := w.offerRepo.TransitionStatus(ctx, offer.ID, []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending}, domain.WaitlistOfferStatusCancelled)
+		if err != nil {
+			return err
+		}
+		withdrawn = withdrawn || ok
+	}
+	if withdrawn {
+		w.cascade(ctx, eventID)
+	}
+	return nil
+}
+
+// openOffer активное предложение пользователя. Просроченное предложение закрывается
+func (w *WaitlistOffer) openOffer(ctx context.Context, user *domain.User, offerID string) (*domain.WaitlistOffer, error) {
+	offer, err := w.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != user.ID {
+		return nil, ErrWaitlistOfferNotFound
+	}
+	if offer.Status != domain.WaitlistOfferStatusPending {
+		return nil, ErrWaitlistOfferClosed
+	}
+	if offer.ExpiresAt.Before(time.Now()) {
+		if expired, err := w.expire(ctx, offer); err != nil {
+			return nil, err
+		} else if expired {
+			w.cascade(ctx, offer.EventID)
+		}
+		return nil, ErrWaitlistOfferClosed
+	}
+	return offer, nil
+}
+
+// expire переводит предложение в expired и сообщает об этом пользователю
+func (w *WaitlistOffer) expire(ctx context.Context, offer *domain.WaitlistOffer) (bool, error) {
+	ok, err := w.close(ctx, offer, domain.WaitlistOfferStatusExpired)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	slog.Info("Waitlist offer expired",
+		"offer_id", offer.ID,
+		"event_id", offer.EventID,
+		"user_id", offer.UserID)
+	w.sendExpired(ctx, offer)
+	return true, nil
+}
+
+// close закрывает активное предложение и убирает пользователя из листа ожидания, чтобы место
+// не предлагалось ему повторно
+func (w *WaitlistOffer) close(ctx context.Context, offer *domain.WaitlistOffer, status domain.WaitlistOfferStatus) (bool, error) {
+	ok, err := w.offerRepo.TransitionStatus(ctx, offer.ID, []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending}, status)
+	if err != nil || !ok {
+		return ok, err
+	}
+	w.removeFromWaitlist(ctx, offer)
+	return true, nil
+}
+
+func (w *WaitlistOffer) removeFromWaitlist(ctx context.Context, offer *domain.WaitlistOffer) {
+	entries, err := w.cases.Waitlist.Filter(ctx, &domain.FilterWaitlist{UserID: &offer.UserID, EventID: &offer.EventID})
+	if err != nil {
+		slog.Error("Failed to get waitlist entry of offer", "offer_id", offer.ID, "error", err)
+		return
+	}
+	for _, entry := range entries {
+		if err := w.cases.Waitlist.Delete(ctx, entry.ID); err != nil {
+			slog.Error("Failed to delete waitlist entry of offer",
+				"offer_id", offer.ID,
+				"waitlist_id", entry.ID,
+				"error", err)
+		}
+	}
+}
+
+// cascade предлагает освободившееся место следующему в листе ожидания
+func (w *WaitlistOffer) cascade(ctx context.Context, eventID string) {
+	if err := w.cases.Event.TryRegisterFromWaitlist(ctx, eventID); err != nil {
+		slog.Error("Failed to offer seat to next user in waitlist", "event_id", eventID, "error", err)
+	}
+}
+
+// sendOffer сообщает о предложении, если пользователь не отключил уведомления листа ожидания.
+// В тихие часы сообщение отправляется без звука
+func (w *WaitlistOffer) sendOffer(ctx context.Context, offerID string, event *domain.Event, user *domain.User, settings *domain.NotificationSettings, expiresAt time.Time) {
+	if !settings.Allows(domain.NotificationCategoryWaitlist) {
+		return
+	}
+	log := slog.With("offer_id", offerID, "event_id", event.ID, "user_id", user.ID)
+
+	location, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	message, err := w.cases.MessageTemplate.RenderForUser(ctx, MessageTemplateWaitlistOffer, user, map[string]any{
+		"event_id":   event.ID,
+		"event_name": event.Name,
+		"event_url":  fmt.Sprintf("https://t.me/%s/app?startapp=%s", w.config.TG.BotUsername, event.ID),
+		"expires_at": expiresAt.In(location).Format(waitlistOfferTimeLayout),
+	})
+	if err != nil {
+		log.Warn("Failed to render waitlist offer", "error", err)
+		return
+	}
+
+	params := sendMessageParams(user.TelegramID, message)
+	if _, quiet := settings.QuietUntil(time.Now()); quiet {
+		params.DisableNotification = true
+	}
+	accept, acceptErr := tgcallback.Sign(w.config.TG.CallbackSecret, user.TelegramID, tgcallback.ActionOfferAccept, offerID)
+	decline, declineErr := tgcallback.Sign(w.config.TG.CallbackSecret, user.TelegramID, tgcallback.ActionOfferDecline, offerID)
+	if err := errors.Join(acceptErr, declineErr); err != nil {
+		log.Warn("Failed to sign waitlist offer buttons, sending without buttons", "error", err)
+	} else {
+		params.ReplyMarkup = &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{Text: "✅ Участвую", CallbackData: accept},
+			{Text: "❌ Отказаться", CallbackData: decline},
+		}}}
+	}
+
+	if _, err := w.bot.SendMessage(ctx, params); err != nil {
+		log.Warn("Failed to send waitlist offer", "error", err)
+	}
+}
+
+func (w *WaitlistOffer) sendExpired(ctx context.Context, offer *domain.WaitlistOffer) {
+	if !w.cases.NotificationSettings.Allows(ctx, offer.UserID, domain.NotificationCategoryWaitlist) {
+		return
+	}
+	log := slog.With("offer_id", offer.ID, "event_id", offer.EventID, "user_id", offer.UserID)
+
+	user, err := repo.First(w.cases.User.AdminFilter)(ctx, &domain.FilterUser{ID: &offer.UserID})
+	if err != nil {
+		log.Warn("Failed to get user of expired waitlist offer", "error", err)
+		return
+	}
+	event, err := w.cases.Event.GetEventByID(ctx, offer.EventID)
+	if err != nil {
+		log.Warn("Failed to get event of expired waitlist offer", "error", err)
+		return
+	}
+
+	message, err := w.cases.MessageTemplate.RenderForUser(ctx, MessageTemplateWaitlistOfferExpired, user, map[string]any{
+		"event_id":   event.ID,
+		"event_name": event.Name,
+		"event_url":  fmt.Sprintf("https://t.me/%s/app?startapp=%s", w.config.TG.BotUsername, event.ID),
+	})
+	if err == nil {
+		_, err = w.bot.SendMessage(ctx, sendMessageParams(user.TelegramID, message))
+	}
+	if err != nil {
+		log.Warn("Failed to send waitlist offer expiration", "error", err)
+	}
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+)
+
+type WebhookEvent struct {
+	webhookEventRepo repo.WebhookEvent
+}
+
+func NewWebhookEvent(ctx context.Context, webhookEventRepo repo.WebhookEvent) *WebhookEvent {
+	return &WebhookEvent{
+		webhookEventRepo: webhookEventRepo,
+	}
+}
+
+// Register записывает уведомление в журнал. Для повторной доставки возвращается существующая запись,
+// у уже обработанного уведомления заполнен ProcessedAt
+func (w *WebhookEvent) Register(ctx context.Context, event *domain.CreateWebhookEvent) (*domain.WebhookEvent, error) {
+	id, err := w.webhookEventRepo.Create(ctx, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register webhook event: %w", err)
+	}
+
+	events, err := w.webhookEventRepo.Filter(ctx, &domain.FilterWebhookEvent{ID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook event: %w", err)
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("webhook event not found")
+	}
+
+	return events[0], nil
+}
+
+func (w *WebhookEvent) MarkProcessed(ctx context.Context, id string) error {
+	now := time.Now()
+	return w.webhookEventRepo.Patch(ctx, id, &domain.PatchWebhookEvent{ProcessedAt: &now})
+}
+
+func (w *WebhookEvent) MarkFailed(ctx context.Context, id string, processErr error) error {
+	lastError := processErr.Error()
+	return w.webhookEventRepo.Patch(ctx, id, &domain.PatchWebhookEvent{LastError: &lastError})
+}
@@ -0,0 +1,137 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+func assertOccurrences(t *testing.T, got []time.Time, want ...time.Time) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d occurrences, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Occurrence %d: expected %s, got %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestDailyRecurrenceWithInterval(t *testing.T) {
+	first := time.Date(2026, 3, 1, 19, 0, 0, 0, time.UTC)
+	rule := &domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyDaily, Interval: 2}
+
+	got := usecase.RecurrenceOccurrences(rule, first, first.Add(-time.Second), first.AddDate(0, 0, 6))
+
+	assertOccurrences(t, got,
+		first,
+		first.AddDate(0, 0, 2),
+		first.AddDate(0, 0, 4),
+		first.AddDate(0, 0, 6),
+	)
+}
+
+func TestWeeklyRecurrenceByWeekday(t *testing.T) {
+	// Среда: понедельник этой недели раньше первого занятия и пропускается
+	first := time.Date(2026, 3, 4, 19, 0, 0, 0, time.UTC)
+	rule := &domain.RecurrenceRule{
+		Frequency: domain.RecurrenceFrequencyWeekly,
+		ByWeekday: []string{"FR", "MO", "WE"},
+	}
+
+	got := usecase.RecurrenceOccurrences(rule, first, first.Add(-time.Second), first.AddDate(0, 0, 9))
+
+	assertOccurrences(t, got,
+		first,
+		time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 9, 19, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 11, 19, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 13, 19, 0, 0, 0, time.UTC),
+	)
+}
+
+func TestMonthlyRecurrenceSkipsShortMonths(t *testing.T) {
+	first := time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC)
+	rule := &domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyMonthly}
+
+	got := usecase.RecurrenceOccurrences(rule, first, first.Add(-time.Second), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	assertOccurrences(t, got,
+		first,
+		time.Date(2026, 3, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, 5, 31, 10, 0, 0, 0, time.UTC),
+	)
+}
+
+func TestRecurrenceCountIncludesMaterializedOccurrences(t *testing.T) {
+	first := time.Date(2026, 3, 1, 19, 0, 0, 0, time.UTC)
+	rule := &domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyDaily, Count: shared.IntPtr(3)}
+
+	// Первые два занятия уже созданы, COUNT оставляет только третье
+	got := usecase.RecurrenceOccurrences(rule, first, first.AddDate(0, 0, 1), first.AddDate(0, 1, 0))
+
+	assertOccurrences(t, got, first.AddDate(0, 0, 2))
+}
+
+func TestRecurrenceStopsAtUntil(t *testing.T) {
+	first := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 16, 19, 0, 0, 0, time.UTC)
+	rule := &domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyWeekly, Until: &until}
+
+	got := usecase.RecurrenceOccurrences(rule, first, first.Add(-time.Second), first.AddDate(1, 0, 0))
+
+	assertOccurrences(t, got, first, first.AddDate(0, 0, 7), until)
+}
+
+func TestRecurrenceKeepsLocalTimeAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+
+	// Переход на летнее время 29 марта 2026: занятие остается в 19:00 по местному времени
+	first := time.Date(2026, 3, 26, 19, 0, 0, 0, berlin)
+	rule := &domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyWeekly}
+
+	got := usecase.RecurrenceOccurrences(rule, first, first.Add(-time.Second), first.AddDate(0, 0, 7))
+
+	assertOccurrences(t, got, first, time.Date(2026, 4, 2, 19, 0, 0, 0, berlin))
+	if got[1].Sub(got[0]) == 7*24*time.Hour {
+		t.Errorf("Expected the week across DST to be an hour shorter, got %s", got[1].Sub(got[0]))
+	}
+}
+
+func TestValidateRecurrenceRule(t *testing.T) {
+	first := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+	before := first.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		rule domain.RecurrenceRule
+	}{
+		{"weekday for daily", domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyDaily, ByWeekday: []string{"MO"}}},
+		{"unknown weekday", domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyWeekly, ByWeekday: []string{"XX"}}},
+		{"unknown frequency", domain.RecurrenceRule{Frequency: "yearly"}},
+		{"zero count", domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyDaily, Count: shared.IntPtr(0)}},
+		{"until before first", domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyDaily, Until: &before}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := usecase.ValidateRecurrenceRule(&tt.rule, first); !errors.Is(err, usecase.ErrInvalidEventSeries) {
+				t.Errorf("Expected ErrInvalidEventSeries, got %v", err)
+			}
+		})
+	}
+
+	valid := domain.RecurrenceRule{Frequency: domain.RecurrenceFrequencyWeekly, ByWeekday: []string{"MO", "TH"}}
+	if err := usecase.ValidateRecurrenceRule(&valid, first); err != nil {
+		t.Errorf("Expected valid rule, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package loyalty_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+// TestRecalculationDemotesUser пользователь на уровне с условиями, которые он больше не выполняет,
+// после пересчета возвращается на базовый уровень
+func TestRecalculationDemotesUser(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+
+	userRepo := pg.NewUserRepo(pool)
+	loyaltyRepo := pg.NewLoyaltyRepo(pool)
+
+	suffix := time.Now().UnixNano()
+	defaultLevelID := createLevel(t, loyaltyRepo, &domain.CreateLoyalty{
+		Name:     fmt.Sprintf("Default %d", suffix),
+		Discount: 0,
+	})
+	highLevelID := createLevel(t, loyaltyRepo, &domain.CreateLoyalty{
+		Name:     fmt.Sprintf("High %d", suffix),
+		Discount: 50,
+		Rules:    &domain.LoyaltyRules{MinConfirmedEvents: 1_000_000},
+	})
+	t.Cleanup(func() {
+		_ = loyaltyRepo.Delete(context.Background(), highLevelID)
+		_ = loyaltyRepo.Delete(context.Background(), defaultLevelID)
+	})
+
+	userID, err := userRepo.Create(ctx, &domain.CreateUser{UserTGData: domain.UserTGData{
+		TelegramID: suffix % 1_000_000_000 * 100,
+		FirstName:  "Loyalty",
+		LastName:   "Demoted",
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	if err := userRepo.Patch(ctx, userID, &domain.PatchUser{LoyaltyID: &highLevelID}); err != nil {
+		t.Fatalf("Failed to assign loyalty level: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Loyalty.DefaultLevelID = defaultLevelID
+
+	cases := &usecase.Cases{}
+	cases.User = usecase.NewUser(ctx, userRepo, nil, cases)
+	cases.Loyalty = usecase.NewLoyalty(ctx, loyaltyRepo, pg.NewTxManager(pool), nil, cfg, cases)
+
+	if err := cases.Loyalty.RecalculateUserLoyalty(ctx, userID); err != nil {
+		t.Fatalf("Failed to recalculate loyalty: %v", err)
+	}
+
+	user, err := repo.First(userRepo.Filter)(ctx, &domain.FilterUser{ID: &userID})
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.Loyalty == nil {
+		t.Fatalf("Expected user to keep a loyalty level after demotion")
+	}
+	if user.Loyalty.ID == highLevelID {
+		t.Fatalf("Expected user to be demoted from level %d", highLevelID)
+	}
+	if user.Loyalty.Discount >= 50 {
+		t.Errorf("Expected lower discount after demotion, got %d", user.Loyalty.Discount)
+	}
+}
+
+func createLevel(t *testing.T, loyaltyRepo *pg.LoyaltyRepo, level *domain.CreateLoyalty) int {
+	t.Helper()
+
+	id, err := loyaltyRepo.Create(context.Background(), level)
+	if err != nil {
+		t.Fatalf("Failed to create loyalty level: %v", err)
+	}
+	levelID, err := strconv.Atoi(id)
+	if err != nil {
+		t.Fatalf("Unexpected loyalty level id %q: %v", id, err)
+	}
+	return levelID
+}
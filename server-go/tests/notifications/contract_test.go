@@ -0,0 +1,117 @@
+package notifications_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gopadel/taskcontract"
+)
+
+// TestTaskContractRoundTrip данные, закодированные сервером, воркер разбирает в ту же структуру
+func TestTaskContractRoundTrip(t *testing.T) {
+	sent := &taskcontract.Reminder{
+		UserTelegramID: 123456789,
+		TournamentID:   "tournament-1",
+		TournamentName: "Кубок",
+		IsPaid:         true,
+	}
+
+	data, err := taskcontract.Encode(taskcontract.TaskTypeTournamentReminder24Hours, sent)
+	if err != nil {
+		t.Fatalf("Failed to encode task data: %v", err)
+	}
+
+	payload, err := taskcontract.Decode(taskcontract.TaskTypeTournamentReminder24Hours, taskcontract.SchemaVersion, data)
+	if err != nil {
+		t.Fatalf("Failed to decode task data: %v", err)
+	}
+
+	received, ok := payload.(*taskcontract.Reminder)
+	if !ok {
+		t.Fatalf("Decoded payload has type %T, expected *taskcontract.Reminder", payload)
+	}
+	if *received != *sent {
+		t.Errorf("Decoded payload %+v, expected %+v", received, sent)
+	}
+}
+
+// TestEventTaskContractRoundTrip данные события, включая время начала и часовой пояс корта, доходят до воркера без изменений
+func TestEventTaskContractRoundTrip(t *testing.T) {
+	sent := &taskcontract.EventReminder{
+		UserTelegramID: 123456789,
+		Event: taskcontract.Event{
+			EventID:       "event-1",
+			EventName:     "Игра в субботу",
+			EventType:     "game",
+			StartTime:     time.Date(2026, 10, 24, 15, 0, 0, 0, time.UTC),
+			CourtName:     "Корт №1",
+			CourtAddress:  "ул. Спортивная, 1",
+			CourtTimezone: "Asia/Yekaterinburg",
+		},
+		IsPaid: false,
+	}
+
+	data, err := taskcontract.Encode(taskcontract.TaskTypeEventReminder48Hours, sent)
+	if err != nil {
+		t.Fatalf("Failed to encode task data: %v", err)
+	}
+
+	payload, err := taskcontract.Decode(taskcontract.TaskTypeEventReminder48Hours, taskcontract.SchemaVersion, data)
+	if err != nil {
+		t.Fatalf("Failed to decode task data: %v", err)
+	}
+
+	received, ok := payload.(*taskcontract.EventReminder)
+	if !ok {
+		t.Fatalf("Decoded payload has type %T, expected *taskcontract.EventReminder", payload)
+	}
+	if !received.StartTime.Equal(sent.StartTime) {
+		t.Errorf("Decoded start time %v, expected %v", received.StartTime, sent.StartTime)
+	}
+	received.StartTime = sent.StartTime
+	if *received != *sent {
+		t.Errorf("Decoded payload %+v, expected %+v", received, sent)
+	}
+
+	// Задача о событии без времени начала не может показать его участнику
+	_, err = taskcontract.Decode(taskcontract.TaskTypeEventReminder48Hours, taskcontract.SchemaVersion,
+		[]byte(`{"user_telegram_id": 123, "event_id": "event-1", "event_type": "game"}`))
+	if !errors.Is(err, taskcontract.ErrInvalidPayload) {
+		t.Errorf("Decode error %v, expected %v", err, taskcontract.ErrInvalidPayload)
+	}
+}
+
+// TestTaskContractRejectsInvalidTasks задачи, которые воркер не сможет выполнить, отклоняются с понятной ошибкой
+func TestTaskContractRejectsInvalidTasks(t *testing.T) {
+	cases := []struct {
+		name     string
+		taskType taskcontract.TaskType
+		version  int
+		data     string
+		expected error
+	}{
+		{"unknown task type", "tournament.unknown", taskcontract.SchemaVersion, `{}`, taskcontract.ErrUnknownTaskType},
+		{"future schema version", taskcontract.TaskTypeBroadcastSend, taskcontract.SchemaVersion + 1, `{"broadcast_id": "b-1"}`, taskcontract.ErrUnsupportedSchemaVersion},
+		{"malformed json", taskcontract.TaskTypeBroadcastSend, taskcontract.SchemaVersion, `{"broadcast_id":`, taskcontract.ErrInvalidPayload},
+		{"wrong field type", taskcontract.TaskTypeTournamentPaymentSuccess, taskcontract.SchemaVersion, `{"user_telegram_id": "123", "tournament_id": "t-1"}`, taskcontract.ErrInvalidPayload},
+		{"unknown field", taskcontract.TaskTypeWaitlistOfferExpire, taskcontract.SchemaVersion, `{"offer_id": "o-1", "offerId": "o-1"}`, taskcontract.ErrInvalidPayload},
+		{"missing required field", taskcontract.TaskTypeTournamentRegistrationAutoDeleteUnpaid, taskcontract.SchemaVersion, `{"user_telegram_id": 123, "tournament_id": "t-1"}`, taskcontract.ErrInvalidPayload},
+		{"payment deadline without event", taskcontract.TaskTypeEventPaymentDeadline, taskcontract.SchemaVersion, `{"user_telegram_id": 123}`, taskcontract.ErrInvalidPayload},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := taskcontract.Decode(c.taskType, c.version, []byte(c.data))
+			if !errors.Is(err, c.expected) {
+				t.Errorf("Decode error %v, expected %v", err, c.expected)
+			}
+		})
+	}
+
+	// Сервер не может записать в outbox данные чужого типа
+	_, err := taskcontract.Encode(taskcontract.TaskTypeBroadcastSend, &taskcontract.WaitlistOfferExpire{OfferID: "o-1"})
+	if !errors.Is(err, taskcontract.ErrInvalidPayload) {
+		t.Errorf("Encode error %v, expected %v", err, taskcontract.ErrInvalidPayload)
+	}
+}
@@ -0,0 +1,91 @@
+package notifications_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+// TestOutboxFollowsTransaction задача из откаченной транзакции не должна попасть к relay,
+// а из зафиксированной — должна быть выдана relay ровно один раз
+func TestOutboxFollowsTransaction(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+
+	txManager := pg.NewTxManager(pool)
+	outboxRepo := pg.NewOutboxRepo(pool)
+
+	create := func(marker string) *domain.CreateOutboxMessage {
+		return &domain.CreateOutboxMessage{
+			TaskType:      "tournament.registration.success",
+			SchemaVersion: 1,
+			ExecuteAt:     time.Now(),
+			Data:          json.RawMessage(fmt.Sprintf(`{"marker": %q}`, marker)),
+		}
+	}
+
+	var rolledBackID string
+	errRollback := errors.New("rollback")
+	err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := outboxRepo.Create(ctx, create("rolled back"))
+		if err != nil {
+			return err
+		}
+		rolledBackID = id
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Expected rollback error, got %v", err)
+	}
+
+	var committedID string
+	err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := outboxRepo.Create(ctx, create("committed"))
+		committedID = id
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit outbox message: %v", err)
+	}
+
+	claimed := map[string]bool{}
+	for {
+		messages, err := outboxRepo.Claim(ctx, 100, time.Minute)
+		if err != nil {
+			t.Fatalf("Failed to claim outbox messages: %v", err)
+		}
+		for _, message := range messages {
+			claimed[message.ID] = true
+			if err := outboxRepo.MarkSent(ctx, message.ID); err != nil {
+				t.Fatalf("Failed to mark outbox message sent: %v", err)
+			}
+		}
+		if len(messages) < 100 {
+			break
+		}
+	}
+
+	if claimed[rolledBackID] {
+		t.Errorf("Outbox message from rolled back transaction was claimed")
+	}
+	if !claimed[committedID] {
+		t.Errorf("Committed outbox message was not claimed")
+	}
+
+	messages, err := outboxRepo.Claim(ctx, 100, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to claim outbox messages: %v", err)
+	}
+	for _, message := range messages {
+		if message.ID == committedID {
+			t.Errorf("Sent outbox message was claimed again")
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package notifications_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+)
+
+func quietHours(start, end, timezone string) domain.NotificationSettings {
+	settings := domain.DefaultNotificationSettings()
+	settings.QuietHoursStart = &start
+	settings.QuietHoursEnd = &end
+	settings.Timezone = timezone
+	return settings
+}
+
+// TestQuietUntil тихие часы на сервере считаются так же, как в воркере
+func TestQuietUntil(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		settings  domain.NotificationSettings
+		now       time.Time
+		wantQuiet bool
+		wantUntil time.Time
+	}{
+		{
+			name:      "inside daytime interval",
+			settings:  quietHours("13:00", "15:30", "UTC"),
+			now:       time.Date(2026, 5, 10, 14, 0, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 10, 15, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "end of interval is not quiet",
+			settings: quietHours("13:00", "15:30", "UTC"),
+			now:      time.Date(2026, 5, 10, 15, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "overnight interval before midnight ends next day",
+			settings:  quietHours("22:00", "08:00", "UTC"),
+			now:       time.Date(2026, 5, 31, 23, 30, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "overnight interval after midnight ends same day",
+			settings:  quietHours("22:00", "08:00", "UTC"),
+			now:       time.Date(2026, 5, 11, 3, 0, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "user timezone",
+			settings:  quietHours("22:00", "08:00", "Europe/Moscow"),
+			now:       time.Date(2026, 5, 10, 20, 0, 0, 0, time.UTC), // 23:00 в Москве
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 11, 8, 0, 0, 0, moscow),
+		},
+		{
+			name:     "quiet hours not set",
+			settings: domain.DefaultNotificationSettings(),
+			now:      time.Date(2026, 5, 10, 3, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := tt.settings.QuietUntil(tt.now)
+			if quiet != tt.wantQuiet {
+				t.Fatalf("Expected quiet=%v, got %v", tt.wantQuiet, quiet)
+			}
+			if quiet && !until.Equal(tt.wantUntil) {
+				t.Errorf("Expected quiet hours until %s, got %s", tt.wantUntil, until)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package payments_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/payments"
+)
+
+// TestRetriedRefundIsNotExecutedTwice повтор возврата с тем же ключом идемпотентности возвращает
+// уже созданный возврат, а новый ключ дает новую операцию
+func TestRetriedRefundIsNotExecutedTwice(t *testing.T) {
+	ctx := context.Background()
+	provider := payments.NewFakeProvider(time.Millisecond)
+
+	payment, err := provider.CreatePayment(ctx, &payments.CreatePaymentRequest{
+		IdempotenceKey: payments.IdempotenceKey("payment", "user", "event", "0", "1000"),
+		Amount:         1000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+
+	retried, err := provider.CreatePayment(ctx, &payments.CreatePaymentRequest{
+		IdempotenceKey: payments.IdempotenceKey("payment", "user", "event", "0", "1000"),
+		Amount:         1000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to retry payment: %v", err)
+	}
+	if retried.ID != payment.ID {
+		t.Errorf("Expected retried payment %s, got new payment %s", payment.ID, retried.ID)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		current, err := provider.GetPayment(ctx, payment.ID)
+		if err != nil {
+			t.Fatalf("Failed to get payment: %v", err)
+		}
+		if current.Status == domain.PaymentStatusSucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Fake payment was not confirmed, status %s", current.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	refundRequest := func(key string) *payments.CreateRefundRequest {
+		return &payments.CreateRefundRequest{
+			IdempotenceKey: key,
+			PaymentID:      payment.ID,
+			Amount:         500,
+		}
+	}
+
+	key := payments.IdempotenceKey("refund", "refund-1")
+	first, err := provider.CreateRefund(ctx, refundRequest(key))
+	if err != nil {
+		t.Fatalf("Failed to create refund: %v", err)
+	}
+	second, err := provider.CreateRefund(ctx, refundRequest(key))
+	if err != nil {
+		t.Fatalf("Failed to retry refund: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("Expected retried refund %s, got new refund %s", first.ID, second.ID)
+	}
+
+	other, err := provider.CreateRefund(ctx, refundRequest(payments.IdempotenceKey("refund", "refund-2")))
+	if err != nil {
+		t.Fatalf("Failed to create second refund: %v", err)
+	}
+	if other.ID == first.ID {
+		t.Errorf("Expected a new refund for a different idempotence key")
+	}
+}
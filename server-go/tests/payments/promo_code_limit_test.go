@@ -0,0 +1,146 @@
+package payments_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+// TestConcurrentPromoCodeUsesRespectLimit N пользователей одновременно оплачивают с промокодом на M применений:
+// платеж с промокодом должны создать ровно M, остальные — получить ошибку о лимите
+func TestConcurrentPromoCodeUsesRespectLimit(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+
+	const maxUses = 3
+	const players = 12
+
+	userRepo := pg.NewUserRepo(pool)
+	eventRepo := pg.NewEventRepo(pool)
+	paymentRepo := pg.NewPaymentRepo(pool)
+	promoCodeRepo := pg.NewPromoCodeRepo(pool)
+	txManager := pg.NewTxManager(pool)
+	promoCodes := usecase.NewPromoCode(ctx, promoCodeRepo, nil)
+
+	telegramIDBase := time.Now().UnixNano() % 1_000_000_000 * 100
+	users := make([]*domain.User, 0, players)
+	for i := 0; i < players; i++ {
+		id, err := userRepo.Create(ctx, &domain.CreateUser{UserTGData: domain.UserTGData{
+			TelegramID: telegramIDBase + int64(i),
+			FirstName:  "Promo",
+			LastName:   fmt.Sprintf("Player %d", i),
+		}})
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		users = append(users, &domain.User{ID: id})
+	}
+
+	eventID, err := eventRepo.Create(ctx, &domain.CreateEvent{
+		Name:        fmt.Sprintf("Promo Tournament %d", time.Now().Unix()),
+		StartTime:   time.Now().Add(24 * time.Hour),
+		EndTime:     time.Now().Add(26 * time.Hour),
+		RankMin:     0.0,
+		RankMax:     7.0,
+		Price:       1000,
+		MaxUsers:    players,
+		Type:        domain.EventTypeTournament,
+		CourtID:     "4ea67445-b73a-4b5b-b200-cc7f98b7f102",
+		OrganizerID: users[0].ID,
+		ClubID:      shared.StringPtr("global"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	promoCodeID, err := promoCodeRepo.Create(ctx, &domain.CreatePromoCode{
+		Code:          fmt.Sprintf("LIMIT%d", time.Now().UnixNano()),
+		DiscountType:  domain.PromoDiscountTypePercent,
+		DiscountValue: 10,
+		MaxUses:       shared.IntPtr(maxUses),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create promo code: %v", err)
+	}
+	promoCode, err := repo.First(promoCodeRepo.Filter)(ctx, &domain.FilterPromoCode{ID: &promoCodeID})
+	if err != nil {
+		t.Fatalf("Failed to get promo code: %v", err)
+	}
+
+	var paymentIDs sync.Map
+	t.Cleanup(func() {
+		paymentIDs.Range(func(id, _ any) bool {
+			paymentRepo.Delete(ctx, id.(string))
+			return true
+		})
+		promoCodeRepo.Delete(ctx, promoCodeID)
+		eventRepo.Delete(ctx, eventID)
+		for _, user := range users {
+			userRepo.Delete(ctx, user.ID)
+		}
+	})
+
+	var used, rejected atomic.Int32
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func(i int, user *domain.User) {
+			defer wg.Done()
+			<-start
+
+			err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+				if err := promoCodes.ReserveUse(ctx, promoCode, user); err != nil {
+					return err
+				}
+				id, err := paymentRepo.Create(ctx, &domain.CreatePayment{
+					PaymentID:   fmt.Sprintf("promo-limit-%d-%d", telegramIDBase, i),
+					Amount:      900,
+					Status:      domain.PaymentStatusPending,
+					UserID:      user.ID,
+					EventID:     eventID,
+					PromoCodeID: &promoCodeID,
+				})
+				if err == nil {
+					paymentIDs.Store(id, true)
+				}
+				return err
+			})
+			switch {
+			case err == nil:
+				used.Add(1)
+			case errors.Is(err, usecase.ErrPromoCodeNotApplicable):
+				rejected.Add(1)
+			default:
+				t.Errorf("Unexpected promo code error: %v", err)
+			}
+		}(i, user)
+	}
+	close(start)
+	wg.Wait()
+
+	if used.Load() != maxUses {
+		t.Errorf("Expected %d payments with promo code, got %d", maxUses, used.Load())
+	}
+	if rejected.Load() != players-maxUses {
+		t.Errorf("Expected %d payments rejected by promo code limit, got %d", players-maxUses, rejected.Load())
+	}
+
+	uses, err := promoCodeRepo.CountUses(ctx, promoCodeID, nil)
+	if err != nil {
+		t.Fatalf("Failed to count promo code uses: %v", err)
+	}
+	if uses != maxUses {
+		t.Errorf("Expected %d promo code uses in database, got %d", maxUses, uses)
+	}
+}
@@ -0,0 +1,217 @@
+package rating_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+const initialRank = 3.0
+
+// TestConcurrentMatchesKeepRankConsistentWithHistory матчи с общими игроками пересчитываются одновременно:
+// итоговый ранг каждого игрока должен совпадать с начальным плюс сумма изменений из истории
+func TestConcurrentMatchesKeepRankConsistentWithHistory(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	env := newRatingEnv(t, pool, 5, 5)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(env.tournament.Matches))
+	for i, match := range env.tournament.Matches {
+		wg.Add(1)
+		go func(match *domain.TournamentMatch, score1 int) {
+			defer wg.Done()
+			score2 := 10 - score1
+			match.Score1, match.Score2 = &score1, &score2
+			errs <- env.rating.ApplyMatchResult(ctx, env.eventID, match)
+		}(match, 6+i%3)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Failed to apply match result: %v", err)
+		}
+	}
+
+	for _, userID := range env.userIDs {
+		user := env.getUser(t, userID)
+		history, err := env.rating.GetHistory(ctx, &domain.FilterRatingChange{UserID: &userID})
+		if err != nil {
+			t.Fatalf("Failed to get rating history: %v", err)
+		}
+
+		expected := initialRank
+		for _, change := range history {
+			expected += change.Delta
+		}
+		if math.Abs(user.Rank-expected) > 1e-9 {
+			t.Errorf("User %s: rank %v does not match history %v", userID, user.Rank, expected)
+		}
+		if user.RatedMatches != len(history) {
+			t.Errorf("User %s: rated matches %d, history has %d changes", userID, user.RatedMatches, len(history))
+		}
+	}
+}
+
+// TestMatchCorrectionAndRevert исправление счета пересчитывает ранг от значения до матча,
+// а удаление турнира откатывает изменения ранга и удаляет их из истории
+func TestMatchCorrectionAndRevert(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	env := newRatingEnv(t, pool, 4, 1)
+	match := env.tournament.Matches[0]
+
+	score1, score2 := 6, 2
+	match.Score1, match.Score2 = &score1, &score2
+	if err := env.rating.ApplyMatchResult(ctx, env.eventID, match); err != nil {
+		t.Fatalf("Failed to apply match result: %v", err)
+	}
+	winner := env.getUser(t, match.Team1Players[0])
+	if winner.Rank <= initialRank || winner.RatedMatches != 1 {
+		t.Fatalf("Expected winner rank above %v after 1 match, got %v after %d", initialRank, winner.Rank, winner.RatedMatches)
+	}
+
+	// Исправление: победила вторая пара
+	score1, score2 = 2, 6
+	if err := env.rating.ApplyMatchResult(ctx, env.eventID, match); err != nil {
+		t.Fatalf("Failed to correct match result: %v", err)
+	}
+	corrected := env.getUser(t, match.Team1Players[0])
+	if corrected.Rank >= initialRank || corrected.RatedMatches != 1 {
+		t.Errorf("Expected corrected rank below %v after 1 match, got %v after %d", initialRank, corrected.Rank, corrected.RatedMatches)
+	}
+	history, err := env.rating.GetHistory(ctx, &domain.FilterRatingChange{MatchID: &match.ID})
+	if err != nil {
+		t.Fatalf("Failed to get rating history: %v", err)
+	}
+	if len(history) != 4 {
+		t.Errorf("Expected 4 rating changes after correction, got %d", len(history))
+	}
+
+	// Матч с изменениями ранга нельзя удалить, не откатив их
+	if err := env.tournamentRepo.Delete(ctx, env.eventID); err == nil {
+		t.Fatalf("Expected tournament with rated matches not to be deleted directly")
+	}
+
+	if err := env.rating.RevertMatchResult(ctx, match.ID); err != nil {
+		t.Fatalf("Failed to revert match result: %v", err)
+	}
+	for _, userID := range env.userIDs {
+		user := env.getUser(t, userID)
+		if math.Abs(user.Rank-initialRank) > 1e-9 || user.RatedMatches != 0 {
+			t.Errorf("User %s: expected rank %v without rated matches, got %v after %d", userID, initialRank, user.Rank, user.RatedMatches)
+		}
+	}
+
+	if err := env.tournamentRepo.Delete(ctx, env.eventID); err != nil {
+		t.Errorf("Failed to delete tournament after revert: %v", err)
+	}
+}
+
+type ratingEnv struct {
+	eventID        string
+	userIDs        []string
+	tournament     *domain.Tournament
+	tournamentRepo *pg.TournamentRepo
+	userRepo       *pg.UserRepo
+	rating         *usecase.Rating
+}
+
+func newRatingEnv(t *testing.T, pool *pgxpool.Pool, players, rounds int) *ratingEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	userRepo := pg.NewUserRepo(pool)
+	eventRepo := pg.NewEventRepo(pool)
+	tournamentRepo := pg.NewTournamentRepo(pool)
+
+	rank := initialRank
+	telegramIDBase := time.Now().UnixNano() % 1_000_000_000 * 100
+	users := make([]*domain.User, 0, players)
+	userIDs := make([]string, 0, players)
+	for i := 0; i < players; i++ {
+		id, err := userRepo.Create(ctx, &domain.CreateUser{UserTGData: domain.UserTGData{
+			TelegramID: telegramIDBase + int64(i),
+			FirstName:  "Rated",
+			LastName:   fmt.Sprintf("Player %d", i),
+		}})
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		if err := userRepo.Patch(ctx, id, &domain.PatchUser{Rank: &rank}); err != nil {
+			t.Fatalf("Failed to set user rank: %v", err)
+		}
+		users = append(users, &domain.User{ID: id, Rank: rank})
+		userIDs = append(userIDs, id)
+	}
+
+	eventID, err := eventRepo.Create(ctx, &domain.CreateEvent{
+		Name:        fmt.Sprintf("Rated Tournament %d", time.Now().UnixNano()),
+		StartTime:   time.Now().Add(-2 * time.Hour),
+		EndTime:     time.Now().Add(-time.Hour),
+		RankMin:     0.0,
+		RankMax:     7.0,
+		MaxUsers:    players,
+		Type:        domain.EventTypeTournament,
+		CourtID:     "4ea67445-b73a-4b5b-b200-cc7f98b7f102",
+		OrganizerID: userIDs[0],
+		ClubID:      shared.StringPtr("global"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	tournament, err := usecase.ScheduleTournament(eventID, users, &domain.CreateTournament{
+		Format: domain.TournamentFormatAmericano,
+		Courts: 1,
+		Rounds: rounds,
+	})
+	if err != nil {
+		t.Fatalf("Failed to schedule tournament: %v", err)
+	}
+	if err := tournamentRepo.Save(ctx, tournament); err != nil {
+		t.Fatalf("Failed to save tournament: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Rating.KFactor = 0.2
+	cfg.Rating.ProvisionalKFactor = 0.4
+	cfg.Rating.ProvisionalMatches = 10
+	cfg.Rating.Scale = 2
+	cfg.Rating.MaxRank = 7
+
+	cases := &usecase.Cases{}
+	cases.User = usecase.NewUser(ctx, userRepo, nil, cases)
+	cases.Rating = usecase.NewRating(ctx, pg.NewRatingRepo(pool), pg.NewTxManager(pool), cfg, cases)
+
+	return &ratingEnv{
+		eventID:        eventID,
+		userIDs:        userIDs,
+		tournament:     tournament,
+		tournamentRepo: tournamentRepo,
+		userRepo:       userRepo,
+		rating:         cases.Rating,
+	}
+}
+
+func (e *ratingEnv) getUser(t *testing.T, userID string) *domain.User {
+	t.Helper()
+
+	user, err := repo.First(e.userRepo.Filter)(context.Background(), &domain.FilterUser{ID: &userID})
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	return user
+}
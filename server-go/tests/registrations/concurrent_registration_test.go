@@ -0,0 +1,238 @@
+package registrations_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+// TestConcurrentRegistrationForLastSeats N игроков одновременно занимают места на событии на M мест:
+// регистрацию должны получить ровно M, остальные — ошибку о заполненном событии
+func TestConcurrentRegistrationForLastSeats(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+
+	const capacity = 4
+	const players = 24
+
+	userRepo := pg.NewUserRepo(pool)
+	eventRepo := pg.NewEventRepo(pool)
+	registrationRepo := pg.NewRegistrationRepo(pool)
+
+	telegramIDBase := time.Now().UnixNano() % 1_000_000_000 * 100
+	userIDs := make([]string, 0, players+1)
+	for i := 0; i <= players; i++ {
+		id, err := userRepo.Create(ctx, &domain.CreateUser{UserTGData: domain.UserTGData{
+			TelegramID: telegramIDBase + int64(i),
+			FirstName:  "Concurrent",
+			LastName:   fmt.Sprintf("Player %d", i),
+		}})
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	organizerID, playerIDs := userIDs[0], userIDs[1:]
+
+	eventID, err := eventRepo.Create(ctx, &domain.CreateEvent{
+		Name:        fmt.Sprintf("Concurrent Tournament %d", time.Now().Unix()),
+		StartTime:   time.Now().Add(24 * time.Hour),
+		EndTime:     time.Now().Add(26 * time.Hour),
+		RankMin:     0.0,
+		RankMax:     7.0,
+		Price:       1000,
+		MaxUsers:    capacity,
+		Type:        domain.EventTypeTournament,
+		CourtID:     "4ea67445-b73a-4b5b-b200-cc7f98b7f102",
+		OrganizerID: organizerID,
+		ClubID:      shared.StringPtr("global"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	t.Cleanup(func() {
+		for _, userID := range playerIDs {
+			registrationRepo.Delete(ctx, userID, eventID)
+		}
+		eventRepo.Delete(ctx, eventID)
+		for _, userID := range userIDs {
+			userRepo.Delete(ctx, userID)
+		}
+	})
+
+	var registered, full atomic.Int32
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, userID := range playerIDs {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			<-start
+
+			err := registrationRepo.OccupySeats(ctx, eventID, []*domain.CreateRegistration{{
+				UserID:  userID,
+				EventID: eventID,
+				Status:  domain.RegistrationStatusPending,
+			}})
+			switch {
+			case err == nil:
+				registered.Add(1)
+			case errors.Is(err, repo.ErrEventFull):
+				full.Add(1)
+			default:
+				t.Errorf("Unexpected registration error: %v", err)
+			}
+		}(userID)
+	}
+	close(start)
+	wg.Wait()
+
+	if registered.Load() != capacity {
+		t.Errorf("Expected %d successful registrations, got %d", capacity, registered.Load())
+	}
+	if full.Load() != players-capacity {
+		t.Errorf("Expected %d registrations rejected as full, got %d", players-capacity, full.Load())
+	}
+
+	status := domain.RegistrationStatusPending
+	registrations, err := registrationRepo.Filter(ctx, &domain.FilterRegistration{EventID: &eventID, Status: &status})
+	if err != nil {
+		t.Fatalf("Failed to get registrations: %v", err)
+	}
+	if len(registrations) != capacity {
+		t.Errorf("Expected %d registrations in database, got %d", capacity, len(registrations))
+	}
+
+	event, err := repo.First(eventRepo.Filter)(ctx, &domain.FilterEvent{ID: &eventID})
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+	if event.Status != domain.EventStatusFull {
+		t.Errorf("Expected event status %s, got %s", domain.EventStatusFull, event.Status)
+	}
+}
+
+// TestConcurrentRegisterForEvent то же через сценарий регистрации: проверка места, статус события
+// и уведомления выполняются вместе с занятием места, лишних регистраций быть не должно
+func TestConcurrentRegisterForEvent(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, _ := shared.NewTestCases(t, pool)
+
+	const capacity = 3
+	const players = 16
+
+	userRepo := pg.NewUserRepo(pool)
+	eventRepo := pg.NewEventRepo(pool)
+	registrationRepo := pg.NewRegistrationRepo(pool)
+
+	telegramIDBase := time.Now().UnixNano() % 1_000_000_000 * 100
+	users := make([]*domain.User, 0, players+1)
+	for i := 0; i <= players; i++ {
+		id, err := userRepo.Create(ctx, &domain.CreateUser{UserTGData: domain.UserTGData{
+			TelegramID: telegramIDBase + int64(i),
+			FirstName:  "Concurrent",
+			LastName:   fmt.Sprintf("Usecase Player %d", i),
+		}})
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		user, err := repo.First(userRepo.Filter)(ctx, &domain.FilterUser{ID: &id})
+		if err != nil {
+			t.Fatalf("Failed to get test user: %v", err)
+		}
+		users = append(users, user)
+	}
+	organizer, playerUsers := users[0], users[1:]
+
+	eventID, err := eventRepo.Create(ctx, &domain.CreateEvent{
+		Name:        fmt.Sprintf("Concurrent Usecase Tournament %d", time.Now().UnixNano()),
+		StartTime:   time.Now().Add(24 * time.Hour),
+		EndTime:     time.Now().Add(26 * time.Hour),
+		RankMin:     0.0,
+		RankMax:     7.0,
+		Price:       1000,
+		MaxUsers:    capacity,
+		Type:        domain.EventTypeTournament,
+		CourtID:     "4ea67445-b73a-4b5b-b200-cc7f98b7f102",
+		OrganizerID: organizer.ID,
+		ClubID:      shared.StringPtr("global"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	t.Cleanup(func() {
+		for _, user := range playerUsers {
+			registrationRepo.Delete(ctx, user.ID, eventID)
+		}
+		eventRepo.Delete(ctx, eventID)
+		for _, user := range users {
+			userRepo.Delete(ctx, user.ID)
+		}
+	})
+
+	var registered, full atomic.Int32
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, user := range playerUsers {
+		wg.Add(1)
+		go func(user *domain.User) {
+			defer wg.Done()
+			<-start
+
+			registration, err := cases.Registration.RegisterForEvent(ctx, user, eventID)
+			switch {
+			case err == nil:
+				if registration.Status != domain.RegistrationStatusPending {
+					t.Errorf("Expected pending registration, got %s", registration.Status)
+				}
+				registered.Add(1)
+			case errors.Is(err, repo.ErrEventFull):
+				full.Add(1)
+			default:
+				// Опоздавшие могут увидеть событие уже заполненным до попытки занять место
+				if err.Error() == "all spots for this event are taken" {
+					full.Add(1)
+					return
+				}
+				t.Errorf("Unexpected registration error: %v", err)
+			}
+		}(user)
+	}
+	close(start)
+	wg.Wait()
+
+	if registered.Load() != capacity {
+		t.Errorf("Expected %d successful registrations, got %d", capacity, registered.Load())
+	}
+	if full.Load() != players-capacity {
+		t.Errorf("Expected %d registrations rejected as full, got %d", players-capacity, full.Load())
+	}
+
+	count, err := cases.Registration.GetActiveRegistrationsCount(ctx, eventID)
+	if err != nil {
+		t.Fatalf("Failed to count registrations: %v", err)
+	}
+	if count != capacity {
+		t.Errorf("Expected %d active registrations in database, got %d", capacity, count)
+	}
+
+	event, err := repo.First(eventRepo.Filter)(ctx, &domain.FilterEvent{ID: &eventID})
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+	if event.Status != domain.EventStatusFull {
+		t.Errorf("Expected event status %s, got %s", domain.EventStatusFull, event.Status)
+	}
+}
@@ -0,0 +1,52 @@
+package registrations_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+func TestPaidTournamentFlow(t *testing.T) {
+	client := shared.NewClient()
+	userToken, adminToken := shared.SkipIfNoTokens(t)
+	shared.SkipIfNoFakePayments(t)
+
+	t.Run("Registration is confirmed after fake payment succeeds", func(t *testing.T) {
+		tournament := shared.CreateTestTournament(t, client, adminToken)
+		defer shared.CleanupEvent(client, adminToken, tournament.ID)
+
+		_, err := client.CreateRegistration(userToken, tournament.ID)
+		if err != nil {
+			t.Fatalf("Failed to create registration: %v", err)
+		}
+
+		payment, err := client.CreatePayment(userToken, tournament.ID)
+		if err != nil {
+			t.Fatalf("Failed to create payment: %v", err)
+		}
+
+		if payment.PaymentID == "" {
+			t.Fatal("Expected payment id")
+		}
+
+		deadline := time.Now().Add(30 * time.Second)
+		for time.Now().Before(deadline) {
+			registrations, err := client.GetMyRegistrations(userToken)
+			if err != nil {
+				t.Fatalf("Failed to get registrations: %v", err)
+			}
+
+			for _, registration := range registrations {
+				if registration.EventID == tournament.ID && registration.Status == domain.RegistrationStatusConfirmed {
+					return
+				}
+			}
+
+			time.Sleep(time.Second)
+		}
+
+		t.Error("Expected registration to become CONFIRMED after payment")
+	})
+}
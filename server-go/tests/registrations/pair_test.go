@@ -0,0 +1,192 @@
+package registrations_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+type pairFixture struct {
+	captain *domain.User
+	partner *domain.User
+	eventID string
+	pairID  string
+}
+
+// newPairFixture создает турнир, капитана со скидкой лояльности, партнера без скидки
+// и зарегистрированную пару, в которой капитан оплачивает оба места
+func newPairFixture(t *testing.T, pool *pgxpool.Pool) *pairFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	userRepo := pg.NewUserRepo(pool)
+	eventRepo := pg.NewEventRepo(pool)
+	loyaltyRepo := pg.NewLoyaltyRepo(pool)
+	registrationRepo := pg.NewRegistrationRepo(pool)
+	pairRepo := pg.NewRegistrationPairRepo(pool)
+	paymentRepo := pg.NewPaymentRepo(pool)
+
+	suffix := time.Now().UnixNano()
+	id, err := loyaltyRepo.Create(ctx, &domain.CreateLoyalty{
+		Name:     fmt.Sprintf("Pair Captain %d", suffix),
+		Discount: 10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create loyalty level: %v", err)
+	}
+	levelID, err := strconv.Atoi(id)
+	if err != nil {
+		t.Fatalf("Unexpected loyalty level id %q: %v", id, err)
+	}
+
+	createUser := func(i int64, lastName string) string {
+		id, err := userRepo.Create(ctx, &domain.CreateUser{UserTGData: domain.UserTGData{
+			TelegramID: suffix%1_000_000_000*100 + i,
+			FirstName:  "Pair",
+			LastName:   lastName,
+		}})
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		return id
+	}
+	captainID := createUser(0, "Captain")
+	partnerID := createUser(1, "Partner")
+
+	if err := userRepo.Patch(ctx, captainID, &domain.PatchUser{LoyaltyID: &levelID}); err != nil {
+		t.Fatalf("Failed to assign loyalty level: %v", err)
+	}
+
+	eventID, err := eventRepo.Create(ctx, &domain.CreateEvent{
+		Name:        fmt.Sprintf("Pair Tournament %d", suffix),
+		StartTime:   time.Now().Add(24 * time.Hour),
+		EndTime:     time.Now().Add(26 * time.Hour),
+		RankMin:     0.0,
+		RankMax:     7.0,
+		Price:       1000,
+		MaxUsers:    8,
+		Type:        domain.EventTypeTournament,
+		CourtID:     "4ea67445-b73a-4b5b-b200-cc7f98b7f102",
+		OrganizerID: captainID,
+		ClubID:      shared.StringPtr("global"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		for _, userID := range []string{captainID, partnerID} {
+			payments, _ := paymentRepo.Filter(ctx, &domain.FilterPayment{UserID: &userID, EventID: &eventID})
+			for _, payment := range payments {
+				_ = paymentRepo.Delete(ctx, payment.ID)
+			}
+			_ = registrationRepo.Delete(ctx, userID, eventID)
+		}
+		_ = eventRepo.Delete(ctx, eventID)
+		_ = userRepo.Delete(ctx, captainID)
+		_ = userRepo.Delete(ctx, partnerID)
+		_ = loyaltyRepo.Delete(ctx, levelID)
+	})
+
+	for _, userID := range []string{captainID, partnerID} {
+		if err := registrationRepo.Create(ctx, &domain.CreateRegistration{
+			UserID:  userID,
+			EventID: eventID,
+			Status:  domain.RegistrationStatusPending,
+		}); err != nil {
+			t.Fatalf("Failed to create registration: %v", err)
+		}
+	}
+
+	pairID, err := pairRepo.Create(ctx, &domain.CreateRegistrationPair{
+		EventID:     eventID,
+		CaptainID:   captainID,
+		PartnerID:   partnerID,
+		PaymentMode: domain.PairPaymentModeCaptain,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pair: %v", err)
+	}
+	if _, err := pairRepo.TransitionStatus(ctx, pairID,
+		[]domain.RegistrationPairStatus{domain.RegistrationPairStatusInvited}, domain.RegistrationPairStatusRegistered); err != nil {
+		t.Fatalf("Failed to register pair: %v", err)
+	}
+
+	getUser := func(id string) *domain.User {
+		user, err := repo.First(userRepo.Filter)(ctx, &domain.FilterUser{ID: &id})
+		if err != nil {
+			t.Fatalf("Failed to get user: %v", err)
+		}
+		return user
+	}
+
+	return &pairFixture{
+		captain: getUser(captainID),
+		partner: getUser(partnerID),
+		eventID: eventID,
+		pairID:  pairID,
+	}
+}
+
+// TestCaptainPaymentDiscountsOnlyOwnSeat скидка лояльности капитана применяется только к его месту,
+// место партнера без скидки оплачивается полностью
+func TestCaptainPaymentDiscountsOnlyOwnSeat(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, _ := shared.NewTestCases(t, pool)
+	f := newPairFixture(t, pool)
+
+	payment, err := cases.Payment.CreateProviderPayment(ctx, f.captain, f.eventID, "https://example.com", "")
+	if err != nil {
+		t.Fatalf("Failed to create captain payment: %v", err)
+	}
+
+	if payment.Amount != 1900 {
+		t.Errorf("Expected 900 for the captain seat and 1000 for the partner seat, got %d", payment.Amount)
+	}
+	if payment.OriginalAmount == nil || *payment.OriginalAmount != 2000 {
+		t.Errorf("Expected original amount 2000, got %v", payment.OriginalAmount)
+	}
+}
+
+// TestCancelRegistrationCancelsPartner отмена регистрации одним из партнеров снимает второго и отменяет пару
+func TestCancelRegistrationCancelsPartner(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, _ := shared.NewTestCases(t, pool)
+	f := newPairFixture(t, pool)
+
+	if _, err := cases.Registration.CancelEventRegistration(ctx, f.captain, f.eventID); err != nil {
+		t.Fatalf("Failed to cancel captain registration: %v", err)
+	}
+
+	registrations, err := cases.Registration.GetRegistrationsByUserAndEvent(ctx, f.partner.ID, f.eventID)
+	if err != nil {
+		t.Fatalf("Failed to get partner registration: %v", err)
+	}
+	if len(registrations) != 1 || registrations[0].Status != domain.RegistrationStatusCancelled {
+		t.Fatalf("Expected partner registration to be cancelled, got %+v", registrations)
+	}
+
+	pairs, err := pg.NewRegistrationPairRepo(pool).Filter(ctx, &domain.FilterRegistrationPair{ID: &f.pairID})
+	if err != nil || len(pairs) != 1 {
+		t.Fatalf("Failed to get pair: %v", err)
+	}
+	if pairs[0].Status != domain.RegistrationPairStatusCancelled {
+		t.Errorf("Expected pair to be cancelled, got %s", pairs[0].Status)
+	}
+
+	// Повторная отмена отклоняется, снимать партнера повторно не нужно
+	if _, err := cases.Registration.CancelEventRegistration(ctx, f.captain, f.eventID); err == nil {
+		t.Errorf("Expected error for already cancelled registration")
+	}
+}
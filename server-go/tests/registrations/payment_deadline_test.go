@@ -0,0 +1,188 @@
+package registrations_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+// registerUnpaid переводит первого из листа ожидания в регистрацию, ожидающую оплаты, на единственное место.
+// Остальные остаются в листе ожидания
+func registerUnpaid(t *testing.T, pool *pgxpool.Pool, f *waitlistFixture) *domain.User {
+	t.Helper()
+	ctx := context.Background()
+	entry := f.entries[0]
+
+	if err := pg.NewWaitlistRepo(pool).Delete(ctx, entry.ID); err != nil {
+		t.Fatalf("Failed to remove user from waitlist: %v", err)
+	}
+	if err := pg.NewRegistrationRepo(pool).OccupySeats(ctx, f.event.ID, []*domain.CreateRegistration{{
+		UserID:  entry.UserID,
+		EventID: f.event.ID,
+		Status:  domain.RegistrationStatusPending,
+	}}); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	return entry.User
+}
+
+// backdateRegistration сдвигает в прошлое переход регистрации в ожидание оплаты
+func backdateRegistration(t *testing.T, pool *pgxpool.Pool, userID, eventID string, age time.Duration) {
+	t.Helper()
+
+	_, err := pool.Exec(context.Background(),
+		`UPDATE registrations SET pending_since = pending_since - make_interval(secs => $1) WHERE user_id = $2 AND event_id = $3`,
+		age.Seconds(), userID, eventID)
+	if err != nil {
+		t.Fatalf("Failed to backdate registration: %v", err)
+	}
+}
+
+func registrationStatus(t *testing.T, pool *pgxpool.Pool, userID, eventID string) domain.RegistrationStatus {
+	t.Helper()
+
+	registrations, err := pg.NewRegistrationRepo(pool).Filter(context.Background(), &domain.FilterRegistration{UserID: &userID, EventID: &eventID})
+	if err != nil || len(registrations) != 1 {
+		t.Fatalf("Failed to get registration: %v", err)
+	}
+	return registrations[0].Status
+}
+
+// TestExpireUnpaidRegistrationCancelsAndOffersSeat по истечении срока регистрация отменяется,
+// а освободившееся место предлагается следующему в листе ожидания
+func TestExpireUnpaidRegistrationCancelsAndOffersSeat(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, cfg := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 2)
+	user := registerUnpaid(t, pool, f)
+	backdateRegistration(t, pool, user.ID, f.event.ID, cfg.Payments.RegistrationDeadline+time.Hour)
+
+	if err := cases.Registration.ExpireUnpaidRegistration(ctx, user.TelegramID, f.event.ID); err != nil {
+		t.Fatalf("Failed to expire unpaid registration: %v", err)
+	}
+
+	if status := registrationStatus(t, pool, user.ID, f.event.ID); status != domain.RegistrationStatusCancelledBeforePayment {
+		t.Errorf("Expected registration status %s, got %s", domain.RegistrationStatusCancelledBeforePayment, status)
+	}
+
+	offers := pendingOffers(t, pool, f.event.ID)
+	if len(offers) != 1 || offers[0].UserID != f.entries[1].UserID {
+		t.Errorf("Expected the freed seat to be offered to the next user in waitlist, got %+v", offers)
+	}
+
+	// Повтор задачи ничего не меняет
+	if err := cases.Registration.ExpireUnpaidRegistration(ctx, user.TelegramID, f.event.ID); err != nil {
+		t.Fatalf("Failed to repeat expiration: %v", err)
+	}
+	if offers := pendingOffers(t, pool, f.event.ID); len(offers) != 1 {
+		t.Errorf("Expected one pending offer after repeated expiration, got %d", len(offers))
+	}
+}
+
+// TestExpireUnpaidRegistrationSkipsPaid оплаченная регистрация не отменяется
+func TestExpireUnpaidRegistrationSkipsPaid(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, cfg := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 2)
+	user := registerUnpaid(t, pool, f)
+	backdateRegistration(t, pool, user.ID, f.event.ID, cfg.Payments.RegistrationDeadline+time.Hour)
+
+	if _, err := pg.NewRegistrationRepo(pool).TransitionStatus(ctx, user.ID, f.event.ID,
+		[]domain.RegistrationStatus{domain.RegistrationStatusPending}, domain.RegistrationStatusConfirmed); err != nil {
+		t.Fatalf("Failed to confirm registration: %v", err)
+	}
+
+	if err := cases.Registration.ExpireUnpaidRegistration(ctx, user.TelegramID, f.event.ID); err != nil {
+		t.Fatalf("Failed to expire registration: %v", err)
+	}
+
+	if status := registrationStatus(t, pool, user.ID, f.event.ID); status != domain.RegistrationStatusConfirmed {
+		t.Errorf("Expected paid registration to stay %s, got %s", domain.RegistrationStatusConfirmed, status)
+	}
+	if offers := pendingOffers(t, pool, f.event.ID); len(offers) != 0 {
+		t.Errorf("Expected no waitlist offers, got %d", len(offers))
+	}
+}
+
+// TestExpireUnpaidRegistrationBeforeDeadline задача, сработавшая раньше срока, регистрацию не отменяет
+func TestExpireUnpaidRegistrationBeforeDeadline(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, _ := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 1)
+	user := registerUnpaid(t, pool, f)
+
+	if err := cases.Registration.ExpireUnpaidRegistration(ctx, user.TelegramID, f.event.ID); err != nil {
+		t.Fatalf("Failed to expire registration: %v", err)
+	}
+
+	if status := registrationStatus(t, pool, user.ID, f.event.ID); status != domain.RegistrationStatusPending {
+		t.Errorf("Expected registration to stay %s before deadline, got %s", domain.RegistrationStatusPending, status)
+	}
+}
+
+// TestExpireUnpaidRegistrationWaitsForPaymentInProgress начатая оплата продлевает срок до окончания окна оплаты
+func TestExpireUnpaidRegistrationWaitsForPaymentInProgress(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, cfg := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 2)
+	user := registerUnpaid(t, pool, f)
+
+	paymentRepo := pg.NewPaymentRepo(pool)
+	paymentID, err := paymentRepo.Create(ctx, &domain.CreatePayment{
+		PaymentID:   fmt.Sprintf("deadline-test-%d", time.Now().UnixNano()),
+		Amount:      1000,
+		Status:      domain.PaymentStatusPending,
+		PaymentLink: "https://example.com/pay",
+		UserID:      user.ID,
+		EventID:     f.event.ID,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+	t.Cleanup(func() { _ = paymentRepo.Delete(context.Background(), paymentID) })
+
+	backdateRegistration(t, pool, user.ID, f.event.ID, cfg.Payments.RegistrationDeadline+time.Hour)
+
+	if err := cases.Registration.ExpireUnpaidRegistration(ctx, user.TelegramID, f.event.ID); err != nil {
+		t.Fatalf("Failed to expire registration: %v", err)
+	}
+
+	if status := registrationStatus(t, pool, user.ID, f.event.ID); status != domain.RegistrationStatusPending {
+		t.Errorf("Expected registration to stay %s while payment is in progress, got %s", domain.RegistrationStatusPending, status)
+	}
+	if offers := pendingOffers(t, pool, f.event.ID); len(offers) != 0 {
+		t.Errorf("Expected no waitlist offers, got %d", len(offers))
+	}
+}
+
+// TestPaymentDeadlineIgnoresUnrelatedUpdates изменение регистрации без смены статуса не продлевает срок оплаты
+func TestPaymentDeadlineIgnoresUnrelatedUpdates(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, cfg := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 2)
+	user := registerUnpaid(t, pool, f)
+	backdateRegistration(t, pool, user.ID, f.event.ID, cfg.Payments.RegistrationDeadline+time.Hour)
+
+	// Любое обновление строки двигает updated_at, но не переход в ожидание оплаты
+	if _, err := pool.Exec(ctx, `UPDATE registrations SET status = status WHERE user_id = $1 AND event_id = $2`, user.ID, f.event.ID); err != nil {
+		t.Fatalf("Failed to touch registration: %v", err)
+	}
+
+	if err := cases.Registration.ExpireUnpaidRegistration(ctx, user.TelegramID, f.event.ID); err != nil {
+		t.Fatalf("Failed to expire unpaid registration: %v", err)
+	}
+	if status := registrationStatus(t, pool, user.ID, f.event.ID); status != domain.RegistrationStatusCancelledBeforePayment {
+		t.Errorf("Expected registration status %s, got %s", domain.RegistrationStatusCancelledBeforePayment, status)
+	}
+}
@@ -0,0 +1,287 @@
+package registrations_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/repo"
+	"github.com/shampsdev/go-telegram-template/pkg/repo/pg"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+	"github.com/shampsdev/go-telegram-template/tests/shared"
+)
+
+type waitlistFixture struct {
+	event   *domain.Event
+	entries []*domain.Waitlist
+}
+
+// newWaitlistFixture создает турнир на одно свободное место и waiting игроков в листе ожидания
+func newWaitlistFixture(t *testing.T, pool *pgxpool.Pool, waiting int) *waitlistFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	userRepo := pg.NewUserRepo(pool)
+	eventRepo := pg.NewEventRepo(pool)
+	registrationRepo := pg.NewRegistrationRepo(pool)
+	waitlistRepo := pg.NewWaitlistRepo(pool)
+
+	telegramIDBase := time.Now().UnixNano() % 1_000_000_000 * 100
+	userIDs := make([]string, 0, waiting+1)
+	for i := 0; i <= waiting; i++ {
+		id, err := userRepo.Create(ctx, &domain.CreateUser{UserTGData: domain.UserTGData{
+			TelegramID: telegramIDBase + int64(i),
+			FirstName:  "Waitlist",
+			LastName:   fmt.Sprintf("Player %d", i),
+		}})
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	organizerID, playerIDs := userIDs[0], userIDs[1:]
+
+	eventID, err := eventRepo.Create(ctx, &domain.CreateEvent{
+		Name:        fmt.Sprintf("Waitlist Tournament %d", time.Now().UnixNano()),
+		StartTime:   time.Now().Add(48 * time.Hour),
+		EndTime:     time.Now().Add(50 * time.Hour),
+		RankMin:     0.0,
+		RankMax:     7.0,
+		Price:       1000,
+		MaxUsers:    1,
+		Type:        domain.EventTypeTournament,
+		CourtID:     "4ea67445-b73a-4b5b-b200-cc7f98b7f102",
+		OrganizerID: organizerID,
+		ClubID:      shared.StringPtr("global"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	t.Cleanup(func() {
+		entries, _ := waitlistRepo.Filter(ctx, &domain.FilterWaitlist{EventID: &eventID})
+		for _, entry := range entries {
+			_ = waitlistRepo.Delete(ctx, entry.ID)
+		}
+		for _, userID := range playerIDs {
+			_ = registrationRepo.Delete(ctx, userID, eventID)
+		}
+		_ = eventRepo.Delete(ctx, eventID)
+		for _, userID := range userIDs {
+			_ = userRepo.Delete(ctx, userID)
+		}
+	})
+
+	for _, userID := range playerIDs {
+		if _, err := waitlistRepo.Create(ctx, &domain.CreateWaitlist{UserID: userID, EventID: eventID}); err != nil {
+			t.Fatalf("Failed to add user to waitlist: %v", err)
+		}
+	}
+	entries, err := waitlistRepo.Filter(ctx, &domain.FilterWaitlist{EventID: &eventID})
+	if err != nil || len(entries) != waiting {
+		t.Fatalf("Failed to get waitlist: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.User, err = repo.First(userRepo.Filter)(ctx, &domain.FilterUser{ID: &entry.UserID}); err != nil {
+			t.Fatalf("Failed to get waitlist user: %v", err)
+		}
+	}
+
+	event, err := repo.First(eventRepo.Filter)(ctx, &domain.FilterEvent{ID: &eventID})
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+	return &waitlistFixture{event: event, entries: entries}
+}
+
+func pendingOffers(t *testing.T, pool *pgxpool.Pool, eventID string) []*domain.WaitlistOffer {
+	t.Helper()
+
+	offers, err := pg.NewWaitlistOfferRepo(pool).Filter(context.Background(), &domain.FilterWaitlistOffer{
+		EventID:  &eventID,
+		Statuses: []domain.WaitlistOfferStatus{domain.WaitlistOfferStatusPending},
+	})
+	if err != nil {
+		t.Fatalf("Failed to get waitlist offers: %v", err)
+	}
+	return offers
+}
+
+// TestConcurrentOffersHoldOneSeat два параллельных разбора листа ожидания не предлагают одно место дважды
+func TestConcurrentOffersHoldOneSeat(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, _ := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 4)
+
+	var offered atomic.Int32
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, entry := range f.entries {
+		wg.Add(1)
+		go func(entry *domain.Waitlist) {
+			defer wg.Done()
+			<-start
+
+			ok, err := cases.WaitlistOffer.Offer(ctx, f.event, entry)
+			if err != nil {
+				t.Errorf("Unexpected offer error: %v", err)
+			}
+			if ok {
+				offered.Add(1)
+			}
+		}(entry)
+	}
+	close(start)
+	wg.Wait()
+
+	if offered.Load() != 1 {
+		t.Errorf("Expected exactly one offer for one free seat, got %d", offered.Load())
+	}
+	if offers := pendingOffers(t, pool, f.event.ID); len(offers) != 1 {
+		t.Errorf("Expected one pending offer in database, got %d", len(offers))
+	}
+}
+
+// TestAcceptRacingExpireKeepsStateConsistent принятие и истечение предложения одновременно:
+// либо предложение принято и пользователь зарегистрирован, либо оно истекло и регистрации нет
+func TestAcceptRacingExpireKeepsStateConsistent(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, _ := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 1)
+	entry := f.entries[0]
+
+	if ok, err := cases.WaitlistOffer.Offer(ctx, f.event, entry); err != nil || !ok {
+		t.Fatalf("Failed to offer seat: ok=%v, err=%v", ok, err)
+	}
+	offers := pendingOffers(t, pool, f.event.ID)
+	if len(offers) != 1 {
+		t.Fatalf("Expected one pending offer, got %d", len(offers))
+	}
+	offerID := offers[0].ID
+
+	var acceptErr, expireErr error
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-start
+		_, acceptErr = cases.WaitlistOffer.Accept(ctx, entry.User, offerID)
+	}()
+	go func() {
+		defer wg.Done()
+		<-start
+		expireErr = cases.WaitlistOffer.Expire(ctx, offerID)
+	}()
+	close(start)
+	wg.Wait()
+
+	if expireErr != nil {
+		t.Fatalf("Unexpected expire error: %v", expireErr)
+	}
+	if acceptErr != nil && !errors.Is(acceptErr, usecase.ErrWaitlistOfferClosed) {
+		t.Fatalf("Unexpected accept error: %v", acceptErr)
+	}
+
+	offer, err := cases.WaitlistOffer.GetByID(ctx, offerID)
+	if err != nil {
+		t.Fatalf("Failed to get offer: %v", err)
+	}
+	registrations, err := cases.Registration.GetRegistrationsByUserAndEvent(ctx, entry.UserID, f.event.ID)
+	if err != nil {
+		t.Fatalf("Failed to get registrations: %v", err)
+	}
+
+	switch offer.Status {
+	case domain.WaitlistOfferStatusAccepted:
+		if acceptErr != nil {
+			t.Errorf("Offer accepted but Accept returned %v", acceptErr)
+		}
+		if len(registrations) != 1 || registrations[0].Status != domain.RegistrationStatusPending {
+			t.Errorf("Expected pending registration for accepted offer, got %+v", registrations)
+		}
+	case domain.WaitlistOfferStatusExpired:
+		if acceptErr == nil {
+			t.Errorf("Offer expired but Accept succeeded")
+		}
+		if len(registrations) != 0 {
+			t.Errorf("Expected no registration for expired offer, got %+v", registrations)
+		}
+	default:
+		t.Errorf("Unexpected offer status %s", offer.Status)
+	}
+}
+
+// TestAcceptExpiredOfferDoesNotRegister просроченное предложение принять нельзя
+func TestAcceptExpiredOfferDoesNotRegister(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, _ := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 1)
+	entry := f.entries[0]
+
+	if ok, err := cases.WaitlistOffer.Offer(ctx, f.event, entry); err != nil || !ok {
+		t.Fatalf("Failed to offer seat: ok=%v, err=%v", ok, err)
+	}
+	offerID := pendingOffers(t, pool, f.event.ID)[0].ID
+	if err := cases.WaitlistOffer.Expire(ctx, offerID); err != nil {
+		t.Fatalf("Failed to expire offer: %v", err)
+	}
+
+	if _, err := cases.WaitlistOffer.Accept(ctx, entry.User, offerID); !errors.Is(err, usecase.ErrWaitlistOfferClosed) {
+		t.Errorf("Expected ErrWaitlistOfferClosed, got %v", err)
+	}
+	registrations, err := cases.Registration.GetRegistrationsByUserAndEvent(ctx, entry.UserID, f.event.ID)
+	if err != nil {
+		t.Fatalf("Failed to get registrations: %v", err)
+	}
+	if len(registrations) != 0 {
+		t.Errorf("Expected no registration, got %+v", registrations)
+	}
+}
+
+// TestOfferWindowStartsAfterQuietHours в тихие часы окно ответа отсчитывается от их окончания
+func TestOfferWindowStartsAfterQuietHours(t *testing.T) {
+	pool := shared.SkipIfNoDatabase(t)
+	ctx := context.Background()
+	cases, cfg := shared.NewTestCases(t, pool)
+	f := newWaitlistFixture(t, pool, 1)
+	entry := f.entries[0]
+
+	now := time.Now().UTC()
+	start := now.Add(-time.Hour).Format(domain.QuietHoursLayout)
+	end := now.Add(time.Hour).Format(domain.QuietHoursLayout)
+	settings, err := cases.NotificationSettings.Patch(ctx, entry.UserID, &domain.PatchNotificationSettings{
+		QuietHoursStart: &start,
+		QuietHoursEnd:   &end,
+		Timezone:        shared.StringPtr("UTC"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to set quiet hours: %v", err)
+	}
+	quietUntil, quiet := settings.QuietUntil(time.Now())
+	if !quiet {
+		t.Fatalf("Expected quiet hours to be active")
+	}
+
+	if ok, err := cases.WaitlistOffer.Offer(ctx, f.event, entry); err != nil || !ok {
+		t.Fatalf("Failed to offer seat: ok=%v, err=%v", ok, err)
+	}
+	offers := pendingOffers(t, pool, f.event.ID)
+	if len(offers) != 1 {
+		t.Fatalf("Expected one pending offer, got %d", len(offers))
+	}
+
+	want := quietUntil.Add(cfg.Waitlist.OfferWindow)
+	if diff := offers[0].ExpiresAt.Sub(want); diff < -time.Second || diff > time.Second {
+		t.Errorf("Expected offer to expire at %s, got %s", want, offers[0].ExpiresAt)
+	}
+}
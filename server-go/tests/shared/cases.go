@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/shampsdev/go-telegram-template/pkg/config"
+	"github.com/shampsdev/go-telegram-template/pkg/payments"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+// NewTestCases собирает сценарии на тестовой БД без сети: локальный платежный провайдер без автоподтверждения,
+// без S3 и outbox, бот отправляет сообщения на недоступный адрес. Конфиг со значениями по умолчанию
+// можно менять в тесте, сценарии читают его при каждом вызове
+func NewTestCases(t *testing.T, pool *pgxpool.Pool) (*usecase.Cases, *config.Config) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	if err := envconfig.Process("TEST", cfg); err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	cfg.Payments.Provider = payments.ProviderFake
+
+	b, err := bot.New("1:test", bot.WithSkipGetMe(), bot.WithServerURL("http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("Failed to create test bot: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cases := usecase.NewCases(ctx, cfg, pool, nil, nil, payments.NewFakeProvider(time.Hour), b)
+	return &cases, cfg
+}
@@ -211,3 +211,61 @@ func (c *Client) PatchEvent(token, eventID string, patch domain.PatchEvent) (*do
 
 	return &updatedEvent, nil
 }
+
+type PaymentResponse struct {
+	PaymentURL string `json:"payment_url"`
+	PaymentID  string `json:"payment_id"`
+}
+
+func (c *Client) CreatePayment(token, eventID string) (*PaymentResponse, error) {
+	url := fmt.Sprintf("%s/registrations/%s/payment", BaseURL, eventID)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-API-Token", token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create payment: status %d", resp.StatusCode)
+	}
+
+	var payment PaymentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payment); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (c *Client) GetMyRegistrations(token string) ([]*domain.RegistrationWithEvent, error) {
+	req, err := http.NewRequest("GET", BaseURL+"/registrations/my", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-API-Token", token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get registrations: status %d", resp.StatusCode)
+	}
+
+	var registrations []*domain.RegistrationWithEvent
+	if err := json.NewDecoder(resp.Body).Decode(&registrations); err != nil {
+		return nil, err
+	}
+
+	return registrations, nil
+}
@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/shampsdev/go-telegram-template/pkg/domain"
 )
@@ -48,6 +50,30 @@ func SkipIfNoTokens(t *testing.T) (userToken, adminToken string) {
 	return userToken, adminToken
 }
 
+// SkipIfNoFakePayments пропускает тест, если сервер запущен не с локальным платежным провайдером
+func SkipIfNoFakePayments(t *testing.T) {
+	LoadEnv()
+	if os.Getenv("PAYMENT_PROVIDER") != "fake" {
+		t.Skip("PAYMENT_PROVIDER=fake must be set for the server and tests to run paid flows without network access.")
+	}
+}
+
+// SkipIfNoDatabase подключается к тестовой БД с примененными миграциями, без TEST_DATABASE_URL тест пропускается
+func SkipIfNoDatabase(t *testing.T) *pgxpool.Pool {
+	LoadEnv()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL must be set to a database with applied migrations to run repository tests.")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
 func StringPtr(s string) *string {
 	return &s
 }
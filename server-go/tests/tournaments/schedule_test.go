@@ -0,0 +1,294 @@
+package tournaments_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+func TestRoundRobinEveryTeamMeetsEveryOtherOnce(t *testing.T) {
+	for _, players := range []int{6, 8, 10} {
+		t.Run(fmt.Sprintf("%d players", players), func(t *testing.T) {
+			tournament := schedule(t, players, &domain.CreateTournament{
+				Format: domain.TournamentFormatRoundRobin,
+				Courts: 2,
+			})
+
+			teams := len(tournament.Teams)
+			if teams != players/2 {
+				t.Fatalf("Expected %d teams, got %d", players/2, teams)
+			}
+			if len(tournament.Matches) != teams*(teams-1)/2 {
+				t.Fatalf("Expected %d matches, got %d", teams*(teams-1)/2, len(tournament.Matches))
+			}
+
+			met := map[[2]string]int{}
+			for _, match := range tournament.Matches {
+				met[pair(*match.Team1ID, *match.Team2ID)]++
+			}
+			for _, count := range met {
+				if count != 1 {
+					t.Errorf("Expected every pair of teams to meet once, got %v", met)
+					break
+				}
+			}
+
+			assertNoPlayerTwiceInRound(t, tournament)
+			assertCourtsPerRound(t, tournament, 2)
+			if tournament.Rounds != lastRound(tournament) {
+				t.Errorf("Expected rounds %d, got %d", lastRound(tournament), tournament.Rounds)
+			}
+		})
+	}
+}
+
+func TestSingleEliminationGivesByesToTopSeeds(t *testing.T) {
+	tournament := schedule(t, 10, &domain.CreateTournament{
+		Format: domain.TournamentFormatSingleElimination,
+		Courts: 2,
+	})
+
+	// 5 пар в сетке на 8: посевы 1-3 проходят во второй раунд без игры, в первом раунде играют 4 и 5
+	if len(tournament.Matches) != len(tournament.Teams)-1 {
+		t.Fatalf("Expected %d matches, got %d", len(tournament.Teams)-1, len(tournament.Matches))
+	}
+	if tournament.Rounds != 3 {
+		t.Fatalf("Expected 3 rounds, got %d", tournament.Rounds)
+	}
+
+	seeds := map[string]int{}
+	for _, team := range tournament.Teams {
+		seeds[team.ID] = team.Seed
+	}
+
+	var firstRound []*domain.TournamentMatch
+	finals := 0
+	for _, match := range tournament.Matches {
+		if match.Round == 1 {
+			firstRound = append(firstRound, match)
+		}
+		if match.NextMatchID == nil {
+			finals++
+		}
+	}
+	if finals != 1 {
+		t.Errorf("Expected exactly one match without next match, got %d", finals)
+	}
+	if len(firstRound) != 1 {
+		t.Fatalf("Expected 1 first round match, got %d", len(firstRound))
+	}
+	if got := []int{seeds[*firstRound[0].Team1ID], seeds[*firstRound[0].Team2ID]}; got[0] != 4 || got[1] != 5 {
+		t.Errorf("Expected seeds 4 and 5 in the first round, got %v", got)
+	}
+
+	seeded := map[int]bool{}
+	for _, match := range tournament.Matches {
+		if match.Round != 2 {
+			continue
+		}
+		for _, teamID := range []*string{match.Team1ID, match.Team2ID} {
+			if teamID != nil {
+				seeded[seeds[*teamID]] = true
+			}
+		}
+	}
+	for _, seed := range []int{1, 2, 3} {
+		if !seeded[seed] {
+			t.Errorf("Expected seed %d to advance to round 2 without a match", seed)
+		}
+	}
+}
+
+func TestAmericanoRotatesPartnersAndRests(t *testing.T) {
+	t.Run("full courts", func(t *testing.T) {
+		tournament := schedule(t, 8, &domain.CreateTournament{
+			Format: domain.TournamentFormatAmericano,
+			Courts: 2,
+		})
+
+		if tournament.Rounds != 7 {
+			t.Fatalf("Expected 7 rounds by default, got %d", tournament.Rounds)
+		}
+		if len(tournament.Matches) != 14 {
+			t.Fatalf("Expected 14 matches, got %d", len(tournament.Matches))
+		}
+		assertNoPlayerTwiceInRound(t, tournament)
+
+		partners := map[[2]string]int{}
+		for _, match := range tournament.Matches {
+			partners[pair(match.Team1Players[0], match.Team1Players[1])]++
+			partners[pair(match.Team2Players[0], match.Team2Players[1])]++
+		}
+		repeated := 0
+		for _, count := range partners {
+			repeated += count - 1
+		}
+		if len(partners) < 20 {
+			t.Errorf("Expected players to rotate partners, got %d distinct pairs with %d repeats", len(partners), repeated)
+		}
+	})
+
+	t.Run("players rest by turns", func(t *testing.T) {
+		tournament := schedule(t, 5, &domain.CreateTournament{
+			Format: domain.TournamentFormatAmericano,
+			Courts: 2,
+			Rounds: 5,
+		})
+
+		if len(tournament.Matches) != 5 {
+			t.Fatalf("Expected one match per round, got %d", len(tournament.Matches))
+		}
+		assertNoPlayerTwiceInRound(t, tournament)
+
+		games := map[string]int{}
+		for _, match := range tournament.Matches {
+			for _, userID := range append(append([]string{}, match.Team1Players...), match.Team2Players...) {
+				games[userID]++
+			}
+		}
+		for userID, count := range games {
+			if count != 4 {
+				t.Errorf("Expected every player to rest once in 5 rounds, %s played %d", userID, count)
+			}
+		}
+	})
+}
+
+func TestMexicanoFirstRoundBalancesByRank(t *testing.T) {
+	tournament := schedule(t, 8, &domain.CreateTournament{
+		Format: domain.TournamentFormatMexicano,
+		Courts: 2,
+		Rounds: 3,
+	})
+
+	if len(tournament.Matches) != 2 {
+		t.Fatalf("Expected only the first round, got %d matches", len(tournament.Matches))
+	}
+
+	// Игроки p1..p8 по убыванию ранга: в четверке первый с четвертым против второго с третьим
+	first := tournament.Matches[0]
+	if pair(first.Team1Players[0], first.Team1Players[1]) != pair("p1", "p4") ||
+		pair(first.Team2Players[0], first.Team2Players[1]) != pair("p2", "p3") {
+		t.Errorf("Expected p1/p4 against p2/p3, got %v against %v", first.Team1Players, first.Team2Players)
+	}
+}
+
+func TestBalancedPairingMatchesStrongestWithWeakest(t *testing.T) {
+	tournament := schedule(t, 8, &domain.CreateTournament{
+		Format: domain.TournamentFormatRoundRobin,
+		Courts: 1,
+	})
+
+	for _, team := range tournament.Teams {
+		if team.Seed == 1 && pair(team.PlayerIDs[0], team.PlayerIDs[1]) != pair("p1", "p8") {
+			t.Errorf("Expected p1 and p8 to form the first seeded team, got %v", team.PlayerIDs)
+		}
+	}
+}
+
+func TestScheduleRejectsInvalidSetup(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		create  *domain.CreateTournament
+	}{
+		{"too few players", 3, &domain.CreateTournament{Format: domain.TournamentFormatAmericano, Courts: 1}},
+		{"odd players for teams", 7, &domain.CreateTournament{Format: domain.TournamentFormatRoundRobin, Courts: 1}},
+		{"player in two manual teams", 4, &domain.CreateTournament{
+			Format:  domain.TournamentFormatSingleElimination,
+			Courts:  1,
+			Pairing: domain.TournamentPairingManual,
+			Teams:   [][]string{{"p1", "p2"}, {"p2", "p3"}},
+		}},
+		{"unknown manual player", 4, &domain.CreateTournament{
+			Format:  domain.TournamentFormatSingleElimination,
+			Courts:  1,
+			Pairing: domain.TournamentPairingManual,
+			Teams:   [][]string{{"p1", "p2"}, {"p3", "p9"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usecase.ScheduleTournament("event", players(tt.players), tt.create)
+			if !errors.Is(err, usecase.ErrInvalidTournament) {
+				t.Errorf("Expected ErrInvalidTournament, got %v", err)
+			}
+		})
+	}
+}
+
+func schedule(t *testing.T, count int, create *domain.CreateTournament) *domain.Tournament {
+	t.Helper()
+
+	tournament, err := usecase.ScheduleTournament("event", players(count), create)
+	if err != nil {
+		t.Fatalf("Failed to schedule tournament: %v", err)
+	}
+	return tournament
+}
+
+// players игроки p1..pN по убыванию ранга
+func players(count int) []*domain.User {
+	users := make([]*domain.User, 0, count)
+	for i := 1; i <= count; i++ {
+		users = append(users, &domain.User{
+			ID:         fmt.Sprintf("p%d", i),
+			UserTGData: domain.UserTGData{FirstName: fmt.Sprintf("Player %d", i)},
+			Rank:       float64(count-i) / 2,
+		})
+	}
+	return users
+}
+
+func assertNoPlayerTwiceInRound(t *testing.T, tournament *domain.Tournament) {
+	t.Helper()
+
+	seen := map[int]map[string]bool{}
+	for _, match := range tournament.Matches {
+		if seen[match.Round] == nil {
+			seen[match.Round] = map[string]bool{}
+		}
+		for _, userID := range append(append([]string{}, match.Team1Players...), match.Team2Players...) {
+			if seen[match.Round][userID] {
+				t.Fatalf("Player %s plays twice in round %d", userID, match.Round)
+			}
+			seen[match.Round][userID] = true
+		}
+	}
+}
+
+func assertCourtsPerRound(t *testing.T, tournament *domain.Tournament, courts int) {
+	t.Helper()
+
+	perRound := map[int]int{}
+	for _, match := range tournament.Matches {
+		perRound[match.Round]++
+		if match.Court < 1 || match.Court > courts {
+			t.Errorf("Match %s is on court %d of %d", match.ID, match.Court, courts)
+		}
+	}
+	for round, count := range perRound {
+		if count > courts {
+			t.Errorf("Round %d has %d matches on %d courts", round, count, courts)
+		}
+	}
+}
+
+func lastRound(tournament *domain.Tournament) int {
+	result := 0
+	for _, match := range tournament.Matches {
+		result = max(result, match.Round)
+	}
+	return result
+}
+
+func pair(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
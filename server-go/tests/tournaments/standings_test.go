@@ -0,0 +1,144 @@
+package tournaments_test
+
+import (
+	"testing"
+
+	"github.com/shampsdev/go-telegram-template/pkg/domain"
+	"github.com/shampsdev/go-telegram-template/pkg/usecase"
+)
+
+func TestRoundRobinStandingsByWinsThenDifference(t *testing.T) {
+	tournament := schedule(t, 6, &domain.CreateTournament{
+		Format: domain.TournamentFormatRoundRobin,
+		Courts: 1,
+	})
+
+	seeds := map[string]int{}
+	for _, team := range tournament.Teams {
+		seeds[team.ID] = team.Seed
+	}
+
+	// Посев 3 обыгрывает всех, посев 1 выигрывает у посева 2 с большой разницей
+	for _, match := range tournament.Matches {
+		seed1, seed2 := seeds[*match.Team1ID], seeds[*match.Team2ID]
+		switch {
+		case seed1 == 3:
+			record(match, 6, 2)
+		case seed2 == 3:
+			record(match, 2, 6)
+		case seed1 == 1:
+			record(match, 6, 0)
+		default:
+			record(match, 0, 6)
+		}
+	}
+
+	standings := usecase.CalculateStandings(tournament, map[string]string{})
+	if len(standings) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(standings))
+	}
+
+	expected := []struct{ seed, won, lost, diff int }{
+		{3, 2, 0, 8},
+		{1, 1, 1, 2},
+		{2, 0, 2, -10},
+	}
+	for i, want := range expected {
+		got := standings[i]
+		if seeds[*got.TeamID] != want.seed || got.Won != want.won || got.Lost != want.lost || got.PointsDiff != want.diff {
+			t.Errorf("Position %d: expected seed %d with %d-%d and diff %d, got seed %d with %d-%d and diff %d",
+				i+1, want.seed, want.won, want.lost, want.diff, seeds[*got.TeamID], got.Won, got.Lost, got.PointsDiff)
+		}
+		if got.Position != i+1 {
+			t.Errorf("Expected position %d, got %d", i+1, got.Position)
+		}
+	}
+}
+
+func TestAmericanoStandingsByPoints(t *testing.T) {
+	tournament := schedule(t, 4, &domain.CreateTournament{
+		Format:         domain.TournamentFormatAmericano,
+		Courts:         1,
+		Rounds:         1,
+		PointsPerMatch: 24,
+	})
+
+	match := tournament.Matches[0]
+	record(match, 15, 9)
+
+	standings := usecase.CalculateStandings(tournament, map[string]string{"p1": "Player 1"})
+	if len(standings) != 4 {
+		t.Fatalf("Expected 4 rows, got %d", len(standings))
+	}
+
+	winners := map[string]bool{match.Team1Players[0]: true, match.Team1Players[1]: true}
+	for i, standing := range standings {
+		if standing.UserID == nil {
+			t.Fatalf("Expected individual standings")
+		}
+		if i < 2 && (!winners[*standing.UserID] || standing.PointsFor != 15 || standing.Won != 1) {
+			t.Errorf("Expected winners on top with 15 points, got %s with %d", *standing.UserID, standing.PointsFor)
+		}
+		if i >= 2 && (winners[*standing.UserID] || standing.PointsFor != 9 || standing.Lost != 1) {
+			t.Errorf("Expected losers below with 9 points, got %s with %d", *standing.UserID, standing.PointsFor)
+		}
+		if *standing.UserID == "p1" && standing.Name != "Player 1" {
+			t.Errorf("Expected player name from names, got %q", standing.Name)
+		}
+	}
+}
+
+func TestEliminationStandingsByReachedRound(t *testing.T) {
+	tournament := schedule(t, 8, &domain.CreateTournament{
+		Format: domain.TournamentFormatSingleElimination,
+		Courts: 2,
+	})
+
+	byID := map[string]*domain.TournamentMatch{}
+	for _, match := range tournament.Matches {
+		byID[match.ID] = match
+	}
+
+	// Во всех матчах побеждает первая сторона, победитель проходит дальше по сетке
+	for round := 1; round <= tournament.Rounds; round++ {
+		for _, match := range tournament.Matches {
+			if match.Round != round {
+				continue
+			}
+			record(match, 6, 3)
+			if match.NextMatchID == nil {
+				continue
+			}
+			next := byID[*match.NextMatchID]
+			if *match.NextMatchSlot == 1 {
+				next.Team1ID, next.Team1Players = match.Team1ID, match.Team1Players
+			} else {
+				next.Team2ID, next.Team2Players = match.Team1ID, match.Team1Players
+			}
+		}
+	}
+
+	var final *domain.TournamentMatch
+	for _, match := range tournament.Matches {
+		if match.NextMatchID == nil {
+			final = match
+		}
+	}
+
+	standings := usecase.CalculateStandings(tournament, map[string]string{})
+	if *standings[0].TeamID != *final.Team1ID {
+		t.Errorf("Expected final winner on top")
+	}
+	if *standings[1].TeamID != *final.Team2ID {
+		t.Errorf("Expected finalist second")
+	}
+	if standings[0].Won != 2 || standings[len(standings)-1].Won != 0 {
+		t.Errorf("Expected winner with 2 wins and last team without wins, got %d and %d",
+			standings[0].Won, standings[len(standings)-1].Won)
+	}
+}
+
+func record(match *domain.TournamentMatch, score1, score2 int) {
+	match.Score1, match.Score2 = &score1, &score2
+	match.Status = domain.MatchStatusCompleted
+}
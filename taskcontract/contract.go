@@ -0,0 +1,72 @@
+// Package taskcontract контракт задач между сервером и воркером: типы задач, данные каждого типа
+// и конверт сообщения в NATS JetStream. Сервер кодирует задачи через Encode, воркер разбирает их
+// через Decode, поэтому новый тип задачи нельзя добавить только в одном из сервисов.
+//
+// При несовместимом изменении данных увеличивается SchemaVersion, а Decode учится разбирать
+// и предыдущую версию, пока в БД воркера остаются задачи, сохраненные со старой версией
+package taskcontract
+
+import (
+	"encoding/json"
+)
+
+// SchemaVersion текущая версия данных задач
+const SchemaVersion = 1
+
+type TaskType string
+
+// Уведомления о событии любого типа: игре, турнире или тренировке
+const (
+	TaskTypeEventRegistrationConfirmed TaskType = "event.registration.confirmed" // Регистрация подтверждена
+	TaskTypeEventReminder48Hours       TaskType = "event.reminder.48hours"
+	TaskTypeEventReminder24Hours       TaskType = "event.reminder.24hours"
+	TaskTypeEventRegistrationCanceled  TaskType = "event.registration.canceled"
+	TaskTypeEventRegistrationRequested TaskType = "event.registration.requested" // Организатору: новая заявка на игру
+	TaskTypeEventRegistrationApproved  TaskType = "event.registration.approved"  // Организатор принял заявку на игру
+	TaskTypeEventRegistrationRejected  TaskType = "event.registration.rejected"  // Организатор отклонил заявку на игру
+	TaskTypeEventTasksCancel           TaskType = "event.tasks.cancel"           // Снять напоминания пользователя о событии
+	TaskTypeEventPaymentDeadline       TaskType = "event.payment.deadline"       // Срок оплаты истек: сервер отменяет неоплаченную регистрацию
+	TaskTypeEventPaymentExpired        TaskType = "event.payment.expired"        // Регистрация отменена из-за неоплаты
+)
+
+// Уведомления о турнире. Регистрацию, напоминания, отмену и срок оплаты сервер ставит задачами event.*,
+// эти типы остаются, пока воркер выполняет сохраненные ранее задачи
+const (
+	TaskTypeTournamentRegistrationSuccess          TaskType = "tournament.registration.success"
+	TaskTypeTournamentReminder48Hours              TaskType = "tournament.reminder.48hours"
+	TaskTypeTournamentReminder24Hours              TaskType = "tournament.reminder.24hours"
+	TaskTypeTournamentFreeReminder48Hours          TaskType = "tournament.free.reminder.48hours"
+	TaskTypeTournamentPaymentSuccess               TaskType = "tournament.payment.success"
+	TaskTypeTournamentLoyaltyChanged               TaskType = "tournament.loyalty.changed"
+	TaskTypeTournamentRegistrationCanceled         TaskType = "tournament.registration.canceled"
+	TaskTypeTournamentRegistrationAutoDeleteUnpaid TaskType = "tournament.registration.auto_delete_unpaid"
+	TaskTypeTournamentTasksCancel                  TaskType = "tournament.tasks.cancel"
+)
+
+const (
+	TaskTypeBroadcastSend       TaskType = "broadcast.send"
+	TaskTypeWaitlistOfferExpire TaskType = "waitlist.offer.expire"
+)
+
+// Message конверт задачи в стриме. Время в RFC3339
+type Message struct {
+	TaskName      string          `json:"task_name"`
+	SchemaVersion int             `json:"schema_version"`
+	ExecuteAt     string          `json:"execute_at"`
+	Data          json.RawMessage `json:"data"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+// ControlMessage команда воркерам обновить запланированную задачу после изменения в БД.
+// Отправляется через обычный NATS, чтобы ее получили все реплики воркера
+type ControlMessage struct {
+	Action ControlAction `json:"action"`
+	TaskID string        `json:"task_id"`
+}
+
+type ControlAction string
+
+const (
+	ControlCancel     ControlAction = "cancel"     // Снять задачу из расписания
+	ControlReschedule ControlAction = "reschedule" // Перечитать задачу из БД и запланировать заново
+)
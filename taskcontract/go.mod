@@ -0,0 +1,3 @@
+module gopadel/taskcontract
+
+go 1.24.0
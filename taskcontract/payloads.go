@@ -0,0 +1,268 @@
+package taskcontract
+
+import (
+	"errors"
+	"time"
+)
+
+// Payload данные задачи одного из типов. Validate проверяет обязательные поля
+type Payload interface {
+	Validate() error
+}
+
+// UserNotification данные задачи, которая отправляет сообщение пользователю
+type UserNotification interface {
+	Payload
+	TelegramID() int64
+}
+
+var (
+	errNoTelegramID   = errors.New("user_telegram_id is required")
+	errNoTournamentID = errors.New("tournament_id is required")
+	errNoEventID      = errors.New("event_id is required")
+)
+
+// Event событие, о котором уведомление. Время начала в UTC, воркер показывает его в часовом поясе корта
+type Event struct {
+	EventID       string    `json:"event_id"`
+	EventName     string    `json:"event_name"`
+	EventType     string    `json:"event_type"` // game, tournament или training
+	StartTime     time.Time `json:"start_time"`
+	CourtName     string    `json:"court_name"`
+	CourtAddress  string    `json:"court_address"`
+	CourtTimezone string    `json:"court_timezone"` // IANA, например Europe/Moscow
+}
+
+func (e *Event) validate() error {
+	if e.EventID == "" {
+		return errNoEventID
+	}
+	if e.EventType == "" {
+		return errors.New("event_type is required")
+	}
+	if e.StartTime.IsZero() {
+		return errors.New("start_time is required")
+	}
+	return nil
+}
+
+// EventNotification уведомление участнику о событии: отмена регистрации, решение организатора по заявке
+type EventNotification struct {
+	UserTelegramID int64 `json:"user_telegram_id"`
+	Event
+}
+
+func (p *EventNotification) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *EventNotification) Validate() error {
+	if p.UserTelegramID == 0 {
+		return errNoTelegramID
+	}
+	return p.Event.validate()
+}
+
+// EventReminder подтверждение регистрации и напоминания о событии. IsPaid — участие оплачено или бесплатно
+type EventReminder struct {
+	UserTelegramID int64 `json:"user_telegram_id"`
+	Event
+	IsPaid bool `json:"is_paid"`
+}
+
+func (p *EventReminder) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *EventReminder) Validate() error {
+	if p.UserTelegramID == 0 {
+		return errNoTelegramID
+	}
+	return p.Event.validate()
+}
+
+// EventRegistrationRequested уведомление организатору о заявке на игру. UserTelegramID — организатор
+type EventRegistrationRequested struct {
+	UserTelegramID int64 `json:"user_telegram_id"`
+	Event
+	ApplicantTelegramID int64  `json:"applicant_telegram_id"`
+	ApplicantName       string `json:"applicant_name"`
+}
+
+func (p *EventRegistrationRequested) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *EventRegistrationRequested) Validate() error {
+	if p.UserTelegramID == 0 {
+		return errNoTelegramID
+	}
+	if p.ApplicantTelegramID == 0 {
+		return errors.New("applicant_telegram_id is required")
+	}
+	return p.Event.validate()
+}
+
+// EventTasksCancel отмена запланированных напоминаний пользователя о событии
+type EventTasksCancel struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	EventID        string `json:"event_id"`
+}
+
+func (p *EventTasksCancel) Validate() error {
+	if p.UserTelegramID == 0 {
+		return errNoTelegramID
+	}
+	if p.EventID == "" {
+		return errNoEventID
+	}
+	return nil
+}
+
+// EventPaymentDeadline срок оплаты регистрации на событие. Воркер передает задачу серверу, регистрацию
+// отменяет сервер, если она к этому времени не оплачена
+type EventPaymentDeadline struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	EventID        string `json:"event_id"`
+}
+
+func (p *EventPaymentDeadline) Validate() error {
+	if p.UserTelegramID == 0 {
+		return errNoTelegramID
+	}
+	if p.EventID == "" {
+		return errNoEventID
+	}
+	return nil
+}
+
+// RegistrationSuccess уведомление об успешной регистрации на турнир
+type RegistrationSuccess struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	TournamentID   string `json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+	IsFree         bool   `json:"is_free"`
+}
+
+func (p *RegistrationSuccess) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *RegistrationSuccess) Validate() error {
+	return validateTournamentNotification(p.UserTelegramID, p.TournamentID)
+}
+
+// Reminder напоминание о турнире за 48 или 24 часа
+type Reminder struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	TournamentID   string `json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+	IsPaid         bool   `json:"is_paid"`
+}
+
+func (p *Reminder) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *Reminder) Validate() error {
+	return validateTournamentNotification(p.UserTelegramID, p.TournamentID)
+}
+
+// PaymentSuccess уведомление об успешной оплате участия
+type PaymentSuccess struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	TournamentID   string `json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+}
+
+func (p *PaymentSuccess) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *PaymentSuccess) Validate() error {
+	return validateTournamentNotification(p.UserTelegramID, p.TournamentID)
+}
+
+// LoyaltyChanged уведомление об изменении уровня лояльности
+type LoyaltyChanged struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	OldLevel       string `json:"old_level"`
+	NewLevel       string `json:"new_level"`
+}
+
+func (p *LoyaltyChanged) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *LoyaltyChanged) Validate() error {
+	if p.UserTelegramID == 0 {
+		return errNoTelegramID
+	}
+	if p.NewLevel == "" {
+		return errors.New("new_level is required")
+	}
+	return nil
+}
+
+// RegistrationCanceled уведомление об отмене регистрации
+type RegistrationCanceled struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	TournamentID   string `json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+}
+
+func (p *RegistrationCanceled) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *RegistrationCanceled) Validate() error {
+	return validateTournamentNotification(p.UserTelegramID, p.TournamentID)
+}
+
+// AutoDeleteUnpaid отмена неоплаченной регистрации на турнир. Как и EventPaymentDeadline, регистрацию отменяет сервер
+type AutoDeleteUnpaid struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	TournamentID   string `json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+	RegistrationID string `json:"registration_id"`
+}
+
+func (p *AutoDeleteUnpaid) TelegramID() int64 { return p.UserTelegramID }
+
+func (p *AutoDeleteUnpaid) Validate() error {
+	if err := validateTournamentNotification(p.UserTelegramID, p.TournamentID); err != nil {
+		return err
+	}
+	if p.RegistrationID == "" {
+		return errors.New("registration_id is required")
+	}
+	return nil
+}
+
+// TasksCancel отмена запланированных напоминаний пользователя по турниру
+type TasksCancel struct {
+	UserTelegramID int64  `json:"user_telegram_id"`
+	TournamentID   string `json:"tournament_id"`
+}
+
+func (p *TasksCancel) Validate() error {
+	return validateTournamentNotification(p.UserTelegramID, p.TournamentID)
+}
+
+// BroadcastSend отправка рассылки. Сообщение и получатели воркер берет из БД
+type BroadcastSend struct {
+	BroadcastID string `json:"broadcast_id"`
+}
+
+func (p *BroadcastSend) Validate() error {
+	if p.BroadcastID == "" {
+		return errors.New("broadcast_id is required")
+	}
+	return nil
+}
+
+// WaitlistOfferExpire истечение предложения места из листа ожидания
+type WaitlistOfferExpire struct {
+	OfferID string `json:"offer_id"`
+}
+
+func (p *WaitlistOfferExpire) Validate() error {
+	if p.OfferID == "" {
+		return errors.New("offer_id is required")
+	}
+	return nil
+}
+
+func validateTournamentNotification(telegramID int64, tournamentID string) error {
+	if telegramID == 0 {
+		return errNoTelegramID
+	}
+	if tournamentID == "" {
+		return errNoTournamentID
+	}
+	return nil
+}
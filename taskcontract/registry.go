@@ -0,0 +1,84 @@
+package taskcontract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrUnknownTaskType          = errors.New("unknown task type")
+	ErrUnsupportedSchemaVersion = errors.New("unsupported task schema version")
+	ErrInvalidPayload           = errors.New("invalid task payload")
+)
+
+// registry данные каждого типа задачи. Тип без записи здесь не может быть ни отправлен, ни выполнен
+var registry = map[TaskType]func() Payload{
+	TaskTypeEventRegistrationConfirmed: func() Payload { return &EventReminder{} },
+	TaskTypeEventReminder48Hours:       func() Payload { return &EventReminder{} },
+	TaskTypeEventReminder24Hours:       func() Payload { return &EventReminder{} },
+	TaskTypeEventRegistrationCanceled:  func() Payload { return &EventNotification{} },
+	TaskTypeEventRegistrationRequested: func() Payload { return &EventRegistrationRequested{} },
+	TaskTypeEventRegistrationApproved:  func() Payload { return &EventNotification{} },
+	TaskTypeEventRegistrationRejected:  func() Payload { return &EventNotification{} },
+	TaskTypeEventTasksCancel:           func() Payload { return &EventTasksCancel{} },
+	TaskTypeEventPaymentDeadline:       func() Payload { return &EventPaymentDeadline{} },
+	TaskTypeEventPaymentExpired:        func() Payload { return &EventNotification{} },
+
+	TaskTypeTournamentRegistrationSuccess:          func() Payload { return &RegistrationSuccess{} },
+	TaskTypeTournamentReminder48Hours:              func() Payload { return &Reminder{} },
+	TaskTypeTournamentReminder24Hours:              func() Payload { return &Reminder{} },
+	TaskTypeTournamentFreeReminder48Hours:          func() Payload { return &Reminder{} },
+	TaskTypeTournamentPaymentSuccess:               func() Payload { return &PaymentSuccess{} },
+	TaskTypeTournamentLoyaltyChanged:               func() Payload { return &LoyaltyChanged{} },
+	TaskTypeTournamentRegistrationCanceled:         func() Payload { return &RegistrationCanceled{} },
+	TaskTypeTournamentRegistrationAutoDeleteUnpaid: func() Payload { return &AutoDeleteUnpaid{} },
+	TaskTypeTournamentTasksCancel:                  func() Payload { return &TasksCancel{} },
+	TaskTypeBroadcastSend:                          func() Payload { return &BroadcastSend{} },
+	TaskTypeWaitlistOfferExpire:                    func() Payload { return &WaitlistOfferExpire{} },
+}
+
+// Encode проверяет, что данные соответствуют типу задачи и заполнены, и сериализует их
+func Encode(taskType TaskType, payload Payload) (json.RawMessage, error) {
+	newPayload, ok := registry[taskType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTaskType, taskType)
+	}
+	if want := reflect.TypeOf(newPayload()); reflect.TypeOf(payload) != want {
+		return nil, fmt.Errorf("%w: %s expects %s, got %T", ErrInvalidPayload, taskType, want, payload)
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, taskType, err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, taskType, err)
+	}
+	return data, nil
+}
+
+// Decode разбирает данные задачи в структуру ее типа. Неизвестные тип, версия и поля,
+// а также незаполненные обязательные поля возвращаются ошибкой
+func Decode(taskType TaskType, schemaVersion int, data []byte) (Payload, error) {
+	newPayload, ok := registry[taskType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTaskType, taskType)
+	}
+	if schemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("%w: %s version %d, supported %d", ErrUnsupportedSchemaVersion, taskType, schemaVersion, SchemaVersion)
+	}
+
+	payload := newPayload()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(payload); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, taskType, err)
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, taskType, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,81 @@
+// Package tgcallback подписывает данные inline-кнопок уведомлений. Данные кнопки имеют вид
+// action:arg1[:arg2]:signature, подпись — HMAC-SHA256 от Telegram ID того, кто должен нажать кнопку,
+// действия и аргументов. Поэтому кнопку нельзя подделать и нельзя нажать из чужого чата.
+// Пакет общий для сервера и воркера: воркер подписывает кнопки уведомлений, бот сервера их проверяет
+package tgcallback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxDataLength ограничение Telegram на callback_data
+const maxDataLength = 64
+
+// signatureLength байт HMAC в подписи, 8 байт достаточно против перебора через Telegram
+const signatureLength = 8
+
+type Action string
+
+const (
+	ActionAttend  Action = "ra" // «Я приду» из напоминания
+	ActionCancel  Action = "rx" // «Отменить запись» из напоминания
+	ActionPay     Action = "rp" // «Оплатить» из напоминания
+	ActionApprove Action = "ga" // организатор принимает заявку на игру
+	ActionReject  Action = "gr" // организатор отклоняет заявку на игру
+	// Предложение места из листа ожидания, аргумент — ID предложения
+	ActionOfferAccept  Action = "wa"
+	ActionOfferDecline Action = "wd"
+)
+
+// Actions действия подписанных кнопок, по ним бот регистрирует обработчики
+var Actions = []Action{ActionAttend, ActionCancel, ActionPay, ActionApprove, ActionReject, ActionOfferAccept, ActionOfferDecline}
+
+var (
+	ErrInvalidSignature = errors.New("invalid callback signature")
+	ErrDataTooLong      = errors.New("callback data too long")
+)
+
+// Prefix начало данных кнопок действия
+func (a Action) Prefix() string {
+	return string(a) + ":"
+}
+
+// Sign строит данные кнопки для пользователя telegramID. Аргументы не должны содержать ':'
+func Sign(secret string, telegramID int64, action Action, args ...string) (string, error) {
+	if secret == "" {
+		return "", errors.New("callback secret is not configured")
+	}
+	payload := strings.Join(append([]string{string(action)}, args...), ":")
+	data := payload + ":" + signature(secret, telegramID, payload)
+	if len(data) > maxDataLength {
+		return "", fmt.Errorf("%w: %d bytes", ErrDataTooLong, len(data))
+	}
+	return data, nil
+}
+
+// Verify проверяет подпись данных кнопки, которую нажал пользователь telegramID, и возвращает действие и аргументы
+func Verify(secret string, telegramID int64, data string) (Action, []string, error) {
+	i := strings.LastIndex(data, ":")
+	if secret == "" || i <= 0 {
+		return "", nil, ErrInvalidSignature
+	}
+	payload, sig := data[:i], data[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, telegramID, payload))) {
+		return "", nil, ErrInvalidSignature
+	}
+
+	parts := strings.Split(payload, ":")
+	return Action(parts[0]), parts[1:], nil
+}
+
+func signature(secret string, telegramID int64, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(telegramID, 10) + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLength])
+}
@@ -0,0 +1,85 @@
+package tgcallback_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopadel/taskcontract/tgcallback"
+)
+
+const secret = "test-secret"
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	eventID := "4ea67445-b73a-4b5b-b200-cc7f98b7f102"
+
+	data, err := tgcallback.Sign(secret, 42, tgcallback.ActionAttend, eventID)
+	if err != nil {
+		t.Fatalf("failed to sign callback: %v", err)
+	}
+	if !strings.HasPrefix(data, tgcallback.ActionAttend.Prefix()) {
+		t.Errorf("expected data to start with %q, got %q", tgcallback.ActionAttend.Prefix(), data)
+	}
+
+	action, args, err := tgcallback.Verify(secret, 42, data)
+	if err != nil {
+		t.Fatalf("failed to verify callback: %v", err)
+	}
+	if action != tgcallback.ActionAttend {
+		t.Errorf("expected action %s, got %s", tgcallback.ActionAttend, action)
+	}
+	if !slices.Equal(args, []string{eventID}) {
+		t.Errorf("expected args [%s], got %v", eventID, args)
+	}
+}
+
+func TestVerifyRejectsTamperedData(t *testing.T) {
+	data, err := tgcallback.Sign(secret, 42, tgcallback.ActionApprove, "event", "1001")
+	if err != nil {
+		t.Fatalf("failed to sign callback: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		secret     string
+		telegramID int64
+		data       string
+	}{
+		{"other user", secret, 43, data},
+		{"other secret", "other-secret", 42, data},
+		{"empty secret", "", 42, data},
+		{"changed action", secret, 42, strings.Replace(data, string(tgcallback.ActionApprove), string(tgcallback.ActionReject), 1)},
+		{"changed argument", secret, 42, strings.Replace(data, "1001", "1002", 1)},
+		{"changed signature", secret, 42, data[:len(data)-1] + otherChar(data[len(data)-1])},
+		{"no signature", secret, 42, "ga:event:1001"},
+		{"empty data", secret, 42, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tgcallback.Verify(tt.secret, tt.telegramID, tt.data); !errors.Is(err, tgcallback.ErrInvalidSignature) {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func otherChar(c byte) string {
+	if c == 'A' {
+		return "B"
+	}
+	return "A"
+}
+
+func TestSignRejectsLongData(t *testing.T) {
+	_, err := tgcallback.Sign(secret, 42, tgcallback.ActionApprove, strings.Repeat("x", 64))
+	if !errors.Is(err, tgcallback.ErrDataTooLong) {
+		t.Errorf("expected ErrDataTooLong, got %v", err)
+	}
+}
+
+func TestSignRequiresSecret(t *testing.T) {
+	if _, err := tgcallback.Sign("", 42, tgcallback.ActionPay, "event"); err == nil {
+		t.Errorf("expected error without callback secret")
+	}
+}
@@ -29,15 +29,48 @@ type Config struct {
 		URL   string `envconfig:"NATS_URL"`
 		Port     uint16 `envconfig:"NATS_PORT" default:"4222"`
 		Token string `envconfig:"NATS_TOKEN" default:""`
+		// JetStream стрим задач, общий с сервером
+		Stream            string        `envconfig:"NATS_STREAM" default:"TASKS"`
+		StreamSubjects    []string      `envconfig:"NATS_STREAM_SUBJECTS" default:"tasks.>"`
+		TasksSubject      string        `envconfig:"NATS_TASKS_SUBJECT" default:"tasks.active"`
+		DeadLetterSubject string        `envconfig:"NATS_DEAD_LETTER_SUBJECT" default:"tasks.dead"`
+		// Команды от админки, читаются каждой репликой через обычную подписку
+		ControlSubject    string        `envconfig:"NATS_CONTROL_SUBJECT" default:"worker.control"`
+		Durable           string        `envconfig:"NATS_DURABLE" default:"worker"`
+		AckWait           time.Duration `envconfig:"NATS_ACK_WAIT" default:"30s"`
+		MaxParseAttempts  int           `envconfig:"NATS_MAX_PARSE_ATTEMPTS" default:"3"`
+		RetryDelay        time.Duration `envconfig:"NATS_RETRY_DELAY" default:"5s"`
+		// Запросы к серверу (request/reply), префикс должен совпадать с настройкой сервера
+		ServerRequestSubjectPrefix string        `envconfig:"NATS_SERVER_REQUEST_SUBJECT_PREFIX" default:"server.requests"`
+		ServerRequestTimeout       time.Duration `envconfig:"NATS_SERVER_REQUEST_TIMEOUT" default:"10s"`
+	}
+	Worker struct {
+		// По умолчанию hostname-pid, должен быть уникальным среди реплик
+		ID            string        `envconfig:"WORKER_ID"`
+		LeaseDuration time.Duration `envconfig:"WORKER_LEASE_DURATION" default:"5m"`
+		PollInterval  time.Duration `envconfig:"WORKER_POLL_INTERVAL" default:"30s"`
+		PollBatchSize uint64        `envconfig:"WORKER_POLL_BATCH_SIZE" default:"50"`
+		// Повтор упавшей задачи: RetryBaseDelay * 2^(попытка-1) с джиттером, но не больше RetryMaxDelay
+		RetryBaseDelay time.Duration `envconfig:"WORKER_RETRY_BASE_DELAY" default:"30s"`
+		RetryMaxDelay  time.Duration `envconfig:"WORKER_RETRY_MAX_DELAY" default:"1h"`
+		// Рассылки: сообщений в секунду (лимит Telegram около 30) и размер пачки получателей
+		BroadcastRate      int    `envconfig:"WORKER_BROADCAST_RATE" default:"25"`
+		BroadcastBatchSize uint64 `envconfig:"WORKER_BROADCAST_BATCH_SIZE" default:"100"`
 	}
 	TG struct {
 		BotToken      string `envconfig:"TG_BOT_TOKEN"`
 		WebAppName    string `envconfig:"WEBAPP_NAME"`
 		TgBotUsername string `envconfig:"TG_BOT_USERNAME"`
+		// Ключ подписи inline-кнопок напоминаний, должен совпадать с ключом сервера. Без него кнопки не отправляются
+		CallbackSecret string `envconfig:"TG_CALLBACK_SECRET"`
 	}
 	Log struct {
 		Handler string `envconfig:"LOG_HANDLER" default:"tint"`
 	}
+	Messages struct {
+		// Язык шаблонов уведомлений, если для языка пользователя нет своего варианта
+		DefaultLanguage string `envconfig:"MESSAGES_DEFAULT_LANGUAGE" default:"ru"`
+	}
 }
 
 func Load(envFile string) *Config {
@@ -66,6 +99,17 @@ func (c *Config) Print() {
 	}
 }
 
+func (c *Config) WorkerID() string {
+	if c.Worker.ID != "" {
+		return c.Worker.ID
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 func (c *Config) TelegramToken() string {
 	return c.TG.BotToken
 }
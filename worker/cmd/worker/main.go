@@ -7,13 +7,16 @@ import (
 	"os/signal"
 
 	"gopadel/scheduler/cmd/config"
+	"gopadel/scheduler/pkg/consumer"
 	"gopadel/scheduler/pkg/handler"
 	"gopadel/scheduler/pkg/repo/pg"
+	"gopadel/scheduler/pkg/server"
 	"gopadel/scheduler/pkg/telegram"
 	"gopadel/scheduler/pkg/utils/slogx"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
 func main() {
@@ -55,8 +58,11 @@ func main() {
 	}
 
 	taskRepo := pg.NewTaskRepo(pool)
-	registrationRepo := pg.NewRegistrationRepo(pool)
-	taskHandler, err := handler.NewTaskHandler(taskRepo, registrationRepo, telegramClient, cfg)
+	broadcastRepo := pg.NewBroadcastRepo(pool)
+	userRepo := pg.NewUserRepo(pool)
+	messageRenderer := telegram.NewMessageRenderer(pg.NewMessageTemplateRepo(pool), userRepo, cfg)
+	serverClient := server.NewClient(nc, cfg)
+	taskHandler, err := handler.NewTaskHandler(taskRepo, broadcastRepo, userRepo, telegramClient, messageRenderer, serverClient, cfg)
 	if err != nil {
 		slog.Error("Error creating task handler", "error", err)
 		os.Exit(1)
@@ -72,14 +78,29 @@ func main() {
 		}
 	}()
 
-	nc.Subscribe("tasks.active", func(m *nats.Msg) {
-		task, err := taskHandler.HandleTaskMessage(ctx, m)
-		if err != nil {
-			log.Error("Error handling task message", slogx.Err(err))
-			return
+	js, err := jetstream.New(nc)
+	if err != nil {
+		slog.Error("Error creating JetStream context", "error", err)
+		os.Exit(1)
+	}
+
+	taskConsumer := consumer.NewTaskConsumer(js, taskHandler, cfg)
+	if err := taskConsumer.Start(ctx); err != nil {
+		slog.Error("Error starting JetStream consumer", "error", err)
+		os.Exit(1)
+	}
+	defer taskConsumer.Stop()
+
+	controlSub, err := nc.Subscribe(cfg.NATS.ControlSubject, func(m *nats.Msg) {
+		if err := taskHandler.HandleControlMessage(ctx, m); err != nil {
+			log.Error("Error handling control message", slogx.Err(err))
 		}
-		log.Info("Task message handled", "task", task)
 	})
+	if err != nil {
+		slog.Error("Error subscribing to control subject", "error", err)
+		os.Exit(1)
+	}
+	defer controlSub.Unsubscribe()
 
 	slog.Info("Worker is running. NATS consumer and task scheduler are active.")
 	
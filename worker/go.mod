@@ -7,6 +7,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/lmittmann/tint v1.1.2
+	gopadel/taskcontract v0.0.0
 )
 
 require (
@@ -59,3 +60,5 @@ require (
 	golang.org/x/text v0.24.0 // indirect
 	google.golang.org/protobuf v1.34.1 // indirect
 )
+
+replace gopadel/taskcontract => ../taskcontract
@@ -0,0 +1,2 @@
+-- Истечение предложения места из листа ожидания: воркер просит сервер закрыть предложение и предложить место следующему
+ALTER TYPE task_type ADD VALUE 'waitlist.offer.expire';
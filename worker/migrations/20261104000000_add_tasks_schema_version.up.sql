@@ -0,0 +1,2 @@
+-- Версия данных задачи из контракта taskcontract. Задачи, сохраненные до контракта, соответствуют версии 1
+ALTER TABLE tasks ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1;
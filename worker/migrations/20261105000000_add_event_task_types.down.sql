@@ -0,0 +1,21 @@
+DELETE FROM tasks WHERE task_type::text LIKE 'event.%';
+
+ALTER TYPE task_type RENAME TO task_type_old;
+
+CREATE TYPE task_type AS ENUM (
+    'tournament.registration.success',
+    'tournament.reminder.48hours',
+    'tournament.reminder.24hours',
+    'tournament.free.reminder.48hours',
+    'tournament.payment.success',
+    'tournament.loyalty.changed',
+    'tournament.registration.canceled',
+    'tournament.registration.auto_delete_unpaid',
+    'tournament.tasks.cancel',
+    'broadcast.send',
+    'waitlist.offer.expire'
+);
+
+ALTER TABLE tasks ALTER COLUMN task_type TYPE task_type USING task_type::text::task_type;
+
+DROP TYPE task_type_old;
@@ -0,0 +1,9 @@
+-- Уведомления о регистрации на любое событие: игру, турнир или тренировку
+ALTER TYPE task_type ADD VALUE 'event.registration.confirmed';
+ALTER TYPE task_type ADD VALUE 'event.reminder.48hours';
+ALTER TYPE task_type ADD VALUE 'event.reminder.24hours';
+ALTER TYPE task_type ADD VALUE 'event.registration.canceled';
+ALTER TYPE task_type ADD VALUE 'event.registration.requested';
+ALTER TYPE task_type ADD VALUE 'event.registration.approved';
+ALTER TYPE task_type ADD VALUE 'event.registration.rejected';
+ALTER TYPE task_type ADD VALUE 'event.tasks.cancel';
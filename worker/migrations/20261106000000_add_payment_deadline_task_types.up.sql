@@ -0,0 +1,3 @@
+-- Срок оплаты регистрации: воркер передает его серверу, регистрацию отменяет сервер
+ALTER TYPE task_type ADD VALUE 'event.payment.deadline';
+ALTER TYPE task_type ADD VALUE 'event.payment.expired';
@@ -0,0 +1,4 @@
+-- Идентификатор сообщения JetStream, по которому отбрасываются повторные доставки
+ALTER TABLE tasks ADD COLUMN message_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_tasks_message_id ON tasks (message_id);
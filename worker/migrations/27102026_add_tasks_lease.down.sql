@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tasks_lease;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE tasks DROP COLUMN IF EXISTS locked_by;
@@ -0,0 +1,6 @@
+-- Аренда задачи воркером: задачу выполняет только тот экземпляр, который ее захватил.
+-- Если воркер упал, задача возвращается в pending после истечения locked_until
+ALTER TABLE tasks ADD COLUMN locked_by VARCHAR(255);
+ALTER TABLE tasks ADD COLUMN locked_until TIMESTAMP;
+
+CREATE INDEX idx_tasks_lease ON tasks (locked_until) WHERE status = 'processing';
@@ -0,0 +1,26 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS last_error;
+
+UPDATE tasks SET status = 'failed' WHERE status = 'dead';
+
+DROP INDEX IF EXISTS idx_tasks_ready;
+DROP INDEX IF EXISTS idx_tasks_cancelled;
+DROP INDEX IF EXISTS idx_tasks_lease;
+
+CREATE TYPE task_status_new AS ENUM (
+    'pending',
+    'processing',
+    'completed',
+    'failed',
+    'cancelled'
+);
+
+ALTER TABLE tasks ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN status TYPE task_status_new USING status::text::task_status_new;
+ALTER TABLE tasks ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE task_status;
+ALTER TYPE task_status_new RENAME TO task_status;
+
+CREATE INDEX idx_tasks_ready ON tasks (execute_at) WHERE status = 'pending';
+CREATE INDEX idx_tasks_cancelled ON tasks (status, created_at) WHERE status = 'cancelled';
+CREATE INDEX idx_tasks_lease ON tasks (locked_until) WHERE status = 'processing';
@@ -0,0 +1,5 @@
+-- Задачи, исчерпавшие попытки или упавшие с неустранимой ошибкой, переводятся в dead.
+-- Админ может посмотреть last_error и перезапустить задачу
+ALTER TYPE task_status ADD VALUE 'dead';
+
+ALTER TABLE tasks ADD COLUMN last_error TEXT;
@@ -0,0 +1,2 @@
+-- Отправка рассылки админа, данные рассылки лежат в таблицах broadcasts и broadcast_recipients сервера
+ALTER TYPE task_type ADD VALUE 'broadcast.send';
@@ -0,0 +1,187 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gopadel/scheduler/cmd/config"
+	"gopadel/scheduler/pkg/handler"
+	"gopadel/scheduler/pkg/repo"
+	"gopadel/scheduler/pkg/utils/slogx"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// Заголовки сообщения в dead-letter
+	headerDeadLetterReason   = "Dead-Letter-Reason"
+	headerDeadLetterSubject  = "Dead-Letter-Subject"
+	headerDeadLetterSequence = "Dead-Letter-Stream-Sequence"
+
+	// streamMaxAge должен совпадать с настройками стрима на сервере
+	streamMaxAge = 7 * 24 * time.Hour
+)
+
+// TaskConsumer читает задачи из JetStream через durable pull consumer.
+// Сообщение подтверждается только после того, как задача сохранена в БД
+type TaskConsumer struct {
+	js         jetstream.JetStream
+	handler    *handler.TaskHandler
+	config     *config.Config
+	consumeCtx jetstream.ConsumeContext
+}
+
+func NewTaskConsumer(js jetstream.JetStream, handler *handler.TaskHandler, config *config.Config) *TaskConsumer {
+	return &TaskConsumer{
+		js:      js,
+		handler: handler,
+		config:  config,
+	}
+}
+
+// Start создает стрим (если его еще нет) и durable consumer и начинает получать сообщения
+func (c *TaskConsumer) Start(ctx context.Context) error {
+	if err := c.ensureStream(ctx); err != nil {
+		return err
+	}
+
+	cons, err := c.js.CreateOrUpdateConsumer(ctx, c.config.NATS.Stream, jetstream.ConsumerConfig{
+		Durable:       c.config.NATS.Durable,
+		FilterSubject: c.config.NATS.TasksSubject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       c.config.NATS.AckWait,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		// Ошибки БД повторяются без ограничений, неразбираемые сообщения уходят в dead-letter
+		MaxDeliver: -1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consumer %s: %w", c.config.NATS.Durable, err)
+	}
+
+	c.consumeCtx, err = cons.Consume(func(msg jetstream.Msg) {
+		c.handle(ctx, msg)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start consuming: %w", err)
+	}
+
+	slog.Info("JetStream consumer started",
+		"stream", c.config.NATS.Stream,
+		"durable", c.config.NATS.Durable,
+		"subject", c.config.NATS.TasksSubject)
+	return nil
+}
+
+func (c *TaskConsumer) Stop() {
+	if c.consumeCtx != nil {
+		c.consumeCtx.Stop()
+	}
+}
+
+// ensureStream создает стрим задач. Существующий стрим не изменяется, его может создать и сервер
+func (c *TaskConsumer) ensureStream(ctx context.Context) error {
+	_, err := c.js.Stream(ctx, c.config.NATS.Stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("failed to get stream %s: %w", c.config.NATS.Stream, err)
+	}
+
+	_, err = c.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:      c.config.NATS.Stream,
+		Subjects:  c.config.NATS.StreamSubjects,
+		Storage:   jetstream.FileStorage,
+		Retention: jetstream.LimitsPolicy,
+		MaxAge:    streamMaxAge,
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("failed to create stream %s: %w", c.config.NATS.Stream, err)
+	}
+
+	slog.Info("JetStream stream created", "stream", c.config.NATS.Stream)
+	return nil
+}
+
+func (c *TaskConsumer) handle(ctx context.Context, msg jetstream.Msg) {
+	log := slogx.FromCtx(ctx)
+
+	task, err := c.handler.HandleTaskMessage(ctx, msg)
+	switch {
+	case errors.Is(err, handler.ErrInvalidMessage):
+		c.handleInvalid(ctx, msg, err)
+		return
+	case errors.Is(err, repo.ErrTaskExists):
+		log.Info("Duplicate task message skipped", "message_id", msg.Headers().Get(jetstream.MsgIDHeader))
+	case err != nil && task == nil:
+		// Задача не сохранена, сообщение будет доставлено повторно
+		log.Error("Error handling task message", slogx.Err(err))
+		if err := msg.NakWithDelay(c.config.NATS.RetryDelay); err != nil {
+			log.Error("Failed to nak task message", slogx.Err(err))
+		}
+		return
+	case err != nil:
+		log.Error("Task saved but not scheduled", "task_id", task.ID, slogx.Err(err))
+	default:
+		log.Info("Task message handled", "task", task)
+	}
+
+	if err := msg.Ack(); err != nil {
+		log.Error("Failed to ack task message", slogx.Err(err))
+	}
+}
+
+// handleInvalid повторяет неразбираемое сообщение MaxParseAttempts раз, а затем
+// перекладывает его в dead-letter subject и больше не доставляет
+func (c *TaskConsumer) handleInvalid(ctx context.Context, msg jetstream.Msg, cause error) {
+	log := slogx.FromCtx(ctx)
+
+	meta, err := msg.Metadata()
+	if err != nil {
+		log.Error("Failed to get task message metadata", slogx.Err(err))
+		_ = msg.NakWithDelay(c.config.NATS.RetryDelay)
+		return
+	}
+
+	if meta.NumDelivered < uint64(c.config.NATS.MaxParseAttempts) {
+		log.Warn("Invalid task message, will retry",
+			"attempt", meta.NumDelivered,
+			slogx.Err(cause))
+		if err := msg.NakWithDelay(c.config.NATS.RetryDelay); err != nil {
+			log.Error("Failed to nak task message", slogx.Err(err))
+		}
+		return
+	}
+
+	deadLetter := nats.NewMsg(c.config.NATS.DeadLetterSubject)
+	deadLetter.Data = msg.Data()
+	for key, values := range msg.Headers() {
+		// Иначе JetStream отбросит dead-letter как дубликат исходного сообщения
+		if key == jetstream.MsgIDHeader {
+			continue
+		}
+		deadLetter.Header[key] = values
+	}
+	deadLetter.Header.Set(headerDeadLetterReason, cause.Error())
+	deadLetter.Header.Set(headerDeadLetterSubject, msg.Subject())
+	deadLetter.Header.Set(headerDeadLetterSequence, strconv.FormatUint(meta.Sequence.Stream, 10))
+
+	if _, err := c.js.PublishMsg(ctx, deadLetter); err != nil {
+		log.Error("Failed to publish task message to dead-letter", slogx.Err(err))
+		_ = msg.NakWithDelay(c.config.NATS.RetryDelay)
+		return
+	}
+
+	log.Error("Invalid task message moved to dead-letter",
+		"subject", c.config.NATS.DeadLetterSubject,
+		"stream_sequence", meta.Sequence.Stream,
+		slogx.Err(cause))
+	if err := msg.Term(); err != nil {
+		log.Error("Failed to terminate task message", slogx.Err(err))
+	}
+}
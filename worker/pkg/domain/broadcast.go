@@ -0,0 +1,32 @@
+package domain
+
+type BroadcastStatus string
+
+const (
+	BroadcastStatusScheduled BroadcastStatus = "scheduled"
+	BroadcastStatusSending   BroadcastStatus = "sending"
+	BroadcastStatusCompleted BroadcastStatus = "completed"
+	BroadcastStatusCancelled BroadcastStatus = "cancelled"
+)
+
+type BroadcastRecipientStatus string
+
+const (
+	BroadcastRecipientStatusPending BroadcastRecipientStatus = "pending"
+	BroadcastRecipientStatusSending BroadcastRecipientStatus = "sending"
+	BroadcastRecipientStatusSent    BroadcastRecipientStatus = "sent"
+	BroadcastRecipientStatusFailed  BroadcastRecipientStatus = "failed"
+	BroadcastRecipientStatusBlocked BroadcastRecipientStatus = "blocked" // Пользователь заблокировал бота
+)
+
+// Broadcast рассылка, созданная в админке. Получатели хранятся в broadcast_recipients
+type Broadcast struct {
+	ID      string
+	Message Message
+	Status  BroadcastStatus
+}
+
+type BroadcastRecipient struct {
+	ID         string
+	TelegramID int64
+}
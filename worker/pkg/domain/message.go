@@ -18,7 +18,17 @@ type Message struct {
 }
 
 type MessageText struct {
-	Text string `json:"text"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"` // HTML, MarkdownV2 или пусто
+	// Buttons inline-клавиатура под сообщением, ряды кнопок
+	Buttons [][]MessageButton `json:"buttons,omitempty"`
+}
+
+// MessageButton inline-кнопка: данные для бота или ссылка
+type MessageButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data,omitempty"`
+	URL          string `json:"url,omitempty"`
 }
 
 type MessagePhoto struct {
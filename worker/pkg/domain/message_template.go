@@ -0,0 +1,9 @@
+package domain
+
+// MessageTemplate шаблон уведомления на text/template, редактируется в админке
+type MessageTemplate struct {
+	Key       string
+	Language  string
+	Body      string
+	ParseMode string
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+	_ "time/tzdata" // В alpine-образах нет базы часовых поясов
+
+	"gopadel/taskcontract"
+)
+
+type NotificationCategory string
+
+const (
+	NotificationCategoryReminders NotificationCategory = "reminders"
+	NotificationCategoryPayments  NotificationCategory = "payments"
+)
+
+// NotificationSettings настройки уведомлений пользователя, их меняет сервер (профиль и команды бота).
+// Время тихих часов — минуты от полуночи в часовом поясе пользователя
+type NotificationSettings struct {
+	Muted           bool
+	Reminders       bool
+	Payments        bool
+	QuietHoursStart *int
+	QuietHoursEnd   *int
+	Timezone        string
+}
+
+// Allows можно ли отправить уведомление категории category. Пустая категория — уведомление без категории,
+// его отключает только полное отключение уведомлений
+func (s *NotificationSettings) Allows(category NotificationCategory) bool {
+	if s.Muted {
+		return false
+	}
+	switch category {
+	case NotificationCategoryReminders:
+		return s.Reminders
+	case NotificationCategoryPayments:
+		return s.Payments
+	default:
+		return true
+	}
+}
+
+// QuietUntil время окончания тихих часов, если now в них попадает. Интервал может переходить через полночь
+func (s *NotificationSettings) QuietUntil(now time.Time) (time.Time, bool) {
+	if s.QuietHoursStart == nil || s.QuietHoursEnd == nil || *s.QuietHoursStart == *s.QuietHoursEnd {
+		return time.Time{}, false
+	}
+	start, end := *s.QuietHoursStart, *s.QuietHoursEnd
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	endOn := func(day int) time.Time {
+		return time.Date(local.Year(), local.Month(), day, end/60, end%60, 0, 0, location)
+	}
+
+	switch {
+	case start < end && minute >= start && minute < end:
+		return endOn(local.Day()), true
+	case start > end && minute >= start:
+		return endOn(local.Day() + 1), true
+	case start > end && minute < end:
+		return endOn(local.Day()), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// TaskNotificationCategory категория уведомления задачи для настроек пользователя
+func TaskNotificationCategory(taskType taskcontract.TaskType) NotificationCategory {
+	switch taskType {
+	case taskcontract.TaskTypeEventReminder48Hours, taskcontract.TaskTypeEventReminder24Hours,
+		taskcontract.TaskTypeTournamentReminder48Hours, taskcontract.TaskTypeTournamentReminder24Hours, taskcontract.TaskTypeTournamentFreeReminder48Hours:
+		return NotificationCategoryReminders
+	// Уведомления о регистрации на событие отключаются вместе с напоминаниями
+	case taskcontract.TaskTypeEventRegistrationConfirmed, taskcontract.TaskTypeEventRegistrationCanceled,
+		taskcontract.TaskTypeEventRegistrationRequested, taskcontract.TaskTypeEventRegistrationApproved, taskcontract.TaskTypeEventRegistrationRejected:
+		return NotificationCategoryReminders
+	case taskcontract.TaskTypeEventPaymentExpired, taskcontract.TaskTypeTournamentRegistrationAutoDeleteUnpaid:
+		return NotificationCategoryPayments
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,130 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"gopadel/scheduler/pkg/domain"
+	"gopadel/taskcontract"
+)
+
+func quietHours(start, end int, timezone string) *domain.NotificationSettings {
+	return &domain.NotificationSettings{
+		QuietHoursStart: &start,
+		QuietHoursEnd:   &end,
+		Timezone:        timezone,
+	}
+}
+
+func TestQuietUntil(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		settings  *domain.NotificationSettings
+		now       time.Time
+		wantQuiet bool
+		wantUntil time.Time
+	}{
+		{
+			name:      "inside daytime interval",
+			settings:  quietHours(13*60, 15*60, "UTC"),
+			now:       time.Date(2026, 5, 10, 14, 0, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 10, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "end of daytime interval is not quiet",
+			settings: quietHours(13*60, 15*60, "UTC"),
+			now:      time.Date(2026, 5, 10, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "overnight interval before midnight ends next day",
+			settings:  quietHours(22*60, 8*60, "UTC"),
+			now:       time.Date(2026, 5, 10, 23, 30, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "overnight interval after midnight ends same day",
+			settings:  quietHours(22*60, 8*60, "UTC"),
+			now:       time.Date(2026, 5, 11, 3, 0, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "overnight interval at the end of month",
+			settings:  quietHours(22*60, 8*60, "UTC"),
+			now:       time.Date(2026, 5, 31, 22, 0, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "outside overnight interval",
+			settings: quietHours(22*60, 8*60, "UTC"),
+			now:      time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "user timezone",
+			settings:  quietHours(22*60, 8*60, "Europe/Moscow"),
+			now:       time.Date(2026, 5, 10, 20, 0, 0, 0, time.UTC), // 23:00 в Москве
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 11, 8, 0, 0, 0, moscow),
+		},
+		{
+			name:     "equal start and end disable quiet hours",
+			settings: quietHours(8*60, 8*60, "UTC"),
+			now:      time.Date(2026, 5, 10, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "quiet hours not set",
+			settings: &domain.NotificationSettings{Timezone: "UTC"},
+			now:      time.Date(2026, 5, 10, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "unknown timezone falls back to UTC",
+			settings:  quietHours(22*60, 8*60, "Mars/Olympus"),
+			now:       time.Date(2026, 5, 10, 23, 0, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2026, 5, 11, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := tt.settings.QuietUntil(tt.now)
+			if quiet != tt.wantQuiet {
+				t.Fatalf("expected quiet=%v, got %v", tt.wantQuiet, quiet)
+			}
+			if quiet && !until.Equal(tt.wantUntil) {
+				t.Errorf("expected quiet hours until %s, got %s", tt.wantUntil, until)
+			}
+		})
+	}
+}
+
+func TestTaskNotificationCategory(t *testing.T) {
+	tests := []struct {
+		taskType taskcontract.TaskType
+		want     domain.NotificationCategory
+	}{
+		{taskcontract.TaskTypeEventReminder24Hours, domain.NotificationCategoryReminders},
+		{taskcontract.TaskTypeEventRegistrationConfirmed, domain.NotificationCategoryReminders},
+		{taskcontract.TaskTypeEventRegistrationCanceled, domain.NotificationCategoryReminders},
+		{taskcontract.TaskTypeEventRegistrationRequested, domain.NotificationCategoryReminders},
+		{taskcontract.TaskTypeEventRegistrationApproved, domain.NotificationCategoryReminders},
+		{taskcontract.TaskTypeEventRegistrationRejected, domain.NotificationCategoryReminders},
+		{taskcontract.TaskTypeEventPaymentExpired, domain.NotificationCategoryPayments},
+		{taskcontract.TaskTypeTournamentLoyaltyChanged, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.taskType), func(t *testing.T) {
+			if got := domain.TaskNotificationCategory(tt.taskType); got != tt.want {
+				t.Errorf("expected category %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -6,17 +6,11 @@ import (
 
 	"encoding/json"
 	"strings"
-)
 
-type NATSTaskMessage struct {
-	TaskName  string          `json:"task_name"`
-	ExecuteAt string          `json:"execute_at"`
-	Data      json.RawMessage `json:"data"`
-	CreatedAt string          `json:"created_at"`
-}
+	"gopadel/taskcontract"
+)
 
 type TaskStatus string
-type TaskType string
 
 const (
 	TaskStatusPending    TaskStatus = "pending"
@@ -24,24 +18,14 @@ const (
 	TaskStatusCompleted  TaskStatus = "completed"
 	TaskStatusFailed     TaskStatus = "failed"
 	TaskStatusCancelled  TaskStatus = "cancelled"
-)
-
-const (
-	TaskTypeTournamentRegistrationSuccess      TaskType = "tournament.registration.success"
-	TaskTypeTournamentReminder48Hours          TaskType = "tournament.reminder.48hours"
-	TaskTypeTournamentReminder24Hours          TaskType = "tournament.reminder.24hours"
-	TaskTypeTournamentFreeReminder48Hours      TaskType = "tournament.free.reminder.48hours"
-	TaskTypeTournamentPaymentSuccess           TaskType = "tournament.payment.success"
-	TaskTypeTournamentLoyaltyChanged           TaskType = "tournament.loyalty.changed"
-	TaskTypeTournamentRegistrationCanceled     TaskType = "tournament.registration.canceled"
-	TaskTypeTournamentRegistrationAutoDeleteUnpaid TaskType = "tournament.registration.auto_delete_unpaid"
-	TaskTypeTournamentTasksCancel              TaskType = "tournament.tasks.cancel"
+	TaskStatusDead       TaskStatus = "dead" // Попытки исчерпаны или ошибка неустранима, перезапускается админом
 )
 
 type Task struct {
     ID           string          `db:"id" json:"id"`
-    TaskType     TaskType        `db:"task_type" json:"task_type"`         // Enum task_type
+    TaskType     taskcontract.TaskType `db:"task_type" json:"task_type"`   // Enum task_type
     Status       TaskStatus      `db:"status" json:"status"`               // Enum task_status
+    SchemaVersion int            `db:"schema_version" json:"schema_version"` // Версия данных из taskcontract
 
     ExecuteAt    time.Time       `db:"execute_at" json:"execute_at"`
     CreatedAt    time.Time       `db:"created_at" json:"created_at"`
@@ -54,10 +38,12 @@ type Task struct {
 }
 
 type CreateTask struct {
-	TaskType     TaskType        `json:"task_type"`              // required
+	TaskType     taskcontract.TaskType `json:"task_type"`        // required
+	SchemaVersion int            `json:"schema_version"`         // required
 	ExecuteAt    time.Time       `json:"execute_at"`             // required
 	Data         json.RawMessage `json:"data"`                   // required
 	MaxRetries   int             `json:"max_retries"`            // optional (default 3)
+	MessageID    *string         `json:"message_id,omitempty"`   // optional, id сообщения JetStream для дедупликации
 }
 
 type PatchTask struct {
@@ -67,6 +53,7 @@ type PatchTask struct {
 	MaxRetries    *int             `json:"max_retries,omitempty"`       // optional
 	ExecuteAt     *time.Time       `json:"execute_at,omitempty"`        // optional
 	Data          *json.RawMessage `json:"data,omitempty"`              // optional
+	LastError     *string          `json:"last_error,omitempty"`        // optional
 }
 
 // НАВАЙБКОЖЕНО!!! ВЫВОД ДЛЯ ЛОГОВ
@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gopadel/scheduler/pkg/domain"
+	"gopadel/scheduler/pkg/repo"
+	"gopadel/taskcontract"
+
+	"github.com/go-telegram/bot"
+)
+
+// executeBroadcastSend отправляет рассылку пачками получателей с ограничением скорости.
+// Получатели берутся в отправку атомарно с арендой, поэтому повторный запуск задачи продолжает
+// отправку с того места, где она остановилась, и не шлет сообщение дважды. Получатели в тихих часах
+// откладываются, и задача переносится до ближайшего из них
+func (e *TaskExecutor) executeBroadcastSend(ctx context.Context, payload *taskcontract.BroadcastSend) error {
+	broadcastID := payload.BroadcastID
+
+	log := slog.With("broadcast_id", broadcastID)
+
+	broadcast, err := e.broadcastRepo.GetByID(ctx, broadcastID)
+	if errors.Is(err, repo.ErrBroadcastNotFound) {
+		return fmt.Errorf("%w: %v", ErrInvalidTaskData, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	started, err := e.broadcastRepo.Start(ctx, broadcastID)
+	if err != nil {
+		return err
+	}
+	if !started {
+		log.Info("broadcast skipped", "status", broadcast.Status)
+		return nil
+	}
+
+	rate := max(e.config.Worker.BroadcastRate, 1)
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	counts := map[domain.BroadcastRecipientStatus]int{}
+	deferred := 0
+	for {
+		// Рассылку могут отменить во время отправки, проверяем статус перед каждой пачкой
+		current, err := e.broadcastRepo.GetByID(ctx, broadcastID)
+		if err != nil {
+			return err
+		}
+		if current.Status == domain.BroadcastStatusCancelled {
+			log.Info("broadcast cancelled during sending", "counts", counts)
+			return nil
+		}
+
+		interrupted, err := e.broadcastRepo.FailInterruptedRecipients(ctx, broadcastID)
+		if err != nil {
+			return err
+		}
+		if interrupted > 0 {
+			log.Warn("broadcast recipients interrupted by worker failure marked as failed", "count", interrupted)
+		}
+
+		recipients, err := e.broadcastRepo.ClaimRecipients(ctx, broadcastID, e.config.Worker.BroadcastBatchSize, e.config.Worker.LeaseDuration)
+		if err != nil {
+			return err
+		}
+		if len(recipients) == 0 {
+			break
+		}
+
+		for i, recipient := range recipients {
+			settings, err := e.userRepo.GetNotificationSettings(ctx, recipient.TelegramID)
+			if err != nil {
+				e.releaseBroadcastRecipients(ctx, recipients[i:])
+				return err
+			}
+			if until, quiet := settings.QuietUntil(time.Now()); quiet {
+				if err := e.broadcastRepo.DeferRecipient(ctx, recipient.ID, until); err != nil {
+					e.releaseBroadcastRecipients(ctx, recipients[i:])
+					return err
+				}
+				deferred++
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				e.releaseBroadcastRecipients(ctx, recipients[i:])
+				return ctx.Err()
+			case <-ticker.C:
+			}
+
+			if err := e.broadcastRepo.MarkAttempted(ctx, recipient.ID); err != nil {
+				e.releaseBroadcastRecipients(ctx, recipients[i:])
+				return err
+			}
+
+			status, sendError, err := e.sendBroadcastMessage(ctx, recipient, broadcast.Message)
+			if err != nil {
+				// Сообщение этому получателю могло уйти, после окончания аренды он будет помечен failed
+				e.releaseBroadcastRecipients(ctx, recipients[i+1:])
+				return err
+			}
+
+			if err := e.broadcastRepo.SetRecipientStatus(ctx, recipient.ID, status, sendError); err != nil {
+				e.releaseBroadcastRecipients(ctx, recipients[i+1:])
+				return err
+			}
+			counts[status]++
+		}
+	}
+
+	// Остались отложенные получатели или получатели с живой арендой: продолжим, когда они освободятся
+	next, err := e.broadcastRepo.NextRecipientAt(ctx, broadcastID)
+	if err != nil {
+		return err
+	}
+	if next != nil {
+		log.Info("broadcast deferred",
+			"until", next,
+			"deferred", deferred,
+			"sent", counts[domain.BroadcastRecipientStatusSent])
+		return &DeferredError{Until: *next, Reason: "broadcast recipients pending"}
+	}
+
+	if err := e.broadcastRepo.Complete(ctx, broadcastID); err != nil {
+		return err
+	}
+
+	log.Info("broadcast completed",
+		"sent", counts[domain.BroadcastRecipientStatusSent],
+		"failed", counts[domain.BroadcastRecipientStatusFailed],
+		"blocked", counts[domain.BroadcastRecipientStatusBlocked])
+	return nil
+}
+
+// sendBroadcastMessage отправляет сообщение одному получателю. На 429 ждет retry_after и повторяет,
+// остальные ошибки Telegram записываются в статус получателя. Ошибка возвращается, только если
+// отправка прервана остановкой воркера
+func (e *TaskExecutor) sendBroadcastMessage(ctx context.Context, recipient *domain.BroadcastRecipient, message domain.Message) (domain.BroadcastRecipientStatus, *string, error) {
+	for {
+		err := e.telegramClient.SendMessage(ctx, domain.Recipient{ChatID: recipient.TelegramID}, message)
+		if err == nil {
+			return domain.BroadcastRecipientStatusSent, nil, nil
+		}
+
+		var tooManyRequests *bot.TooManyRequestsError
+		if errors.As(err, &tooManyRequests) {
+			retryAfter := time.Duration(tooManyRequests.RetryAfter) * time.Second
+			slog.Warn("broadcast rate limited by telegram", "telegram_id", recipient.TelegramID, "retry_after", retryAfter)
+			select {
+			case <-ctx.Done():
+				return "", nil, ctx.Err()
+			case <-time.After(retryAfter):
+			}
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+
+		sendError := err.Error()
+		if errors.Is(err, bot.ErrorForbidden) {
+			return domain.BroadcastRecipientStatusBlocked, &sendError, nil
+		}
+		return domain.BroadcastRecipientStatusFailed, &sendError, nil
+	}
+}
+
+// releaseBroadcastRecipients возвращает в pending получателей, до которых не дошла очередь
+func (e *TaskExecutor) releaseBroadcastRecipients(ctx context.Context, recipients []*domain.BroadcastRecipient) {
+	if len(recipients) == 0 {
+		return
+	}
+
+	ids := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		ids[i] = recipient.ID
+	}
+
+	// Контекст задачи может быть уже отменен
+	if err := e.broadcastRepo.ReleaseRecipients(context.WithoutCancel(ctx), ids); err != nil {
+		slog.Error("failed to release broadcast recipients", "count", len(ids), "error", err)
+	}
+}
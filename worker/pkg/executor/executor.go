@@ -6,33 +6,59 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"time"
 
 	"gopadel/scheduler/cmd/config"
 	"gopadel/scheduler/pkg/domain"
 	"gopadel/scheduler/pkg/repo"
+	"gopadel/scheduler/pkg/server"
 	"gopadel/scheduler/pkg/telegram"
+	"gopadel/taskcontract"
+	"gopadel/taskcontract/tgcallback"
 )
 
+// ErrInvalidTaskData данные задачи не соответствуют контракту, повторное выполнение не поможет
+var ErrInvalidTaskData = errors.New("invalid task data")
+
+// DeferredError задачу нужно выполнить позже, например после тихих часов пользователя.
+// Это не ошибка выполнения: попытка не расходуется
+type DeferredError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *DeferredError) Error() string {
+	return fmt.Sprintf("task deferred until %s: %s", e.Until.UTC().Format(time.RFC3339), e.Reason)
+}
+
 type TaskSchedulerInterface interface {
 	CancelTask(taskID string) error
 }
 
 type TaskExecutor struct {
 	repo              repo.Task
-	registrationRepo  repo.Registration
+	broadcastRepo     repo.Broadcast
+	userRepo          repo.User
 	telegramClient    *telegram.TelegramClient
+	messageRenderer   *telegram.MessageRenderer
+	serverClient      *server.Client
 	config            *config.Config
 	scheduler         TaskSchedulerInterface
 }
 
-func NewTaskExecutor(repo repo.Task, registrationRepo repo.Registration, telegramClient *telegram.TelegramClient, config *config.Config) *TaskExecutor {
+func NewTaskExecutor(repo repo.Task, broadcastRepo repo.Broadcast, userRepo repo.User, telegramClient *telegram.TelegramClient, messageRenderer *telegram.MessageRenderer, serverClient *server.Client, config *config.Config) *TaskExecutor {
 	return &TaskExecutor{
-		repo:             repo,
-		registrationRepo: registrationRepo,
-		telegramClient:   telegramClient,
-		config:           config,
+		repo:            repo,
+		broadcastRepo:   broadcastRepo,
+		userRepo:        userRepo,
+		telegramClient:  telegramClient,
+		messageRenderer: messageRenderer,
+		serverClient:    serverClient,
+		config:          config,
 	}
 }
 
@@ -49,31 +75,61 @@ func (e *TaskExecutor) ExecuteTask(ctx context.Context, task *domain.Task) error
 	// 	return nil
 	// }
 	
-	var taskData map[string]interface{}
-	if err := json.Unmarshal(task.Data, &taskData); err != nil {
-		return fmt.Errorf("failed to unmarshal task data: %w", err)
+	payload, err := taskcontract.Decode(task.TaskType, task.SchemaVersion, task.Data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTaskData, err)
 	}
 
-	switch task.TaskType {
-	case domain.TaskTypeTournamentTasksCancel:
-		return e.executeTournamentTasksCancel(ctx, taskData)
-	case domain.TaskTypeTournamentRegistrationAutoDeleteUnpaid:
-		return e.executeTournamentRegistrationAutoDeleteUnpaid(ctx, taskData)
+	switch payload := payload.(type) {
+	case *taskcontract.EventTasksCancel:
+		return e.cancelEventTasks(ctx, payload.UserTelegramID, payload.EventID)
+	case *taskcontract.TasksCancel:
+		return e.cancelEventTasks(ctx, payload.UserTelegramID, payload.TournamentID)
+	case *taskcontract.EventPaymentDeadline:
+		return e.expireRegistrationPayment(ctx, payload.UserTelegramID, payload.EventID)
+	case *taskcontract.AutoDeleteUnpaid:
+		return e.expireRegistrationPayment(ctx, payload.UserTelegramID, payload.TournamentID)
+	case *taskcontract.BroadcastSend:
+		return e.executeBroadcastSend(ctx, payload)
+	case *taskcontract.WaitlistOfferExpire:
+		return e.executeWaitlistOfferExpire(ctx, payload)
+	case taskcontract.UserNotification:
+		return e.sendTelegramMessage(ctx, task.TaskType, payload)
 	default:
-		chatIDFloat, ok := taskData["user_telegram_id"].(float64)
-		if !ok {
-			return fmt.Errorf("user_telegram_id not found or invalid in task data")
-		}
-		chatID := int64(chatIDFloat)
-		return e.sendTelegramMessage(ctx, task.TaskType, chatID, taskData)
+		return fmt.Errorf("%w: no executor for task type %s", ErrInvalidTaskData, task.TaskType)
 	}
 }
 
-func (e *TaskExecutor) sendTelegramMessage(ctx context.Context, taskType domain.TaskType, chatID int64, data map[string]interface{}) error {
+// sendTelegramMessage отправляет уведомление с учетом настроек пользователя: отключенные уведомления
+// пропускаются, в тихие часы задача откладывается до их окончания
+func (e *TaskExecutor) sendTelegramMessage(ctx context.Context, taskType taskcontract.TaskType, payload taskcontract.UserNotification) error {
+	chatID := payload.TelegramID()
 	recipient := domain.Recipient{ChatID: chatID}
+
+	settings, err := e.userRepo.GetNotificationSettings(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	if !settings.Allows(domain.TaskNotificationCategory(taskType)) {
+		slog.Info("notification disabled by user settings, skipping", "task_type", taskType, "user_telegram_id", chatID)
+		return nil
+	}
+	if until, quiet := settings.QuietUntil(time.Now()); quiet {
+		return &DeferredError{Until: until, Reason: "user quiet hours"}
+	}
 	
-	messageText := telegram.GetMessageForTaskType(taskType, data, e.config)
-	
+	data, err := templateData(payload)
+	if err != nil {
+		return err
+	}
+
+	messageText, err := e.messageRenderer.Render(ctx, taskType, payload, data)
+	if err != nil {
+		return err
+	}
+
+	messageText.Buttons = e.messageButtons(taskType, chatID, payload)
+
 	message := domain.Message{
 		Type: domain.MessageTypeText,
 		Text: messageText,
@@ -82,27 +138,101 @@ func (e *TaskExecutor) sendTelegramMessage(ctx context.Context, taskType domain.
 	return e.telegramClient.SendMessage(ctx, recipient, message)
 }
 
-func (e *TaskExecutor) executeTournamentTasksCancel(ctx context.Context, data map[string]interface{}) error {
-	userTelegramIDFloat, ok := data["user_telegram_id"].(float64)
-	if !ok {
-		return fmt.Errorf("user_telegram_id not found or invalid in task data")
+// messageButtons кнопки уведомления. Под напоминанием за 48 часов — подтвердить участие, отменить запись
+// и оплатить, если участие еще не оплачено; под заявкой на игру — принять или отклонить ее.
+// Кнопки обрабатывает бот сервера, поэтому их данные подписываются
+func (e *TaskExecutor) messageButtons(taskType taskcontract.TaskType, chatID int64, payload taskcontract.Payload) [][]domain.MessageButton {
+	if e.config.TG.CallbackSecret == "" {
+		return nil
+	}
+
+	var eventID string
+	var args []string
+	var rows [][]tgcallback.Action
+	switch payload := payload.(type) {
+	case *taskcontract.EventReminder:
+		if taskType != taskcontract.TaskTypeEventReminder48Hours {
+			return nil
+		}
+		eventID, args = payload.EventID, []string{payload.EventID}
+		rows = [][]tgcallback.Action{{tgcallback.ActionAttend, tgcallback.ActionCancel}}
+		if !payload.IsPaid {
+			rows = append(rows, []tgcallback.Action{tgcallback.ActionPay})
+		}
+	case *taskcontract.Reminder:
+		if taskType != taskcontract.TaskTypeTournamentReminder48Hours && taskType != taskcontract.TaskTypeTournamentFreeReminder48Hours {
+			return nil
+		}
+		eventID, args = payload.TournamentID, []string{payload.TournamentID}
+		rows = [][]tgcallback.Action{{tgcallback.ActionAttend, tgcallback.ActionCancel}}
+		if !payload.IsPaid && taskType == taskcontract.TaskTypeTournamentReminder48Hours {
+			rows = append(rows, []tgcallback.Action{tgcallback.ActionPay})
+		}
+	case *taskcontract.EventRegistrationRequested:
+		// Кнопки подписываются для организатора, заявка определяется событием и участником
+		eventID, args = payload.EventID, []string{payload.EventID, strconv.FormatInt(payload.ApplicantTelegramID, 10)}
+		rows = [][]tgcallback.Action{{tgcallback.ActionApprove, tgcallback.ActionReject}}
+	default:
+		return nil
+	}
+
+	buttons := make([][]domain.MessageButton, 0, len(rows))
+	for i, row := range rows {
+		buttonRow := make([]domain.MessageButton, 0, len(row))
+		for _, action := range row {
+			callbackData, err := tgcallback.Sign(e.config.TG.CallbackSecret, chatID, action, args...)
+			if err != nil {
+				slog.Warn("failed to sign message button, sending without buttons", "task_type", taskType, "event_id", eventID, "error", err)
+				// Без основного ряда кнопки не нужны, без дополнительного уходят основные
+				if i == 0 {
+					return nil
+				}
+				return buttons
+			}
+			buttonRow = append(buttonRow, domain.MessageButton{Text: callbackButtonText[action], CallbackData: callbackData})
+		}
+		buttons = append(buttons, buttonRow)
 	}
-	userTelegramID := int64(userTelegramIDFloat)
+	return buttons
+}
+
+// callbackButtonText подписи кнопок уведомлений
+var callbackButtonText = map[tgcallback.Action]string{
+	tgcallback.ActionAttend:  "✅ Я приду",
+	tgcallback.ActionCancel:  "❌ Отменить запись",
+	tgcallback.ActionPay:     "💳 Оплатить",
+	tgcallback.ActionApprove: "✅ Принять",
+	tgcallback.ActionReject:  "❌ Отклонить",
+}
 
-	tournamentID, ok := data["tournament_id"].(string)
-	if !ok {
-		return fmt.Errorf("tournament_id not found or invalid in task data")
+// executeWaitlistOfferExpire истекло окно ответа на предложение места из листа ожидания. Предложения
+// ведет сервер, воркер только сообщает ему о сроке; если сервер недоступен, задача повторяется
+func (e *TaskExecutor) executeWaitlistOfferExpire(ctx context.Context, payload *taskcontract.WaitlistOfferExpire) error {
+	offerID := payload.OfferID
+
+	if err := e.serverClient.ExpireWaitlistOffer(ctx, offerID); err != nil {
+		return fmt.Errorf("failed to expire waitlist offer %s: %w", offerID, err)
 	}
 
-	taskTypesToCancel := []domain.TaskType{
-		domain.TaskTypeTournamentReminder48Hours,
-		domain.TaskTypeTournamentReminder24Hours,
-		domain.TaskTypeTournamentFreeReminder48Hours,
-		domain.TaskTypeTournamentRegistrationAutoDeleteUnpaid,
+	slog.Info("waitlist offer expiration sent to server", "offer_id", offerID)
+	return nil
+}
+
+// cancelEventTasks снимает отложенные задачи пользователя о событии после отмены регистрации:
+// напоминания и отмену неоплаченной регистрации, в том числе поставленные до перехода на event.*
+func (e *TaskExecutor) cancelEventTasks(ctx context.Context, userTelegramID int64, eventID string) error {
+	taskTypesToCancel := []taskcontract.TaskType{
+		taskcontract.TaskTypeEventReminder48Hours,
+		taskcontract.TaskTypeEventReminder24Hours,
+		taskcontract.TaskTypeEventPaymentDeadline,
+		taskcontract.TaskTypeTournamentReminder48Hours,
+		taskcontract.TaskTypeTournamentReminder24Hours,
+		taskcontract.TaskTypeTournamentFreeReminder48Hours,
+		taskcontract.TaskTypeTournamentRegistrationAutoDeleteUnpaid,
 	}
 
 	statuses := []domain.TaskStatus{domain.TaskStatusPending}
-	tasks, err := e.repo.FindTasksByUserAndTournament(ctx, userTelegramID, tournamentID, statuses)
+	tasks, err := e.repo.FindTasksByUserAndEvent(ctx, userTelegramID, eventID, statuses)
 	if err != nil {
 		return fmt.Errorf("failed to find tasks to cancel: %w", err)
 	}
@@ -134,34 +264,38 @@ func (e *TaskExecutor) executeTournamentTasksCancel(ctx context.Context, data ma
 		}
 	}
 
-	slog.Info("tournament tasks cancellation completed", 
+	slog.Info("event tasks cancellation completed", 
 		"user_telegram_id", userTelegramID, 
-		"tournament_id", tournamentID, 
+		"event_id", eventID, 
 		"total_found", len(tasks), 
 		"canceled_count", canceledCount)
 
 	return nil
 }
 
-func (e *TaskExecutor) executeTournamentRegistrationAutoDeleteUnpaid(ctx context.Context, data map[string]interface{}) error {
-	registrationID, ok := data["registration_id"].(string)
-	if !ok {
-		return fmt.Errorf("registration_id not found or invalid in task data")
-	}
-
-	if err := e.registrationRepo.SetCanceledStatus(ctx, registrationID); err != nil {
-		return fmt.Errorf("failed to set registration status to canceled: %w", err)
+// expireRegistrationPayment истек срок оплаты регистрации. Регистрацию отменяет сервер: он освобождает место,
+// предлагает его листу ожидания и уведомляет пользователя. Если сервер недоступен, задача повторяется
+func (e *TaskExecutor) expireRegistrationPayment(ctx context.Context, userTelegramID int64, eventID string) error {
+	if err := e.serverClient.ExpireRegistrationPayment(ctx, userTelegramID, eventID); err != nil {
+		return fmt.Errorf("failed to expire registration payment for event %s: %w", eventID, err)
 	}
 
-	slog.Info("registration automatically canceled due to unpaid status", 
-		"registration_id", registrationID)
+	slog.Info("registration payment expiration sent to server",
+		"user_telegram_id", userTelegramID,
+		"event_id", eventID)
+	return nil
+}
 
-	chatIDFloat, ok := data["user_telegram_id"].(float64)
-	if !ok {
-		slog.Warn("user_telegram_id not found, skipping notification", "registration_id", registrationID)
-		return nil
+// templateData данные задачи в виде, в котором их получают шаблоны сообщений: поля по именам из JSON
+func templateData(payload taskcontract.Payload) (map[string]interface{}, error) {
+	dataBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task data: %w", err)
 	}
-	chatID := int64(chatIDFloat)
 
-	return e.sendTelegramMessage(ctx, domain.TaskTypeTournamentRegistrationAutoDeleteUnpaid, chatID, data)
-}
\ No newline at end of file
+	var data map[string]interface{}
+	if err := json.Unmarshal(dataBytes, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task data: %w", err)
+	}
+	return data, nil
+}
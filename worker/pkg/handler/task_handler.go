@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -13,21 +14,27 @@ import (
 	"gopadel/scheduler/pkg/executor"
 	"gopadel/scheduler/pkg/repo"
 	"gopadel/scheduler/pkg/scheduler"
+	"gopadel/scheduler/pkg/server"
 	"gopadel/scheduler/pkg/telegram"
+	"gopadel/taskcontract"
 
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
+// ErrInvalidMessage сообщение не удалось разобрать, повторная доставка его не исправит
+var ErrInvalidMessage = errors.New("invalid task message")
+
 type TaskHandler struct {
 	repo      repo.Task
 	executor  *executor.TaskExecutor
 	scheduler *scheduler.TaskScheduler
 }
 
-func NewTaskHandler(repo repo.Task, registrationRepo repo.Registration, telegramClient *telegram.TelegramClient, config *config.Config) (*TaskHandler, error) {
-	taskExecutor := executor.NewTaskExecutor(repo, registrationRepo, telegramClient, config)
+func NewTaskHandler(repo repo.Task, broadcastRepo repo.Broadcast, userRepo repo.User, telegramClient *telegram.TelegramClient, messageRenderer *telegram.MessageRenderer, serverClient *server.Client, config *config.Config) (*TaskHandler, error) {
+	taskExecutor := executor.NewTaskExecutor(repo, broadcastRepo, userRepo, telegramClient, messageRenderer, serverClient, config)
 	
-	taskScheduler, err := scheduler.NewTaskScheduler(taskExecutor, repo)
+	taskScheduler, err := scheduler.NewTaskScheduler(taskExecutor, repo, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task scheduler: %w", err)
 	}
@@ -49,22 +56,41 @@ func (h *TaskHandler) Stop() error {
 	return h.scheduler.Stop()
 }
 
-func (h *TaskHandler) HandleTaskMessage(ctx context.Context, msg *nats.Msg) (*domain.Task, error) {
-	var natsMsg domain.NATSTaskMessage
-	if err := json.Unmarshal(msg.Data, &natsMsg); err != nil {
-		return nil, fmt.Errorf("failed to parse NATS message: %w", err)
+// HandleTaskMessage сохраняет задачу из сообщения и планирует ее выполнение.
+// Если задача вернулась вместе с ошибкой, она уже сохранена и сообщение можно подтверждать
+func (h *TaskHandler) HandleTaskMessage(ctx context.Context, msg jetstream.Msg) (*domain.Task, error) {
+	var natsMsg taskcontract.Message
+	if err := json.Unmarshal(msg.Data(), &natsMsg); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse NATS message: %v", ErrInvalidMessage, err)
+	}
+	if natsMsg.TaskName == "" {
+		return nil, fmt.Errorf("%w: task_name is empty", ErrInvalidMessage)
+	}
+	// Сообщения, опубликованные до появления версии в контракте, соответствуют версии 1
+	if natsMsg.SchemaVersion == 0 {
+		natsMsg.SchemaVersion = 1
 	}
 	
 	executeAt, err := parseTimeString(natsMsg.ExecuteAt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse execute_at '%s': %w", natsMsg.ExecuteAt, err)
+		return nil, fmt.Errorf("%w: failed to parse execute_at '%s': %v", ErrInvalidMessage, natsMsg.ExecuteAt, err)
+	}
+	
+	// Данные проверяются до сохранения: задача, которую воркер не сможет выполнить, не попадает в БД
+	taskType := taskcontract.TaskType(natsMsg.TaskName)
+	if _, err := taskcontract.Decode(taskType, natsMsg.SchemaVersion, natsMsg.Data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
 	}
 	
 	createTask := &domain.CreateTask{
-		TaskType:   domain.TaskType(natsMsg.TaskName),
-		ExecuteAt:  executeAt,
-		Data:       natsMsg.Data,
-		MaxRetries: 3,
+		TaskType:      taskType,
+		SchemaVersion: natsMsg.SchemaVersion,
+		ExecuteAt:     executeAt,
+		Data:          natsMsg.Data,
+		MaxRetries:    3,
+	}
+	if messageID := msg.Headers().Get(jetstream.MsgIDHeader); messageID != "" {
+		createTask.MessageID = &messageID
 	}
 	
 	taskID, err := h.repo.Create(ctx, createTask)
@@ -73,14 +99,15 @@ func (h *TaskHandler) HandleTaskMessage(ctx context.Context, msg *nats.Msg) (*do
 	}
 	
 	task := &domain.Task{
-		ID:         taskID,
-		TaskType:   createTask.TaskType,
-		Status:     domain.TaskStatusPending,
-		ExecuteAt:  createTask.ExecuteAt,
-		Data:       createTask.Data,
-		MaxRetries: createTask.MaxRetries,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		ID:            taskID,
+		TaskType:      createTask.TaskType,
+		Status:        domain.TaskStatusPending,
+		SchemaVersion: createTask.SchemaVersion,
+		ExecuteAt:     createTask.ExecuteAt,
+		Data:          createTask.Data,
+		MaxRetries:    createTask.MaxRetries,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 	
 	slog.Info("task created from NATS message", 
@@ -96,6 +123,35 @@ func (h *TaskHandler) HandleTaskMessage(ctx context.Context, msg *nats.Msg) (*do
 	return task, nil
 }
 
+// HandleControlMessage обновляет расписание этой реплики после изменения задачи в админке.
+// Повторное выполнение защищено захватом задачи в БД, поэтому команду получают все реплики
+func (h *TaskHandler) HandleControlMessage(ctx context.Context, msg *nats.Msg) error {
+	var control taskcontract.ControlMessage
+	if err := json.Unmarshal(msg.Data, &control); err != nil {
+		return fmt.Errorf("failed to parse control message: %w", err)
+	}
+	
+	if err := h.scheduler.CancelTask(control.TaskID); err != nil {
+		return err
+	}
+	
+	switch control.Action {
+	case taskcontract.ControlCancel:
+		return nil
+	case taskcontract.ControlReschedule:
+		task, err := h.repo.GetByID(ctx, control.TaskID)
+		if err != nil {
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+		if task.Status != domain.TaskStatusPending {
+			return nil
+		}
+		return h.scheduler.ScheduleTask(ctx, task)
+	default:
+		return fmt.Errorf("unknown control action: %s", control.Action)
+	}
+}
+
 func (h *TaskHandler) ExecuteTask(ctx context.Context, task *domain.Task) error {
 	return h.executor.ExecuteTask(ctx, task)
 }
@@ -0,0 +1,231 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gopadel/scheduler/pkg/domain"
+	"gopadel/scheduler/pkg/repo"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BroadcastRepo рассылки создает сервер, воркер только отправляет их и пишет статусы доставки
+type BroadcastRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewBroadcastRepo(db *pgxpool.Pool) *BroadcastRepo {
+	return &BroadcastRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *BroadcastRepo) GetByID(ctx context.Context, id string) (*domain.Broadcast, error) {
+	var broadcast domain.Broadcast
+	var message []byte
+
+	err := r.db.QueryRow(ctx, "SELECT id, message, status FROM broadcasts WHERE id = $1", id).
+		Scan(&broadcast.ID, &message, &broadcast.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.ErrBroadcastNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast %s: %w", id, err)
+	}
+
+	if err := json.Unmarshal(message, &broadcast.Message); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal broadcast message: %w", err)
+	}
+	return &broadcast, nil
+}
+
+// Start переводит рассылку в sending. Повторный запуск (после падения воркера) продолжает отправку
+func (r *BroadcastRepo) Start(ctx context.Context, id string) (bool, error) {
+	tag, err := r.db.Exec(
+		ctx,
+		`UPDATE broadcasts
+		SET status = 'sending', started_at = COALESCE(started_at, NOW() AT TIME ZONE 'UTC')
+		WHERE id = $1 AND status IN ('scheduled', 'sending')`,
+		id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to start broadcast %s: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimRecipients переводит пачку получателей в sending с арендой до now+lease. Берутся получатели в pending,
+// срок которых наступил, и получатели с истекшей арендой, которым сообщение еще не отправлялось
+// (воркер упал, не дойдя до них). Получатель с живой арендой другим запуском не берется
+func (r *BroadcastRepo) ClaimRecipients(ctx context.Context, broadcastID string, limit uint64, lease time.Duration) ([]*domain.BroadcastRecipient, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`UPDATE broadcast_recipients
+		SET status = 'sending', locked_until = NOW() AT TIME ZONE 'UTC' + $3 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM broadcast_recipients
+			WHERE broadcast_id = $1 AND (
+				(status = 'pending' AND (not_before IS NULL OR not_before <= NOW() AT TIME ZONE 'UTC'))
+				OR (status = 'sending' AND attempted_at IS NULL AND locked_until < NOW() AT TIME ZONE 'UTC')
+			)
+			ORDER BY created_at, telegram_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, telegram_id`,
+		broadcastID, limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim broadcast recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []*domain.BroadcastRecipient{}
+	for rows.Next() {
+		var recipient domain.BroadcastRecipient
+		if err := rows.Scan(&recipient.ID, &recipient.TelegramID); err != nil {
+			return nil, fmt.Errorf("failed to scan broadcast recipient: %w", err)
+		}
+		recipients = append(recipients, &recipient)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim broadcast recipients: %w", err)
+	}
+
+	return recipients, nil
+}
+
+// FailInterruptedRecipients помечает failed получателей с истекшей арендой, отправка которым уже начиналась:
+// неизвестно, дошло ли сообщение, а повтор может прислать его дважды
+func (r *BroadcastRepo) FailInterruptedRecipients(ctx context.Context, broadcastID string) (int64, error) {
+	tag, err := r.db.Exec(
+		ctx,
+		`UPDATE broadcast_recipients
+		SET status = 'failed', error = 'delivery interrupted, message may not have been sent', locked_until = NULL
+		WHERE broadcast_id = $1 AND status = 'sending' AND attempted_at IS NOT NULL
+			AND locked_until < NOW() AT TIME ZONE 'UTC'`,
+		broadcastID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted broadcast recipients: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// MarkAttempted отмечает, что отправка получателю началась
+func (r *BroadcastRepo) MarkAttempted(ctx context.Context, id string) error {
+	if _, err := r.db.Exec(ctx, "UPDATE broadcast_recipients SET attempted_at = NOW() AT TIME ZONE 'UTC' WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to mark broadcast recipient attempted: %w", err)
+	}
+	return nil
+}
+
+// DeferRecipient возвращает получателя в pending до notBefore, например до конца его тихих часов
+func (r *BroadcastRepo) DeferRecipient(ctx context.Context, id string, notBefore time.Time) error {
+	query, args, err := r.psql.Update("broadcast_recipients").
+		Set("status", domain.BroadcastRecipientStatusPending).
+		Set("not_before", notBefore.UTC()).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to defer broadcast recipient: %w", err)
+	}
+	return nil
+}
+
+func (r *BroadcastRepo) ReleaseRecipients(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := r.psql.Update("broadcast_recipients").
+		Set("status", domain.BroadcastRecipientStatusPending).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": ids, "status": domain.BroadcastRecipientStatusSending}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to release broadcast recipients: %w", err)
+	}
+	return nil
+}
+
+func (r *BroadcastRepo) SetRecipientStatus(ctx context.Context, id string, status domain.BroadcastRecipientStatus, sendError *string) error {
+	s := r.psql.Update("broadcast_recipients").
+		Set("status", status).
+		Set("error", sendError).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id})
+
+	if status == domain.BroadcastRecipientStatusSent {
+		s = s.Set("sent_at", sq.Expr("NOW() AT TIME ZONE 'UTC'"))
+	}
+
+	query, args, err := s.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to set broadcast recipient status: %w", err)
+	}
+	return nil
+}
+
+// NextRecipientAt ближайшее время, когда у рассылки появится получатель для отправки: конец тихих часов
+// отложенного получателя или окончание аренды получателей в отправке. nil — недоставленных получателей нет
+func (r *BroadcastRepo) NextRecipientAt(ctx context.Context, broadcastID string) (*time.Time, error) {
+	var next pgtype.Timestamp
+	err := r.db.QueryRow(
+		ctx,
+		`SELECT MIN(CASE WHEN status = 'pending' THEN COALESCE(not_before, NOW() AT TIME ZONE 'UTC') ELSE locked_until END)
+		FROM broadcast_recipients
+		WHERE broadcast_id = $1 AND status IN ('pending', 'sending')`,
+		broadcastID,
+	).Scan(&next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next broadcast recipient: %w", err)
+	}
+	if !next.Valid {
+		return nil, nil
+	}
+
+	at := next.Time.UTC()
+	return &at, nil
+}
+
+// Complete завершает рассылку, если недоставленных получателей не осталось.
+// Отмененная во время отправки рассылка остается отмененной
+func (r *BroadcastRepo) Complete(ctx context.Context, id string) error {
+	_, err := r.db.Exec(
+		ctx,
+		`UPDATE broadcasts
+		SET status = 'completed', finished_at = NOW() AT TIME ZONE 'UTC'
+		WHERE id = $1 AND status = 'sending' AND NOT EXISTS (
+			SELECT 1 FROM broadcast_recipients
+			WHERE broadcast_id = $1 AND status IN ('pending', 'sending')
+		)`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete broadcast %s: %w", id, err)
+	}
+	return nil
+}
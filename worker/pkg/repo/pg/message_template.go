@@ -0,0 +1,56 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"gopadel/scheduler/pkg/domain"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MessageTemplateRepo struct {
+	db   *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewMessageTemplateRepo(db *pgxpool.Pool) *MessageTemplateRepo {
+	return &MessageTemplateRepo{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *MessageTemplateRepo) FindByLanguages(ctx context.Context, key string, languages []string) ([]*domain.MessageTemplate, error) {
+	query, args, err := r.psql.Select("key", "language", "body", "parse_mode").
+		From("message_templates").
+		Where(sq.Eq{"key": key, "language": languages}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message templates %s: %w", key, err)
+	}
+	defer rows.Close()
+
+	templates := []*domain.MessageTemplate{}
+	for rows.Next() {
+		var template domain.MessageTemplate
+		var parseMode pgtype.Text
+		if err := rows.Scan(&template.Key, &template.Language, &template.Body, &parseMode); err != nil {
+			return nil, fmt.Errorf("failed to scan message template: %w", err)
+		}
+		template.ParseMode = parseMode.String
+		templates = append(templates, &template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get message templates %s: %w", key, err)
+	}
+
+	return templates, nil
+}
@@ -4,5 +4,7 @@ import "gopadel/scheduler/pkg/repo"
 
 var (
 	_ repo.Task = &TaskRepo{}
-	_ repo.Registration = &RegistrationRepo{}
+	_ repo.Broadcast = &BroadcastRepo{}
+	_ repo.MessageTemplate = &MessageTemplateRepo{}
+	_ repo.User = &UserRepo{}
 )
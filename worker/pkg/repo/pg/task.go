@@ -2,11 +2,15 @@ package pg
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"gopadel/scheduler/pkg/domain"
+	"gopadel/scheduler/pkg/repo"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -26,19 +30,24 @@ func (r *TaskRepo) Create(ctx context.Context, task *domain.CreateTask) (string,
 	var id string
 	err := r.db.QueryRow(
 		ctx,
-		"INSERT INTO tasks (task_type, execute_at, data, max_retries) VALUES ($1, $2, $3, $4) RETURNING id",
+		"INSERT INTO tasks (task_type, schema_version, execute_at, data, max_retries, message_id) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (message_id) DO NOTHING RETURNING id",
 		task.TaskType,
+		task.SchemaVersion,
 		task.ExecuteAt,
 		task.Data,
 		task.MaxRetries,
+		task.MessageID,
 	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", repo.ErrTaskExists
+	}
 	return id, err
 }
 
 func (r *TaskRepo) GetReadyTasks(ctx context.Context) ([]*domain.Task, error) {
 	rows, err := r.db.Query(
 		ctx,
-		`SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries FROM tasks WHERE status = 'pending' AND execute_at <= NOW() AT TIME ZONE 'UTC'`,
+		`SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries, schema_version FROM tasks WHERE status = 'pending' AND execute_at <= NOW() AT TIME ZONE 'UTC'`,
 	)	
 	if err != nil {
 		return nil, err
@@ -47,7 +56,7 @@ func (r *TaskRepo) GetReadyTasks(ctx context.Context) ([]*domain.Task, error) {
 	var tasks []*domain.Task
 	for rows.Next() {
 		var task domain.Task
-		err := rows.Scan(&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.CreatedAt, &task.UpdatedAt, &task.Data, &task.RetryCount, &task.MaxRetries)
+		err := rows.Scan(&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.CreatedAt, &task.UpdatedAt, &task.Data, &task.RetryCount, &task.MaxRetries, &task.SchemaVersion)
 		if err != nil {
 			return nil, err
 		}
@@ -59,7 +68,7 @@ func (r *TaskRepo) GetReadyTasks(ctx context.Context) ([]*domain.Task, error) {
 func (r *TaskRepo) GetPendingTasksNow(ctx context.Context) ([]*domain.Task, error) {
 	rows, err := r.db.Query(
 		ctx,
-		`SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries FROM tasks WHERE status = 'pending' AND execute_at <= NOW() AT TIME ZONE 'UTC'`,
+		`SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries, schema_version FROM tasks WHERE status = 'pending' AND execute_at <= NOW() AT TIME ZONE 'UTC'`,
 	)	
 	if err != nil {
 		return nil, err
@@ -69,7 +78,7 @@ func (r *TaskRepo) GetPendingTasksNow(ctx context.Context) ([]*domain.Task, erro
 	var tasks []*domain.Task
 	for rows.Next() {
 		var task domain.Task
-		err := rows.Scan(&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.CreatedAt, &task.UpdatedAt, &task.Data, &task.RetryCount, &task.MaxRetries)
+		err := rows.Scan(&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.CreatedAt, &task.UpdatedAt, &task.Data, &task.RetryCount, &task.MaxRetries, &task.SchemaVersion)
 		if err != nil {
 			return nil, err
 		}
@@ -81,7 +90,7 @@ func (r *TaskRepo) GetPendingTasksNow(ctx context.Context) ([]*domain.Task, erro
 func (r *TaskRepo) GetPendingTasksFuture(ctx context.Context) ([]*domain.Task, error) {
 	rows, err := r.db.Query(
 		ctx,
-		`SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries FROM tasks WHERE status = 'pending' AND execute_at > NOW() AT TIME ZONE 'UTC' ORDER BY execute_at ASC`,
+		`SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries, schema_version FROM tasks WHERE status = 'pending' AND execute_at > NOW() AT TIME ZONE 'UTC' ORDER BY execute_at ASC`,
 	)	
 	if err != nil {
 		return nil, err
@@ -91,7 +100,7 @@ func (r *TaskRepo) GetPendingTasksFuture(ctx context.Context) ([]*domain.Task, e
 	var tasks []*domain.Task
 	for rows.Next() {
 		var task domain.Task
-		err := rows.Scan(&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.CreatedAt, &task.UpdatedAt, &task.Data, &task.RetryCount, &task.MaxRetries)
+		err := rows.Scan(&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.CreatedAt, &task.UpdatedAt, &task.Data, &task.RetryCount, &task.MaxRetries, &task.SchemaVersion)
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +110,7 @@ func (r *TaskRepo) GetPendingTasksFuture(ctx context.Context) ([]*domain.Task, e
 }
 
 func (r *TaskRepo) CompleteTask(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, "UPDATE tasks SET status = 'completed' WHERE id = $1", id)
+	_, err := r.db.Exec(ctx, "UPDATE tasks SET status = 'completed', locked_by = NULL, locked_until = NULL WHERE id = $1", id)
 	if err != nil {
 		return fmt.Errorf("failed to complete task %s: %w", id, err)
 	}
@@ -109,7 +118,7 @@ func (r *TaskRepo) CompleteTask(ctx context.Context, id string) error {
 }
 
 func (r *TaskRepo) FailTask(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, "UPDATE tasks SET status = 'failed' WHERE id = $1", id)
+	_, err := r.db.Exec(ctx, "UPDATE tasks SET status = 'failed', locked_by = NULL, locked_until = NULL WHERE id = $1", id)
 	if err != nil {
 		return fmt.Errorf("failed to fail task %s: %w", id, err)
 	}
@@ -117,29 +126,31 @@ func (r *TaskRepo) FailTask(ctx context.Context, id string) error {
 }
 
 func (r *TaskRepo) CancelTask(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, "UPDATE tasks SET status = 'cancelled' WHERE id = $1", id)
+	_, err := r.db.Exec(ctx, "UPDATE tasks SET status = 'cancelled', locked_by = NULL, locked_until = NULL WHERE id = $1", id)
 	if err != nil {
 		return fmt.Errorf("failed to cancel task %s: %w", id, err)
 	}
 	return nil
 }
 
-func (r *TaskRepo) FindTasksByUserAndTournament(ctx context.Context, userTelegramID int64, tournamentID string, statuses []domain.TaskStatus) ([]*domain.Task, error) {
+// FindTasksByUserAndEvent задачи пользователя о событии. Задачи, поставленные до перехода на event.*,
+// хранят событие в tournament_id
+func (r *TaskRepo) FindTasksByUserAndEvent(ctx context.Context, userTelegramID int64, eventID string, statuses []domain.TaskStatus) ([]*domain.Task, error) {
 	statusStrings := make([]string, len(statuses))
 	for i, status := range statuses {
 		statusStrings[i] = string(status)
 	}
 	
 	query := `
-		SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries
+		SELECT id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries, schema_version
 		FROM tasks 
 		WHERE data->>'user_telegram_id' = $1 
-		AND data->>'tournament_id' = $2 
+		AND COALESCE(data->>'event_id', data->>'tournament_id') = $2 
 		AND status = ANY($3)
 		ORDER BY created_at DESC
 	`
 	
-	rows, err := r.db.Query(ctx, query, fmt.Sprintf("%.0f", float64(userTelegramID)), tournamentID, statusStrings)
+	rows, err := r.db.Query(ctx, query, fmt.Sprintf("%.0f", float64(userTelegramID)), eventID, statusStrings)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find tasks: %w", err)
 	}
@@ -151,7 +162,7 @@ func (r *TaskRepo) FindTasksByUserAndTournament(ctx context.Context, userTelegra
 		err := rows.Scan(
 			&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, 
 			&task.CreatedAt, &task.UpdatedAt, &task.Data, 
-			&task.RetryCount, &task.MaxRetries,
+			&task.RetryCount, &task.MaxRetries, &task.SchemaVersion,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan task: %w", err)
@@ -168,6 +179,10 @@ func (r *TaskRepo) Patch(ctx context.Context, task *domain.PatchTask) error {
 
 	if task.Status != nil {
 		s = s.Set("status", *task.Status)
+		// Аренда нужна только на время выполнения
+		if *task.Status != domain.TaskStatusProcessing {
+			s = s.Set("locked_by", nil).Set("locked_until", nil)
+		}
 	}
 
 	if task.RetryCount != nil {
@@ -186,6 +201,10 @@ func (r *TaskRepo) Patch(ctx context.Context, task *domain.PatchTask) error {
 		s = s.Set("data", *task.Data)
 	}
 
+	if task.LastError != nil {
+		s = s.Set("last_error", *task.LastError)
+	}
+
 	sql, args, err := s.ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build SQL: %w", err)
@@ -203,3 +222,102 @@ func (r *TaskRepo) Delete(ctx context.Context, id string) error {
 	_, err := r.db.Exec(ctx, "DELETE FROM tasks WHERE id = $1", id)
 	return err
 }
+
+func (r *TaskRepo) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = $1", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task %s: %w", id, err)
+	}
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, repo.ErrTaskNotFound
+	}
+	return tasks[0], nil
+}
+
+const taskColumns = "id, task_type, status, execute_at, created_at, updated_at, data, retry_count, max_retries, schema_version"
+
+// ClaimTask атомарно захватывает задачу для выполнения этим воркером.
+// Если задача уже захвачена, отменена, выполнена или ее время еще не наступило, возвращается repo.ErrTaskNotClaimed
+func (r *TaskRepo) ClaimTask(ctx context.Context, id, workerID string, lease time.Duration) (*domain.Task, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`UPDATE tasks
+		SET status = 'processing', locked_by = $2, locked_until = NOW() AT TIME ZONE 'UTC' + $3 * INTERVAL '1 second'
+		WHERE id = $1 AND status = 'pending' AND execute_at <= NOW() AT TIME ZONE 'UTC'
+		RETURNING `+taskColumns,
+		id, workerID, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim task %s: %w", id, err)
+	}
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, repo.ErrTaskNotClaimed
+	}
+	return tasks[0], nil
+}
+
+// ClaimReadyTasks захватывает до limit готовых к выполнению задач.
+// SKIP LOCKED не дает двум воркерам захватить одну и ту же задачу
+func (r *TaskRepo) ClaimReadyTasks(ctx context.Context, workerID string, lease time.Duration, limit uint64) ([]*domain.Task, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`UPDATE tasks
+		SET status = 'processing', locked_by = $1, locked_until = NOW() AT TIME ZONE 'UTC' + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM tasks
+			WHERE status = 'pending' AND execute_at <= NOW() AT TIME ZONE 'UTC'
+			ORDER BY execute_at ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+taskColumns,
+		workerID, lease.Seconds(), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim ready tasks: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// RecoverExpiredTasks возвращает в pending задачи, аренда которых истекла (воркер упал во время выполнения)
+func (r *TaskRepo) RecoverExpiredTasks(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(
+		ctx,
+		`UPDATE tasks
+		SET status = 'pending', locked_by = NULL, locked_until = NULL
+		WHERE status = 'processing' AND locked_until < NOW() AT TIME ZONE 'UTC'`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recover expired tasks: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanTasks(rows pgx.Rows) ([]*domain.Task, error) {
+	defer rows.Close()
+
+	var tasks []*domain.Task
+	for rows.Next() {
+		var task domain.Task
+		err := rows.Scan(&task.ID, &task.TaskType, &task.Status, &task.ExecuteAt, &task.CreatedAt, &task.UpdatedAt, &task.Data, &task.RetryCount, &task.MaxRetries, &task.SchemaVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, &task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+	return tasks, nil
+}
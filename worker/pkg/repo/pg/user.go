@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gopadel/scheduler/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserRepo пользователей создает сервер, воркер только читает нужные ему поля
+type UserRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUserRepo(db *pgxpool.Pool) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) GetLanguageCode(ctx context.Context, telegramID int64) (string, error) {
+	var languageCode pgtype.Text
+	err := r.db.QueryRow(ctx, "SELECT language_code FROM users WHERE telegram_id = $1", telegramID).Scan(&languageCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user language: %w", err)
+	}
+	return languageCode.String, nil
+}
+
+func (r *UserRepo) GetNotificationSettings(ctx context.Context, telegramID int64) (*domain.NotificationSettings, error) {
+	settings := domain.NotificationSettings{Reminders: true, Payments: true}
+	var quietHoursStart, quietHoursEnd pgtype.Int4
+	var timezone pgtype.Text
+
+	err := r.db.QueryRow(ctx, `
+		SELECT s.muted, s.reminders, s.payments,
+			EXTRACT(HOUR FROM s.quiet_hours_start)::int * 60 + EXTRACT(MINUTE FROM s.quiet_hours_start)::int,
+			EXTRACT(HOUR FROM s.quiet_hours_end)::int * 60 + EXTRACT(MINUTE FROM s.quiet_hours_end)::int,
+			s.timezone
+		FROM user_notification_settings s
+		JOIN users u ON u.id = s.user_id
+		WHERE u.telegram_id = $1`, telegramID).
+		Scan(&settings.Muted, &settings.Reminders, &settings.Payments, &quietHoursStart, &quietHoursEnd, &timezone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	if quietHoursStart.Valid && quietHoursEnd.Valid {
+		start, end := int(quietHoursStart.Int32), int(quietHoursEnd.Int32)
+		settings.QuietHoursStart, settings.QuietHoursEnd = &start, &end
+	}
+	settings.Timezone = timezone.String
+	return &settings, nil
+}
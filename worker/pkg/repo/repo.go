@@ -2,23 +2,68 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"gopadel/scheduler/pkg/domain"
 )
 
+var (
+	// ErrTaskExists задача из этого сообщения уже сохранена (повторная доставка)
+	ErrTaskExists = errors.New("task already exists")
+	// ErrTaskNotClaimed задачу уже захватил другой воркер, она больше не ожидает выполнения или ее срок перенесен
+	ErrTaskNotClaimed = errors.New("task not claimed")
+	ErrTaskNotFound   = errors.New("task not found")
+	ErrBroadcastNotFound = errors.New("broadcast not found")
+)
+
 type Task interface {
 	Create(ctx context.Context, task *domain.CreateTask) (string, error)
+	GetByID(ctx context.Context, id string) (*domain.Task, error)
 	Patch(ctx context.Context, task *domain.PatchTask) error
 	Delete(ctx context.Context, id string) error
 	GetReadyTasks(ctx context.Context) ([]*domain.Task, error)
 	GetPendingTasksNow(ctx context.Context) ([]*domain.Task, error)
 	GetPendingTasksFuture(ctx context.Context) ([]*domain.Task, error)
-	FindTasksByUserAndTournament(ctx context.Context, userTelegramID int64, tournamentID string, statuses []domain.TaskStatus) ([]*domain.Task, error)
+	FindTasksByUserAndEvent(ctx context.Context, userTelegramID int64, eventID string, statuses []domain.TaskStatus) ([]*domain.Task, error)
 	CompleteTask(ctx context.Context, id string) error
 	FailTask(ctx context.Context, id string) error
 	CancelTask(ctx context.Context, id string) error
+	ClaimTask(ctx context.Context, id, workerID string, lease time.Duration) (*domain.Task, error)
+	ClaimReadyTasks(ctx context.Context, workerID string, lease time.Duration, limit uint64) ([]*domain.Task, error)
+	RecoverExpiredTasks(ctx context.Context) (int64, error)
+}
+
+type MessageTemplate interface {
+	// FindByLanguages возвращает варианты шаблона для перечисленных языков
+	FindByLanguages(ctx context.Context, key string, languages []string) ([]*domain.MessageTemplate, error)
 }
 
-type Registration interface {
-	SetCanceledStatus(ctx context.Context, id string) error
-}
\ No newline at end of file
+type User interface {
+	// GetLanguageCode возвращает language_code пользователя или пустую строку
+	GetLanguageCode(ctx context.Context, telegramID int64) (string, error)
+	// GetNotificationSettings возвращает настройки уведомлений или настройки по умолчанию, если их не меняли
+	GetNotificationSettings(ctx context.Context, telegramID int64) (*domain.NotificationSettings, error)
+}
+
+type Broadcast interface {
+	GetByID(ctx context.Context, id string) (*domain.Broadcast, error)
+	// Start переводит рассылку в sending. false, если рассылка отменена или уже завершена
+	Start(ctx context.Context, id string) (bool, error)
+	// ClaimRecipients берет в отправку с арендой lease очередную пачку получателей: pending, срок которых наступил,
+	// и брошенных упавшим воркером, которым сообщение еще не отправлялось
+	ClaimRecipients(ctx context.Context, broadcastID string, limit uint64, lease time.Duration) ([]*domain.BroadcastRecipient, error)
+	// FailInterruptedRecipients помечает failed брошенных получателей, отправка которым уже начиналась
+	FailInterruptedRecipients(ctx context.Context, broadcastID string) (int64, error)
+	// MarkAttempted отмечает начало отправки получателю, после этого он не отправляется повторно
+	MarkAttempted(ctx context.Context, id string) error
+	// DeferRecipient откладывает получателя до notBefore
+	DeferRecipient(ctx context.Context, id string, notBefore time.Time) error
+	// ReleaseRecipients возвращает в pending получателей, которым сообщение так и не отправлялось
+	ReleaseRecipients(ctx context.Context, ids []string) error
+	SetRecipientStatus(ctx context.Context, id string, status domain.BroadcastRecipientStatus, sendError *string) error
+	// NextRecipientAt когда появится следующий получатель для отправки, nil — все получатели обработаны
+	NextRecipientAt(ctx context.Context, broadcastID string) (*time.Time, error)
+	// Complete завершает рассылку, если все получатели обработаны
+	Complete(ctx context.Context, id string) error
+}
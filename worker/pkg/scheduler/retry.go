@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"gopadel/scheduler/pkg/executor"
+
+	"github.com/go-telegram/bot"
+)
+
+// isPermanentError ошибки, которые не исчезнут при повторе: пользователь заблокировал бота,
+// чат не найден или в задаче нет нужных данных
+func isPermanentError(err error) bool {
+	return errors.Is(err, executor.ErrInvalidTaskData) ||
+		errors.Is(err, bot.ErrorForbidden) ||
+		errors.Is(err, bot.ErrorBadRequest)
+}
+
+// telegramRetryAfter возвращает retry_after из ответа Telegram 429
+func telegramRetryAfter(err error) (time.Duration, bool) {
+	var tooManyRequests *bot.TooManyRequestsError
+	if !errors.As(err, &tooManyRequests) {
+		return 0, false
+	}
+	return time.Duration(tooManyRequests.RetryAfter) * time.Second, true
+}
+
+// backoffDelay экспоненциальная задержка перед попыткой attempt (с 1) с джиттером:
+// половина задержки фиксирована, вторая половина случайна, чтобы повторы не шли пачкой
+func backoffDelay(attempt int, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gopadel/scheduler/pkg/executor"
+
+	"github.com/go-telegram/bot"
+)
+
+func TestBackoffDelayGrowsExponentiallyWithJitter(t *testing.T) {
+	base := time.Second
+	maxDelay := time.Minute
+
+	for attempt, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		6: 32 * time.Second,
+	} {
+		for range 100 {
+			delay := backoffDelay(attempt, base, maxDelay)
+			if delay < want/2 || delay >= want {
+				t.Fatalf("attempt %d: expected delay in [%s, %s), got %s", attempt, want/2, want, delay)
+			}
+		}
+	}
+}
+
+func TestBackoffDelayIsCappedByMaxDelay(t *testing.T) {
+	base := time.Second
+	maxDelay := 10 * time.Second
+
+	for _, attempt := range []int{5, 10, 100} {
+		for range 100 {
+			delay := backoffDelay(attempt, base, maxDelay)
+			if delay < maxDelay/2 || delay >= maxDelay {
+				t.Fatalf("attempt %d: expected delay in [%s, %s), got %s", attempt, maxDelay/2, maxDelay, delay)
+			}
+		}
+	}
+}
+
+func TestBackoffDelayWithoutJitterRange(t *testing.T) {
+	if delay := backoffDelay(1, time.Nanosecond, time.Second); delay != time.Nanosecond {
+		t.Errorf("expected %s, got %s", time.Nanosecond, delay)
+	}
+	if delay := backoffDelay(3, 0, time.Second); delay != 0 {
+		t.Errorf("expected zero delay for zero base, got %s", delay)
+	}
+}
+
+func TestIsPermanentError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"invalid task data", fmt.Errorf("%w: missing event_id", executor.ErrInvalidTaskData), true},
+		{"bot blocked by user", fmt.Errorf("failed to send message: %w", bot.ErrorForbidden), true},
+		{"chat not found", fmt.Errorf("failed to send message: %w", bot.ErrorBadRequest), true},
+		{"too many requests", &bot.TooManyRequestsError{Message: "slow down", RetryAfter: 3}, false},
+		{"network error", errors.New("connection reset by peer"), false},
+		{"server unavailable", fmt.Errorf("request failed: %w", errors.New("status 502")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPermanentError(tt.err); got != tt.want {
+				t.Errorf("isPermanentError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTelegramRetryAfter(t *testing.T) {
+	delay, ok := telegramRetryAfter(fmt.Errorf("failed to send message: %w", &bot.TooManyRequestsError{Message: "slow down", RetryAfter: 7}))
+	if !ok || delay != 7*time.Second {
+		t.Errorf("expected 7s retry_after, got %s (ok=%v)", delay, ok)
+	}
+
+	if _, ok := telegramRetryAfter(errors.New("connection reset by peer")); ok {
+		t.Errorf("expected no retry_after for a non-429 error")
+	}
+}
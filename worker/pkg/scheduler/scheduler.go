@@ -2,10 +2,13 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"gopadel/scheduler/cmd/config"
 	"gopadel/scheduler/pkg/domain"
 	"gopadel/scheduler/pkg/executor"
 	"gopadel/scheduler/pkg/repo"
@@ -13,35 +16,58 @@ import (
 	"github.com/go-co-op/gocron/v2"
 )
 
+// TaskScheduler планирует задачи в памяти через gocron, но выполняет задачу только после
+// того, как захватил ее в БД. Поэтому воркер можно запускать в нескольких репликах:
+// gocron-задания нескольких реплик на одну задачу не приводят к повторной отправке,
+// а задачи упавшей реплики подбирает периодический опрос БД
 type TaskScheduler struct {
 	scheduler gocron.Scheduler
 	executor  *executor.TaskExecutor
 	repo      repo.Task
 	jobs      map[string]gocron.Job 
+	jobsMu    sync.Mutex
+
+	workerID      string
+	leaseDuration time.Duration
+	pollInterval  time.Duration
+	pollBatchSize uint64
+
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
 }
 
-func NewTaskScheduler(executor *executor.TaskExecutor, repo repo.Task) (*TaskScheduler, error) {
+func NewTaskScheduler(executor *executor.TaskExecutor, repo repo.Task, config *config.Config) (*TaskScheduler, error) {
 	scheduler, err := gocron.NewScheduler()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gocron scheduler: %w", err)
 	}
 
 	return &TaskScheduler{
-		scheduler: scheduler,
-		executor:  executor,
-		repo:      repo,
-		jobs:      make(map[string]gocron.Job),
+		scheduler:     scheduler,
+		executor:      executor,
+		repo:          repo,
+		jobs:          make(map[string]gocron.Job),
+		workerID:      config.WorkerID(),
+		leaseDuration: config.Worker.LeaseDuration,
+		pollInterval:  config.Worker.PollInterval,
+		pollBatchSize: config.Worker.PollBatchSize,
+
+		retryBaseDelay: config.Worker.RetryBaseDelay,
+		retryMaxDelay:  config.Worker.RetryMaxDelay,
 	}, nil
 }
 
 func (s *TaskScheduler) Start(ctx context.Context) error {
-	slog.Info("Starting task scheduler")
+	slog.Info("Starting task scheduler", "worker_id", s.workerID)
 	
 	if err := s.loadExistingTasks(ctx); err != nil {
 		return fmt.Errorf("failed to load existing tasks: %w", err)
 	}
 
 	s.scheduler.Start()
+	if s.pollInterval > 0 {
+		go s.poller(ctx)
+	}
 	slog.Info("Task scheduler started")
 	return nil
 }
@@ -115,7 +141,9 @@ func (s *TaskScheduler) scheduleTask(ctx context.Context, task *domain.Task) err
 		return fmt.Errorf("failed to create job for task %s: %w", task.ID, err)
 	}
 
+	s.jobsMu.Lock()
 	s.jobs[task.ID] = job
+	s.jobsMu.Unlock()
 	slog.Info("Task scheduled", 
 		"task_id", task.ID, 
 		"task_type", task.TaskType,
@@ -125,7 +153,12 @@ func (s *TaskScheduler) scheduleTask(ctx context.Context, task *domain.Task) err
 }
 
 func (s *TaskScheduler) CancelTask(taskID string) error {
+	s.jobsMu.Lock()
 	job, exists := s.jobs[taskID]
+	if exists {
+		delete(s.jobs, taskID)
+	}
+	s.jobsMu.Unlock()
 	if !exists {
 		slog.Debug("Task not found in scheduler, might already be executed or cancelled", "task_id", taskID)
 		return nil
@@ -135,54 +168,38 @@ func (s *TaskScheduler) CancelTask(taskID string) error {
 		return fmt.Errorf("failed to remove job for task %s: %w", taskID, err)
 	}
 
-	delete(s.jobs, taskID)
 	slog.Info("Task cancelled from scheduler", "task_id", taskID)
 	return nil
 }
 
+// executeTask захватывает задачу и выполняет ее. Если задачу уже захватила другая реплика
+// или она отменена, выполнение пропускается
 func (s *TaskScheduler) executeTask(ctx context.Context, task *domain.Task) error {
-	defer func() {
-		if _, exists := s.jobs[task.ID]; exists {
-			delete(s.jobs, task.ID)
-		}
-	}()
+	s.jobsMu.Lock()
+	delete(s.jobs, task.ID)
+	s.jobsMu.Unlock()
 
-	patchTask := &domain.PatchTask{
-		ID:     task.ID,
-		Status: &[]domain.TaskStatus{domain.TaskStatusProcessing}[0],
+	claimed, err := s.repo.ClaimTask(ctx, task.ID, s.workerID, s.leaseDuration)
+	if errors.Is(err, repo.ErrTaskNotClaimed) {
+		slog.Debug("Task already claimed, no longer pending or not due yet, skipping", "task_id", task.ID)
+		return nil
 	}
-	
-	if err := s.repo.Patch(ctx, patchTask); err != nil {
-		return fmt.Errorf("failed to update task status to processing: %w", err)
+	if err != nil {
+		return fmt.Errorf("failed to claim task: %w", err)
 	}
 
+	return s.runClaimedTask(ctx, claimed)
+}
+
+// runClaimedTask выполняет захваченную этим воркером задачу
+func (s *TaskScheduler) runClaimedTask(ctx context.Context, task *domain.Task) error {
 	if err := s.executor.ExecuteTask(ctx, task); err != nil {
-		task.RetryCount++
-		
-		if task.RetryCount >= task.MaxRetries {
-			if failErr := s.repo.FailTask(ctx, task.ID); failErr != nil {
-				slog.Error("failed to mark task as failed", "task_id", task.ID, "error", failErr)
-			}
-		} else {
-			retryDelay := time.Duration(task.RetryCount) * time.Minute
-			newExecuteAt := time.Now().UTC().Add(retryDelay)
-			
-			retryPatch := &domain.PatchTask{
-				ID:         task.ID,
-				Status:     &[]domain.TaskStatus{domain.TaskStatusPending}[0],
-				RetryCount: &task.RetryCount,
-				ExecuteAt:  &newExecuteAt,
-			}
-			
-			if patchErr := s.repo.Patch(ctx, retryPatch); patchErr != nil {
-				slog.Error("failed to reschedule task", "task_id", task.ID, "error", patchErr)
-			} else {
-				task.ExecuteAt = newExecuteAt
-				if schedErr := s.scheduleTask(ctx, task); schedErr != nil {
-					slog.Error("failed to reschedule task in scheduler", "task_id", task.ID, "error", schedErr)
-				}
-			}
+		var deferred *executor.DeferredError
+		if errors.As(err, &deferred) {
+			s.deferTask(ctx, task, deferred)
+			return nil
 		}
+		s.handleTaskError(ctx, task, err)
 		return err
 	}
 
@@ -192,4 +209,137 @@ func (s *TaskScheduler) executeTask(ctx context.Context, task *domain.Task) erro
 
 	slog.Info("Task completed successfully", "task_id", task.ID, "task_type", task.TaskType)
 	return nil
-} 
\ No newline at end of file
+}
+
+// handleTaskError планирует повтор упавшей задачи или переводит ее в dead.
+// Ответ 429 от Telegram не расходует попытку: задача повторяется через retry_after
+func (s *TaskScheduler) handleTaskError(ctx context.Context, task *domain.Task, taskErr error) {
+	lastError := taskErr.Error()
+
+	retryAfter, rateLimited := telegramRetryAfter(taskErr)
+	if !rateLimited {
+		task.RetryCount++
+	}
+
+	if isPermanentError(taskErr) || task.RetryCount >= task.MaxRetries {
+		deadPatch := &domain.PatchTask{
+			ID:         task.ID,
+			Status:     &[]domain.TaskStatus{domain.TaskStatusDead}[0],
+			RetryCount: &task.RetryCount,
+			LastError:  &lastError,
+		}
+		if patchErr := s.repo.Patch(ctx, deadPatch); patchErr != nil {
+			slog.Error("failed to mark task as dead", "task_id", task.ID, "error", patchErr)
+			return
+		}
+		slog.Error("Task moved to dead state",
+			"task_id", task.ID,
+			"task_type", task.TaskType,
+			"retry_count", task.RetryCount,
+			"error", taskErr)
+		return
+	}
+
+	retryDelay := backoffDelay(task.RetryCount, s.retryBaseDelay, s.retryMaxDelay)
+	if rateLimited {
+		// Небольшой джиттер, чтобы задачи, получившие одинаковый retry_after, не упирались в лимит снова
+		retryDelay = retryAfter + backoffDelay(1, time.Second, time.Second)
+	}
+	newExecuteAt := time.Now().UTC().Add(retryDelay)
+
+	retryPatch := &domain.PatchTask{
+		ID:         task.ID,
+		Status:     &[]domain.TaskStatus{domain.TaskStatusPending}[0],
+		RetryCount: &task.RetryCount,
+		ExecuteAt:  &newExecuteAt,
+		LastError:  &lastError,
+	}
+	if patchErr := s.repo.Patch(ctx, retryPatch); patchErr != nil {
+		slog.Error("failed to reschedule task", "task_id", task.ID, "error", patchErr)
+		return
+	}
+
+	slog.Warn("Task failed, retry scheduled",
+		"task_id", task.ID,
+		"task_type", task.TaskType,
+		"retry_count", task.RetryCount,
+		"retry_in", retryDelay,
+		"error", taskErr)
+
+	task.ExecuteAt = newExecuteAt
+	if schedErr := s.scheduleTask(ctx, task); schedErr != nil {
+		slog.Error("failed to reschedule task in scheduler", "task_id", task.ID, "error", schedErr)
+	}
+}
+
+// deferTask возвращает задачу в pending на время, которое назвал исполнитель, не расходуя попытку
+func (s *TaskScheduler) deferTask(ctx context.Context, task *domain.Task, deferred *executor.DeferredError) {
+	executeAt := deferred.Until.UTC()
+	patch := &domain.PatchTask{
+		ID:        task.ID,
+		Status:    &[]domain.TaskStatus{domain.TaskStatusPending}[0],
+		ExecuteAt: &executeAt,
+	}
+	if err := s.repo.Patch(ctx, patch); err != nil {
+		slog.Error("failed to defer task", "task_id", task.ID, "error", err)
+		return
+	}
+
+	slog.Info("Task deferred",
+		"task_id", task.ID,
+		"task_type", task.TaskType,
+		"execute_at", executeAt,
+		"reason", deferred.Reason)
+
+	task.ExecuteAt = executeAt
+	if err := s.scheduleTask(ctx, task); err != nil {
+		slog.Error("failed to reschedule deferred task", "task_id", task.ID, "error", err)
+	}
+}
+
+// poller периодически возвращает задачи с истекшей арендой и выполняет готовые задачи,
+// которые не были запланированы в этой реплике
+func (s *TaskScheduler) poller(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx)
+		}
+	}
+}
+
+func (s *TaskScheduler) poll(ctx context.Context) {
+	recovered, err := s.repo.RecoverExpiredTasks(ctx)
+	if err != nil {
+		slog.Error("failed to recover expired tasks", "error", err)
+	} else if recovered > 0 {
+		slog.Warn("Recovered tasks with expired lease", "count", recovered)
+	}
+
+	tasks, err := s.repo.ClaimReadyTasks(ctx, s.workerID, s.leaseDuration, s.pollBatchSize)
+	if err != nil {
+		slog.Error("failed to claim ready tasks", "error", err)
+		return
+	}
+
+	for _, task := range tasks {
+		s.jobsMu.Lock()
+		job, exists := s.jobs[task.ID]
+		delete(s.jobs, task.ID)
+		s.jobsMu.Unlock()
+		if exists {
+			_ = s.scheduler.RemoveJob(job.ID())
+		}
+
+		go func(t *domain.Task) {
+			if err := s.runClaimedTask(ctx, t); err != nil {
+				slog.Error("failed to execute claimed task", "task_id", t.ID, "error", err)
+			}
+		}(task)
+	}
+}
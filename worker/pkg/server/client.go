@@ -0,0 +1,70 @@
+// Package server запросы воркера к серверу через NATS request/reply. Сервер отвечает
+// {"error": "..."} или пустым объектом, если запрос обработан
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gopadel/scheduler/cmd/config"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Запросы, которые обрабатывает сервер (server-go/pkg/notifications/requests.go)
+const (
+	RequestWaitlistOfferExpire       = "waitlist.offer.expire"
+	RequestRegistrationPaymentExpire = "registration.payment.expire"
+)
+
+type reply struct {
+	Error string `json:"error,omitempty"`
+}
+
+type Client struct {
+	nc     *nats.Conn
+	config *config.Config
+}
+
+func NewClient(nc *nats.Conn, config *config.Config) *Client {
+	return &Client{nc: nc, config: config}
+}
+
+// ExpireWaitlistOffer просит сервер закрыть предложение места из листа ожидания и предложить место следующему
+func (c *Client) ExpireWaitlistOffer(ctx context.Context, offerID string) error {
+	return c.request(ctx, RequestWaitlistOfferExpire, map[string]string{"offer_id": offerID})
+}
+
+// ExpireRegistrationPayment просит сервер отменить регистрацию пользователя на событие, если она не оплачена
+func (c *Client) ExpireRegistrationPayment(ctx context.Context, userTelegramID int64, eventID string) error {
+	return c.request(ctx, RequestRegistrationPaymentExpire, map[string]any{
+		"user_telegram_id": userTelegramID,
+		"event_id":         eventID,
+	})
+}
+
+func (c *Client) request(ctx context.Context, request string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", request, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.NATS.ServerRequestTimeout)
+	defer cancel()
+
+	subject := c.config.NATS.ServerRequestSubjectPrefix + "." + request
+	msg, err := c.nc.RequestWithContext(ctx, subject, data)
+	if err != nil {
+		return fmt.Errorf("server request %s failed: %w", subject, err)
+	}
+
+	var r reply
+	if err := json.Unmarshal(msg.Data, &r); err != nil {
+		return fmt.Errorf("invalid server reply to %s: %w", subject, err)
+	}
+	if r.Error != "" {
+		return fmt.Errorf("server rejected %s: %s", subject, r.Error)
+	}
+	return nil
+}
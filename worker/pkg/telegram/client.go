@@ -29,8 +29,10 @@ func (c *TelegramClient) SendMessage(ctx context.Context, recipient domain.Recip
 	switch message.Type {
 	case domain.MessageTypeText:
 		_, err := c.bot.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: recipient.ChatID,
-			Text:   message.Text.Text,
+			ChatID:      recipient.ChatID,
+			Text:        message.Text.Text,
+			ParseMode:   models.ParseMode(message.Text.ParseMode),
+			ReplyMarkup: inlineKeyboard(message.Text.Buttons),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to send message: %w", err)
@@ -81,4 +83,23 @@ func (c *TelegramClient) SendMessage(ctx context.Context, recipient domain.Recip
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+func inlineKeyboard(buttons [][]domain.MessageButton) models.ReplyMarkup {
+	if len(buttons) == 0 {
+		return nil
+	}
+
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(buttons))
+	for _, row := range buttons {
+		keyboardRow := make([]models.InlineKeyboardButton, 0, len(row))
+		for _, button := range row {
+			keyboardRow = append(keyboardRow, models.InlineKeyboardButton{
+				Text:         button.Text,
+				CallbackData: button.CallbackData,
+				URL:          button.URL,
+			})
+		}
+		keyboard = append(keyboard, keyboardRow)
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
@@ -1,131 +1,172 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
 	"gopadel/scheduler/cmd/config"
 	"gopadel/scheduler/pkg/domain"
+	"gopadel/scheduler/pkg/repo"
+	"gopadel/taskcontract"
 )
 
+// defaultTemplateKey шаблон для типов задач, у которых нет своего шаблона
+const defaultTemplateKey = "default"
+
+// fallbackMessage отправляется, если в БД нет ни шаблона задачи, ни шаблона по умолчанию
+const fallbackMessage = "🏓 У нас есть новости для вас!\n\nПроверьте приложение GoPadel для получения подробной информации."
+
+// startTimeLocalLayout формат времени начала события в часовом поясе корта
+const startTimeLocalLayout = "02.01.2006 15:04"
+
 func GenerateTournamentURL(config *config.Config, tournamentID string) string {
 	return fmt.Sprintf("%s?startapp=tour-%s", config.TelegramWebAppURL(), tournamentID)
 }
 
-func GetMessageForTaskType(taskType domain.TaskType, data map[string]interface{}, config *config.Config) *domain.MessageText {
-	tournamentID := ""
-	if id, ok := data["tournament_id"].(string); ok {
-		tournamentID = id
+func GenerateEventURL(config *config.Config, eventID string) string {
+	return fmt.Sprintf("%s?startapp=%s", config.TelegramWebAppURL(), eventID)
+}
+
+// MessageRenderer строит текст уведомления по шаблону из БД на языке пользователя.
+// Тексты редактируются в админке, ключ шаблона — тип задачи
+type MessageRenderer struct {
+	templates repo.MessageTemplate
+	users     repo.User
+	config    *config.Config
+}
+
+func NewMessageRenderer(templates repo.MessageTemplate, users repo.User, config *config.Config) *MessageRenderer {
+	return &MessageRenderer{
+		templates: templates,
+		users:     users,
+		config:    config,
 	}
-	tournamentURL := GenerateTournamentURL(config, tournamentID)
-
-	switch taskType {
-	case domain.TaskTypeTournamentRegistrationSuccess:
-		isFree, _ := data["is_free"].(bool)
-		if isFree {
-			return &domain.MessageText{
-				Text: fmt.Sprintf(
-					"🎉 Поздравляем! Вы успешно зарегистрированы на бесплатный турнир '%s'!\n\n✅ Ваше место забронировано\n🆓 Участие абсолютно бесплатное\n📅 Не забудьте прийти вовремя\n\nДо встречи на корте! 🏓\n\n%s",
-					data["tournament_name"],
-					tournamentURL,
-				),
-			}
-		} else {
-			return &domain.MessageText{
-				Text: fmt.Sprintf(
-					"🎉 Поздравляем! Вы успешно зарегистрированы на турнир '%s'!\n\n✅ Ваше место забронировано\n💡 Не забудьте оплатить участие в течение 24 часов\n\nДо встречи на корте! 🏓\n\n%s",
-					data["tournament_name"],
-					tournamentURL,
-				),
-			}
-		}
-	
-	case domain.TaskTypeTournamentReminder48Hours:
-		isPaid, _ := data["is_paid"].(bool)
-		if isPaid {
-			return &domain.MessageText{
-				Text: fmt.Sprintf(
-					"🎾 Скоро большая игра!\n\nЧерез 2 дня стартует турнир '%s'!\n\n✅ Все готово к вашему участию\n🏓 Готовьте ракетки и настройтесь на победу!\n\nУвидимся на корте! 💪\n\n%s",
-					data["tournament_name"],
-					tournamentURL,
-				),
-			}
-		} else {
-			return &domain.MessageText{
-				Text: fmt.Sprintf(
-					"⚡️ Внимание! Осталось 2 дня до турнира '%s'\n\n⏰ Ваша регистрация пока не оплачена\n💳 Завершите оплату прямо сейчас, чтобы гарантированно участвовать в турнире\n\n👆 Нажмите на ссылку ниже:\n\n%s",
-					data["tournament_name"],
-					tournamentURL,
-				),
-			}
-		}
-	
-	case domain.TaskTypeTournamentReminder24Hours:
-		isPaid, _ := data["is_paid"].(bool)
-		if isPaid {
-			return &domain.MessageText{
-				Text: fmt.Sprintf(
-					"🔥 Завтра ваш турнир!\n\nТурнир '%s' стартует уже завтра!\n\n🎯 Вы готовы показать свою лучшую игру\n⏰ Не забудьте прийти вовремя\n🏓 Возьмите ракетку и хорошее настроение!\n\nУдачи на турнире! 🏆\n\n%s",
-					data["tournament_name"],
-					tournamentURL,
-				),
-			}
-		} else {
-			return &domain.MessageText{
-				Text: fmt.Sprintf(
-					"🚨 Последний шанс!\n\nДо турнира '%s' остался всего 1 день!\n\n💳 Срочно завершите оплату, чтобы не потерять свое место\n⚠️ Без оплаты регистрация будет автоматически отменена\n\n👇 Оплатить можно здесь:\n\n%s",
-					data["tournament_name"],
-					tournamentURL,
-				),
-			}
-		}
-	
-	case domain.TaskTypeTournamentFreeReminder48Hours:
-		return &domain.MessageText{
-			Text: fmt.Sprintf(
-				"🎉 Отличная новость!\n\nЧерез 2 дня стартует бесплатный турнир '%s'!\n\n🆓 Участие абсолютно бесплатное\n✅ Вы уже зарегистрированы\n🏓 Просто приходите и играйте!\n\n📅 Добавьте турнир в календарь, чтобы не забыть\n\nВстретимся на корте! 🤝\n\n%s",
-				data["tournament_name"],
-				tournamentURL,
-			),
+}
+
+// Render выбирает вариант шаблона по цепочке языков: language_code получателя payload, базовый язык,
+// язык по умолчанию. Данные шаблона — данные задачи и tournament_url, а для задач о событии
+// еще event_url и start_time_local — время начала в часовом поясе корта
+func (r *MessageRenderer) Render(ctx context.Context, taskType taskcontract.TaskType, payload taskcontract.UserNotification, data map[string]interface{}) (*domain.MessageText, error) {
+	templateData := make(map[string]interface{}, len(data)+3)
+	for k, v := range data {
+		templateData[k] = v
+	}
+	tournamentID, _ := data["tournament_id"].(string)
+	templateData["tournament_url"] = GenerateTournamentURL(r.config, tournamentID)
+	if eventID, ok := data["event_id"].(string); ok {
+		templateData["event_url"] = GenerateEventURL(r.config, eventID)
+	}
+	if startTime, ok := data["start_time"].(string); ok {
+		timezone, _ := data["court_timezone"].(string)
+		templateData["start_time_local"] = localStartTime(startTime, timezone)
+	}
+
+	languages := languageChain(r.userLanguage(ctx, payload.TelegramID()), r.config.Messages.DefaultLanguage)
+
+	for _, key := range []string{string(taskType), defaultTemplateKey} {
+		tmpl, err := r.findTemplate(ctx, key, languages)
+		if err != nil {
+			return nil, err
 		}
-	
-	case domain.TaskTypeTournamentPaymentSuccess:
-		return &domain.MessageText{
-			Text: fmt.Sprintf(
-				"🎉 Отлично! Оплата прошла успешно!\n\nВы официально участвуете в турнире '%s'!\n\n✅ Место забронировано\n🏆 Готовьтесь к победе\n📱 Следите за обновлениями в приложении\n\nУдачи на турнире! 💪\n\n%s",
-				data["tournament_name"],
-				tournamentURL,
-			),
+		if tmpl == nil {
+			continue
 		}
-	
-	case domain.TaskTypeTournamentRegistrationCanceled:
-		return &domain.MessageText{
-			Text: fmt.Sprintf(
-				"😔 Ваша регистрация отменена\n\nРегистрация на турнир '%s' была отменена.\n\n🔄 Вы можете зарегистрироваться снова, если есть свободные места\n📱 Проверьте актуальную информацию в приложении\n\nНе расстраивайтесь - впереди еще много турниров! 💪\n\n%s",
-				data["tournament_name"],
-				tournamentURL,
-			),
+
+		text, err := renderTemplate(tmpl, templateData)
+		if err != nil {
+			return nil, err
 		}
-	
-	case domain.TaskTypeTournamentLoyaltyChanged:
-		return &domain.MessageText{
-			Text: fmt.Sprintf(
-				"🎉 Отличные новости!\n\nВаш уровень лояльности повышен!\n\n📈 Было: %s\n⭐️ Стало: %s\n\n🎁 Теперь вам доступны новые преимущества и скидки!\n📱 Проверьте их в разделе \"Лояльность\"\n\nПродолжайте играть и достигайте новых высот! 🚀",
-				data["old_level"],
-				data["new_level"],
-			),
+		return &domain.MessageText{Text: text, ParseMode: tmpl.ParseMode}, nil
+	}
+
+	slog.Warn("message template not found, using fallback", "task_type", taskType, "languages", languages)
+	return &domain.MessageText{Text: fallbackMessage}, nil
+}
+
+// localStartTime время начала события в часовом поясе корта. Если пояс неизвестен, время выводится в UTC
+func localStartTime(startTime, timezone string) string {
+	t, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		slog.Warn("failed to parse event start time", "start_time", startTime, "error", err)
+		return startTime
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		slog.Warn("unknown court timezone, using UTC", "court_timezone", timezone, "error", err)
+		location = time.UTC
+	}
+	return t.In(location).Format(startTimeLocalLayout)
+}
+
+func (r *MessageRenderer) userLanguage(ctx context.Context, chatID int64) string {
+	languageCode, err := r.users.GetLanguageCode(ctx, chatID)
+	if err != nil {
+		// Без языка пользователя уведомление уйдет на языке по умолчанию
+		slog.Warn("failed to get user language", "user_telegram_id", chatID, "error", err)
+		return ""
+	}
+	return languageCode
+}
+
+func (r *MessageRenderer) findTemplate(ctx context.Context, key string, languages []string) (*domain.MessageTemplate, error) {
+	templates, err := r.templates.FindByLanguages(ctx, key, languages)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, language := range languages {
+		for _, tmpl := range templates {
+			if tmpl.Language == language {
+				return tmpl, nil
+			}
 		}
-	
-	case domain.TaskTypeTournamentRegistrationAutoDeleteUnpaid:
-		return &domain.MessageText{
-			Text: fmt.Sprintf(
-				"⏰ Время вышло...\n\nК сожалению, ваша регистрация на турнир '%s' была отменена из-за неоплаты.\n\n💡 В следующий раз не забудьте оплатить участие вовремя\n🔄 Если есть свободные места, вы можете зарегистрироваться повторно\n\nДо встречи на следующих турнирах! 🏓\n\n%s",
-				data["tournament_name"],
-				tournamentURL,
-			),
+	}
+	return nil, nil
+}
+
+func renderTemplate(tmpl *domain.MessageTemplate, data map[string]interface{}) (string, error) {
+	t, err := template.New(tmpl.Key).Parse(tmpl.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse message template %s/%s: %w", tmpl.Key, tmpl.Language, err)
+	}
+
+	var text strings.Builder
+	if err := t.Execute(&text, data); err != nil {
+		return "", fmt.Errorf("failed to render message template %s/%s: %w", tmpl.Key, tmpl.Language, err)
+	}
+	return text.String(), nil
+}
+
+// languageChain язык пользователя, его базовый язык (pt для pt-br) и язык по умолчанию без повторов
+func languageChain(languageCode, defaultLanguage string) []string {
+	languageCode = normalizeLanguage(languageCode)
+
+	chain := make([]string, 0, 3)
+	add := func(language string) {
+		if language == "" {
+			return
 		}
-	
-	default:
-		return &domain.MessageText{
-			Text: "🏓 У нас есть новости для вас!\n\nПроверьте приложение GoPadel для получения подробной информации.",
+		for _, l := range chain {
+			if l == language {
+				return
+			}
 		}
+		chain = append(chain, language)
+	}
+
+	add(languageCode)
+	if base, _, ok := strings.Cut(languageCode, "-"); ok {
+		add(base)
 	}
-}
\ No newline at end of file
+	add(normalizeLanguage(defaultLanguage))
+	return chain
+}
+
+func normalizeLanguage(language string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(language)), "_", "-")
+}